@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// outcomes of an eviction attempt
+type EvictionOutcome string
+
+const (
+	// the eviction request was accepted
+	EvictionOutcomeEvicted EvictionOutcome = "Evicted"
+	// the eviction request was rejected
+	EvictionOutcomeFailed EvictionOutcome = "Failed"
+	// the pod was deleted outright after its PodDisruptionBudget blocked its eviction for too long
+	EvictionOutcomeForceDeleted EvictionOutcome = "ForceDeleted"
+)
+
+// describes a single eviction attempted by the controller
+type EvictionRecordSpec struct {
+	Pod    string    `json:"pod"`
+	PodUID types.UID `json:"podUID"`
+	// node the pod was evicted from
+	Node string `json:"node"`
+	// why the pod was selected for eviction
+	Reason string `json:"reason"`
+	// workload profile governing the pod
+	// +optional
+	Profile string `json:"profile,omitempty"`
+	// QoS class of the pod at the time of the eviction
+	QOSClass core.PodQOSClass `json:"qosClass"`
+	// RebalancePlan the eviction was carried out for
+	// +optional
+	Plan string `json:"plan,omitempty"`
+	// time the eviction was attempted
+	Time    meta.Time       `json:"time"`
+	Outcome EvictionOutcome `json:"outcome"`
+	// error returned for failed evictions
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=evictionrecords,scope=Namespaced,singular=evictionrecord,shortName=er
+// +kubebuilder:printcolumn:name="Pod",type="string",JSONPath=".spec.pod",description="Evicted pod"
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".spec.node",description="Node the pod was evicted from"
+// +kubebuilder:printcolumn:name="Profile",type="string",JSONPath=".spec.profile",description="Workload profile governing the pod"
+// +kubebuilder:printcolumn:name="Outcome",type="string",JSONPath=".spec.outcome",description="Outcome of the eviction"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// audit entry for an eviction attempted by the controller, kept in the evicted pod's namespace until its TTL lapses
+type EvictionRecord struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EvictionRecordSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// list of several EvictionRecord
+type EvictionRecordList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []EvictionRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EvictionRecord{}, &EvictionRecordList{})
+}
@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// lifecycle phases of a NodeDrain
+type NodeDrainPhase string
+
+const (
+	// the node is cordoned and its evictable pods are being evicted
+	NodeDrainDraining NodeDrainPhase = "Draining"
+	// no evictable pod is left on the node
+	NodeDrainCompleted NodeDrainPhase = "Completed"
+	// the node recovered before the drain completed, and was uncordoned
+	NodeDrainCancelled NodeDrainPhase = "Cancelled"
+)
+
+// describes the drain of a degraded node
+type NodeDrainSpec struct {
+	// drained node
+	Node string `json:"node"`
+	// degradation the node is drained for
+	Reason string `json:"reason"`
+	// severity of the node's degradation
+	// +optional
+	Severity string `json:"severity,omitempty"`
+}
+
+// defines the observed state of NodeDrain
+type NodeDrainStatus struct {
+	// +optional
+	Phase NodeDrainPhase `json:"phase,omitempty"`
+	// time the node was cordoned
+	// +optional
+	StartTime *meta.Time `json:"startTime,omitempty"`
+	// time the last evictable pod left the node, or the node recovered
+	// +optional
+	CompletionTime *meta.Time `json:"completionTime,omitempty"`
+	// evictable pods still on the node, including those terminating
+	// +optional
+	RemainingPods int32 `json:"remainingPods"`
+	// pods left on the node as they may not be evicted (e.g. protected, opted out or keeping data on the node)
+	// +optional
+	UnevictablePods int32 `json:"unevictablePods"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=nodedrains,scope=Cluster,singular=nodedrain,shortName=nd
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".spec.node",description="Drained node"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Lifecycle phase of the drain"
+// +kubebuilder:printcolumn:name="Remaining",type="integer",JSONPath=".status.remainingPods",description="Evictable pods still on the node"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// full drain of a degraded node, named after the node and owned by it, reporting the drain's progress
+type NodeDrain struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeDrainSpec   `json:"spec,omitempty"`
+	Status NodeDrainStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// list of several NodeDrain
+type NodeDrainList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []NodeDrain `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeDrain{}, &NodeDrainList{})
+}
@@ -0,0 +1,90 @@
+package v1alpha1
+
+import (
+	"time"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// lifecycle phases of a NodeMaintenanceWindow
+type NodeMaintenanceWindowPhase string
+
+const (
+	// the drain has not started yet
+	NodeMaintenanceScheduled NodeMaintenanceWindowPhase = "Scheduled"
+	// the matching nodes are being drained ahead of, or during, the maintenance
+	NodeMaintenanceDraining NodeMaintenanceWindowPhase = "Draining"
+	// the maintenance has ended
+	NodeMaintenanceCompleted NodeMaintenanceWindowPhase = "Completed"
+)
+
+// default time before a maintenance starts at which its nodes begin to be drained
+const DefaultNodeMaintenanceDrainAhead = time.Hour
+
+// defines the desired state of NodeMaintenanceWindow; nodes matching either the names or the selector are drained
+type NodeMaintenanceWindowSpec struct {
+	// nodes under maintenance, by name
+	// +optional
+	NodeNames []string `json:"nodeNames,omitempty"`
+	// nodes under maintenance, by label
+	// +optional
+	NodeSelector *meta.LabelSelector `json:"nodeSelector,omitempty"`
+	// time the maintenance starts
+	Start meta.Time `json:"start"`
+	// time the maintenance ends, after which the nodes are no longer drained
+	End meta.Time `json:"end"`
+	// how long before the start the nodes begin to be drained; one hour when unset
+	// +optional
+	DrainAhead *meta.Duration `json:"drainAhead,omitempty"`
+	// human-readable explanation of the maintenance, recorded on the drained nodes
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// returns the time at which the nodes begin to be drained
+func (s *NodeMaintenanceWindowSpec) DrainStart() time.Time {
+	drainAhead := DefaultNodeMaintenanceDrainAhead
+	if s.DrainAhead != nil {
+		drainAhead = s.DrainAhead.Duration
+	}
+	return s.Start.Add(-drainAhead)
+}
+
+// defines the observed state of NodeMaintenanceWindow
+type NodeMaintenanceWindowStatus struct {
+	// +optional
+	Phase NodeMaintenanceWindowPhase `json:"phase,omitempty"`
+	// nodes currently matched by the window
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=nodemaintenancewindows,scope=Cluster,singular=nodemaintenancewindow,shortName=nmw
+// +kubebuilder:printcolumn:name="Start",type="date",JSONPath=".spec.start",description="Time the maintenance starts"
+// +kubebuilder:printcolumn:name="End",type="date",JSONPath=".spec.end",description="Time the maintenance ends"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Lifecycle phase of the window"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// planned maintenance on a set of nodes, which are drained ahead of it
+type NodeMaintenanceWindow struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeMaintenanceWindowSpec   `json:"spec,omitempty"`
+	Status NodeMaintenanceWindowStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// list of several NodeMaintenanceWindow
+type NodeMaintenanceWindowList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []NodeMaintenanceWindow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeMaintenanceWindow{}, &NodeMaintenanceWindowList{})
+}
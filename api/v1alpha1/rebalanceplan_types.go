@@ -0,0 +1,120 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// lifecycle phases of a RebalancePlan
+type RebalancePlanPhase string
+
+const (
+	// the plan awaits approval, or execution in apply mode
+	RebalancePlanPending RebalancePlanPhase = "Pending"
+	// the plan's evictions are being carried out
+	RebalancePlanApplying RebalancePlanPhase = "Applying"
+	// every eviction of the plan has been attempted
+	RebalancePlanCompleted RebalancePlanPhase = "Completed"
+	// the plan was replaced by a newer one before it was approved
+	RebalancePlanSuperseded RebalancePlanPhase = "Superseded"
+)
+
+// outcomes of a single planned eviction
+type PlannedEvictionOutcome string
+
+const (
+	// the pod was evicted
+	PlannedEvictionEvicted PlannedEvictionOutcome = "Evicted"
+	// the eviction no longer applied when the plan was executed (e.g. the pod is gone or its node recovered)
+	PlannedEvictionSkipped PlannedEvictionOutcome = "Skipped"
+	// the eviction request was rejected
+	PlannedEvictionFailed PlannedEvictionOutcome = "Failed"
+	// the eviction was admitted as a server-side dry run, leaving the pod running
+	PlannedEvictionDryRun PlannedEvictionOutcome = "DryRun"
+	// the eviction failed in a way worth retrying and awaits a retry, after which the result is replaced by the final outcome
+	PlannedEvictionRetrying PlannedEvictionOutcome = "Retrying"
+	// the pod was deleted outright after its PodDisruptionBudget blocked its eviction for too long
+	PlannedEvictionForceDeleted PlannedEvictionOutcome = "ForceDeleted"
+)
+
+// pod the controller intends to evict
+type PlannedEviction struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	// UID of the planned pod, so that a pod recreated under the same name is never evicted in its place
+	UID types.UID `json:"uid"`
+	// degraded node the pod is evicted from
+	Node string `json:"node"`
+	// workload profile governing the pod
+	// +optional
+	Profile string `json:"profile,omitempty"`
+	// why the pod was selected for eviction
+	Reason string `json:"reason"`
+	// rebalancing strategy that selected the pod; evictions without one were planned to evacuate a degraded node
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+	// UID of the pod a pod group member is evicted along with; the member is skipped unless that pod was evicted
+	// +optional
+	EvictedWith types.UID `json:"evictedWith,omitempty"`
+}
+
+// defines the desired state of RebalancePlan
+type RebalancePlanSpec struct {
+	// set by an approver to let the controller execute the plan when it runs in plan mode; approvers may instead
+	// annotate the plan with kube-balance.io/approve=true, or each of its nodes with kube-balance.io/approve=<plan name>
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+	// evictions in the order they are carried out
+	Evictions []PlannedEviction `json:"evictions"`
+}
+
+// result of carrying out a single planned eviction
+type PlannedEvictionResult struct {
+	Pod       string                 `json:"pod"`
+	Namespace string                 `json:"namespace"`
+	Outcome   PlannedEvictionOutcome `json:"outcome"`
+	// +optional
+	Message string    `json:"message,omitempty"`
+	Time    meta.Time `json:"time"`
+}
+
+// defines the observed state of RebalancePlan
+type RebalancePlanStatus struct {
+	// +optional
+	Phase RebalancePlanPhase `json:"phase,omitempty"`
+	// results of the evictions attempted so far
+	// +optional
+	Results []PlannedEvictionResult `json:"results,omitempty"`
+	// time the last planned eviction was attempted
+	// +optional
+	CompletionTime *meta.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=rebalanceplans,scope=Cluster,singular=rebalanceplan
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Lifecycle phase of the plan"
+// +kubebuilder:printcolumn:name="Approved",type="boolean",JSONPath=".spec.approved",description="Whether the plan has been approved"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// evictions the controller intends to carry out in a rebalancing cycle, written before acting so they can be reviewed
+type RebalancePlan struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RebalancePlanSpec   `json:"spec,omitempty"`
+	Status RebalancePlanStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// list of several RebalancePlan
+type RebalancePlanList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []RebalancePlan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RebalancePlan{}, &RebalancePlanList{})
+}
@@ -0,0 +1,322 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recurring weekly period during which evictions are approved
+type MaintenanceWindow struct {
+	// days the window opens on (e.g. "Mon", "Saturday"); every day when empty
+	// +optional
+	Days []string `json:"days,omitempty"`
+	// local time the window opens, as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// local time the window closes, as HH:MM; a window closing at or before its start closes on the following day
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+	// IANA time zone the times are expressed in (e.g. "Europe/Berlin"); UTC when empty
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// restricts which namespaces are considered for rebalancing
+type NamespaceFilter struct {
+	// only pods in these namespaces are considered, replacing --namespace-allowlist; empty means all namespaces except
+	// kube-system, which is only considered when listed
+	Include []string `json:"include,omitempty"`
+	// pods in these namespaces are never considered, replacing --namespace-denylist
+	Exclude []string `json:"exclude,omitempty"`
+	// only namespaces annotated with kube-balance.io/enabled: "true" are considered; namespaces annotated with "false"
+	// are never considered either way
+	// +optional
+	OptIn *bool `json:"optIn,omitempty"`
+}
+
+// criterion pods are ordered by for eviction: "qos-class" evicts BestEffort pods first, "profile-priority" pods without
+// a workload profile and then those with the highest eviction priority, "pod-priority" those with the lowest scheduling
+// priority, "deletion-cost" those with the lowest pod deletion cost, "age" the youngest and "restarts" those with the most
+// container restarts
+// +kubebuilder:validation:Enum=qos-class;profile-priority;pod-priority;deletion-cost;age;restarts
+type EvictionOrderCriterion string
+
+// utilization of a node's allocatable CPU, memory and pod capacity, in percent; unset resources are ignored
+type ResourceThresholds struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CPU *int `json:"cpu,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Memory *int `json:"memory,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Pods *int `json:"pods,omitempty"`
+}
+
+// balances load across nodes that aren't degraded by moving pods off over-utilized nodes while under-utilized ones
+// can take them, similar to the descheduler's LowNodeUtilization strategy
+type LowNodeUtilization struct {
+	// runs the strategy
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// what a node's utilization is measured from: the requests of its pods ("requests") or the usage reported by the
+	// metrics API ("usage"), in which case pod counts are still taken from the node's pods; "requests" when empty
+	// +kubebuilder:validation:Enum=requests;usage
+	// +optional
+	Basis string `json:"basis,omitempty"`
+	// nodes whose utilization is below every threshold set are under-utilized
+	Thresholds ResourceThresholds `json:"thresholds"`
+	// nodes whose utilization is above any of the target thresholds set are over-utilized, and pods are moved off them
+	// until they drop below, or the under-utilized nodes reach them
+	TargetThresholds ResourceThresholds `json:"targetThresholds"`
+}
+
+// packs workloads onto fewer nodes by moving all the pods off under-utilized nodes, so that the cluster autoscaler or
+// Karpenter can remove the emptied nodes, similar to the descheduler's HighNodeUtilization strategy
+type HighNodeUtilization struct {
+	// runs the strategy
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// nodes whose utilization, measured from the requests of their pods, is below every threshold set are emptied
+	Thresholds ResourceThresholds `json:"thresholds"`
+}
+
+// restores the balance of pods violating their topology spread constraints, e.g. once a failed zone recovers, by
+// evicting the fewest pods needed to bring each constraint's skew back within its maxSkew
+type TopologySpread struct {
+	// runs the strategy
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// also balances ScheduleAnyway constraints, which the scheduler only tries to satisfy; only DoNotSchedule
+	// constraints are balanced by default
+	// +optional
+	IncludeSoftConstraints bool `json:"includeSoftConstraints,omitempty"`
+}
+
+// spreads the replicas of an owner colocated on a single node, so that losing the node takes down as few of them as
+// possible; replicas are only moved when other nodes can take them
+type RemoveDuplicates struct {
+	// runs the strategy
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// kinds of owners, e.g. "ReplicaSet" or "StatefulSet", whose colocated replicas are left in place
+	// +optional
+	ExcludeOwnerKinds []string `json:"excludeOwnerKinds,omitempty"`
+}
+
+// moves pods off expensive nodes onto cheaper ones that can take them, e.g. spot capacity, so that workloads tolerant of
+// disruption run on the cheapest capacity available
+type CostAware struct {
+	// runs the strategy
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// where the nodes' hourly costs come from: their instance and capacity type labels ("labels") or the
+	// node_total_hourly_cost metric OpenCost exports to Prometheus ("opencost"); "labels" when empty
+	// +kubebuilder:validation:Enum=labels;opencost
+	// +optional
+	Source string `json:"source,omitempty"`
+	// cost of each instance type, from the node.kubernetes.io/instance-type label, in any unit shared by all of them,
+	// e.g. cents per hour; instance types not listed cost 100
+	// +optional
+	InstanceTypeCosts map[string]int `json:"instanceTypeCosts,omitempty"`
+	// cost of each capacity type, "spot" or "on-demand", relative to its instance type's cost, in percent, e.g.
+	// {"spot": 30}; capacity types not listed cost 100
+	// +optional
+	CapacityTypeCostPercent map[string]int `json:"capacityTypeCostPercent,omitempty"`
+	// saving, in percent of the cost of a pod's node, below which moving the pod isn't worthwhile; 20 when unset
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MinSavingsPercent *int `json:"minSavingsPercent,omitempty"`
+	// QoS classes of the pods moved; Burstable and BestEffort when empty, Guaranteed pods usually being sized for
+	// steady capacity
+	// +kubebuilder:validation:items:Enum=BestEffort;Burstable;Guaranteed
+	// +optional
+	QoSClasses []string `json:"qosClasses,omitempty"`
+}
+
+// moves load back onto nodes that recovered from a degradation, so that the cluster isn't left lopsided once the pods
+// evacuated from them were rescheduled elsewhere
+type PostRecovery struct {
+	// runs the strategy
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// duration a node must stay recovered before load is moved back onto it, so that a flapping node isn't refilled
+	// right away; 5m when unset
+	// +optional
+	StabilizationPeriod *meta.Duration `json:"stabilizationPeriod,omitempty"`
+	// duration after a node's recovery during which load is moved back onto it; 1h when unset
+	// +optional
+	Window *meta.Duration `json:"window,omitempty"`
+	// maximum number of pods moved back onto each recovered node per cycle; 5 when unset
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxPodsPerNodePerCycle *int `json:"maxPodsPerNodePerCycle,omitempty"`
+}
+
+// moves pods off nodes that no longer satisfy their scheduling constraints, as the scheduler only checks them when
+// placing pods and labels or taints may change afterwards
+type NodeConstraintViolations struct {
+	// evicts pods whose node no longer matches their node selector or required node affinity
+	// +optional
+	NodeAffinity bool `json:"nodeAffinity,omitempty"`
+	// evicts pods not tolerating a NoSchedule taint added to their node after they were scheduled; NoExecute taints
+	// are already enforced by the taint manager
+	// +optional
+	NodeTaints bool `json:"nodeTaints,omitempty"`
+	// keys of the taints left alone by nodeTaints; the node.kubernetes.io/ taints reporting node conditions and cordons
+	// always are
+	// +optional
+	ExcludedTaints []string `json:"excludedTaints,omitempty"`
+}
+
+// defines the desired state of RebalancePolicy; unset fields fall back to the controller's command-line flags
+// +kubebuilder:validation:XValidation:rule="!(has(self.lowNodeUtilization) && self.lowNodeUtilization.enabled && has(self.highNodeUtilization) && self.highNodeUtilization.enabled)",message="lowNodeUtilization and highNodeUtilization can't both be enabled"
+type RebalancePolicySpec struct {
+	// interval for the controller to re-evaluate node/pod states
+	RecheckInterval *meta.Duration `json:"recheckInterval,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	MaxEvictionsPerNodePerCycle *int `json:"maxEvictionsPerNodePerCycle,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	UrgentMaxEvictionsPerNodePerCycle *int `json:"urgentMaxEvictionsPerNodePerCycle,omitempty"`
+	// pods evicted per minute across all nodes and strategies, so that a mass degradation can't churn the cluster; 0
+	// disables the limit
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxEvictionsPerMinute *int `json:"maxEvictionsPerMinute,omitempty"`
+	// share of an owner's desired replicas, in percent, that may be evicted or rescheduled at once across all nodes and
+	// cycles, at least one; 0 disables the limit
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxOwnerDisruptionPercent *int `json:"maxOwnerDisruptionPercent,omitempty"`
+	// Pending pods past which all evictions are paused until the scheduler catches up, so that a capacity crunch
+	// doesn't turn into an eviction storm; 0 never pauses them
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PendingPodsThreshold *int `json:"pendingPodsThreshold,omitempty"`
+	// which Pending pods count towards pendingPodsThreshold: "cluster" for every Pending pod, "evicted" for the
+	// replacements of pods kube-balance evicted
+	// +kubebuilder:validation:Enum=cluster;evicted
+	// +optional
+	PendingPodsScope string `json:"pendingPodsScope,omitempty"`
+	// consecutive reconcile cycles, counted at most once per recheck interval, a node must stay degraded before
+	// evictions start
+	// +kubebuilder:validation:Minimum=1
+	DegradationConfirmationCycles *int           `json:"degradationConfirmationCycles,omitempty"`
+	DegradationConfirmationPeriod *meta.Duration `json:"degradationConfirmationPeriod,omitempty"`
+	// percentage of a zone's nodes that must be degraded at once for the zone to be treated as a correlated failure; 0 disables the check
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	ZoneDegradationThresholdPercent *int `json:"zoneDegradationThresholdPercent,omitempty"`
+	// +kubebuilder:validation:Enum=pause;throttle
+	ZoneDegradationAction string `json:"zoneDegradationAction,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	ZoneThrottledMaxEvictions *int `json:"zoneThrottledMaxEvictions,omitempty"`
+	// restricts which namespaces are considered for rebalancing
+	Namespaces *NamespaceFilter `json:"namespaces,omitempty"`
+	// pods matching any of these selectors are never evicted
+	ProtectedPodSelectors []meta.LabelSelector `json:"protectedPodSelectors,omitempty"`
+	// periods during which evictions may happen cluster-wide; evictions outside them are deferred, except from urgently degraded nodes
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// "apply" executes each RebalancePlan as soon as it is written; "plan" stops once the plan is written and waits for it to be approved
+	// +kubebuilder:validation:Enum=plan;apply
+	Mode string `json:"mode,omitempty"`
+	// halts all evictions and forced deletions at once, e.g. during an incident, while degraded nodes are still detected
+	// and the evictions that would be carried out are still planned and reported
+	// +optional
+	Paused *bool `json:"paused,omitempty"`
+	// degraded nodes fully drained at once rather than a few pods per cycle: "off", "urgent" for urgently degraded
+	// nodes only or "all"; a drained node is cordoned, and its progress is reported on a NodeDrain named after it
+	// +kubebuilder:validation:Enum=off;urgent;all
+	DrainMode string `json:"drainMode,omitempty"`
+	// cordons the degraded nodes being evacuated a few pods per cycle too, so that the scheduler doesn't keep placing
+	// new pods on them, and uncordons them once their degradation clears
+	// +optional
+	CordonDegradedNodes *bool `json:"cordonDegradedNodes,omitempty"`
+	// handling of pods without a controller, which nothing recreates once evicted: "skip", "evict-with-warning" or
+	// "evict-if-profiled", which only evicts those governed by a workload profile
+	// +kubebuilder:validation:Enum=skip;evict-with-warning;evict-if-profiled
+	// +optional
+	BarePods string `json:"barePods,omitempty"`
+	// handling of pods owned by a Job, including those of CronJobs, whose progress is lost when evicted: "skip",
+	// "evict-with-warning" or "evict-if-profiled", which only evicts those governed by a workload profile
+	// +kubebuilder:validation:Enum=skip;evict-with-warning;evict-if-profiled
+	// +optional
+	JobPods string `json:"jobPods,omitempty"`
+	// estimated progress, in percent, from which the pods of a Job are left to finish on their node rather than losing
+	// their work, unless it is urgently degraded; 0 evicts them whatever their progress
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	JobCompletionThresholdPercent *int `json:"jobCompletionThresholdPercent,omitempty"`
+	// eviction priority points a pod on a degraded node gains per container restart, pods in CrashLoopBackOff being
+	// evicted first; 0 leaves restarts out of the eviction order
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RestartCountWeight *int `json:"restartCountWeight,omitempty"`
+	// criteria a node's pods are ordered by for eviction, the first one telling two pods apart deciding which goes first;
+	// qos-class, profile-priority, pod-priority and deletion-cost when empty
+	// +optional
+	EvictionOrder []EvictionOrderCriterion `json:"evictionOrder,omitempty"`
+	// moves pods off over-utilized nodes onto under-utilized ones, in addition to evacuating degraded nodes
+	// +optional
+	LowNodeUtilization *LowNodeUtilization `json:"lowNodeUtilization,omitempty"`
+	// moves all the pods off under-utilized nodes onto the other nodes, so that the emptied nodes can be removed; can't
+	// be enabled along with lowNodeUtilization, which spreads load the other way
+	// +optional
+	HighNodeUtilization *HighNodeUtilization `json:"highNodeUtilization,omitempty"`
+	// evicts pods violating their topology spread constraints so that they are spread again
+	// +optional
+	TopologySpread *TopologySpread `json:"topologySpread,omitempty"`
+	// evicts replicas of an owner colocated on a single node so that they are spread across nodes
+	// +optional
+	RemoveDuplicates *RemoveDuplicates `json:"removeDuplicates,omitempty"`
+	// evicts pods whose node no longer satisfies their required node affinity or tolerations
+	// +optional
+	NodeConstraintViolations *NodeConstraintViolations `json:"nodeConstraintViolations,omitempty"`
+	// moves pods off expensive nodes onto cheaper ones, e.g. spot capacity, that can take them
+	// +optional
+	CostAware *CostAware `json:"costAware,omitempty"`
+	// moves load back onto nodes that recovered from a degradation, off the nodes more utilized than average
+	// +optional
+	PostRecovery *PostRecovery `json:"postRecovery,omitempty"`
+}
+
+// defines the observed state of RebalancePolicy
+type RebalancePolicyStatus struct {
+	// generation of the spec most recently applied by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=rebalancepolicies,scope=Cluster,singular=rebalancepolicy
+// +kubebuilder:printcolumn:name="Recheck Interval",type="string",JSONPath=".spec.recheckInterval",description="Interval between rebalancing cycles"
+// +kubebuilder:printcolumn:name="Max Evictions",type="integer",JSONPath=".spec.maxEvictionsPerNodePerCycle",description="Maximum evictions per node per cycle"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// cluster-wide runtime configuration for the rebalancer, hot-reloaded by the controller
+type RebalancePolicy struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RebalancePolicySpec   `json:"spec,omitempty"`
+	Status RebalancePolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// list of several RebalancePolicy
+type RebalancePolicyList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []RebalancePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RebalancePolicy{}, &RebalancePolicyList{})
+}
@@ -0,0 +1,183 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// annotation carrying the v1beta1 spec of an object served as v1alpha1, so that fields v1alpha1 cannot represent survive a round trip
+const PreservedSpecAnnotation = "kube-balance.io/v1beta1-spec"
+
+// converts this WorkloadProfile to the v1beta1 hub version
+func (src *WorkloadProfile) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.WorkloadProfile)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if err := specToHub(&src.Spec, &dst.Spec, &dst.ObjectMeta); err != nil {
+		return fmt.Errorf("failed to convert WorkloadProfile %s to v1beta1: %w", src.Name, err)
+	}
+	statusToHub(&src.Status, &dst.Status)
+	return nil
+}
+
+// converts the v1beta1 hub version to this WorkloadProfile
+func (dst *WorkloadProfile) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.WorkloadProfile)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if err := specFromHub(&src.Spec, &dst.Spec, &dst.ObjectMeta); err != nil {
+		return fmt.Errorf("failed to convert WorkloadProfile %s from v1beta1: %w", src.Name, err)
+	}
+	statusFromHub(&src.Status, &dst.Status)
+	return nil
+}
+
+// converts this NamespacedWorkloadProfile to the v1beta1 hub version
+func (src *NamespacedWorkloadProfile) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.NamespacedWorkloadProfile)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if err := specToHub(&src.Spec, &dst.Spec, &dst.ObjectMeta); err != nil {
+		return fmt.Errorf("failed to convert NamespacedWorkloadProfile %s/%s to v1beta1: %w", src.Namespace, src.Name, err)
+	}
+	statusToHub(&src.Status, &dst.Status)
+	return nil
+}
+
+// converts the v1beta1 hub version to this NamespacedWorkloadProfile
+func (dst *NamespacedWorkloadProfile) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.NamespacedWorkloadProfile)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if err := specFromHub(&src.Spec, &dst.Spec, &dst.ObjectMeta); err != nil {
+		return fmt.Errorf("failed to convert NamespacedWorkloadProfile %s/%s from v1beta1: %w", src.Namespace, src.Name, err)
+	}
+	statusFromHub(&src.Status, &dst.Status)
+	return nil
+}
+
+// converts a v1alpha1 spec to v1beta1, starting from the spec preserved by an earlier down-conversion, if any
+func specToHub(src *WorkloadProfileSpec, dst *v1beta1.WorkloadProfileSpec, dstMeta *meta.ObjectMeta) error {
+	if preserved, ok := dstMeta.Annotations[PreservedSpecAnnotation]; ok {
+		if err := json.Unmarshal([]byte(preserved), dst); err != nil {
+			return fmt.Errorf("failed to decode preserved spec annotation: %w", err)
+		}
+		delete(dstMeta.Annotations, PreservedSpecAnnotation)
+		if len(dstMeta.Annotations) == 0 {
+			dstMeta.Annotations = nil
+		}
+	}
+
+	dst.PodSelector = src.PodSelector.DeepCopy()
+	dst.IsDefault = src.IsDefault
+	dst.Resources.CPU = copyQuantity(src.CPURequests)
+	dst.Resources.Memory = copyQuantity(src.MemoryRequests)
+	dst.Eviction.Priority = copyInt(&src.EvictionPriority)
+	dst.Eviction.GracePeriodSeconds = copyInt64(src.GracePeriodSeconds)
+	dst.Eviction.MaxConcurrent = copyInt32(src.MaxConcurrentEvictions)
+	dst.Eviction.Protected = nil
+	if src.Protected {
+		protected := true
+		dst.Eviction.Protected = &protected
+	}
+	return nil
+}
+
+// converts a v1beta1 spec to v1alpha1, preserving the full spec in an annotation when v1alpha1 cannot represent all of it
+func specFromHub(src *v1beta1.WorkloadProfileSpec, dst *WorkloadProfileSpec, dstMeta *meta.ObjectMeta) error {
+	dst.PodSelector = src.PodSelector.DeepCopy()
+	dst.IsDefault = src.IsDefault
+	dst.CPURequests = copyQuantity(src.Resources.CPU)
+	dst.MemoryRequests = copyQuantity(src.Resources.Memory)
+	dst.EvictionPriority = src.Eviction.PriorityOrDefault()
+	dst.GracePeriodSeconds = copyInt64(src.Eviction.GracePeriodSeconds)
+	dst.MaxConcurrentEvictions = copyInt32(src.Eviction.MaxConcurrent)
+	dst.Protected = src.Eviction.IsProtected()
+
+	roundTrip := v1beta1.WorkloadProfileSpec{}
+	if err := specToHub(dst, &roundTrip, &meta.ObjectMeta{}); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(&roundTrip, src) {
+		return nil
+	}
+
+	preserved, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("failed to encode preserved spec annotation: %w", err)
+	}
+	if dstMeta.Annotations == nil {
+		dstMeta.Annotations = make(map[string]string)
+	}
+	dstMeta.Annotations[PreservedSpecAnnotation] = string(preserved)
+	return nil
+}
+
+// converts a v1alpha1 status to v1beta1; v1alpha1 doesn't report resource drift, which the controller republishes on its next pass
+func statusToHub(src *WorkloadProfileStatus, dst *v1beta1.WorkloadProfileStatus) {
+	dst.ObservedGeneration = src.ObservedGeneration
+	dst.MatchedPods = src.MatchedPods
+	dst.EvictionsLast24h = src.EvictionsLast24h
+	dst.LastEvictionTime = src.LastEvictionTime.DeepCopy()
+	dst.Conditions = copyConditions(src.Conditions)
+}
+
+// converts a v1beta1 status to v1alpha1, dropping the resource drift
+func statusFromHub(src *v1beta1.WorkloadProfileStatus, dst *WorkloadProfileStatus) {
+	dst.ObservedGeneration = src.ObservedGeneration
+	dst.MatchedPods = src.MatchedPods
+	dst.EvictionsLast24h = src.EvictionsLast24h
+	dst.LastEvictionTime = src.LastEvictionTime.DeepCopy()
+	dst.Conditions = copyConditions(src.Conditions)
+}
+
+// returns a copy of an optional quantity
+func copyQuantity(q *resource.Quantity) *resource.Quantity {
+	if q == nil {
+		return nil
+	}
+	c := q.DeepCopy()
+	return &c
+}
+
+// returns a copy of an optional int
+func copyInt(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	return &c
+}
+
+// returns a copy of an optional int64
+func copyInt64(i *int64) *int64 {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	return &c
+}
+
+// returns a copy of an optional int32
+func copyInt32(i *int32) *int32 {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	return &c
+}
+
+// returns a deep copy of a condition list
+func copyConditions(conditions []meta.Condition) []meta.Condition {
+	if conditions == nil {
+		return nil
+	}
+	c := make([]meta.Condition, len(conditions))
+	for i := range conditions {
+		conditions[i].DeepCopyInto(&c[i])
+	}
+	return c
+}
@@ -0,0 +1,118 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// returns a v1beta1 profile with the given spec
+func hubProfile(spec v1beta1.WorkloadProfileSpec) *v1beta1.WorkloadProfile {
+	return &v1beta1.WorkloadProfile{
+		ObjectMeta: meta.ObjectMeta{Name: "web", Annotations: map[string]string{"team": "payments"}},
+		Spec:       spec,
+	}
+}
+
+func TestWorkloadProfileRoundTrip(t *testing.T) {
+	priority, maxConcurrent, minAvailable := 70, int32(2), int32(3)
+	memory := resource.MustParse("256Mi")
+	representable := v1beta1.WorkloadProfileSpec{
+		PodSelector: &meta.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		Resources:   v1beta1.ResourceRecommendation{Memory: &memory},
+		Eviction:    v1beta1.EvictionPolicy{Priority: &priority, MaxConcurrent: &maxConcurrent},
+	}
+	beyondV1alpha1 := *representable.DeepCopy()
+	beyondV1alpha1.BaseProfile = "base"
+	beyondV1alpha1.MinAvailable = &minAvailable
+	beyondV1alpha1.Eviction.Strategy = v1beta1.EvictionStrategySurgeThenEvict
+	beyondV1alpha1.Resources.Containers = map[string]v1beta1.ContainerRecommendation{
+		"proxy": {Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("50m")}},
+	}
+
+	tests := []struct {
+		name          string
+		spec          v1beta1.WorkloadProfileSpec
+		wantPreserved bool
+	}{
+		{name: "spec v1alpha1 represents", spec: representable},
+		{name: "spec beyond v1alpha1", spec: beyondV1alpha1, wantPreserved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := hubProfile(tt.spec)
+			spoke := &WorkloadProfile{}
+			if err := spoke.ConvertFrom(src); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+			if _, ok := spoke.Annotations[PreservedSpecAnnotation]; ok != tt.wantPreserved {
+				t.Errorf("preserved spec annotation present = %v, want %v", ok, tt.wantPreserved)
+			}
+			if spoke.Spec.EvictionPriority != priority || spoke.Spec.MemoryRequests.Cmp(memory) != 0 {
+				t.Errorf("v1alpha1 spec = %+v, want the v1beta1 fields it represents", spoke.Spec)
+			}
+
+			hub := &v1beta1.WorkloadProfile{}
+			if err := spoke.ConvertTo(hub); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+			if !equality.Semantic.DeepEqual(hub.Spec, src.Spec) {
+				t.Errorf("round-tripped spec = %+v, want %+v", hub.Spec, src.Spec)
+			}
+			if !equality.Semantic.DeepEqual(hub.Annotations, src.Annotations) {
+				t.Errorf("round-tripped annotations = %v, want %v", hub.Annotations, src.Annotations)
+			}
+		})
+	}
+}
+
+func TestWorkloadProfileKeepsV1alpha1EditsOverThePreservedSpec(t *testing.T) {
+	priority, minAvailable := 70, int32(3)
+	src := hubProfile(v1beta1.WorkloadProfileSpec{
+		BaseProfile:  "base",
+		MinAvailable: &minAvailable,
+		Eviction:     v1beta1.EvictionPolicy{Priority: &priority},
+	})
+	spoke := &WorkloadProfile{}
+	if err := spoke.ConvertFrom(src); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+
+	// a v1alpha1 client edits a field both versions hold
+	spoke.Spec.EvictionPriority = 10
+	spoke.Spec.Protected = true
+	hub := &v1beta1.WorkloadProfile{}
+	if err := spoke.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if hub.Spec.Eviction.PriorityOrDefault() != 10 || !hub.Spec.Eviction.IsProtected() {
+		t.Errorf("eviction = %+v, want the v1alpha1 edits", hub.Spec.Eviction)
+	}
+	if hub.Spec.BaseProfile != "base" || hub.Spec.MinAvailable == nil || *hub.Spec.MinAvailable != minAvailable {
+		t.Errorf("spec = %+v, want the fields only v1beta1 holds preserved", hub.Spec)
+	}
+}
+
+func TestNamespacedWorkloadProfileRoundTrip(t *testing.T) {
+	priority := 20
+	src := &v1beta1.NamespacedWorkloadProfile{
+		ObjectMeta: meta.ObjectMeta{Name: "web", Namespace: "team-a"},
+		Spec:       v1beta1.WorkloadProfileSpec{BaseProfile: "web", Eviction: v1beta1.EvictionPolicy{Priority: &priority}},
+	}
+	spoke := &NamespacedWorkloadProfile{}
+	if err := spoke.ConvertFrom(src); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	hub := &v1beta1.NamespacedWorkloadProfile{}
+	if err := spoke.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if !equality.Semantic.DeepEqual(hub.Spec, src.Spec) || len(hub.Annotations) != 0 {
+		t.Errorf("round-tripped profile = %+v, want %+v", hub, src)
+	}
+}
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/scheme"
@@ -13,13 +14,57 @@ var SchemeGroupVersion = schema.GroupVersion{
 
 // defines the desired state of WorloadProfile
 type WorkloadProfileSpec struct {
-	CPURequests      string `json:"cpuRequests,omitempty"`
-	MemoryRequests   string `json:"memoryRequests,omitempty"`
-	EvictionPriority int    `json:"evictionPriority"`
+	// recommended CPU requests for the workload type, also used as the size of its pods that declare no CPU requests
+	// +optional
+	CPURequests *resource.Quantity `json:"cpuRequests,omitempty"`
+	// recommended memory requests for the workload type, also used as the size of its pods that declare no memory requests
+	// +optional
+	MemoryRequests *resource.Quantity `json:"memoryRequests,omitempty"`
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Minimum=0
+	EvictionPriority int `json:"evictionPriority"`
+	// seconds granted to evicted pods to terminate gracefully; defaults to 30 seconds when unset
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+	// maximum number of pods governed by this profile that may be terminating or awaiting rescheduling at once, across the whole cluster
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentEvictions *int32 `json:"maxConcurrentEvictions,omitempty"`
+	// exempts the pods governed by this profile from eviction, regardless of their QoS class or node degradation
+	// +optional
+	Protected bool `json:"protected,omitempty"`
+	// applies this profile to pods that match no other profile; a namespaced default takes precedence over a cluster-scoped one
+	// +optional
+	IsDefault bool `json:"isDefault,omitempty"`
+	// selects the pods governed by this profile, in addition to pods whose workload type label names the profile
+	PodSelector *meta.LabelSelector `json:"podSelector,omitempty"`
 }
 
+// condition types reported on WorkloadProfile status
+const (
+	// the profile currently governs at least one pod
+	WorkloadProfileConditionActive = "Active"
+	// the profile's pod selector can be evaluated
+	WorkloadProfileConditionSelectorValid = "SelectorValid"
+)
+
 // defines the observed state of WorkloadProfile
 type WorkloadProfileStatus struct {
+	// generation of the spec last observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// number of pods currently governed by this profile
+	MatchedPods int32 `json:"matchedPods"`
+	// number of pods evicted under this profile in the last 24 hours
+	EvictionsLast24h int32 `json:"evictionsLast24h"`
+	// time of the most recent eviction under this profile
+	// +optional
+	LastEvictionTime *meta.Time `json:"lastEvictionTime,omitempty"`
+	// latest observations of the profile's state
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []meta.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -28,6 +73,9 @@ type WorkloadProfileStatus struct {
 // +kubebuilder:printcolumn:name="CPU Requests",type="string",JSONPath=".spec.cpuRequests",description="Recommended CPU requests"
 // +kubebuilder:printcolumn:name="Memory Requests",type="string",JSONPath=".spec.memoryRequests",description="Recommended memory requests"
 // +kubebuilder:printcolumn:name="Eviction Priority",type="integer",JSONPath=".spec.evictionPriority",description="Eviction priority for the workload profile"
+// +kubebuilder:printcolumn:name="Matched Pods",type="integer",JSONPath=".status.matchedPods",description="Pods currently governed by the workload profile"
+// +kubebuilder:printcolumn:name="Evictions (24h)",type="integer",JSONPath=".status.evictionsLast24h",description="Pods evicted under the workload profile in the last 24 hours"
+// +kubebuilder:printcolumn:name="Last Eviction",type="date",JSONPath=".status.lastEvictionTime",description="Time of the most recent eviction"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // schema for the API
@@ -5,18 +5,951 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WorkloadProfile) DeepCopyInto(out *WorkloadProfile) {
+func (in *CostAware) DeepCopyInto(out *CostAware) {
+	*out = *in
+	if in.InstanceTypeCosts != nil {
+		in, out := &in.InstanceTypeCosts, &out.InstanceTypeCosts
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CapacityTypeCostPercent != nil {
+		in, out := &in.CapacityTypeCostPercent, &out.CapacityTypeCostPercent
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MinSavingsPercent != nil {
+		in, out := &in.MinSavingsPercent, &out.MinSavingsPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.QoSClasses != nil {
+		in, out := &in.QoSClasses, &out.QoSClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CostAware.
+func (in *CostAware) DeepCopy() *CostAware {
+	if in == nil {
+		return nil
+	}
+	out := new(CostAware)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionRecord) DeepCopyInto(out *EvictionRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionRecord.
+func (in *EvictionRecord) DeepCopy() *EvictionRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvictionRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionRecordList) DeepCopyInto(out *EvictionRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EvictionRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionRecordList.
+func (in *EvictionRecordList) DeepCopy() *EvictionRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvictionRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionRecordSpec) DeepCopyInto(out *EvictionRecordSpec) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionRecordSpec.
+func (in *EvictionRecordSpec) DeepCopy() *EvictionRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HighNodeUtilization) DeepCopyInto(out *HighNodeUtilization) {
+	*out = *in
+	in.Thresholds.DeepCopyInto(&out.Thresholds)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HighNodeUtilization.
+func (in *HighNodeUtilization) DeepCopy() *HighNodeUtilization {
+	if in == nil {
+		return nil
+	}
+	out := new(HighNodeUtilization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LowNodeUtilization) DeepCopyInto(out *LowNodeUtilization) {
+	*out = *in
+	in.Thresholds.DeepCopyInto(&out.Thresholds)
+	in.TargetThresholds.DeepCopyInto(&out.TargetThresholds)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LowNodeUtilization.
+func (in *LowNodeUtilization) DeepCopy() *LowNodeUtilization {
+	if in == nil {
+		return nil
+	}
+	out := new(LowNodeUtilization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceFilter) DeepCopyInto(out *NamespaceFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OptIn != nil {
+		in, out := &in.OptIn, &out.OptIn
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceFilter.
+func (in *NamespaceFilter) DeepCopy() *NamespaceFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedWorkloadProfile) DeepCopyInto(out *NamespacedWorkloadProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedWorkloadProfile.
+func (in *NamespacedWorkloadProfile) DeepCopy() *NamespacedWorkloadProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedWorkloadProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedWorkloadProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedWorkloadProfileList) DeepCopyInto(out *NamespacedWorkloadProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespacedWorkloadProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedWorkloadProfileList.
+func (in *NamespacedWorkloadProfileList) DeepCopy() *NamespacedWorkloadProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedWorkloadProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedWorkloadProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConstraintViolations) DeepCopyInto(out *NodeConstraintViolations) {
+	*out = *in
+	if in.ExcludedTaints != nil {
+		in, out := &in.ExcludedTaints, &out.ExcludedTaints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConstraintViolations.
+func (in *NodeConstraintViolations) DeepCopy() *NodeConstraintViolations {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConstraintViolations)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDrain) DeepCopyInto(out *NodeDrain) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDrain.
+func (in *NodeDrain) DeepCopy() *NodeDrain {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDrain)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeDrain) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDrainList) DeepCopyInto(out *NodeDrainList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeDrain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDrainList.
+func (in *NodeDrainList) DeepCopy() *NodeDrainList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDrainList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeDrainList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDrainSpec) DeepCopyInto(out *NodeDrainSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDrainSpec.
+func (in *NodeDrainSpec) DeepCopy() *NodeDrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDrainStatus) DeepCopyInto(out *NodeDrainStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDrainStatus.
+func (in *NodeDrainStatus) DeepCopy() *NodeDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceWindow) DeepCopyInto(out *NodeMaintenanceWindow) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceWindow.
+func (in *NodeMaintenanceWindow) DeepCopy() *NodeMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeMaintenanceWindow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceWindowList) DeepCopyInto(out *NodeMaintenanceWindowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeMaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceWindowList.
+func (in *NodeMaintenanceWindowList) DeepCopy() *NodeMaintenanceWindowList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceWindowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeMaintenanceWindowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceWindowSpec) DeepCopyInto(out *NodeMaintenanceWindowSpec) {
+	*out = *in
+	if in.NodeNames != nil {
+		in, out := &in.NodeNames, &out.NodeNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+	if in.DrainAhead != nil {
+		in, out := &in.DrainAhead, &out.DrainAhead
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceWindowSpec.
+func (in *NodeMaintenanceWindowSpec) DeepCopy() *NodeMaintenanceWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceWindowStatus) DeepCopyInto(out *NodeMaintenanceWindowStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceWindowStatus.
+func (in *NodeMaintenanceWindowStatus) DeepCopy() *NodeMaintenanceWindowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceWindowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedEviction) DeepCopyInto(out *PlannedEviction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedEviction.
+func (in *PlannedEviction) DeepCopy() *PlannedEviction {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedEviction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedEvictionResult) DeepCopyInto(out *PlannedEvictionResult) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedEvictionResult.
+func (in *PlannedEvictionResult) DeepCopy() *PlannedEvictionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedEvictionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostRecovery) DeepCopyInto(out *PostRecovery) {
+	*out = *in
+	if in.StabilizationPeriod != nil {
+		in, out := &in.StabilizationPeriod, &out.StabilizationPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxPodsPerNodePerCycle != nil {
+		in, out := &in.MaxPodsPerNodePerCycle, &out.MaxPodsPerNodePerCycle
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostRecovery.
+func (in *PostRecovery) DeepCopy() *PostRecovery {
+	if in == nil {
+		return nil
+	}
+	out := new(PostRecovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalancePlan) DeepCopyInto(out *RebalancePlan) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePlan.
+func (in *RebalancePlan) DeepCopy() *RebalancePlan {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RebalancePlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalancePlanList) DeepCopyInto(out *RebalancePlanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RebalancePlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePlanList.
+func (in *RebalancePlanList) DeepCopy() *RebalancePlanList {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePlanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RebalancePlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalancePlanSpec) DeepCopyInto(out *RebalancePlanSpec) {
+	*out = *in
+	if in.Evictions != nil {
+		in, out := &in.Evictions, &out.Evictions
+		*out = make([]PlannedEviction, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePlanSpec.
+func (in *RebalancePlanSpec) DeepCopy() *RebalancePlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalancePlanStatus) DeepCopyInto(out *RebalancePlanStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]PlannedEvictionResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePlanStatus.
+func (in *RebalancePlanStatus) DeepCopy() *RebalancePlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalancePolicy) DeepCopyInto(out *RebalancePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePolicy.
+func (in *RebalancePolicy) DeepCopy() *RebalancePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RebalancePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalancePolicyList) DeepCopyInto(out *RebalancePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RebalancePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePolicyList.
+func (in *RebalancePolicyList) DeepCopy() *RebalancePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RebalancePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalancePolicySpec) DeepCopyInto(out *RebalancePolicySpec) {
+	*out = *in
+	if in.RecheckInterval != nil {
+		in, out := &in.RecheckInterval, &out.RecheckInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxEvictionsPerNodePerCycle != nil {
+		in, out := &in.MaxEvictionsPerNodePerCycle, &out.MaxEvictionsPerNodePerCycle
+		*out = new(int)
+		**out = **in
+	}
+	if in.UrgentMaxEvictionsPerNodePerCycle != nil {
+		in, out := &in.UrgentMaxEvictionsPerNodePerCycle, &out.UrgentMaxEvictionsPerNodePerCycle
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxEvictionsPerMinute != nil {
+		in, out := &in.MaxEvictionsPerMinute, &out.MaxEvictionsPerMinute
+		*out = new(int)
+		**out = **in
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CordonDegradedNodes != nil {
+		in, out := &in.CordonDegradedNodes, &out.CordonDegradedNodes
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxOwnerDisruptionPercent != nil {
+		in, out := &in.MaxOwnerDisruptionPercent, &out.MaxOwnerDisruptionPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.PendingPodsThreshold != nil {
+		in, out := &in.PendingPodsThreshold, &out.PendingPodsThreshold
+		*out = new(int)
+		**out = **in
+	}
+	if in.DegradationConfirmationCycles != nil {
+		in, out := &in.DegradationConfirmationCycles, &out.DegradationConfirmationCycles
+		*out = new(int)
+		**out = **in
+	}
+	if in.DegradationConfirmationPeriod != nil {
+		in, out := &in.DegradationConfirmationPeriod, &out.DegradationConfirmationPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ZoneDegradationThresholdPercent != nil {
+		in, out := &in.ZoneDegradationThresholdPercent, &out.ZoneDegradationThresholdPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.ZoneThrottledMaxEvictions != nil {
+		in, out := &in.ZoneThrottledMaxEvictions, &out.ZoneThrottledMaxEvictions
+		*out = new(int)
+		**out = **in
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = new(NamespaceFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProtectedPodSelectors != nil {
+		in, out := &in.ProtectedPodSelectors, &out.ProtectedPodSelectors
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JobCompletionThresholdPercent != nil {
+		in, out := &in.JobCompletionThresholdPercent, &out.JobCompletionThresholdPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.RestartCountWeight != nil {
+		in, out := &in.RestartCountWeight, &out.RestartCountWeight
+		*out = new(int)
+		**out = **in
+	}
+	if in.EvictionOrder != nil {
+		in, out := &in.EvictionOrder, &out.EvictionOrder
+		*out = make([]EvictionOrderCriterion, len(*in))
+		copy(*out, *in)
+	}
+	if in.LowNodeUtilization != nil {
+		in, out := &in.LowNodeUtilization, &out.LowNodeUtilization
+		*out = new(LowNodeUtilization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighNodeUtilization != nil {
+		in, out := &in.HighNodeUtilization, &out.HighNodeUtilization
+		*out = new(HighNodeUtilization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(TopologySpread)
+		**out = **in
+	}
+	if in.RemoveDuplicates != nil {
+		in, out := &in.RemoveDuplicates, &out.RemoveDuplicates
+		*out = new(RemoveDuplicates)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeConstraintViolations != nil {
+		in, out := &in.NodeConstraintViolations, &out.NodeConstraintViolations
+		*out = new(NodeConstraintViolations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CostAware != nil {
+		in, out := &in.CostAware, &out.CostAware
+		*out = new(CostAware)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostRecovery != nil {
+		in, out := &in.PostRecovery, &out.PostRecovery
+		*out = new(PostRecovery)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePolicySpec.
+func (in *RebalancePolicySpec) DeepCopy() *RebalancePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalancePolicyStatus) DeepCopyInto(out *RebalancePolicyStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePolicyStatus.
+func (in *RebalancePolicyStatus) DeepCopy() *RebalancePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalancePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoveDuplicates) DeepCopyInto(out *RemoveDuplicates) {
+	*out = *in
+	if in.ExcludeOwnerKinds != nil {
+		in, out := &in.ExcludeOwnerKinds, &out.ExcludeOwnerKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoveDuplicates.
+func (in *RemoveDuplicates) DeepCopy() *RemoveDuplicates {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoveDuplicates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceThresholds) DeepCopyInto(out *ResourceThresholds) {
+	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		*out = new(int)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(int)
+		**out = **in
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceThresholds.
+func (in *ResourceThresholds) DeepCopy() *ResourceThresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceThresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpread) DeepCopyInto(out *TopologySpread) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpread.
+func (in *TopologySpread) DeepCopy() *TopologySpread {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologySpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadProfile) DeepCopyInto(out *WorkloadProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadProfile.
 func (in *WorkloadProfile) DeepCopy() *WorkloadProfile {
 	if in == nil {
@@ -66,3 +999,69 @@ func (in *WorkloadProfileList) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadProfileSpec) DeepCopyInto(out *WorkloadProfileSpec) {
+	*out = *in
+	if in.CPURequests != nil {
+		in, out := &in.CPURequests, &out.CPURequests
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MemoryRequests != nil {
+		in, out := &in.MemoryRequests, &out.MemoryRequests
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.GracePeriodSeconds != nil {
+		in, out := &in.GracePeriodSeconds, &out.GracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxConcurrentEvictions != nil {
+		in, out := &in.MaxConcurrentEvictions, &out.MaxConcurrentEvictions
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadProfileSpec.
+func (in *WorkloadProfileSpec) DeepCopy() *WorkloadProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadProfileStatus) DeepCopyInto(out *WorkloadProfileStatus) {
+	*out = *in
+	if in.LastEvictionTime != nil {
+		in, out := &in.LastEvictionTime, &out.LastEvictionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadProfileStatus.
+func (in *WorkloadProfileStatus) DeepCopy() *WorkloadProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
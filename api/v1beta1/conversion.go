@@ -0,0 +1,7 @@
+package v1beta1
+
+// marks WorkloadProfile as the conversion hub that other API versions convert to and from
+func (*WorkloadProfile) Hub() {}
+
+// marks NamespacedWorkloadProfile as the conversion hub that other API versions convert to and from
+func (*NamespacedWorkloadProfile) Hub() {}
@@ -0,0 +1,40 @@
+package v1beta1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=namespacedworkloadprofiles,scope=Namespaced,singular=namespacedworkloadprofile,shortName=nswp
+// +kubebuilder:printcolumn:name="CPU",type="string",JSONPath=".spec.resources.cpu",description="Recommended CPU requests"
+// +kubebuilder:printcolumn:name="Memory",type="string",JSONPath=".spec.resources.memory",description="Recommended memory requests"
+// +kubebuilder:printcolumn:name="Eviction Priority",type="integer",JSONPath=".spec.eviction.priority",description="Eviction priority for the workload profile"
+// +kubebuilder:printcolumn:name="Protected",type="boolean",JSONPath=".spec.eviction.protected",description="Whether the profile's pods are exempt from eviction"
+// +kubebuilder:printcolumn:name="Matched Pods",type="integer",JSONPath=".status.matchedPods",description="Pods currently governed by the workload profile"
+// +kubebuilder:printcolumn:name="Evictions (24h)",type="integer",JSONPath=".status.evictionsLast24h",description="Pods evicted under the workload profile in the last 24 hours"
+// +kubebuilder:printcolumn:name="Last Eviction",type="date",JSONPath=".status.lastEvictionTime",description="Time of the most recent eviction"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// namespace-scoped workload profile; applies only to pods in its namespace and overrides cluster-scoped WorkloadProfiles there
+type NamespacedWorkloadProfile struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WorkloadProfileSpec   `json:"spec,omitempty"`
+	Status WorkloadProfileStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// list of several NamespacedWorkloadProfile
+type NamespacedWorkloadProfileList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []NamespacedWorkloadProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespacedWorkloadProfile{}, &NamespacedWorkloadProfileList{})
+}
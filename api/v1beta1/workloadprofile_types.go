@@ -0,0 +1,427 @@
+package v1beta1
+
+import (
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var SchemeGroupVersion = schema.GroupVersion{
+	Group:   "kube-balance.io",
+	Version: "v1beta1",
+}
+
+// resource requests recommended for a workload type
+type ResourceRecommendation struct {
+	// recommended CPU requests, also used as the size of pods that declare no CPU requests; covers the containers
+	// without a CPU recommendation of their own
+	// +optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+	// recommended memory requests, also used as the size of pods that declare no memory requests; covers the
+	// containers without a memory recommendation of their own
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// recommendations for individual containers by container name, so that sidecars of multi-container pods are
+	// sized apart from the main container
+	// +optional
+	Containers map[string]ContainerRecommendation `json:"containers,omitempty"`
+	// QoS class intended for the pods, used when injecting the recommendation into pods that declare no requests;
+	// Guaranteed also sets limits equal to the injected requests; defaults to Burstable
+	// +kubebuilder:validation:Enum=Guaranteed;Burstable
+	// +optional
+	QOSClass core.PodQOSClass `json:"qosClass,omitempty"`
+}
+
+// resource requests and limits recommended for a single container
+type ContainerRecommendation struct {
+	// recommended requests of the container
+	// +optional
+	Requests core.ResourceList `json:"requests,omitempty"`
+	// recommended limits of the container, set along with the requests when injecting them
+	// +optional
+	Limits core.ResourceList `json:"limits,omitempty"`
+}
+
+// reports whether nothing is recommended, for the pod or any of its containers
+func (r *ResourceRecommendation) IsEmpty() bool {
+	return r.CPU == nil && r.Memory == nil && len(r.Containers) == 0
+}
+
+// returns the pod-level recommended request for a resource, nil when there is none
+func (r *ResourceRecommendation) PodRequest(resourceName core.ResourceName) *resource.Quantity {
+	switch resourceName {
+	case core.ResourceCPU:
+		return r.CPU
+	case core.ResourceMemory:
+		return r.Memory
+	}
+	return nil
+}
+
+// returns a container's own recommended request for a resource; containers without one are covered by the pod-level recommendation
+func (r *ResourceRecommendation) ContainerRequest(containerName string, resourceName core.ResourceName) (resource.Quantity, bool) {
+	request, ok := r.Containers[containerName].Requests[resourceName]
+	return request, ok
+}
+
+// returns a container's own recommended limit for a resource
+func (r *ResourceRecommendation) ContainerLimit(containerName string, resourceName core.ResourceName) (resource.Quantity, bool) {
+	limit, ok := r.Containers[containerName].Limits[resourceName]
+	return limit, ok
+}
+
+// recurring weekly period during which evictions are approved
+type MaintenanceWindow struct {
+	// days the window opens on (e.g. "Mon", "Saturday"); every day when empty
+	// +optional
+	Days []string `json:"days,omitempty"`
+	// local time the window opens, as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// local time the window closes, as HH:MM; a window closing at or before its start closes on the following day
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+	// IANA time zone the times are expressed in (e.g. "Europe/Berlin"); UTC when empty
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// eviction priority of profiles that neither set nor inherit one
+const DefaultEvictionPriority = 50
+
+// governs how the pods of a workload type are evicted from degraded nodes
+type EvictionPolicy struct {
+	// how likely the pods are to be evicted; higher values are evicted first, 0 suits critical services; defaults to 50
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Priority *int `json:"priority,omitempty"`
+	// seconds granted to evicted pods to terminate gracefully, taking precedence over the pods' own
+	// terminationGracePeriodSeconds, whether shorter or longer; when unset, pods get --eviction-grace-period-seconds,
+	// or their own terminationGracePeriodSeconds when that is longer
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+	// maximum number of pods that may be terminating or awaiting rescheduling at once, across the whole cluster
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrent *int32 `json:"maxConcurrent,omitempty"`
+	// minimum age of the pods before they may be evicted, so that a pod rescheduled back onto a degraded node isn't
+	// evicted again right away; defaults to --min-pod-age when unset, and urgently degraded nodes evict younger pods too
+	// +optional
+	MinPodAge *meta.Duration `json:"minPodAge,omitempty"`
+	// exempts the pods from eviction, regardless of their QoS class or node degradation
+	// +optional
+	Protected *bool `json:"protected,omitempty"`
+	// allows evicting pods using emptyDir volumes or local PersistentVolumes, losing the data kept on the node or leaving
+	// them unschedulable elsewhere; defaults to --evict-local-storage when unset
+	// +optional
+	EvictLocalStorage *bool `json:"evictLocalStorage,omitempty"`
+	// allows evicting the last Ready replica of the pods' Deployment, StatefulSet or ReplicaSet, leaving it unavailable
+	// until the replacement is Ready; the last Ready replica is left in place when unset, even without a
+	// PodDisruptionBudget
+	// +optional
+	EvictLastReplica *bool `json:"evictLastReplica,omitempty"`
+	// how the pods are moved off degraded nodes: Evict evicts them right away, while SurgeThenEvict first scales the
+	// Deployment owning a pod up by one and evicts the pod once the extra replica is Ready on a healthy node, so that no
+	// capacity is lost; pods of other owners are evicted right away; defaults to Evict
+	// +optional
+	Strategy EvictionStrategy `json:"strategy,omitempty"`
+	// actions run against each pod, in order, right before it is evicted, so that the application can flush caches, hand
+	// off leadership or drain connections first
+	// +optional
+	PreEvictionHooks []PreEvictionHook `json:"preEvictionHooks,omitempty"`
+	// Lease the pods compete for to elect their leader, so that the leader is evicted after the other replicas and
+	// leader-only pre-eviction hooks can hand its leadership over first
+	// +optional
+	LeaderElection *LeaderElection `json:"leaderElection,omitempty"`
+	// periods during which the pods may be evicted; evictions outside them are deferred, while an empty list allows evictions at any time
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// returns the eviction priority, falling back to DefaultEvictionPriority when unset
+func (e *EvictionPolicy) PriorityOrDefault() int {
+	if e.Priority == nil {
+		return DefaultEvictionPriority
+	}
+	return *e.Priority
+}
+
+// reports whether the pods are exempt from eviction
+func (e *EvictionPolicy) IsProtected() bool {
+	return e.Protected != nil && *e.Protected
+}
+
+// how the pods of a workload type are moved off degraded nodes
+// +kubebuilder:validation:Enum=Evict;SurgeThenEvict
+type EvictionStrategy string
+
+const (
+	// the pods are evicted right away
+	EvictionStrategyEvict EvictionStrategy = "Evict"
+	// the Deployment owning a pod is scaled up by one first, and the pod is evicted once the extra replica is Ready
+	EvictionStrategySurgeThenEvict EvictionStrategy = "SurgeThenEvict"
+)
+
+// Lease-based leader election among the pods of a workload type, as done by client-go's leaderelection package, whose
+// holder identity is the leader's pod name, optionally followed by an underscore and a unique suffix
+type LeaderElection struct {
+	// name of the Lease the pods compete for
+	LeaseName string `json:"leaseName"`
+	// namespace of the Lease; defaults to the pod's namespace
+	// +optional
+	LeaseNamespace string `json:"leaseNamespace,omitempty"`
+}
+
+// seconds a pre-eviction hook may run for when it sets no timeout
+const DefaultHookTimeoutSeconds = 30
+
+// how an eviction proceeds when a pre-eviction hook fails or times out
+// +kubebuilder:validation:Enum=Fail;Ignore
+type HookFailurePolicy string
+
+const (
+	// the eviction is abandoned and reported as failed
+	HookFailurePolicyFail HookFailurePolicy = "Fail"
+	// the failure is reported and the eviction goes ahead
+	HookFailurePolicyIgnore HookFailurePolicy = "Ignore"
+)
+
+// action run against a pod right before it is evicted; exactly one of http and exec is set
+// +kubebuilder:validation:XValidation:rule="has(self.http) != has(self.exec)",message="exactly one of http and exec must be set"
+type PreEvictionHook struct {
+	// name identifying the hook in events and logs
+	Name string `json:"name"`
+	// HTTP POST request announcing the eviction
+	// +optional
+	HTTP *HTTPHook `json:"http,omitempty"`
+	// command executed in one of the pod's containers
+	// +optional
+	Exec *ExecHook `json:"exec,omitempty"`
+	// seconds the hook may run for before it counts as failed; defaults to 30
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// whether the eviction is abandoned (Fail) or goes ahead (Ignore) when the hook fails; defaults to Fail
+	// +optional
+	FailurePolicy HookFailurePolicy `json:"failurePolicy,omitempty"`
+	// runs the hook only against the pod holding the Lease of the profile's leaderElection, e.g. to hand its leadership
+	// over before it is evicted
+	// +optional
+	LeaderOnly bool `json:"leaderOnly,omitempty"`
+}
+
+// returns the duration the hook may run for, falling back to DefaultHookTimeoutSeconds when unset
+func (h *PreEvictionHook) Timeout() time.Duration {
+	if h.TimeoutSeconds == nil {
+		return DefaultHookTimeoutSeconds * time.Second
+	}
+	return time.Duration(*h.TimeoutSeconds) * time.Second
+}
+
+// reports whether the eviction goes ahead when the hook fails
+func (h *PreEvictionHook) IgnoresFailure() bool {
+	return h.FailurePolicy == HookFailurePolicyIgnore
+}
+
+// HTTP POST request announcing the eviction of a pod, with the pod's identity as a JSON body; any 2xx response is a success
+type HTTPHook struct {
+	// URL the request is sent to; when empty, it is sent to the pod's IP on the port and path
+	// +optional
+	URL string `json:"url,omitempty"`
+	// port on the pod the request is sent to when no URL is set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// path on the pod the request is sent to when no URL is set
+	// +optional
+	Path string `json:"path,omitempty"`
+	// scheme used to reach the pod when no URL is set; defaults to HTTP
+	// +kubebuilder:validation:Enum=HTTP;HTTPS
+	// +optional
+	Scheme core.URIScheme `json:"scheme,omitempty"`
+}
+
+// command executed in one of the pod's containers; a non-zero exit status is a failure
+type ExecHook struct {
+	// command line to run, not in a shell
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+	// container the command runs in; defaults to the pod's first container
+	// +optional
+	Container string `json:"container,omitempty"`
+}
+
+// placement hints added to the governed pods as they are created, through the pod mutating webhook served with
+// --enable-pod-resource-injection, so that the replacements of evicted pods land on healthier nodes; owners' pod
+// templates are left untouched, so no workload is rolled out
+type ReschedulingHints struct {
+	// node labels the replacement pods should preferably be scheduled onto, added as a preferred node affinity term
+	// +optional
+	PreferredNodeSelector map[string]string `json:"preferredNodeSelector,omitempty"`
+	// steers the replacement pods away from nodes labelled as degraded by the controller
+	// +optional
+	AvoidDegradedNodes bool `json:"avoidDegradedNodes,omitempty"`
+	// topology keys (e.g. "topology.kubernetes.io/zone") across which the replacement pods should preferably be spread
+	// +optional
+	SpreadTopologyKeys []string `json:"spreadTopologyKeys,omitempty"`
+}
+
+// ties the pods of a workload type to a Kubernetes PriorityClass, keeping their eviction order consistent with scheduler preemption
+type PriorityClassMapping struct {
+	// name of the PriorityClass the pods are expected to run with
+	Name string `json:"name"`
+	// sets the class on the pod template of the owners of governed pods running with another one
+	// +optional
+	Reconcile bool `json:"reconcile,omitempty"`
+}
+
+// defines the desired state of WorkloadProfile
+type WorkloadProfileSpec struct {
+	// selects the pods governed by this profile, in addition to pods whose workload type label names the profile
+	// +optional
+	PodSelector *meta.LabelSelector `json:"podSelector,omitempty"`
+	// CEL expression evaluated against the pod, available as "object", which must hold for the profile to govern it
+	// (e.g. "object.spec.containers.exists(c, c.image.startsWith('nginx'))"); on its own it selects pods like a pod selector
+	// +optional
+	MatchExpression string `json:"matchExpression,omitempty"`
+	// restricts the profile to pods running on nodes matching this selector (e.g. a spot node pool);
+	// profiles scoped to a pod's node take precedence over profiles without a node selector
+	// +optional
+	NodeSelector *meta.LabelSelector `json:"nodeSelector,omitempty"`
+	// restricts the profile to pods owned by these kinds of workload, where "Pod" stands for pods without a controller;
+	// pods of other kinds are left to other profiles; every kind when empty
+	// +kubebuilder:validation:items:Enum=Deployment;StatefulSet;ReplicaSet;Job;Pod
+	// +optional
+	TargetKinds []string `json:"targetKinds,omitempty"`
+	// applies this profile to pods that match no other profile; a namespaced default takes precedence over a cluster-scoped one
+	// +optional
+	IsDefault bool `json:"isDefault,omitempty"`
+	// name of a profile whose resources and eviction settings this profile inherits wherever it leaves them unset;
+	// namespaced profiles look for the base in their own namespace before the cluster-scoped profiles
+	// +optional
+	BaseProfile string `json:"baseProfile,omitempty"`
+	// +optional
+	Resources ResourceRecommendation `json:"resources,omitempty"`
+	// +optional
+	Eviction EvictionPolicy `json:"eviction,omitempty"`
+	// minimum number of ready replicas the owner of a governed pod must keep; pods are never evicted when that would take
+	// their owner below it, whether or not a PodDisruptionBudget covers them; inherited from the base profile when unset
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinAvailable *int32 `json:"minAvailable,omitempty"`
+	// placement hints added to governed pods as they are created; inherited from the base profile when unset
+	// +optional
+	Rescheduling *ReschedulingHints `json:"rescheduling,omitempty"`
+	// PriorityClass the governed pods are expected to run with; inherited from the base profile when unset
+	// +optional
+	PriorityClass *PriorityClassMapping `json:"priorityClass,omitempty"`
+}
+
+// condition types reported on WorkloadProfile status
+const (
+	// the profile currently governs at least one pod
+	WorkloadProfileConditionActive = "Active"
+	// the profile's pod and node selectors and match expression can be evaluated
+	WorkloadProfileConditionSelectorValid = "SelectorValid"
+	// the profile's base profile chain could be resolved
+	WorkloadProfileConditionBaseProfileResolved = "BaseProfileResolved"
+	// the governed pods run with the profile's PriorityClass
+	WorkloadProfileConditionPriorityClassConsistent = "PriorityClassConsistent"
+)
+
+// deviation of one workload's pod requests from the profile's recommendation
+type WorkloadDrift struct {
+	// kind of the workload, as in targetKinds
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// CPU requested by each pod of the workload
+	// +optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+	// memory requested by each pod of the workload
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// deviation of the CPU requests from the recommendation, in percent of it; negative when under-provisioned
+	// +optional
+	CPUDriftPercent *int32 `json:"cpuDriftPercent,omitempty"`
+	// deviation of the memory requests from the recommendation, in percent of it; negative when under-provisioned
+	// +optional
+	MemoryDriftPercent *int32 `json:"memoryDriftPercent,omitempty"`
+}
+
+// deviation of the governed pods' resource requests from the profile's recommendation, beyond the controller's drift tolerance
+type ResourceDrift struct {
+	// number of governed pods requesting less CPU or memory than recommended
+	UnderProvisionedPods int32 `json:"underProvisionedPods"`
+	// number of governed pods requesting more CPU or memory than recommended
+	OverProvisionedPods int32 `json:"overProvisionedPods"`
+	// workloads drifting the furthest from the recommendation, at most ten
+	// +optional
+	Workloads []WorkloadDrift `json:"workloads,omitempty"`
+}
+
+// defines the observed state of WorkloadProfile
+type WorkloadProfileStatus struct {
+	// generation of the spec last observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// number of pods currently governed by this profile
+	MatchedPods int32 `json:"matchedPods"`
+	// number of pods evicted under this profile in the last 24 hours
+	EvictionsLast24h int32 `json:"evictionsLast24h"`
+	// time of the most recent eviction under this profile
+	// +optional
+	LastEvictionTime *meta.Time `json:"lastEvictionTime,omitempty"`
+	// drift of the governed pods' requests from the recommended resources, reported when drift reporting is enabled
+	// +optional
+	ResourceDrift *ResourceDrift `json:"resourceDrift,omitempty"`
+	// latest observations of the profile's state
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []meta.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=workloadprofiles,scope=Cluster,singular=workloadprofile
+// +kubebuilder:printcolumn:name="CPU",type="string",JSONPath=".spec.resources.cpu",description="Recommended CPU requests"
+// +kubebuilder:printcolumn:name="Memory",type="string",JSONPath=".spec.resources.memory",description="Recommended memory requests"
+// +kubebuilder:printcolumn:name="Eviction Priority",type="integer",JSONPath=".spec.eviction.priority",description="Eviction priority for the workload profile"
+// +kubebuilder:printcolumn:name="Protected",type="boolean",JSONPath=".spec.eviction.protected",description="Whether the profile's pods are exempt from eviction"
+// +kubebuilder:printcolumn:name="Matched Pods",type="integer",JSONPath=".status.matchedPods",description="Pods currently governed by the workload profile"
+// +kubebuilder:printcolumn:name="Evictions (24h)",type="integer",JSONPath=".status.evictionsLast24h",description="Pods evicted under the workload profile in the last 24 hours"
+// +kubebuilder:printcolumn:name="Last Eviction",type="date",JSONPath=".status.lastEvictionTime",description="Time of the most recent eviction"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// schema for the API
+type WorkloadProfile struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WorkloadProfileSpec   `json:"spec,omitempty"`
+	Status WorkloadProfileStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// list of several WorkloadProfile
+type WorkloadProfileList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+	Items         []WorkloadProfile `json:"items"`
+}
+
+var SchemeBuilder = &scheme.Builder{
+	GroupVersion: SchemeGroupVersion,
+}
+
+func init() {
+	SchemeBuilder.Register(&WorkloadProfile{}, &WorkloadProfileList{})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lokeshllkumar/kube-balance/internal/agent"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var scheme = runtime.NewScheme()
+var setupLog = ctrl.Log.WithName("agent")
+
+func init() {
+	utilruntime.Must(clientscheme.AddToScheme(scheme))
+}
+
+func main() {
+	var nodeName string
+	var procRoot string
+	var interval time.Duration
+	var pressureRules string
+	var pressureSustain time.Duration
+	var spotInterruptionProvider string
+	var spotInterruptionInterval time.Duration
+	var ioPressureThreshold float64
+	var ioPressureSustain time.Duration
+
+	flag.StringVar(&nodeName, "node-name", os.Getenv("NODE_NAME"), "Name of the node the agent runs on (defaults to $NODE_NAME)")
+	flag.StringVar(&procRoot, "proc-root", "/host/proc", "Mount point of the host's /proc filesystem")
+	flag.DurationVar(&interval, "interval", 15*time.Second, "Interval between node pressure samples")
+	flag.StringVar(&pressureRules, "pressure-rules", "io:full:avg10>25", "Comma-separated PSI rules of the form resource:kind:window>threshold (e.g. io:full:avg10>25,memory:full:avg60>10,cpu:some:avg60>80)")
+	flag.DurationVar(&pressureSustain, "pressure-sustain", 2*time.Minute, "How long a pressure rule must stay breached before the node is marked as degraded")
+	flag.StringVar(&spotInterruptionProvider, "spot-interruption-provider", "", "Cloud provider whose instance metadata service is polled for spot interruption notices (aws or gcp); empty disables polling")
+	flag.DurationVar(&spotInterruptionInterval, "spot-interruption-interval", 5*time.Second, "Interval between spot interruption notice polls")
+	flag.Float64Var(&ioPressureThreshold, "io-pressure-threshold", 25, "Deprecated: use --pressure-rules=io:full:avg10>THRESHOLD; adds that rule when set")
+	flag.DurationVar(&ioPressureSustain, "io-pressure-sustain", 2*time.Minute, "Deprecated: use --pressure-sustain, which it overrides when set")
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zap.Options{
+		Development: true,
+	})))
+
+	if nodeName == "" {
+		setupLog.Info("node name is required; set --node-name or $NODE_NAME")
+		os.Exit(1)
+	}
+
+	rules, err := agent.ParsePressureRules(pressureRules)
+	if err != nil {
+		setupLog.Error(err, "invalid --pressure-rules")
+		os.Exit(1)
+	}
+
+	// folding the flags of the I/O saturation detector the pressure rules replaced into them
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "io-pressure-threshold":
+			setupLog.Info("--io-pressure-threshold is deprecated, use --pressure-rules instead")
+			rules = append(rules, agent.PressureRule{Resource: "io", Kind: "full", Window: "avg10", Threshold: ioPressureThreshold})
+		case "io-pressure-sustain":
+			setupLog.Info("--io-pressure-sustain is deprecated, use --pressure-sustain instead")
+			pressureSustain = ioPressureSustain
+		}
+	})
+
+	cli, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+
+	marker := degradation.NewMarker(cli, setupLog.WithName("degradation-marker"))
+
+	runners := []func(context.Context) error{
+		(&agent.PressureDetector{
+			NodeName:   nodeName,
+			ProcRoot:   procRoot,
+			Rules:      rules,
+			SustainFor: pressureSustain,
+			Interval:   interval,
+			Marker:     marker,
+			Log:        setupLog.WithName("pressure"),
+		}).Run,
+	}
+
+	if spotInterruptionProvider != "" {
+		runners = append(runners, (&agent.SpotInterruptionWatcher{
+			NodeName:   nodeName,
+			Provider:   spotInterruptionProvider,
+			Interval:   spotInterruptionInterval,
+			HTTPClient: &http.Client{Timeout: 2 * time.Second},
+			Marker:     marker,
+			Log:        setupLog.WithName("spot-interruption"),
+		}).Run)
+	}
+
+	setupLog.Info("starting agent", "node", nodeName)
+	ctx := ctrl.SetupSignalHandler()
+
+	var wg sync.WaitGroup
+	failed := false
+	var failedMu sync.Mutex
+	for _, run := range runners {
+		wg.Add(1)
+		go func(run func(context.Context) error) {
+			defer wg.Done()
+			if err := run(ctx); err != nil {
+				setupLog.Error(err, "problem running agent")
+				failedMu.Lock()
+				failed = true
+				failedMu.Unlock()
+			}
+		}(run)
+	}
+	wg.Wait()
+
+	if failed {
+		os.Exit(1)
+	}
+}
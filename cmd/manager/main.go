@@ -1,23 +1,42 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/lokeshllkumar/kube-balance/controllers"
+	"github.com/lokeshllkumar/kube-balance/internal/detectors"
+	"github.com/lokeshllkumar/kube-balance/internal/features"
+	"github.com/lokeshllkumar/kube-balance/internal/hooks"
+	"github.com/lokeshllkumar/kube-balance/internal/injection"
+	"github.com/lokeshllkumar/kube-balance/internal/migration"
+	"github.com/lokeshllkumar/kube-balance/internal/policy"
 	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+	"github.com/lokeshllkumar/kube-balance/internal/promquery"
+	"github.com/lokeshllkumar/kube-balance/internal/receiver"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
 	"github.com/lokeshllkumar/kube-balance/pkg/eviction"
 	"github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/api/v1beta1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 var scheme = runtime.NewScheme()
@@ -26,6 +45,7 @@ var setupLog = ctrl.Log.WithName("setup")
 func init() {
 	utilruntime.Must(clientscheme.AddToScheme(scheme))
 	utilruntime.Must(v1alpha1.SchemeBuilder.AddToScheme(scheme))
+	utilruntime.Must(v1beta1.SchemeBuilder.AddToScheme(scheme))
 }
 
 func main() {
@@ -34,6 +54,94 @@ func main() {
 	var probeAddr string
 	var recheckInterval time.Duration
 	var maxEvictionsPerNodePerCycle int
+	var degradationConfirmationCycles int
+	var degradationConfirmationPeriod time.Duration
+	var receiverOpts receiver.Options
+	var enableAWSInstanceStatusDetector bool
+	var awsInstanceStatusInterval time.Duration
+	var urgentMaxEvictionsPerNodePerCycle int
+	var enableSpotInterruptionDetector bool
+	var spotInterruptionInterval time.Duration
+	var prometheusURL string
+	var enableGPUHealthDetector bool
+	var gpuHealthInterval time.Duration
+	var gpuUnhealthyNodeLabels string
+	var dcgmExporterNodeLabel string
+	var zoneDegradationThreshold float64
+	var zoneDegradationAction string
+	var zoneThrottledMaxEvictions int
+	var enableNodeFlappingDetector bool
+	var nodeFlappingWindow time.Duration
+	var nodeFlappingThreshold int
+	var nodeFlappingInterval time.Duration
+	var enableRuntimeHealthDetector bool
+	var runtimeHealthWindow time.Duration
+	var runtimeHealthThreshold float64
+	var enableFilesystemExhaustionDetector bool
+	var filesystemUsageLimit float64
+	var filesystemExhaustionHorizon time.Duration
+	var enableNetworkExhaustionDetector bool
+	var nodeExporterNodeLabel string
+	var conntrackFillThreshold float64
+	var nicErrorRateThreshold float64
+	var packetDropRateThreshold float64
+	var degradationKeys string
+	var rebalancePolicyName string
+	var enableWebhooks bool
+	var webhookPort int
+	var webhookCertDir string
+	var migrateStorageVersion bool
+	var rebalanceMode string
+	var evictionRecordTTL time.Duration
+	var enableNodeMaintenanceWindows bool
+	var managePDBs bool
+	var reportResourceDrift bool
+	var resourceDriftTolerance float64
+	var enforceRightSizing bool
+	var rightSizingThreshold float64
+	var enablePodResourceInjection bool
+	var dryRun bool
+	var paused bool
+	var evictionGracePeriodSeconds int64
+	var evictionRetryMaxAttempts int
+	var evictionRetryBaseDelay time.Duration
+	var evictionRetryMaxDelay time.Duration
+	var evictionConcurrency int
+	var waitForReschedule bool
+	var skipRollouts bool
+	var waitForRescheduleTimeout time.Duration
+	var evictLocalStorage bool
+	var pdbBlockForceDeleteAfter time.Duration
+	var pdbBlockMaxBackoff time.Duration
+	var surgeTimeout time.Duration
+	var drainMode string
+	var cordonDegradedNodes bool
+	var featureGates string
+	var stuckTerminatingForceDeleteAfter time.Duration
+	var stuckTerminatingForceDeleteStatefulSets bool
+	var checkSchedulingFeasibility bool
+	var checkClusterHeadroom bool
+	var clusterHeadroomReservePercent int
+	var preserveZoneBalance bool
+	var groupAwareEviction bool
+	var skipDebuggedPods bool
+	var restartCountWeight int
+	var minPodAge time.Duration
+	var maxEvictionsPerMinute int
+	var namespaceOptIn bool
+	var namespaceAllowlist string
+	var namespaceDenylist string
+	var protectedPriorityClasses string
+	var evictionPacing time.Duration
+	var nodeWorkers int
+	var barePodPolicy string
+	var jobPodPolicy string
+	var jobCompletionThresholdPercent int
+	var maxOwnerDisruptionPercent int
+	var pendingPodsThreshold int
+	var pendingPodsScope string
+	var podEvictionCooldown time.Duration
+	var cooldownCollectionInterval time.Duration
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to")
@@ -41,8 +149,234 @@ func main() {
 		"Enable leader election for controller manager"+"Enabling this ensures that only one controller manager instance runs at a time")
 	flag.DurationVar(&recheckInterval, "recheck-interval", 2 * time.Minute, "Interval for the controller to re-evaluate node/pod states")
 	flag.IntVar(&maxEvictionsPerNodePerCycle, "max-evictions-per-node-per-cycle", 1, "Maximum number of pods to evict from a single degraded node per reconcilation cycle")
+	flag.IntVar(&degradationConfirmationCycles, "degradation-confirmation-cycles", 1, "Number of consecutive reconcile cycles, counted at most once per recheck interval, a node must remain degraded before evictions start")
+	flag.DurationVar(&degradationConfirmationPeriod, "degradation-confirmation-period", 0, "Minimum duration a node must remain degraded before evictions start")
+	flag.StringVar(&receiverOpts.BindAddress, "degradation-receiver-bind-address", "", "The address the degradation webhook receiver binds to; empty disables the receiver")
+	flag.StringVar(&receiverOpts.TokenFile, "degradation-receiver-token-file", "", "Path to a file holding the bearer token required by the degradation receiver")
+	flag.StringVar(&receiverOpts.TLSCertFile, "degradation-receiver-tls-cert", "", "Path to the degradation receiver's TLS serving certificate")
+	flag.StringVar(&receiverOpts.TLSKeyFile, "degradation-receiver-tls-key", "", "Path to the degradation receiver's TLS serving key")
+	flag.StringVar(&receiverOpts.ClientCAFile, "degradation-receiver-client-ca", "", "Path to a CA bundle used to verify degradation receiver client certificates (mTLS)")
+	flag.BoolVar(&receiverOpts.Insecure, "degradation-receiver-insecure", false, "Allow the degradation receiver's bearer token over plain HTTP, without a serving certificate; the token can then be read by anyone on the network path")
+	flag.StringVar(&receiverOpts.AlertNodeLabel, "alertmanager-node-label", "node", "Alert label holding the name of the node an Alertmanager alert refers to")
+	flag.DurationVar(&receiverOpts.AlertTTL, "alertmanager-degradation-ttl", 0, "Lifetime of degradation markers set from firing Alertmanager alerts, and of the alerts recorded as firing on a node without being notified again; 0 keeps them until the alert resolves")
+	flag.BoolVar(&enableAWSInstanceStatusDetector, "enable-aws-instance-status-detector", false, "Mark nodes as degraded when their EC2 instance fails status checks or has a scheduled event pending")
+	flag.DurationVar(&awsInstanceStatusInterval, "aws-instance-status-interval", time.Minute, "Interval between EC2 instance status checks")
+	flag.StringVar(&namespaceAllowlist, "namespace-allowlist", "", "Comma-separated namespaces whose pods are the only ones considered for rebalancing; empty means all namespaces, except kube-system unless listed")
+	flag.StringVar(&namespaceDenylist, "namespace-denylist", "", "Comma-separated namespaces whose pods are never considered for rebalancing")
+	flag.StringVar(&protectedPriorityClasses, "protected-priority-classes", "", "Comma-separated PriorityClasses whose pods are never evicted, on top of system-cluster-critical and system-node-critical")
+	flag.BoolVar(&namespaceOptIn, "namespace-opt-in", false, "Only consider pods in namespaces annotated with kube-balance.io/enabled: \"true\"; namespaces annotated with \"false\" are never considered either way")
+	flag.DurationVar(&cooldownCollectionInterval, "cooldown-collection-interval", 10*time.Minute, "Interval between sweeps removing expired eviction cooldown annotations from workloads; 0 leaves them in place")
+	flag.DurationVar(&podEvictionCooldown, "pod-eviction-cooldown", 10*time.Minute, "Time a pod name is exempt from eviction after a pod of that name was evicted, so that a StatefulSet pod recreated on another degraded node isn't evicted again right away; 0 disables it")
+	flag.IntVar(&pendingPodsThreshold, "pending-pods-threshold", 0, "Pending pods past which all evictions are paused until the scheduler catches up; 0 never pauses them")
+	flag.StringVar(&pendingPodsScope, "pending-pods-scope", controllers.PendingPodsScopeCluster, "Pending pods counted towards --pending-pods-threshold: cluster for every Pending pod, evicted for the replacements of pods kube-balance evicted")
+	flag.IntVar(&maxOwnerDisruptionPercent, "max-owner-disruption-percent", 25, "Share of a Deployment's, StatefulSet's or ReplicaSet's desired replicas, in percent, that may be evicted or rescheduled at once across all nodes and cycles, at least one; 0 disables the limit")
+	flag.IntVar(&maxEvictionsPerMinute, "max-evictions-per-minute", 0, "Maximum number of pods evicted per minute across all nodes and strategies, so that a mass degradation can't churn the cluster; 0 disables the limit")
+	flag.IntVar(&urgentMaxEvictionsPerNodePerCycle, "urgent-max-evictions-per-node-per-cycle", 10, "Maximum number of pods to evict from a single urgently degraded node (e.g. a spot interruption) per reconcilation cycle")
+	flag.BoolVar(&enableSpotInterruptionDetector, "enable-spot-interruption-detector", false, "Mark nodes carrying spot/preemptible termination handler taints as urgently degraded")
+	flag.DurationVar(&spotInterruptionInterval, "spot-interruption-interval", 10*time.Second, "Interval between spot interruption taint checks")
+	flag.StringVar(&prometheusURL, "prometheus-url", "", "Base URL of the Prometheus server queried by metric-based detectors and for the node costs exported by OpenCost")
+	flag.BoolVar(&enableGPUHealthDetector, "enable-gpu-health-detector", false, "Mark GPU nodes as degraded when DCGM exporter metrics report XID/ECC errors or an unhealthy GPU label is present")
+	flag.DurationVar(&gpuHealthInterval, "gpu-health-interval", time.Minute, "Interval between GPU health checks")
+	flag.StringVar(&gpuUnhealthyNodeLabels, "gpu-unhealthy-node-labels", "", "Comma-separated key=value node labels that flag a node's GPUs as unhealthy")
+	flag.StringVar(&dcgmExporterNodeLabel, "dcgm-exporter-node-label", detectors.DefaultGPUNodeLabel, "Label on DCGM exporter series holding the node name; the exporter's Hostname label holds its pod's hostname, so relabel __meta_kubernetes_pod_node_name into this label")
+	flag.Float64Var(&zoneDegradationThreshold, "zone-degradation-threshold", 0.5, "Share of a topology zone's nodes that must be degraded at once for the zone to be treated as a correlated failure; 0 disables the check")
+	flag.StringVar(&zoneDegradationAction, "zone-degradation-action", controllers.ZoneDegradationActionThrottle, "Action taken for zones with a correlated failure: pause or throttle")
+	flag.IntVar(&zoneThrottledMaxEvictions, "zone-throttled-max-evictions", 1, "Maximum number of pods to evict per reconcilation cycle across all degraded nodes of a throttled zone")
+	flag.BoolVar(&enableNodeFlappingDetector, "enable-node-flapping-detector", false, "Mark nodes whose Ready condition repeatedly flips between Ready and NotReady as degraded")
+	flag.DurationVar(&nodeFlappingWindow, "node-flapping-window", 30*time.Minute, "Sliding window over which node Ready transitions are counted")
+	flag.IntVar(&nodeFlappingThreshold, "node-flapping-threshold", 4, "Number of Ready transitions within the window at which a node counts as flapping")
+	flag.DurationVar(&nodeFlappingInterval, "node-flapping-interval", 15*time.Second, "Interval between node flapping checks; transitions are recorded as they happen")
+	flag.BoolVar(&enableRuntimeHealthDetector, "enable-runtime-health-detector", false, "Mark nodes with high rates of container runtime failure events (sandbox failures, image pull timeouts, runtime restarts) as degraded")
+	flag.DurationVar(&runtimeHealthWindow, "runtime-health-window", 15*time.Minute, "Window over which container runtime failure events are scored")
+	flag.Float64Var(&runtimeHealthThreshold, "runtime-health-threshold", 10, "Container runtime degradation score at which a node is marked as degraded")
+	flag.BoolVar(&enableFilesystemExhaustionDetector, "enable-filesystem-exhaustion-detector", false, "Mark nodes whose filesystem space or inode usage is predicted to reach the usage limit within the horizon")
+	flag.Float64Var(&filesystemUsageLimit, "filesystem-usage-limit", 0.85, "Used fraction of node/image filesystem space or inodes treated as exhausted; keep it below the kubelet's hard eviction thresholds")
+	flag.DurationVar(&filesystemExhaustionHorizon, "filesystem-exhaustion-horizon", 30*time.Minute, "How far ahead filesystem exhaustion is predicted")
+	flag.BoolVar(&enableNetworkExhaustionDetector, "enable-network-exhaustion-detector", false, "Mark nodes as degraded on network resource exhaustion signals (conntrack table fill, NIC errors, packet drops) from node-exporter metrics")
+	flag.StringVar(&nodeExporterNodeLabel, "node-exporter-node-label", "node", "Label on node-exporter series holding the node name")
+	flag.Float64Var(&conntrackFillThreshold, "conntrack-fill-threshold", 0.9, "Conntrack table fill ratio above which a node is marked as degraded")
+	flag.Float64Var(&nicErrorRateThreshold, "nic-error-rate-threshold", 10, "NIC receive/transmit errors per second above which a node is marked as degraded")
+	flag.Float64Var(&packetDropRateThreshold, "packet-drop-rate-threshold", 100, "Packets dropped per second above which a node is marked as degraded")
+	flag.StringVar(&degradationKeys, "degradation-keys", "", "Comma-separated node annotations/labels that additionally mark a node as degraded, of the form annotation:<name>[=<value>] or label:<name>[=<value>]")
+	flag.StringVar(&rebalancePolicyName, "rebalance-policy-name", policy.DefaultPolicyName, "Name of the cluster-scoped RebalancePolicy whose fields override these flags at runtime")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true, "Serve the WorkloadProfile conversion webhook; required while the CRDs use webhook conversion")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "Port the webhook server listens on")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "Directory holding the webhook server's tls.crt and tls.key")
+	flag.BoolVar(&migrateStorageVersion, "migrate-storage-version", true, "Rewrite stored WorkloadProfiles in the v1beta1 storage version and drop v1alpha1 from the CRDs' stored versions")
+	flag.StringVar(&rebalanceMode, "rebalance-mode", controllers.RebalanceModeApply, "Whether RebalancePlans are executed as soon as they are written (apply) or only once approved (plan)")
+	flag.Int64Var(&evictionGracePeriodSeconds, "eviction-grace-period-seconds", eviction.DefaultGracePeriodSeconds, "Seconds granted to evicted pods to terminate gracefully unless their workload profile sets a grace period; a pod's own terminationGracePeriodSeconds is honoured when longer")
+	flag.IntVar(&evictionRetryMaxAttempts, "eviction-retry-max-attempts", 5, "Attempts after which an eviction blocked by a PodDisruptionBudget or failing on a transient API error is given up; 1 disables individual retries")
+	flag.DurationVar(&evictionRetryBaseDelay, "eviction-retry-base-delay", 5*time.Second, "Backoff before the first retry of a failed eviction, doubled on every further attempt")
+	flag.DurationVar(&evictionRetryMaxDelay, "eviction-retry-max-delay", 5*time.Minute, "Upper bound of the backoff between the retries of a failed eviction")
+	flag.IntVar(&evictionConcurrency, "eviction-concurrency", eviction.DefaultConcurrency, "Evictions of a rebalance plan sent at once, in parallel, when evictions are retried individually; 1 evicts a single pod at a time")
+	flag.IntVar(&nodeWorkers, "node-workers", 4, "Nodes whose eviction candidates are checked in parallel, e.g. against their PodDisruptionBudgets, while planning evictions")
+	flag.DurationVar(&evictionPacing, "eviction-pacing", time.Second, "Delay between the batches of evictions sent while carrying out a rebalance plan, whose evictions, up to --max-evictions-per-node-per-cycle per node, are all sent within a single reconcile; 0 sends them back to back")
+	flag.BoolVar(&skipRollouts, "skip-rollouts", true, "Leave the pods of Deployments, StatefulSets and Argo Rollouts rolling out a new revision in place until the rollout completes, unless their node is urgently degraded")
+	flag.BoolVar(&waitForReschedule, "wait-for-reschedule", false, "Hold back the eviction of a workload's other pods until the pod evicted last has a replacement scheduled and Ready on a node that isn't degraded")
+	flag.DurationVar(&waitForRescheduleTimeout, "wait-for-reschedule-timeout", 10*time.Minute, "Duration after an eviction beyond which a workload's other pods are no longer held back waiting for its replacement; 0 waits indefinitely")
+	flag.BoolVar(&evictLocalStorage, "evict-local-storage", false, "Evict pods using emptyDir volumes or local PersistentVolumes, losing the data kept on the node, unless their workload profile says otherwise")
+	flag.DurationVar(&pdbBlockMaxBackoff, "pdb-block-max-backoff", 10*time.Minute, "Longest a pod found blocked by its PodDisruptionBudget backs off before it is checked again, its backoff starting at the recheck interval and doubling with each check still finding it blocked")
+	flag.DurationVar(&pdbBlockForceDeleteAfter, "pdb-block-force-delete-after", 0, "Duration a pod on a degraded node may stay blocked by its PodDisruptionBudget before it is deleted outright, violating the budget; 0 never deletes pods")
+	flag.DurationVar(&surgeTimeout, "surge-timeout", 10*time.Minute, "Duration a Deployment scaled up ahead of an eviction may take to get its extra replica Ready before the pod is evicted regardless; 0 waits indefinitely")
+	flag.DurationVar(&stuckTerminatingForceDeleteAfter, "stuck-terminating-force-delete-after", 0, "Duration a pod kube-balance evicted from an unreachable degraded node may stay terminating past its grace period before it is deleted outright; 0 only reports such pods")
+	flag.BoolVar(&stuckTerminatingForceDeleteStatefulSets, "stuck-terminating-force-delete-statefulsets", false, "Force-delete StatefulSet pods stuck terminating too; their replacement, sharing their identity and volumes, may then run before the unreachable node stops them")
+	flag.BoolVar(&checkClusterHeadroom, "check-cluster-headroom", true, "Throttle evictions to the CPU and memory left free across the Ready, schedulable nodes that aren't degraded, skipping the pods the rest of the cluster has no room to reschedule")
+	flag.IntVar(&clusterHeadroomReservePercent, "cluster-headroom-reserve-percent", 0, "Share of the allocatable CPU and memory of the nodes that aren't degraded, in percent, kept free when checking the cluster headroom")
+	flag.BoolVar(&skipDebuggedPods, "skip-debugged-pods", true, "Defer the eviction of pods with a running ephemeral container, e.g. an active kubectl debug session, unless their node is urgently degraded")
+	flag.BoolVar(&groupAwareEviction, "group-aware-eviction", true, "Evict the pods of a group, named by the kube-balance.io/pod-group label or by Kueue, coscheduling or Volcano, together or not at all")
+	flag.BoolVar(&preserveZoneBalance, "preserve-zone-balance", true, "Leave a workload's last replica in a topology zone in place when none of the zone's nodes that aren't degraded could take its replacement, unless its node is urgently degraded")
+	flag.BoolVar(&checkSchedulingFeasibility, "check-scheduling-feasibility", true, "Skip the evictions of pods that would fit on none of the Ready, schedulable nodes that aren't degraded, given their requests, node selector, required node affinity and tolerations")
+	flag.IntVar(&restartCountWeight, "restart-count-weight", 1, "Eviction priority points a pod on a degraded node gains per container restart, pods in CrashLoopBackOff being evicted first; 0 leaves restarts out of the eviction order")
+	flag.DurationVar(&minPodAge, "min-pod-age", 0, "Minimum age of a pod before it may be evicted, unless its workload profile sets eviction.minPodAge, so that a pod rescheduled back onto a degraded node isn't evicted again right away; urgently degraded nodes evict younger pods too")
+	flag.StringVar(&barePodPolicy, "bare-pod-policy", controllers.UnmanagedPodPolicyEvictWithWarning, "Handling of pods without a controller, which nothing recreates once evicted: skip, evict-with-warning or evict-if-profiled")
+	flag.StringVar(&jobPodPolicy, "job-pod-policy", controllers.UnmanagedPodPolicySkip, "Handling of pods owned by a Job, including those of CronJobs, whose progress is lost when evicted: skip, evict-with-warning or evict-if-profiled")
+	flag.IntVar(&jobCompletionThresholdPercent, "job-completion-threshold-percent", 80, "Estimated progress, in percent, from which the pods of a Job are left to finish on their node rather than losing their work, unless it is urgently degraded; 0 evicts them whatever their progress")
+	flag.BoolVar(&cordonDegradedNodes, "cordon-degraded-nodes", false, "Cordon the degraded nodes evacuated a few pods per cycle too, uncordoning them once their degradation clears, so that the scheduler doesn't keep placing new pods on them")
+	flag.StringVar(&drainMode, "drain-mode", controllers.DrainModeOff, "Degraded nodes fully drained at once, cordoned and with every evictable pod evicted, rather than a few pods per cycle: off, urgent or all")
+	flag.BoolVar(&dryRun, "dry-run", false, "Send evictions as server-side dry runs, reporting the pods that would be evicted through events, logs and metrics without evicting them")
+	flag.BoolVar(&paused, "paused", false, "Halt all evictions and forced deletions, still detecting degraded nodes and planning and reporting the evictions that would be carried out; a RebalancePolicy can pause evictions too, but can't resume those paused by this flag")
+	flag.DurationVar(&evictionRecordTTL, "eviction-record-ttl", 7*24*time.Hour, "Age after which EvictionRecords are deleted; 0 keeps them forever")
+	flag.BoolVar(&enableNodeMaintenanceWindows, "enable-node-maintenance-windows", true, "Drain nodes covered by a NodeMaintenanceWindow ahead of the planned maintenance")
+	flag.BoolVar(&reportResourceDrift, "report-resource-drift", false, "Report how far the resource requests of the pods governed by each workload profile drift from its recommendation, on the profile's status and as metrics")
+	flag.Float64Var(&resourceDriftTolerance, "resource-drift-tolerance", 0.2, "Relative deviation from a workload profile's recommended requests beyond which a pod's requests count as drifting")
+	flag.BoolVar(&enforceRightSizing, "enforce-right-sizing", false, "Rewrite the requests of Deployments and StatefulSets that deviate from their workload profile's recommendation by more than the right-sizing threshold")
+	flag.Float64Var(&rightSizingThreshold, "right-sizing-threshold", 0.5, "Relative deviation from a workload profile's recommended requests beyond which a workload is right-sized")
+	flag.BoolVar(&enablePodResourceInjection, "enable-pod-resource-injection", false, "Serve the pod mutating webhook setting the requests pods don't declare to their workload profile's recommendation and adding the profile's rescheduling hints; requires --enable-webhooks")
+	flag.BoolVar(&managePDBs, "manage-pdbs", false, "Create and manage PodDisruptionBudgets for workloads governed by a workload profile with a minAvailable")
+	flag.StringVar(&featureGates, "feature-gates", "", "Comma-separated list of <feature>=<bool> pairs switching alpha features on or off; known features: "+strings.Join(features.Known(), ", "))
 	flag.Parse()
 
+	if zoneDegradationAction != controllers.ZoneDegradationActionPause && zoneDegradationAction != controllers.ZoneDegradationActionThrottle {
+		fmt.Fprintf(os.Stderr, "invalid --zone-degradation-action %q: must be %q or %q\n", zoneDegradationAction, controllers.ZoneDegradationActionPause, controllers.ZoneDegradationActionThrottle)
+		os.Exit(1)
+	}
+
+	if rebalanceMode != controllers.RebalanceModeApply && rebalanceMode != controllers.RebalanceModePlan {
+		fmt.Fprintf(os.Stderr, "invalid --rebalance-mode %q: must be %q or %q\n", rebalanceMode, controllers.RebalanceModeApply, controllers.RebalanceModePlan)
+		os.Exit(1)
+	}
+
+	for name, policy := range map[string]string{"bare-pod-policy": barePodPolicy, "job-pod-policy": jobPodPolicy} {
+		if policy != controllers.UnmanagedPodPolicySkip && policy != controllers.UnmanagedPodPolicyEvictWithWarning && policy != controllers.UnmanagedPodPolicyEvictIfProfiled {
+			fmt.Fprintf(os.Stderr, "invalid --%s %q: must be %q, %q or %q\n", name, policy, controllers.UnmanagedPodPolicySkip, controllers.UnmanagedPodPolicyEvictWithWarning, controllers.UnmanagedPodPolicyEvictIfProfiled)
+			os.Exit(1)
+		}
+	}
+	if drainMode != controllers.DrainModeOff && drainMode != controllers.DrainModeUrgent && drainMode != controllers.DrainModeAll {
+		fmt.Fprintf(os.Stderr, "invalid --drain-mode %q: must be %q, %q or %q\n", drainMode, controllers.DrainModeOff, controllers.DrainModeUrgent, controllers.DrainModeAll)
+		os.Exit(1)
+	}
+
+	if resourceDriftTolerance < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --resource-drift-tolerance %v: must not be negative\n", resourceDriftTolerance)
+		os.Exit(1)
+	}
+
+	if rightSizingThreshold < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --right-sizing-threshold %v: must not be negative\n", rightSizingThreshold)
+		os.Exit(1)
+	}
+
+	if evictionGracePeriodSeconds < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --eviction-grace-period-seconds %d: must not be negative\n", evictionGracePeriodSeconds)
+		os.Exit(1)
+	}
+
+	if evictionRetryMaxAttempts < 1 {
+		fmt.Fprintf(os.Stderr, "invalid --eviction-retry-max-attempts %d: must be at least 1\n", evictionRetryMaxAttempts)
+		os.Exit(1)
+	}
+	if evictionConcurrency < 1 {
+		fmt.Fprintf(os.Stderr, "invalid --eviction-concurrency %d: must be at least 1\n", evictionConcurrency)
+		os.Exit(1)
+	}
+	if nodeWorkers < 1 {
+		fmt.Fprintf(os.Stderr, "invalid --node-workers %d: must be at least 1\n", nodeWorkers)
+		os.Exit(1)
+	}
+	if evictionPacing < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --eviction-pacing %v: must not be negative\n", evictionPacing)
+		os.Exit(1)
+	}
+
+	if waitForRescheduleTimeout < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --wait-for-reschedule-timeout %v: must not be negative\n", waitForRescheduleTimeout)
+		os.Exit(1)
+	}
+	if pdbBlockMaxBackoff < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --pdb-block-max-backoff %v: must not be negative\n", pdbBlockMaxBackoff)
+		os.Exit(1)
+	}
+	if pdbBlockForceDeleteAfter < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --pdb-block-force-delete-after %v: must not be negative\n", pdbBlockForceDeleteAfter)
+		os.Exit(1)
+	}
+	if surgeTimeout < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --surge-timeout %v: must not be negative\n", surgeTimeout)
+		os.Exit(1)
+	}
+	if maxEvictionsPerMinute < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --max-evictions-per-minute %d: must not be negative\n", maxEvictionsPerMinute)
+		os.Exit(1)
+	}
+	if cooldownCollectionInterval < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --cooldown-collection-interval %v: must not be negative\n", cooldownCollectionInterval)
+		os.Exit(1)
+	}
+	if podEvictionCooldown < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --pod-eviction-cooldown %v: must not be negative\n", podEvictionCooldown)
+		os.Exit(1)
+	}
+	if pendingPodsThreshold < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --pending-pods-threshold %d: must not be negative\n", pendingPodsThreshold)
+		os.Exit(1)
+	}
+	if pendingPodsScope != controllers.PendingPodsScopeCluster && pendingPodsScope != controllers.PendingPodsScopeEvicted {
+		fmt.Fprintf(os.Stderr, "invalid --pending-pods-scope %q: must be %q or %q\n", pendingPodsScope, controllers.PendingPodsScopeCluster, controllers.PendingPodsScopeEvicted)
+		os.Exit(1)
+	}
+	if clusterHeadroomReservePercent < 0 || clusterHeadroomReservePercent > 100 {
+		fmt.Fprintf(os.Stderr, "invalid --cluster-headroom-reserve-percent %d: must be between 0 and 100\n", clusterHeadroomReservePercent)
+		os.Exit(1)
+	}
+	if jobCompletionThresholdPercent < 0 || jobCompletionThresholdPercent > 100 {
+		fmt.Fprintf(os.Stderr, "invalid --job-completion-threshold-percent %d: must be between 0 and 100\n", jobCompletionThresholdPercent)
+		os.Exit(1)
+	}
+	if maxOwnerDisruptionPercent < 0 || maxOwnerDisruptionPercent > 100 {
+		fmt.Fprintf(os.Stderr, "invalid --max-owner-disruption-percent %d: must be between 0 and 100\n", maxOwnerDisruptionPercent)
+		os.Exit(1)
+	}
+	if minPodAge < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --min-pod-age %v: must not be negative\n", minPodAge)
+		os.Exit(1)
+	}
+	if restartCountWeight < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --restart-count-weight %d: must not be negative\n", restartCountWeight)
+		os.Exit(1)
+	}
+	if stuckTerminatingForceDeleteAfter < 0 {
+		fmt.Fprintf(os.Stderr, "invalid --stuck-terminating-force-delete-after %v: must not be negative\n", stuckTerminatingForceDeleteAfter)
+		os.Exit(1)
+	}
+
+	if enablePodResourceInjection && !enableWebhooks {
+		fmt.Fprintf(os.Stderr, "invalid --enable-pod-resource-injection: requires --enable-webhooks\n")
+		os.Exit(1)
+	}
+
+	gates, err := features.Parse(featureGates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --feature-gates: %v\n", err)
+		os.Exit(1)
+	}
+
+	keys, err := degradation.ParseKeys(degradationKeys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --degradation-keys: %v\n", err)
+		os.Exit(1)
+	}
+
 	// configuring the K8s plugin logger
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zap.Options{
 		Development: true,
@@ -55,27 +389,122 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection: enableLeaderElection,
 		LeaderElectionID: "kube-balance-leader-election",
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port: webhookPort,
+			CertDir: webhookCertDir,
+		}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	// creating a new Evictor instance to perform pod evictions
-	evictor := eviction.NewEvictor(mgr.GetClient(), setupLog.WithName("evictor"))
+	// serving conversion between the v1alpha1 and v1beta1 workload profile APIs
+	if enableWebhooks {
+		for _, hub := range []client.Object{&v1beta1.WorkloadProfile{}, &v1beta1.NamespacedWorkloadProfile{}} {
+			if err := ctrl.NewWebhookManagedBy(mgr).For(hub).Complete(); err != nil {
+				setupLog.Error(err, "unable to create conversion webhook", "kind", fmt.Sprintf("%T", hub))
+				os.Exit(1)
+			}
+		}
+	}
+
+	// creating a new Evictor instance to perform pod evictions, through EvictionRequests when the feature is enabled
+	var evictor eviction.Evictor = eviction.NewEvictor(mgr.GetClient(), setupLog.WithName("evictor"), eviction.WithDefaultGracePeriodSeconds(evictionGracePeriodSeconds), eviction.WithConcurrency(evictionConcurrency))
+	if gates.Enabled(features.EvictionRequest) {
+		setupLog.Info("evicting pods through EvictionRequests", "requester", eviction.DefaultRequester)
+		evictor = eviction.NewEvictionRequestEvictor(mgr.GetClient(), setupLog.WithName("evictor"), evictionConcurrency)
+	}
+
+	// creating a new hook runner to run the pre-eviction hooks of workload profiles
+	hookRunner, err := hooks.NewRunner(mgr.GetConfig(), setupLog.WithName("hooks"), mgr.GetEventRecorderFor("kube-balance-controller"))
+	if err != nil {
+		setupLog.Error(err, "unable to create pre-eviction hook runner")
+		os.Exit(1)
+	}
 
 	// creating a new WorkloadProfileWatcher instance
 	profileWatcher := profiles.NewWorkloadProfileWatcher(mgr.GetClient(), mgr.GetCache(), setupLog.WithName("profile-watcher"))
 
+	// serving the pod mutating webhook injecting profile resource recommendations, if enabled
+	if enablePodResourceInjection {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(&core.Pod{}).WithDefaulter(&injection.PodResourceInjector{
+			Log: ctrl.Log.WithName("webhooks").WithName("PodResourceInjector"),
+			ProfilerWatcher: profileWatcher,
+		}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create pod resource injection webhook")
+			os.Exit(1)
+		}
+	}
+
+	// creating a new RebalancePolicyWatcher instance
+	policyWatcher := policy.NewRebalancePolicyWatcher(mgr.GetCache(), rebalancePolicyName, setupLog.WithName("policy-watcher"))
+
+	var prom *promquery.Client
+	if prometheusURL != "" {
+		prom = promquery.NewClient(prometheusURL)
+	}
+
 	if err = (&controllers.PodRebalancer{
 		Client: mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
 		Scheme: mgr.GetScheme(),
 		Log: ctrl.Log.WithName("controllers").WithName("PodRebalancer"),
 		Evictor: evictor,
+		Hooks: hookRunner,
+		Prometheus: prom,
 		ProfilerWatcher: profileWatcher,
+		PolicyWatcher: policyWatcher,
+		DegradationClassifier: &degradation.Classifier{Keys: keys},
 		RecheckInterval: recheckInterval,
 		MaxEvictionsPerNodePerCycle: maxEvictionsPerNodePerCycle,
 		Recorder: mgr.GetEventRecorderFor("kube-balance-controller"),
+		DegradationConfirmationCycles: degradationConfirmationCycles,
+		DegradationConfirmationPeriod: degradationConfirmationPeriod,
+		UrgentMaxEvictionsPerNodePerCycle: urgentMaxEvictionsPerNodePerCycle,
+		ZoneDegradationThreshold: zoneDegradationThreshold,
+		ZoneDegradationAction: zoneDegradationAction,
+		ZoneThrottledMaxEvictions: zoneThrottledMaxEvictions,
+		RebalanceMode: rebalanceMode,
+		DryRun: dryRun,
+		Paused: paused,
+		EvictionRetryMaxAttempts: evictionRetryMaxAttempts,
+		EvictionRetryBaseDelay: evictionRetryBaseDelay,
+		EvictionRetryMaxDelay: evictionRetryMaxDelay,
+		EvictionConcurrency: evictionConcurrency,
+		EvictionPacing: evictionPacing,
+		NodeWorkers: nodeWorkers,
+		WaitForReschedule: waitForReschedule,
+		SkipRollouts: skipRollouts,
+		WaitForRescheduleTimeout: waitForRescheduleTimeout,
+		EvictLocalStorage: evictLocalStorage,
+		PDBBlockForceDeleteAfter: pdbBlockForceDeleteAfter,
+		PDBBlockMaxBackoff: pdbBlockMaxBackoff,
+		SurgeTimeout: surgeTimeout,
+		DrainMode: drainMode,
+		CordonDegradedNodes: cordonDegradedNodes,
+		BarePodPolicy: barePodPolicy,
+		JobPodPolicy: jobPodPolicy,
+		JobCompletionThresholdPercent: jobCompletionThresholdPercent,
+		MaxOwnerDisruptionPercent: maxOwnerDisruptionPercent,
+		PodEvictionCooldown: podEvictionCooldown,
+		PendingPodsThreshold: pendingPodsThreshold,
+		PendingPodsScope: pendingPodsScope,
+		StuckTerminatingForceDeleteAfter: stuckTerminatingForceDeleteAfter,
+		StuckTerminatingForceDeleteStatefulSets: stuckTerminatingForceDeleteStatefulSets,
+		CheckSchedulingFeasibility: checkSchedulingFeasibility,
+		CheckClusterHeadroom: checkClusterHeadroom,
+		ClusterHeadroomReservePercent: clusterHeadroomReservePercent,
+		PreserveZoneBalance: preserveZoneBalance,
+		GroupAwareEviction: groupAwareEviction,
+		SkipDebuggedPods: skipDebuggedPods,
+		RestartCountWeight: restartCountWeight,
+		MinPodAge: minPodAge,
+		MaxEvictionsPerMinute: maxEvictionsPerMinute,
+		NamespaceOptIn: namespaceOptIn,
+		IncludedNamespaces: parseList(namespaceAllowlist),
+		ExcludedNamespaces: parseList(namespaceDenylist),
+		ProtectedPriorityClasses: parseList(protectedPriorityClasses),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PodRebalancer")
 		os.Exit(1)
@@ -87,6 +516,205 @@ func main() {
 		os.Exit(1)
 	}
 
+	// starting the RebalancePolicyWatcher
+	if err := mgr.Add(policyWatcher); err != nil {
+		setupLog.Error(err, "unable to add policy watcher to manager")
+		os.Exit(1)
+	}
+
+	// migrating stored workload profiles to the v1beta1 storage version, if enabled
+	if migrateStorageVersion {
+		if err := mgr.Add(&migration.StorageVersionMigrator{
+			Client: mgr.GetClient(),
+			Log: ctrl.Log.WithName("storage-version-migrator"),
+			StorageVersion: v1beta1.SchemeGroupVersion.Version,
+			Resources: []migration.Resource{
+				{CRDName: "workloadprofiles.kube-balance.io", List: &v1beta1.WorkloadProfileList{}},
+				{CRDName: "namespacedworkloadprofiles.kube-balance.io", List: &v1beta1.NamespacedWorkloadProfileList{}},
+			},
+			RetryInterval: time.Minute,
+		}); err != nil {
+			setupLog.Error(err, "unable to add storage version migrator to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the workload profile status updater
+	if err := mgr.Add(&controllers.ProfileStatusUpdater{
+		Client: mgr.GetClient(),
+		Log: ctrl.Log.WithName("controllers").WithName("ProfileStatusUpdater"),
+		ProfilerWatcher: profileWatcher,
+		Interval: recheckInterval,
+		ReportResourceDrift: reportResourceDrift,
+		ResourceDriftTolerance: resourceDriftTolerance,
+	}); err != nil {
+		setupLog.Error(err, "unable to add profile status updater to manager")
+		os.Exit(1)
+	}
+
+	// starting the PodDisruptionBudget manager, if enabled
+	if managePDBs {
+		if err := mgr.Add(&controllers.PDBManager{
+			Client: mgr.GetClient(),
+			Log: ctrl.Log.WithName("controllers").WithName("PDBManager"),
+			ProfilerWatcher: profileWatcher,
+			Interval: recheckInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add PodDisruptionBudget manager to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the right-sizer, if enabled
+	if enforceRightSizing {
+		if err := mgr.Add(&controllers.RightSizer{
+			Client: mgr.GetClient(),
+			Log: ctrl.Log.WithName("controllers").WithName("RightSizer"),
+			Recorder: mgr.GetEventRecorderFor("kube-balance-controller"),
+			ProfilerWatcher: profileWatcher,
+			Threshold: rightSizingThreshold,
+			Interval: recheckInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add right-sizer to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the eviction record garbage collector, unless records are kept forever
+	if evictionRecordTTL > 0 {
+		if err := mgr.Add(&controllers.EvictionRecordCollector{
+			Client: mgr.GetClient(),
+			Log: ctrl.Log.WithName("controllers").WithName("EvictionRecordCollector"),
+			TTL: evictionRecordTTL,
+			Interval: time.Hour,
+		}); err != nil {
+			setupLog.Error(err, "unable to add eviction record collector to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the sweeper of expired cooldown annotations, unless they are left in place
+	if cooldownCollectionInterval > 0 {
+		if err := mgr.Add(&controllers.CooldownAnnotationCollector{
+			Client: mgr.GetClient(),
+			Log: ctrl.Log.WithName("controllers").WithName("CooldownAnnotationCollector"),
+			Interval: cooldownCollectionInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add cooldown annotation collector to manager")
+			os.Exit(1)
+		}
+	}
+
+	marker := degradation.NewMarker(mgr.GetClient(), setupLog.WithName("degradation-marker"))
+
+	// starting the degradation webhook receiver, if enabled
+	if receiverOpts.BindAddress != "" {
+		degradationReceiver, err := receiver.NewServer(receiverOpts, marker, setupLog.WithName("degradation-receiver"))
+		if err != nil {
+			setupLog.Error(err, "unable to create degradation receiver")
+			os.Exit(1)
+		}
+		if err := mgr.Add(degradationReceiver); err != nil {
+			setupLog.Error(err, "unable to add degradation receiver to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the AWS instance status detector, if enabled
+	if enableAWSInstanceStatusDetector {
+		awsCfg, err := awsconfig.LoadDefaultConfig(context.Background())
+		if err != nil {
+			setupLog.Error(err, "unable to load AWS configuration")
+			os.Exit(1)
+		}
+		detector := detectors.NewAWSInstanceStatusDetector(mgr.GetClient(), ec2.NewFromConfig(awsCfg))
+		if err := mgr.Add(degradation.NewDetectorRunner(detector, marker, awsInstanceStatusInterval, setupLog.WithName("aws-instance-status-detector"))); err != nil {
+			setupLog.Error(err, "unable to add AWS instance status detector to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the spot interruption detector, if enabled
+	if enableSpotInterruptionDetector {
+		detector := detectors.NewSpotInterruptionDetector(mgr.GetClient(), detectors.DefaultSpotInterruptionTaints)
+		if err := mgr.Add(degradation.NewDetectorRunner(detector, marker, spotInterruptionInterval, setupLog.WithName("spot-interruption-detector"))); err != nil {
+			setupLog.Error(err, "unable to add spot interruption detector to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the node maintenance window detector, if enabled
+	if enableNodeMaintenanceWindows {
+		detector := detectors.NewNodeMaintenanceDetector(mgr.GetClient())
+		if err := mgr.Add(degradation.NewDetectorRunner(detector, marker, 30*time.Second, setupLog.WithName("node-maintenance-detector"))); err != nil {
+			setupLog.Error(err, "unable to add node maintenance detector to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the GPU health detector, if enabled
+	if enableGPUHealthDetector {
+		unhealthyLabels, err := parseKeyValuePairs(gpuUnhealthyNodeLabels)
+		if err != nil {
+			setupLog.Error(err, "invalid --gpu-unhealthy-node-labels")
+			os.Exit(1)
+		}
+		detector := detectors.NewGPUHealthDetector(mgr.GetClient(), prom, dcgmExporterNodeLabel, unhealthyLabels)
+		if err := mgr.Add(degradation.NewDetectorRunner(detector, marker, gpuHealthInterval, setupLog.WithName("gpu-health-detector"))); err != nil {
+			setupLog.Error(err, "unable to add GPU health detector to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the node flapping detector, if enabled
+	if enableNodeFlappingDetector {
+		detector := detectors.NewNodeFlappingDetector(mgr.GetClient(), mgr.GetCache(), nodeFlappingWindow, nodeFlappingThreshold)
+		if err := mgr.Add(detector); err != nil {
+			setupLog.Error(err, "unable to add node flapping detector to manager")
+			os.Exit(1)
+		}
+		if err := mgr.Add(degradation.NewDetectorRunner(detector, marker, nodeFlappingInterval, setupLog.WithName("node-flapping-detector"))); err != nil {
+			setupLog.Error(err, "unable to add node flapping detector to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the container runtime health detector, if enabled
+	if enableRuntimeHealthDetector {
+		detector := detectors.NewRuntimeHealthDetector(mgr.GetAPIReader(), runtimeHealthWindow, runtimeHealthThreshold)
+		if err := mgr.Add(degradation.NewDetectorRunner(detector, marker, time.Minute, setupLog.WithName("runtime-health-detector"))); err != nil {
+			setupLog.Error(err, "unable to add container runtime health detector to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the filesystem exhaustion detector, if enabled
+	if enableFilesystemExhaustionDetector {
+		clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "unable to create clientset")
+			os.Exit(1)
+		}
+		detector := detectors.NewFilesystemExhaustionDetector(mgr.GetClient(), &detectors.ProxySummaryFetcher{Clientset: clientset}, filesystemUsageLimit, filesystemExhaustionHorizon, time.Hour)
+		if err := mgr.Add(degradation.NewDetectorRunner(detector, marker, time.Minute, setupLog.WithName("filesystem-exhaustion-detector"))); err != nil {
+			setupLog.Error(err, "unable to add filesystem exhaustion detector to manager")
+			os.Exit(1)
+		}
+	}
+
+	// starting the network exhaustion detector, if enabled
+	if enableNetworkExhaustionDetector {
+		if prom == nil {
+			setupLog.Info("--enable-network-exhaustion-detector requires --prometheus-url")
+			os.Exit(1)
+		}
+		detector := detectors.NewNetworkExhaustionDetector(mgr.GetClient(), prom, nodeExporterNodeLabel, conntrackFillThreshold, nicErrorRateThreshold, packetDropRateThreshold)
+		if err := mgr.Add(degradation.NewDetectorRunner(detector, marker, time.Minute, setupLog.WithName("network-exhaustion-detector"))); err != nil {
+			setupLog.Error(err, "unable to add network exhaustion detector to manager")
+			os.Exit(1)
+		}
+	}
+
 	// add helth checks to the manager
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -103,3 +731,31 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parses a comma-separated list, dropping empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parses a comma-separated list of key=value pairs
+func parseKeyValuePairs(value string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("malformed key=value pair %q", pair)
+		}
+		pairs[key] = val
+	}
+	return pairs, nil
+}
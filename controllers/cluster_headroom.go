@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+)
+
+// resources whose cluster-wide headroom is checked before evicting pods
+var headroomResources = []core.ResourceName{core.ResourceCPU, core.ResourceMemory}
+
+// CPU and memory left free across the Ready, schedulable nodes that aren't degraded, as the evictions planned in a
+// cycle are admitted against it, along with the requests of the pods left in place for lack of it
+type clusterHeadroom struct {
+	free      core.ResourceList
+	shortfall core.ResourceList
+}
+
+// sums the CPU and memory not yet requested on the nodes evicted pods may be rescheduled onto, keeping back the given
+// share, in percent, of their allocatable resources
+func newClusterHeadroom(nodes []core.Node, pods []core.Pod, degradedNodes map[string]*core.Node, reservePercent int) *clusterHeadroom {
+	h := &clusterHeadroom{free: core.ResourceList{}, shortfall: core.ResourceList{}}
+	for _, target := range schedulingTargets(nodes, pods, degradedNodes) {
+		for _, resourceName := range headroomResources {
+			free := h.free[resourceName]
+			if available, ok := target.free[resourceName]; ok {
+				free.Add(available)
+			}
+			if allocatable, ok := target.node.Status.Allocatable[resourceName]; ok && reservePercent > 0 {
+				free.Sub(*resource.NewMilliQuantity(allocatable.MilliValue()*int64(reservePercent)/100, allocatable.Format))
+			}
+			h.free[resourceName] = free
+		}
+	}
+	return h
+}
+
+// reserves headroom for a pod to be rescheduled, reporting whether there was enough of it; the requests of a pod that
+// doesn't fit are added to the shortfall instead
+func (h *clusterHeadroom) admit(pod *core.Pod) bool {
+	requests := podSchedulingRequests(pod)
+	fits := true
+	for _, resourceName := range headroomResources {
+		request, ok := requests[resourceName]
+		if !ok {
+			continue
+		}
+		if free := h.free[resourceName]; free.Cmp(request) < 0 {
+			fits = false
+		}
+	}
+	for _, resourceName := range headroomResources {
+		request, ok := requests[resourceName]
+		if !ok {
+			continue
+		}
+		if fits {
+			free := h.free[resourceName]
+			free.Sub(request)
+			h.free[resourceName] = free
+		} else {
+			shortfall := h.shortfall[resourceName]
+			shortfall.Add(request)
+			h.shortfall[resourceName] = shortfall
+		}
+	}
+	return fits
+}
+
+// reserves headroom for a pod evicted regardless, which may leave less than none
+func (h *clusterHeadroom) reserve(pod *core.Pod) {
+	requests := podSchedulingRequests(pod)
+	for _, resourceName := range headroomResources {
+		if request, ok := requests[resourceName]; ok {
+			free := h.free[resourceName]
+			free.Sub(request)
+			h.free[resourceName] = free
+		}
+	}
+}
+
+// gives back the headroom reserved for a pod left in place after all
+func (h *clusterHeadroom) release(pod *core.Pod) {
+	requests := podSchedulingRequests(pod)
+	for _, resourceName := range headroomResources {
+		if request, ok := requests[resourceName]; ok {
+			free := h.free[resourceName]
+			free.Add(request)
+			h.free[resourceName] = free
+		}
+	}
+}
+
+// describes the requests of the pods left in place beyond the headroom, for events
+func (h *clusterHeadroom) describeShortfall() string {
+	var parts []string
+	for _, resourceName := range headroomResources {
+		if shortfall, ok := h.shortfall[resourceName]; ok && !shortfall.IsZero() {
+			parts = append(parts, fmt.Sprintf("%s %s", resourceName, shortfall.String()))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reports the requests of the pods left in place beyond the headroom in the last cycle, by resource
+func (h *clusterHeadroom) reportShortfall() {
+	for _, resourceName := range headroomResources {
+		shortfall := h.shortfall[resourceName]
+		metrics.ClusterHeadroomShortfall.WithLabelValues(string(resourceName)).Set(shortfall.AsApproximateFloat64())
+	}
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+)
+
+// counts terminating and not-yet-ready pods of a single owner
+type ownerDisruption struct {
+	terminating int
+	unready     int
+}
+
+// returns the number of the owner's pods that are mid-eviction or mid-rescheduling
+//
+// a terminating pod and the unready replacement created for it make up a single disruption, so the larger of the two counts is used
+func (d ownerDisruption) disruptions() int {
+	return max(d.terminating, d.unready)
+}
+
+// counts, per profile, the pods across the whole cluster that are currently being evicted or rescheduled
+func countProfileDisruptions(pods []core.Pod, resolve func(pod *core.Pod) (api_v1.WorkloadProfile, bool)) map[string]int {
+	owners := map[string]map[types.UID]ownerDisruption{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		terminating := pod.DeletionTimestamp != nil
+		if !terminating && podReady(pod) {
+			continue
+		}
+		profile, ok := resolve(pod)
+		if !ok || profile.Spec.Eviction.MaxConcurrent == nil {
+			continue
+		}
+
+		// bare pods are their own owner
+		ownerUID := pod.UID
+		if ref := meta.GetControllerOf(pod); ref != nil {
+			ownerUID = ref.UID
+		}
+
+		key := profiles.Key(profile)
+		if owners[key] == nil {
+			owners[key] = map[types.UID]ownerDisruption{}
+		}
+		d := owners[key][ownerUID]
+		if terminating {
+			d.terminating++
+		} else {
+			d.unready++
+		}
+		owners[key][ownerUID] = d
+	}
+
+	disruptions := make(map[string]int, len(owners))
+	for key, byOwner := range owners {
+		for _, d := range byOwner {
+			disruptions[key] += d.disruptions()
+		}
+	}
+	return disruptions
+}
+
+// counts, per controller, the pods across the whole cluster that are currently being evicted or rescheduled
+func countOwnerDisruptions(pods []core.Pod) map[types.UID]int {
+	owners := map[types.UID]ownerDisruption{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		terminating := pod.DeletionTimestamp != nil
+		if !terminating && podReady(pod) {
+			continue
+		}
+		ref := meta.GetControllerOf(pod)
+		if ref == nil {
+			continue
+		}
+		d := owners[ref.UID]
+		if terminating {
+			d.terminating++
+		} else {
+			d.unready++
+		}
+		owners[ref.UID] = d
+	}
+
+	disruptions := make(map[types.UID]int, len(owners))
+	for uid, d := range owners {
+		disruptions[uid] = d.disruptions()
+	}
+	return disruptions
+}
+
+// returns the number of an owner's pods that may be evicted or rescheduled at once, percent of its desired replicas
+// rounded down but at least one, false for owners without desired replicas or when percent is 0
+func ownerDisruptionLimit(owner client.Object, percent int) (int, bool) {
+	if percent <= 0 {
+		return 0, false
+	}
+	var replicas *int32
+	switch o := owner.(type) {
+	case *apps.Deployment:
+		replicas = o.Spec.Replicas
+	case *apps.StatefulSet:
+		replicas = o.Spec.Replicas
+	case *apps.ReplicaSet:
+		replicas = o.Spec.Replicas
+	default:
+		return 0, false
+	}
+	// the API server defaults unset replicas to 1
+	desired := 1
+	if replicas != nil {
+		desired = int(*replicas)
+	}
+	return max(desired*percent/100, 1), true
+}
+
+// reports whether a pod's Ready condition is true
+func podReady(pod *core.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == core.PodReady {
+			return condition.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
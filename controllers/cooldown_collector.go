@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// periodically removes the eviction cooldown annotations whose time has passed from pod owners, so that workloads
+// aren't left carrying stale kube-balance metadata
+type CooldownAnnotationCollector struct {
+	client.Client
+	Log      logr.Logger
+	Interval time.Duration
+}
+
+// implements the manager.Runnable interface to collect expired cooldown annotations until the context is cancelled
+func (c *CooldownAnnotationCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	c.Log.Info("starting cooldown annotation collector", "interval", c.Interval)
+	for {
+		if err := c.collect(ctx, time.Now()); err != nil {
+			c.Log.Error(err, "failed to collect expired cooldown annotations")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// removes the cooldown annotation from every Deployment, StatefulSet and ReplicaSet whose cooldown has ended, or whose
+// annotation is invalid
+func (c *CooldownAnnotationCollector) collect(ctx context.Context, now time.Time) error {
+	var owners []client.Object
+	deployments := &apps.DeploymentList{}
+	if err := c.List(ctx, deployments); err != nil {
+		return fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		owners = append(owners, &deployments.Items[i])
+	}
+	statefulSets := &apps.StatefulSetList{}
+	if err := c.List(ctx, statefulSets); err != nil {
+		return fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		owners = append(owners, &statefulSets.Items[i])
+	}
+	replicaSets := &apps.ReplicaSetList{}
+	if err := c.List(ctx, replicaSets); err != nil {
+		return fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	for i := range replicaSets.Items {
+		owners = append(owners, &replicaSets.Items[i])
+	}
+
+	removed := 0
+	for _, owner := range owners {
+		untilStr, ok := owner.GetAnnotations()[EvictionCooldownAnnotation]
+		if !ok {
+			continue
+		}
+		if until, err := time.Parse(time.RFC3339, untilStr); err == nil && now.Before(until) {
+			continue
+		}
+		patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
+		annotations := owner.GetAnnotations()
+		delete(annotations, EvictionCooldownAnnotation)
+		owner.SetAnnotations(annotations)
+		if err := c.Patch(ctx, owner, patch); err != nil && !errors.IsNotFound(err) {
+			c.Log.Error(err, "failed to remove expired cooldown annotation", "owner", owner.GetName(), "namespace", owner.GetNamespace())
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		c.Log.V(1).Info("removed expired cooldown annotations", "count", removed)
+	}
+	return nil
+}
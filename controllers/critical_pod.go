@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+
+	core "k8s.io/api/core/v1"
+)
+
+// built-in PriorityClasses of the pods a cluster or its nodes can't run without
+const (
+	SystemClusterCriticalPriorityClass = "system-cluster-critical"
+	SystemNodeCriticalPriorityClass    = "system-node-critical"
+)
+
+// lowest scheduling priority of the built-in critical PriorityClasses; user-defined PriorityClasses can't reach it
+const SystemCriticalPriority = 2000000000
+
+// legacy pod annotation marking a pod as critical, from before critical pods were given PriorityClasses
+const CriticalPodAnnotation = "scheduler.alpha.kubernetes.io/critical-pod"
+
+// returns why a pod is critical to its cluster or node, through a built-in critical PriorityClass, one of the
+// additionally protected PriorityClasses or the legacy critical-pod annotation; empty when the pod may be evicted
+func (r *PodRebalancer) criticalPodReason(pod *core.Pod) string {
+	switch className := pod.Spec.PriorityClassName; {
+	case className == SystemClusterCriticalPriorityClass || className == SystemNodeCriticalPriorityClass:
+		return fmt.Sprintf("it runs with the critical PriorityClass %s", className)
+	case className != "" && slices.Contains(r.ProtectedPriorityClasses, className):
+		return fmt.Sprintf("it runs with the protected PriorityClass %s", className)
+	case podSchedulingPriority(pod) >= SystemCriticalPriority:
+		return fmt.Sprintf("it runs with the critical scheduling priority %d", podSchedulingPriority(pod))
+	}
+	if _, ok := pod.Annotations[CriticalPodAnnotation]; ok {
+		return fmt.Sprintf("it is annotated with %s", CriticalPodAnnotation)
+	}
+	return ""
+}
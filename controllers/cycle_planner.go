@@ -0,0 +1,439 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// evictions planned in a cycle, along with the budgets the checks spanning nodes count down as they are planned
+type cyclePlanner struct {
+	r     *PodRebalancer
+	state *rebalanceState
+	// pods of each capped profile and of each owner being evicted or rescheduled, including those planned so far
+	profileDisruptions map[string]int
+	ownerDisruptions   map[types.UID]int
+	// owners with an eviction planned in the cycle
+	plannedOwners map[types.UID]bool
+	readyLeft     *readyReplicasLeft
+	budgets       *pdbBudgets
+	zoneEvictions map[string]int
+	// nodes evicted pods may land on, when scheduling feasibility is checked
+	targets []*schedulingTarget
+	// running replicas of each owner in each zone, and the nodes their replacements may land on, when zone balance is
+	// preserved
+	ownerZoneReplicas map[types.UID]map[string]int
+	zoneTargets       []*schedulingTarget
+	// free capacity of the nodes that aren't degraded, when cluster headroom is checked, and the nodes whose pods it
+	// left in place
+	headroom           *clusterHeadroom
+	headroomShortNodes map[string]*core.Node
+	// members of each pod group, by namespace and group name, when pod groups are evicted together
+	podGroups           map[string][]*core.Pod
+	plannedGroupMembers map[types.UID]bool
+	// highest ordinal of each StatefulSet on the degraded nodes, and the outcome of the checks applied to each candidate
+	// on its own node, by pod, which the members of a pod group are checked with too
+	statefulSetOrdinals map[types.UID]int
+	nodeChecks          map[types.UID]candidateCheck
+	evictions           []api_v1alpha1.PlannedEviction
+}
+
+// copy of the planner's bookkeeping, restored when a pod group turns out not to be evictable whole
+type plannerSnapshot struct {
+	profileDisruptions map[string]int
+	ownerDisruptions   map[types.UID]int
+	plannedOwners      map[types.UID]bool
+	readyLeft          map[types.UID]int32
+	pdbPlanned         map[types.NamespacedName]int32
+	zoneEvictions      map[string]int
+	ownerZoneReplicas  map[types.UID]map[string]int
+	targetsFree        []core.ResourceList
+	headroomFree       core.ResourceList
+	evictions          int
+}
+
+// sets up the bookkeeping of a cycle's evictions
+func (r *PodRebalancer) newCyclePlanner(ctx context.Context, state *rebalanceState) (*cyclePlanner, error) {
+	budgets, err := r.listPDBBudgets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p := &cyclePlanner{
+		r:     r,
+		state: state,
+		profileDisruptions: countProfileDisruptions(state.pods, func(pod *core.Pod) (api_v1.WorkloadProfile, bool) {
+			return profiles.MatchPodScoped(pod, state.nodesByName[pod.Spec.NodeName], state.namespacedProfiles, state.workloadProfiles)
+		}),
+		ownerDisruptions:    countOwnerDisruptions(state.pods),
+		plannedOwners:       map[types.UID]bool{},
+		readyLeft:           newReadyReplicasLeft(state.pods),
+		budgets:             budgets,
+		zoneEvictions:       map[string]int{},
+		headroomShortNodes:  map[string]*core.Node{},
+		plannedGroupMembers: map[types.UID]bool{},
+		statefulSetOrdinals: r.highestDegradedOrdinals(state.pods, state.degradedNodes),
+		nodeChecks:          map[types.UID]candidateCheck{},
+	}
+	if r.CheckSchedulingFeasibility {
+		p.targets = schedulingTargets(state.nodes, state.pods, state.degradedNodes)
+	}
+	if r.CheckClusterHeadroom {
+		p.headroom = newClusterHeadroom(state.nodes, state.pods, state.degradedNodes, r.ClusterHeadroomReservePercent)
+	}
+	if r.PreserveZoneBalance {
+		p.ownerZoneReplicas = countOwnerZoneReplicas(state.pods, state.nodesByName)
+		p.zoneTargets = schedulingTargets(state.nodes, state.pods, state.degradedNodes)
+	}
+	if r.GroupAwareEviction {
+		p.podGroups = groupPods(state.pods)
+	}
+	return p, nil
+}
+
+// returns why a candidate can't be planned given the evictions planned so far, or nil along with the target reserved
+// for it, if any; the room reserved for it is given back by release should it be left in place after all
+func (p *cyclePlanner) check(candidate strategyCandidate, owner client.Object) (*podSkip, *schedulingTarget) {
+	if skip := p.zoneThrottleSkip(candidate); skip != nil {
+		return skip, nil
+	}
+	if skip := p.profileConcurrencySkip(candidate); skip != nil {
+		return skip, nil
+	}
+	if skip := p.plannedOwnerSkip(candidate, owner); skip != nil {
+		return skip, nil
+	}
+	if skip := p.ownerDisruptionsSkip(candidate, owner); skip != nil {
+		return skip, nil
+	}
+	if skip := p.readyReplicasSkip(candidate, owner); skip != nil {
+		return skip, nil
+	}
+	if skip := p.pdbBudgetSkip(candidate); skip != nil {
+		return skip, nil
+	}
+	if skip := p.zoneBalanceSkip(candidate); skip != nil {
+		return skip, nil
+	}
+	if skip := p.surgeSkip(candidate, owner); skip != nil {
+		return skip, nil
+	}
+	target, skip := p.feasibleTarget(candidate)
+	if skip != nil {
+		return skip, nil
+	}
+	// checked last, as the headroom a pod is admitted against is taken from the later candidates
+	if skip := p.headroomSkip(candidate); skip != nil {
+		if target != nil {
+			target.release(candidate.pod)
+		}
+		return skip, nil
+	}
+	return nil, target
+}
+
+// gives back the room reserved for a candidate that passed check but is left in place after all
+func (p *cyclePlanner) release(candidate strategyCandidate, target *schedulingTarget) {
+	if target != nil {
+		target.release(candidate.pod)
+	}
+	if _, degraded := p.state.degradedNodes[candidate.node.Name]; p.headroom != nil && degraded {
+		p.headroom.release(candidate.pod)
+	}
+}
+
+// caps the evictions from a zone with a correlated failure
+func (p *cyclePlanner) zoneThrottleSkip(candidate strategyCandidate) *podSkip {
+	pod, node, cfg := candidate.pod, candidate.node, p.state.cfg
+	zone := node.Labels[TopologyZoneLabel]
+	if _, throttled := p.state.failingZones[zone]; !throttled || p.zoneEvictions[zone] < cfg.zoneThrottledMaxEvictions {
+		return nil
+	}
+	p.state.log.V(1).Info("reached max evictions for throttled zone in the current cycle, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "node", node.Name, "zone", zone, "maxEvictions", cfg.zoneThrottledMaxEvictions)
+	return &podSkip{}
+}
+
+// caps the pods of a profile disrupted at once across the cluster
+func (p *cyclePlanner) profileConcurrencySkip(candidate strategyCandidate) *podSkip {
+	pod, profile := candidate.pod, candidate.profile
+	if profile.Spec.Eviction.MaxConcurrent == nil {
+		return nil
+	}
+	inFlight := p.profileDisruptions[profiles.Key(profile)]
+	if inFlight < int(*profile.Spec.Eviction.MaxConcurrent) {
+		return nil
+	}
+	p.state.log.V(1).Info("profile reached its max concurrent evictions, skipping pod",
+		"pod", pod.Name, "namespace", pod.Namespace, "profile", profile.Name, "inFlight", inFlight, "maxConcurrentEvictions", *profile.Spec.Eviction.MaxConcurrent)
+	return evictionSkipped(metrics.SkipReasonProfileMaxConcurrent, fmt.Sprintf("Pod %s skipped as %d pods of profile %s are already being evicted or rescheduled", pod.Name, inFlight, profile.Name))
+}
+
+// plans a single eviction per owner, as the cooldown set by the first one holds back the others, except from drained
+// nodes and for the members of a pod group
+func (p *cyclePlanner) plannedOwnerSkip(candidate strategyCandidate, owner client.Object) *podSkip {
+	pod := candidate.pod
+	if _, draining := p.state.drains[candidate.node.Name]; draining || candidate.strategy == PodGroupStrategy || owner == nil || !p.plannedOwners[owner.GetUID()] {
+		return nil
+	}
+	p.state.log.V(1).Info("an eviction is already planned for the pod owner, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName())
+	return &podSkip{}
+}
+
+// caps the share of the owner's replicas disrupted at once, across nodes and cycles, unless the node is urgently degraded
+func (p *cyclePlanner) ownerDisruptionsSkip(candidate strategyCandidate, owner client.Object) *podSkip {
+	pod, cfg := candidate.pod, p.state.cfg
+	ownerRef := meta.GetControllerOf(pod)
+	if ownerRef == nil || owner == nil || degradation.NodeSeverity(candidate.node) == degradation.SeverityUrgent {
+		return nil
+	}
+	limit, ok := ownerDisruptionLimit(owner, cfg.maxOwnerDisruptionPercent)
+	if !ok || p.ownerDisruptions[ownerRef.UID] < limit {
+		return nil
+	}
+	p.state.log.V(1).Info("owner reached its max concurrent disruptions, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName(), "disruptions", p.ownerDisruptions[ownerRef.UID], "maxDisruptions", limit)
+	return evictionSkipped(metrics.SkipReasonOwnerDisruptions, fmt.Sprintf("Pod %s skipped as %d pods of %s are already being evicted or rescheduled, its limit at %d%% of its replicas", pod.Name, p.ownerDisruptions[ownerRef.UID], owner.GetName(), cfg.maxOwnerDisruptionPercent))
+}
+
+// keeps the owner at the profile's minimum ready replicas, and with a ready replica at all, against the ready replicas
+// the evictions planned so far left it
+func (p *cyclePlanner) readyReplicasSkip(candidate strategyCandidate, owner client.Object) *podSkip {
+	pod, profile := candidate.pod, candidate.profile
+	ready, ok := p.readyLeft.of(owner)
+	if !ok {
+		return nil
+	}
+	if candidate.profileFound && profile.Spec.MinAvailable != nil {
+		if err := checkMinAvailableLeft(pod, owner, ready, *profile.Spec.MinAvailable); err != nil {
+			p.state.log.V(1).Info("pod eviction would violate the profile's min available replicas, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "profile", profile.Name, "reason", err.Error())
+			return evictionSkipped(metrics.SkipReasonMinAvailable, fmt.Sprintf("Pod %s skipped as %v", pod.Name, err))
+		}
+	}
+	if evictsLastReplica(candidate.podProfile()) {
+		return nil
+	}
+	if err := checkLastReplicaLeft(pod, owner, ready); err != nil {
+		p.state.log.V(1).Info("pod is the last ready replica of its owner, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName())
+		return lastReplicaSkip(pod, owner, err)
+	}
+	return nil
+}
+
+// caps the evictions planned against a PodDisruptionBudget at what it allowed when the cycle started
+func (p *cyclePlanner) pdbBudgetSkip(candidate strategyCandidate) *podSkip {
+	pod := candidate.pod
+	pdbName := p.budgets.exhausted(pod)
+	if pdbName == "" {
+		return nil
+	}
+	p.state.log.V(1).Info("evictions planned in the cycle used up the pod's PDB, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "pdb", pdbName)
+	return evictionSkipped(metrics.SkipReasonPDBBudgetPlanned, fmt.Sprintf("Pod %s skipped as the evictions planned in this cycle used up the disruptions PodDisruptionBudget %s allows", pod.Name, pdbName))
+}
+
+// keeps a replica of the owner in the pod's zone when its replacement would land in another one, unless the node is
+// urgently degraded
+func (p *cyclePlanner) zoneBalanceSkip(candidate strategyCandidate) *podSkip {
+	pod, node := candidate.pod, candidate.node
+	zone := node.Labels[TopologyZoneLabel]
+	if !p.r.PreserveZoneBalance || degradation.NodeSeverity(node) == degradation.SeverityUrgent || !collapsesZone(pod, zone, p.ownerZoneReplicas, p.zoneTargets) {
+		return nil
+	}
+	p.state.log.V(1).Info("pod is the last replica of its owner in its zone, which has no room for its replacement, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "node", node.Name, "zone", zone)
+	return evictionSkipped(metrics.SkipReasonZoneBalance, fmt.Sprintf("Pod %s skipped as it is the last replica of its owner in zone %s, whose nodes that aren't degraded have no room for its replacement", pod.Name, zone))
+}
+
+// surges a Deployment for one pod at a time
+func (p *cyclePlanner) surgeSkip(candidate strategyCandidate, owner client.Object) *podSkip {
+	pod := candidate.pod
+	if candidate.profile.Spec.Eviction.Strategy != api_v1.EvictionStrategySurgeThenEvict {
+		return nil
+	}
+	deploy, isDeployment := owner.(*apps.Deployment)
+	if !isDeployment {
+		return nil
+	}
+	surged, err := surgedForOtherPod(deploy, pod)
+	if err != nil {
+		p.state.log.Error(err, "failed to read the surge of deployment, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "deployment", deploy.Name)
+		return &podSkip{}
+	}
+	if surged {
+		p.state.log.V(1).Info("deployment is surged for another pod, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "deployment", deploy.Name)
+		return &podSkip{}
+	}
+	return nil
+}
+
+// reserves room for the pod on a node that could take it, as evicting it would otherwise only leave it Pending
+func (p *cyclePlanner) feasibleTarget(candidate strategyCandidate) (*schedulingTarget, *podSkip) {
+	pod := candidate.pod
+	if !p.r.CheckSchedulingFeasibility {
+		return nil, nil
+	}
+	target := feasibleTarget(pod, p.targets)
+	if target == nil {
+		reason := noFeasibleTargetReason(p.targets)
+		p.state.log.V(1).Info("no feasible target node for pod, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "reason", reason)
+		return nil, &podSkip{
+			reason:    metrics.SkipReasonNoFeasibleTarget,
+			eventType: core.EventTypeWarning,
+			event:     "NoFeasibleTarget",
+			message:   fmt.Sprintf("Pod %s skipped as it has no feasible target: %s", pod.Name, reason),
+		}
+	}
+	p.state.log.V(1).Info("found feasible target node for pod", "pod", pod.Name, "namespace", pod.Namespace, "target", target.node.Name)
+	return target, nil
+}
+
+// admits pods moved off degraded nodes against the CPU and memory left free on the others; urgently degraded nodes are
+// evacuated regardless, and pods moved off nodes that aren't degraded free as much as they take
+func (p *cyclePlanner) headroomSkip(candidate strategyCandidate) *podSkip {
+	pod, node := candidate.pod, candidate.node
+	if _, degraded := p.state.degradedNodes[node.Name]; p.headroom == nil || !degraded {
+		return nil
+	}
+	if degradation.NodeSeverity(node) == degradation.SeverityUrgent {
+		p.headroom.reserve(pod)
+		return nil
+	}
+	if p.headroom.admit(pod) {
+		return nil
+	}
+	p.state.log.V(1).Info("not enough cluster headroom to reschedule pod, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "node", node.Name)
+	p.headroomShortNodes[node.Name] = node
+	return evictionSkipped(metrics.SkipReasonInsufficientHeadroom, fmt.Sprintf("Pod %s skipped as the nodes that aren't degraded lack the free CPU or memory to reschedule it", pod.Name))
+}
+
+// plans a candidate's eviction, counting it against the budgets of the later candidates
+func (p *cyclePlanner) plan(candidate strategyCandidate, owner client.Object) {
+	pod, node, profile := candidate.pod, candidate.node, candidate.profile
+	zone := node.Labels[TopologyZoneLabel]
+	p.evictions = append(p.evictions, api_v1alpha1.PlannedEviction{
+		Pod:         pod.Name,
+		Namespace:   pod.Namespace,
+		UID:         pod.UID,
+		Node:        node.Name,
+		Profile:     profile.Name,
+		Reason:      candidate.reason,
+		Strategy:    candidate.strategy,
+		EvictedWith: candidate.evictedWith,
+	})
+	if owner != nil {
+		p.plannedOwners[owner.GetUID()] = true
+	}
+	p.readyLeft.evict(pod, owner)
+	if ownerRef := meta.GetControllerOf(pod); ownerRef != nil {
+		p.ownerDisruptions[ownerRef.UID]++
+		if pod.Status.Phase == core.PodRunning && p.ownerZoneReplicas[ownerRef.UID][zone] > 0 {
+			p.ownerZoneReplicas[ownerRef.UID][zone]--
+		}
+	}
+	p.zoneEvictions[zone]++
+	p.profileDisruptions[profiles.Key(profile)]++
+	p.budgets.consume(pod)
+	p.r.skipReports.forget(pod.UID)
+}
+
+// plans a candidate along with the other members of its pod group, each passing the same checks, or none of them,
+// returning why the group was left in place
+func (p *cyclePlanner) planGroup(ctx context.Context, leader strategyCandidate, owner client.Object, group string) *podSkip {
+	pod, log := leader.pod, p.state.log
+	members, blocker := p.r.podGroupMembers(ctx, p.state, pod, group, p.podGroups[pod.Namespace+"/"+group])
+	snapshot := p.snapshot()
+	if blocker == nil {
+		if skip, _ := p.check(leader, owner); skip != nil {
+			return skip
+		}
+		p.plan(leader, owner)
+	}
+	for _, member := range members {
+		if blocker != nil {
+			break
+		}
+		check, ok := p.nodeChecks[member.pod.UID]
+		if !ok {
+			check = p.r.checkNodeCandidate(ctx, p.state, member, p.statefulSetOrdinals, &p.state.requeueAfter)
+			p.nodeChecks[member.pod.UID] = check
+		}
+		if !check.passed {
+			blocker = member.pod
+			break
+		}
+		if skip, _ := p.check(member, check.owner); skip != nil {
+			p.r.reportSkip(member.pod, member.profile.Name, skip)
+			blocker = member.pod
+			break
+		}
+		p.plan(member, check.owner)
+	}
+	if blocker != nil {
+		p.restore(snapshot)
+		log.V(1).Info("a member of the pod group can't be evicted, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "group", group, "member", blocker.Name)
+		return evictionSkipped(metrics.SkipReasonPodGroup, fmt.Sprintf("Pod %s skipped as pod %s of its pod group %s can't be evicted, and evicting part of the group would waste the rest", pod.Name, blocker.Name, group))
+	}
+	for _, member := range members {
+		log.Info("planning eviction of pod group member", "pod", member.pod.Name, "namespace", member.pod.Namespace, "node", member.node.Name, "group", group, "evictedWith", pod.Name)
+		p.plannedGroupMembers[member.pod.UID] = true
+	}
+	return nil
+}
+
+// copies the planner's bookkeeping
+func (p *cyclePlanner) snapshot() plannerSnapshot {
+	s := plannerSnapshot{
+		profileDisruptions: maps.Clone(p.profileDisruptions),
+		ownerDisruptions:   maps.Clone(p.ownerDisruptions),
+		plannedOwners:      maps.Clone(p.plannedOwners),
+		readyLeft:          maps.Clone(p.readyLeft.ready),
+		pdbPlanned:         maps.Clone(p.budgets.planned),
+		zoneEvictions:      maps.Clone(p.zoneEvictions),
+		ownerZoneReplicas:  make(map[types.UID]map[string]int, len(p.ownerZoneReplicas)),
+		evictions:          len(p.evictions),
+	}
+	for uid, zones := range p.ownerZoneReplicas {
+		s.ownerZoneReplicas[uid] = maps.Clone(zones)
+	}
+	for _, target := range p.targets {
+		s.targetsFree = append(s.targetsFree, target.free.DeepCopy())
+	}
+	if p.headroom != nil {
+		s.headroomFree = p.headroom.free.DeepCopy()
+	}
+	return s
+}
+
+// restores the planner's bookkeeping to a snapshot, dropping the evictions planned since
+func (p *cyclePlanner) restore(s plannerSnapshot) {
+	p.profileDisruptions, p.ownerDisruptions, p.plannedOwners = s.profileDisruptions, s.ownerDisruptions, s.plannedOwners
+	p.readyLeft.ready, p.budgets.planned, p.zoneEvictions = s.readyLeft, s.pdbPlanned, s.zoneEvictions
+	p.ownerZoneReplicas = s.ownerZoneReplicas
+	for i, target := range p.targets {
+		target.free = s.targetsFree[i]
+	}
+	if p.headroom != nil {
+		p.headroom.free = s.headroomFree
+	}
+	p.evictions = p.evictions[:s.evictions]
+}
+
+// reports the capacity missing to reschedule the pods left in place for lack of headroom
+func (p *cyclePlanner) reportHeadroom() {
+	if p.headroom == nil {
+		return
+	}
+	p.headroom.reportShortfall()
+	for nodeName, node := range p.headroomShortNodes {
+		p.state.log.Info("not enough cluster headroom to reschedule the pods of node, throttling evictions", "node", nodeName, "shortfall", p.headroom.describeShortfall())
+		p.r.Recorder.Eventf(node, core.EventTypeWarning, "InsufficientHeadroom", "Evictions from node %s throttled as the nodes that aren't degraded lack %s to reschedule the pods left in place", nodeName, p.headroom.describeShortfall())
+	}
+}
@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	core "k8s.io/api/core/v1"
+)
+
+// returns the name of an ephemeral container running in the pod, e.g. one added by `kubectl debug`, whose session the
+// pod's eviction would end, or an empty string when none is running
+func runningEphemeralContainer(pod *core.Pod) string {
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.State.Running != nil {
+			return status.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+)
+
+// records when a node was first seen degraded and for how many consecutive cycles it has stayed that way
+type degradationObservation struct {
+	firstSeen time.Time
+	cycles    int
+	// when the last cycle was counted
+	countedAt time.Time
+}
+
+// tracks continuously degraded nodes across reconcile cycles so that evictions only start once the degradation is confirmed
+type degradationTracker struct {
+	mu           sync.Mutex
+	observations map[string]*degradationObservation
+	// when each node that was degraded last recovered, until it degrades again or the recovery is forgotten
+	recoveries map[string]time.Time
+}
+
+// creates a new degradationTracker instance
+func newDegradationTracker() *degradationTracker {
+	return &degradationTracker{
+		observations: make(map[string]*degradationObservation),
+		recoveries:   make(map[string]time.Time),
+	}
+}
+
+// records a reconcile cycle in which the given nodes were observed as degraded; nodes missing from the set are forgotten, which resets their window;
+// a cycle is counted at most once per interval, as pod and workload events trigger many reconciles within one
+func (t *degradationTracker) observe(degradedNodes map[string]bool, now time.Time, interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for nodeName := range t.observations {
+		if !degradedNodes[nodeName] {
+			delete(t.observations, nodeName)
+			t.recoveries[nodeName] = now
+		}
+	}
+
+	for nodeName := range degradedNodes {
+		delete(t.recoveries, nodeName)
+		obs, ok := t.observations[nodeName]
+		if !ok {
+			obs = &degradationObservation{firstSeen: now}
+			t.observations[nodeName] = obs
+		} else if now.Sub(obs.countedAt) < interval {
+			continue
+		}
+		obs.cycles++
+		obs.countedAt = now
+	}
+}
+
+// reports whether a node has been degraded for at least the required number of cycles and duration, along with the time left until the duration requirement is met
+func (t *degradationTracker) confirmed(nodeName string, minCycles int, minDuration time.Duration, now time.Time) (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	obs, ok := t.observations[nodeName]
+	if !ok {
+		return false, minDuration
+	}
+
+	remaining := minDuration - now.Sub(obs.firstSeen)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return obs.cycles >= minCycles && remaining == 0, remaining
+}
+
+// returns when each node that was degraded recovered, for the nodes that recovered after the given time; older
+// recoveries are forgotten
+func (t *degradationTracker) recoveredSince(since time.Time) map[string]time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	recoveries := make(map[string]time.Time, len(t.recoveries))
+	for nodeName, recoveredAt := range t.recoveries {
+		if recoveredAt.Before(since) {
+			delete(t.recoveries, nodeName)
+			continue
+		}
+		recoveries[nodeName] = recoveredAt
+	}
+	return recoveries
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"context"
+
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// adds the degraded node label to degraded nodes and removes it from the others
+func (r *PodRebalancer) syncDegradedNodeLabels(ctx context.Context, nodes []core.Node, degradedNodes map[string]*core.Node) {
+	for i := range nodes {
+		node := &nodes[i]
+		_, degraded := degradedNodes[node.Name]
+		if labelled := node.Labels[degradation.DegradedNodeLabel] == "true"; labelled == degraded {
+			continue
+		}
+
+		patch := client.MergeFrom(node.DeepCopy())
+		if degraded {
+			if node.Labels == nil {
+				node.Labels = make(map[string]string)
+			}
+			node.Labels[degradation.DegradedNodeLabel] = "true"
+		} else {
+			delete(node.Labels, degradation.DegradedNodeLabel)
+		}
+		if err := r.Patch(ctx, node, patch); err != nil {
+			r.Log.Error(err, "failed to update degraded node label", "node", node.Name, "degraded", degraded)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+)
+
+// pod annotation exempting a pod from eviction by kube-balance when set to "true"
+const DoNotEvictAnnotation = "kube-balance.io/do-not-evict"
+
+// cluster autoscaler's pod annotation, exempting a pod from scale-down evictions when set to "false"
+const SafeToEvictAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+
+// Karpenter's pod annotation, exempting a pod from voluntary disruptions when set to "true"
+const DoNotDisruptAnnotation = "karpenter.sh/do-not-disrupt"
+
+// returns why a pod opted out of eviction through kube-balance's own annotation or the disruption-exclusion
+// annotations of the cluster autoscaler and Karpenter; empty when the pod may be evicted
+func doNotEvictReason(pod *core.Pod) string {
+	annotations := pod.Annotations
+	switch {
+	case annotations[DoNotEvictAnnotation] == "true":
+		return fmt.Sprintf("it is annotated with %s=true", DoNotEvictAnnotation)
+	case annotations[SafeToEvictAnnotation] == "false":
+		return fmt.Sprintf("it is annotated with %s=false", SafeToEvictAnnotation)
+	case annotations[DoNotDisruptAnnotation] == "true":
+		return fmt.Sprintf("it is annotated with %s=true", DoNotDisruptAnnotation)
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"cmp"
+
+	core "k8s.io/api/core/v1"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// criteria a node's pods are ordered by for eviction, in the order the rebalance policy lists them
+const (
+	// BestEffort pods first, then Burstable and Guaranteed pods
+	EvictionOrderQoSClass = "qos-class"
+	// pods without a workload profile first, then those with the highest eviction priority, raised by the restart
+	// count weight per container restart
+	EvictionOrderProfilePriority = "profile-priority"
+	// pods the scheduler would preempt first, those with the lowest scheduling priority
+	EvictionOrderPodPriority = "pod-priority"
+	// pods their owner marked as cheaper to lose, as the ReplicaSet controller would scale them down first
+	EvictionOrderDeletionCost = "deletion-cost"
+	// the youngest pods, which lose the least by moving, as the ReplicaSet controller would scale them down first
+	EvictionOrderAge = "age"
+	// pods with the most container restarts
+	EvictionOrderRestarts = "restarts"
+)
+
+// order pods are evicted in when the rebalance policy sets none
+var defaultEvictionOrder = []string{EvictionOrderQoSClass, EvictionOrderProfilePriority, EvictionOrderPodPriority, EvictionOrderDeletionCost}
+
+// compares two pods by an eviction order criterion, returning a negative number when podA is evicted first, a positive
+// one when podB is, and 0 when the criterion doesn't tell them apart
+func compareForEviction(criterion string, podA *core.Pod, podB *core.Pod, podProfiles map[*core.Pod]api_v1.WorkloadProfile, restartCountWeight int) int {
+	switch criterion {
+	case EvictionOrderQoSClass:
+		return cmp.Compare(qosClassToEvictionRank(getPodQoSClass(podB)), qosClassToEvictionRank(getPodQoSClass(podA)))
+	case EvictionOrderProfilePriority:
+		profileA, okA := podProfiles[podA]
+		profileB, okB := podProfiles[podB]
+		switch {
+		case !okA && !okB:
+			return 0
+		case !okA:
+			return -1
+		case !okB:
+			return 1
+		}
+		priorityA := profileA.Spec.Eviction.PriorityOrDefault() + restartCountWeight*podRestartCount(podA)
+		priorityB := profileB.Spec.Eviction.PriorityOrDefault() + restartCountWeight*podRestartCount(podB)
+		return cmp.Compare(priorityB, priorityA)
+	case EvictionOrderPodPriority:
+		return cmp.Compare(podSchedulingPriority(podA), podSchedulingPriority(podB))
+	case EvictionOrderDeletionCost:
+		return cmp.Compare(podDeletionCost(podA), podDeletionCost(podB))
+	case EvictionOrderAge:
+		return podB.CreationTimestamp.Compare(podA.CreationTimestamp.Time)
+	case EvictionOrderRestarts:
+		return cmp.Compare(podRestartCount(podB), podRestartCount(podA))
+	}
+	return 0
+}
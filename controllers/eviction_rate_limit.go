@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+)
+
+// cluster-wide token bucket limiting the evictions sent per minute across all nodes and strategies, so that a mass
+// degradation can't churn the cluster; it holds a minute's worth of evictions, refilled continuously
+type evictionRateLimiter struct {
+	mu        sync.Mutex
+	perMinute int
+	tokens    float64
+	last      time.Time
+}
+
+// creates a new evictionRateLimiter instance
+func newEvictionRateLimiter() *evictionRateLimiter {
+	return &evictionRateLimiter{}
+}
+
+// refills the bucket up to the current time, resizing it when the limit changed; the caller holds the lock
+func (l *evictionRateLimiter) refill(perMinute int, now time.Time) {
+	if perMinute != l.perMinute || l.last.IsZero() {
+		// a new or changed limit starts out with a full bucket
+		l.perMinute, l.tokens, l.last = perMinute, float64(perMinute), now
+		return
+	}
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Minutes() * float64(perMinute)
+		l.last = now
+	}
+	if l.tokens > float64(perMinute) {
+		l.tokens = float64(perMinute)
+	}
+}
+
+// reserves up to want evictions to be sent right away and returns how many were reserved, along with the delay until
+// the next one may be sent when none were; checking and taking the tokens under a single lock keeps concurrent callers
+// from reserving the same ones, and evictions aren't limited when perMinute is 0
+func (l *evictionRateLimiter) reserve(perMinute int, want int, now time.Time) (int, time.Duration) {
+	if perMinute <= 0 {
+		return want, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(perMinute, now)
+	if l.tokens < 1 {
+		return 0, time.Duration((1 - l.tokens) / float64(perMinute) * float64(time.Minute))
+	}
+	n := min(int(l.tokens), want)
+	l.tokens -= float64(n)
+	return n, 0
+}
+
+// hands back n reserved evictions that were never sent
+func (l *evictionRateLimiter) refund(perMinute int, n int, now time.Time) {
+	if perMinute <= 0 || n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(perMinute, now)
+	l.tokens = min(l.tokens+float64(n), float64(perMinute))
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups="kube-balance.io",resources=evictionrecords,verbs=get;list;watch;create;delete
+
+// writes an EvictionRecord in the pod's namespace auditing an attempted eviction
+func (r *PodRebalancer) recordEviction(ctx context.Context, pod *core.Pod, planned api_v1alpha1.PlannedEviction, planName string, outcome api_v1alpha1.EvictionOutcome, message string) {
+	record := &api_v1alpha1.EvictionRecord{
+		ObjectMeta: meta.ObjectMeta{
+			GenerateName: pod.Name + "-",
+			Namespace:    pod.Namespace,
+		},
+		Spec: api_v1alpha1.EvictionRecordSpec{
+			Pod:      pod.Name,
+			PodUID:   pod.UID,
+			Node:     planned.Node,
+			Reason:   planned.Reason,
+			Profile:  planned.Profile,
+			QOSClass: getPodQoSClass(pod),
+			Plan:     planName,
+			Time:     meta.Now(),
+			Outcome:  outcome,
+			Message:  message,
+		},
+	}
+	if err := r.Create(ctx, record); err != nil {
+		r.Log.Error(err, "failed to write eviction record", "pod", pod.Name, "namespace", pod.Namespace)
+	}
+}
+
+// periodically deletes EvictionRecords older than their TTL
+type EvictionRecordCollector struct {
+	client.Client
+	Log logr.Logger
+	// age after which records are deleted
+	TTL      time.Duration
+	Interval time.Duration
+}
+
+// implements the manager.Runnable interface to collect expired records until the context is cancelled
+func (c *EvictionRecordCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	c.Log.Info("starting eviction record collector", "ttl", c.TTL, "interval", c.Interval)
+	for {
+		if err := c.collect(ctx, time.Now()); err != nil {
+			c.Log.Error(err, "failed to collect expired eviction records")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// deletes every record whose eviction happened more than the TTL ago
+func (c *EvictionRecordCollector) collect(ctx context.Context, now time.Time) error {
+	recordList := &api_v1alpha1.EvictionRecordList{}
+	if err := c.List(ctx, recordList); err != nil {
+		return fmt.Errorf("failed to list eviction records: %w", err)
+	}
+
+	deleted := 0
+	for i := range recordList.Items {
+		record := &recordList.Items[i]
+		if now.Sub(record.Spec.Time.Time) < c.TTL {
+			continue
+		}
+		if err := c.Delete(ctx, record); err != nil && !errors.IsNotFound(err) {
+			c.Log.Error(err, "failed to delete expired eviction record", "record", record.Name, "namespace", record.Namespace)
+			continue
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		c.Log.V(1).Info("deleted expired eviction records", "count", deleted)
+	}
+	return nil
+}
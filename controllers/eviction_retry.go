@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/pkg/eviction"
+)
+
+// planned eviction awaiting a retry, identified by the plan listing it and its position there
+type evictionRetry struct {
+	plan    string
+	index   int
+	planned api_v1alpha1.PlannedEviction
+}
+
+// rate-limited queue of evictions that failed in a way worth retrying (a PodDisruptionBudget block or a transient API
+// error), retried with exponential backoff per pod until they succeed, no longer apply or run out of attempts
+type evictionRetryQueue struct {
+	queue       workqueue.TypedRateLimitingInterface[evictionRetry]
+	maxAttempts int
+
+	mu sync.Mutex
+	// evictions awaiting a retry by the UID of their pod, left out of new plans
+	pending map[types.UID]evictionRetry
+}
+
+// creates a retry queue backing off from baseDelay up to maxDelay between the attempts of a pod
+func newEvictionRetryQueue(baseDelay time.Duration, maxDelay time.Duration, maxAttempts int) *evictionRetryQueue {
+	return &evictionRetryQueue{
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[evictionRetry](baseDelay, maxDelay),
+			workqueue.TypedRateLimitingQueueConfig[evictionRetry]{Name: "eviction-retries"},
+		),
+		maxAttempts: maxAttempts,
+		pending:     make(map[types.UID]evictionRetry),
+	}
+}
+
+// schedules a retry of a planned eviction after its backoff
+func (q *evictionRetryQueue) add(item evictionRetry) {
+	q.mu.Lock()
+	q.pending[item.planned.UID] = item
+	metrics.EvictionRetriesPending.Set(float64(len(q.pending)))
+	q.mu.Unlock()
+
+	q.queue.AddRateLimited(item)
+}
+
+// drops a planned eviction from the queue along with its backoff
+func (q *evictionRetryQueue) forget(item evictionRetry) {
+	q.queue.Forget(item)
+
+	q.mu.Lock()
+	delete(q.pending, item.planned.UID)
+	metrics.EvictionRetriesPending.Set(float64(len(q.pending)))
+	q.mu.Unlock()
+}
+
+// reports whether a pod awaits a retry of its eviction
+func (q *evictionRetryQueue) isPending(uid types.UID) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, ok := q.pending[uid]
+	return ok
+}
+
+// reports whether an eviction still awaits its retry, i.e. it wasn't cancelled while backing off
+func (q *evictionRetryQueue) isQueued(item evictionRetry) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.pending[item.planned.UID] == item
+}
+
+// cancels the evictions awaiting a retry that match the given function, returning them; their retries are dropped
+// once their backoff ends
+func (q *evictionRetryQueue) cancel(match func(item evictionRetry) bool) []evictionRetry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var cancelled []evictionRetry
+	for uid, item := range q.pending {
+		if match(item) {
+			delete(q.pending, uid)
+			cancelled = append(cancelled, item)
+		}
+	}
+	metrics.EvictionRetriesPending.Set(float64(len(q.pending)))
+	return cancelled
+}
+
+// retries the queued evictions until the context is cancelled; implements the manager.RunnableFunc signature
+func (r *PodRebalancer) runEvictionRetries(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		r.evictionRetries.queue.ShutDown()
+	}()
+
+	for {
+		item, shutdown := r.evictionRetries.queue.Get()
+		if shutdown {
+			return nil
+		}
+		r.retryEviction(ctx, item)
+		r.evictionRetries.queue.Done(item)
+	}
+}
+
+// attempts a queued eviction again, requeueing it while it keeps failing in a way worth retrying and attempts remain,
+// and records the final outcome on its plan
+func (r *PodRebalancer) retryEviction(ctx context.Context, item evictionRetry) {
+	log := r.Log.WithValues("plan", item.plan, "pod", item.planned.Pod, "namespace", item.planned.Namespace)
+	if !r.evictionRetries.isQueued(item) {
+		log.V(1).Info("eviction retry was cancelled, dropping it")
+		r.evictionRetries.queue.Forget(item)
+		return
+	}
+	attempt := r.evictionRetries.queue.NumRequeues(item) + 1
+
+	// a retry held back while evictions are paused, whether by an operator or as too many pods are Pending, or by the
+	// cluster-wide eviction rate limit, doesn't use up an attempt
+	cfg := r.currentConfig()
+	if r.evictionsPaused(cfg) || r.pendingPodsPausedNow(ctx, cfg) {
+		log.V(1).Info("evictions paused, delaying eviction retry", "wait", cfg.recheckInterval)
+		r.evictionRetries.queue.AddAfter(item, cfg.recheckInterval)
+		return
+	}
+	if reserved, wait := r.evictionRate.reserve(cfg.maxEvictionsPerMinute, 1, time.Now()); reserved == 0 {
+		log.V(1).Info("cluster-wide eviction rate limit reached, delaying eviction retry", "wait", wait.Round(time.Second))
+		metrics.EvictionsRateLimited.Inc()
+		r.evictionRetries.queue.AddAfter(item, wait)
+		return
+	}
+
+	outcome, message, err := r.executePlannedEviction(ctx, cfg, item.plan, item.planned, r.ProfilerWatcher.GetNamespacedProfiles(), r.ProfilerWatcher.GetProfiles())
+	if err != nil {
+		if attempt < r.evictionRetries.maxAttempts {
+			log.V(1).Info("eviction retry failed, backing off", "attempt", attempt, "reason", eviction.ReasonOf(err), "error", err.Error())
+			r.evictionRetries.queue.AddRateLimited(item)
+			return
+		}
+		log.Info("giving up on eviction after repeated failures", "attempts", attempt, "error", err.Error())
+		outcome, message = api_v1alpha1.PlannedEvictionFailed, fmt.Sprintf("gave up after %d attempts: %v", attempt, err)
+		r.recordRetryExhausted(ctx, item, attempt, err)
+	}
+	r.evictionRetries.forget(item)
+
+	if err := r.recordRetryOutcome(ctx, item, outcome, message); err != nil {
+		log.Error(err, "failed to record eviction retry outcome on rebalance plan")
+	}
+}
+
+// reports an eviction that ran out of attempts through an event and an eviction record
+func (r *PodRebalancer) recordRetryExhausted(ctx context.Context, item evictionRetry, attempts int, err error) {
+	pod := &core.Pod{}
+	if getErr := r.Get(ctx, types.NamespacedName{Namespace: item.planned.Namespace, Name: item.planned.Pod}, pod); getErr != nil || pod.UID != item.planned.UID {
+		return
+	}
+	r.Recorder.Eventf(pod, core.EventTypeWarning, "EvictionFailed", "Gave up evicting pod %s after %d attempts: %v", pod.Name, attempts, err)
+	if !r.DryRun {
+		r.recordEviction(ctx, pod, item.planned, item.plan, api_v1alpha1.EvictionOutcomeFailed, err.Error())
+	}
+}
+
+// replaces the Retrying result of a planned eviction with its final outcome; plans deleted since are left alone
+func (r *PodRebalancer) recordRetryOutcome(ctx context.Context, item evictionRetry, outcome api_v1alpha1.PlannedEvictionOutcome, message string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		plan := &api_v1alpha1.RebalancePlan{}
+		if err := r.Get(ctx, types.NamespacedName{Name: item.plan}, plan); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if item.index >= len(plan.Status.Results) || plan.Status.Results[item.index].Outcome != api_v1alpha1.PlannedEvictionRetrying {
+			return nil
+		}
+
+		// the optimistic lock keeps the results the reconciler appends concurrently
+		patch := client.MergeFromWithOptions(plan.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		result := &plan.Status.Results[item.index]
+		result.Outcome, result.Message, result.Time = outcome, message, meta.Now()
+		return r.Status().Patch(ctx, plan, patch)
+	})
+}
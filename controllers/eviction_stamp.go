@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// annotations stamped on a pod, and on the event reporting it, right before kube-balance evicts it, so that audit
+// pipelines can attribute the disruption to kube-balance and to the degradation behind it
+const (
+	// node the pod is evicted from
+	EvictionNodeAnnotation = "kube-balance.io/eviction-node"
+	// detector, or degradation key, that marked the node as degraded
+	EvictionDetectorAnnotation = "kube-balance.io/eviction-detector"
+	// workload profile governing the pod, if any
+	EvictionProfileAnnotation = "kube-balance.io/eviction-profile"
+	// severity of the node's degradation, normal or urgent
+	EvictionSeverityAnnotation = "kube-balance.io/eviction-severity"
+	// RebalancePlan the eviction belongs to
+	EvictionPlanAnnotation = "kube-balance.io/eviction-plan"
+	// why the pod was selected for eviction
+	EvictionReasonAnnotation = "kube-balance.io/eviction-reason"
+	// rebalancing strategy that selected the pod
+	EvictionStrategyAnnotation = "kube-balance.io/eviction-strategy"
+)
+
+// machine-readable metadata describing why a pod is evicted
+type evictionStamp struct {
+	node     string
+	detector string
+	profile  string
+	severity string
+	plan     string
+	reason   string
+	strategy string
+}
+
+// describes a planned eviction of a pod from a node, matching the degradation key when degraded; the detector is the
+// source recorded on kube-balance's own marker, falling back to the degradation key, and neither it nor the severity is
+// set for nodes that aren't degraded
+func newEvictionStamp(node *core.Node, key string, planned api_v1alpha1.PlannedEviction, plan string) evictionStamp {
+	stamp := evictionStamp{
+		node:     node.Name,
+		profile:  planned.Profile,
+		plan:     plan,
+		reason:   planned.Reason,
+		strategy: planned.Strategy,
+	}
+	if key == "" {
+		return stamp
+	}
+	stamp.detector = key
+	if source := node.Annotations[degradation.SourceAnnotation]; source != "" {
+		stamp.detector = source
+	}
+	stamp.severity = string(degradation.NodeSeverity(node))
+	if stamp.severity == string(degradation.SeverityNormal) {
+		stamp.severity = "normal"
+	}
+	return stamp
+}
+
+// returns the stamp as annotations, leaving out those without a value
+func (s evictionStamp) annotations() map[string]string {
+	annotations := map[string]string{}
+	for key, value := range map[string]string{
+		EvictionNodeAnnotation:     s.node,
+		EvictionDetectorAnnotation: s.detector,
+		EvictionProfileAnnotation:  s.profile,
+		EvictionSeverityAnnotation: s.severity,
+		EvictionPlanAnnotation:     s.plan,
+		EvictionReasonAnnotation:   s.reason,
+		EvictionStrategyAnnotation: s.strategy,
+	} {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// annotates a pod about to be evicted with the reason for its eviction and reports it through an event carrying the same
+// annotations; failing to annotate the pod doesn't hold back its eviction, which the event still attributes
+func (r *PodRebalancer) stampEviction(ctx context.Context, pod *core.Pod, stamp evictionStamp) {
+	annotations := stamp.annotations()
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	for key, value := range annotations {
+		pod.Annotations[key] = value
+	}
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		r.Log.Error(err, "failed to stamp eviction reason on pod", "pod", pod.Name, "namespace", pod.Namespace)
+	}
+
+	var detail []string
+	for _, field := range [][2]string{{"strategy", stamp.strategy}, {"detector", stamp.detector}, {"severity", stamp.severity}, {"profile", stamp.profile}} {
+		if field[1] != "" {
+			detail = append(detail, field[0]+" "+field[1])
+		}
+	}
+	r.Recorder.AnnotatedEventf(pod, annotations, core.EventTypeNormal, "EvictionStamped", "Pod %s to be evicted from node %s by plan %s (%s)", pod.Name, stamp.node, stamp.plan, strings.Join(detail, ", "))
+}
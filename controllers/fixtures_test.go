@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1beta1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+	"github.com/lokeshllkumar/kube-balance/pkg/eviction"
+)
+
+// scheme of the fake clients the controller is tested against
+var testScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientscheme.AddToScheme(testScheme))
+	utilruntime.Must(api_v1alpha1.SchemeBuilder.AddToScheme(testScheme))
+	utilruntime.Must(api_v1beta1.SchemeBuilder.AddToScheme(testScheme))
+}
+
+// returns a PodRebalancer backed by a fake client holding the given objects, evicting through a FakeEvictor, with its
+// trackers set up as SetupWithManager does
+func newTestRebalancer(t *testing.T, objs ...client.Object) (*PodRebalancer, *eviction.FakeEvictor) {
+	t.Helper()
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&api_v1alpha1.RebalancePlan{}, &api_v1alpha1.NodeDrain{}).
+		Build()
+	evictor := &eviction.FakeEvictor{}
+	r := &PodRebalancer{
+		Client:                      c,
+		Scheme:                      testScheme,
+		Log:                         logr.Discard(),
+		Evictor:                     evictor,
+		RecheckInterval:             time.Minute,
+		MaxEvictionsPerNodePerCycle: 1,
+		// a recorder without a channel drops the events rather than blocking once full
+		Recorder:              &record.FakeRecorder{},
+		DegradationClassifier: &degradation.Classifier{},
+		degradationTracker:    newDegradationTracker(),
+		evictionRate:          newEvictionRateLimiter(),
+		nodeRotation:          newDegradedNodeRotation(),
+		podCooldowns:          newPodCooldownTracker(),
+		pendingPods:           newPendingPodsBreaker(),
+		pdbBlocks:             newPDBBlockTracker(),
+		zoneFailures:          newZoneFailureReporter(),
+		skipReports:           newSkipReporter(),
+	}
+	return r, evictor
+}
+
+// returns a node marked degraded with the given severity
+func degradedNode(name string, severity degradation.Severity) *core.Node {
+	return &core.Node{
+		ObjectMeta: meta.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				degradation.DegradedAnnotation: "true",
+				degradation.SeverityAnnotation: string(severity),
+			},
+		},
+		Status: core.NodeStatus{
+			Conditions: []core.NodeCondition{{Type: core.NodeReady, Status: core.ConditionTrue}},
+		},
+	}
+}
+
+// returns a Deployment reporting the given ready replicas, selecting its pods by the app label when selected
+func testDeployment(name string, ready int32, selected bool) *apps.Deployment {
+	replicas := ready
+	deploy := &apps.Deployment{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: apps.DeploymentSpec{
+			Replicas: &replicas,
+		},
+		Status: apps.DeploymentStatus{
+			Replicas:      ready,
+			ReadyReplicas: ready,
+		},
+	}
+	if selected {
+		deploy.Spec.Selector = &meta.LabelSelector{MatchLabels: map[string]string{"app": name}}
+	}
+	return deploy
+}
+
+// returns a ready, running pod of a Deployment on a node, requesting the given memory, if any
+func testPod(name string, owner *apps.Deployment, node string, memory string) *core.Pod {
+	controller := true
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID(name + "-uid"),
+			Labels:            map[string]string{"app": owner.Name},
+			CreationTimestamp: meta.NewTime(time.Now().Add(-time.Hour)),
+			OwnerReferences: []meta.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       owner.Name,
+				UID:        owner.UID,
+				Controller: &controller,
+			}},
+		},
+		Spec: core.PodSpec{
+			NodeName:   node,
+			Containers: []core.Container{{Name: "app", Image: "app"}},
+		},
+		Status: core.PodStatus{
+			Phase:      core.PodRunning,
+			Conditions: []core.PodCondition{{Type: core.PodReady, Status: core.ConditionTrue}},
+		},
+	}
+	if memory != "" {
+		pod.Spec.Containers[0].Resources.Requests = core.ResourceList{core.ResourceMemory: resource.MustParse(memory)}
+	}
+	return pod
+}
+
+// returns a default workload profile governing every pod
+func defaultProfiles() map[string]api_v1beta1.WorkloadProfile {
+	return map[string]api_v1beta1.WorkloadProfile{
+		"default": {
+			ObjectMeta: meta.ObjectMeta{Name: "default"},
+			Spec:       api_v1beta1.WorkloadProfileSpec{IsDefault: true},
+		},
+	}
+}
+
+// returns the state of a reconcile cycle over the given nodes and pods, every degraded node being marked so
+func testState(r *PodRebalancer, nodes []*core.Node, pods []*core.Pod) *rebalanceState {
+	state := &rebalanceState{
+		log:                logr.Discard(),
+		cfg:                r.currentConfig(),
+		now:                time.Now(),
+		nodesByName:        map[string]*core.Node{},
+		degradedNodes:      map[string]*core.Node{},
+		degradationKeys:    map[string]string{},
+		namespacedProfiles: map[string]map[string]api_v1beta1.WorkloadProfile{},
+		workloadProfiles:   defaultProfiles(),
+		drains:             map[string]*nodeDrainProgress{},
+	}
+	for _, node := range nodes {
+		state.nodes = append(state.nodes, *node)
+	}
+	for i := range state.nodes {
+		node := &state.nodes[i]
+		state.nodesByName[node.Name] = node
+		if degraded, key := r.DegradationClassifier.IsDegraded(node, state.now); degraded {
+			state.degradedNodes[node.Name] = node
+			state.degradationKeys[node.Name] = key
+		}
+	}
+	for _, pod := range pods {
+		state.pods = append(state.pods, *pod)
+	}
+	return state
+}
+
+// returns the names of the pods of planned evictions, in order
+func plannedPods(evictions []api_v1alpha1.PlannedEviction) []string {
+	names := make([]string, len(evictions))
+	for i, planned := range evictions {
+		names[i] = planned.Pod
+	}
+	return names
+}
+
+// returns the objects of the given nodes, Deployments and pods, for a fake client to hold
+func testObjects(nodes []*core.Node, deployments []*apps.Deployment, pods []*core.Pod) []client.Object {
+	var objs []client.Object
+	for _, node := range nodes {
+		objs = append(objs, node)
+	}
+	for _, deploy := range deployments {
+		objs = append(objs, deploy)
+	}
+	for _, pod := range pods {
+		objs = append(objs, pod)
+	}
+	return objs
+}
+
+// returns a PodDisruptionBudget-blocked eviction error for a pod
+func blockedByPDB(pod *core.Pod) error {
+	return &eviction.Error{
+		Reason: eviction.FailureBlockedByPDB,
+		Pod:    types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
+		Err:    fmt.Errorf("cannot evict pod as it would violate the pod's disruption budget"),
+	}
+}
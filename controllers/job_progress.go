@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// +kubebuilder:rbac:groups="batch",resources=jobs,verbs=get
+
+// estimates how far along the Job a pod runs is, in percent, along with what the estimate is based on for its events;
+// the estimate is the furthest of the Job's completions, the pod's run time against that of the Job's succeeded pods,
+// or, when none succeeded yet, the Job's run time against its active deadline, as its pods are killed once it passes
+// anyway; -1 is returned for pods of no Job, or of a Job whose progress can't be estimated
+func (r *PodRebalancer) jobPodProgress(ctx context.Context, pod *core.Pod, pods []core.Pod, now time.Time) (int, string, error) {
+	controllerRef := meta.GetControllerOf(pod)
+	if controllerRef == nil || controllerRef.Kind != "Job" || controllerRef.APIVersion != batch.SchemeGroupVersion.String() {
+		return -1, "", nil
+	}
+	job := &batch.Job{}
+	// Jobs are read uncached, as only the Jobs of pods about to be evicted are, and caching them would mean watching all of them
+	if err := r.uncachedReader().Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: controllerRef.Name}, job); err != nil {
+		if errors.IsNotFound(err) {
+			return -1, "", nil
+		}
+		return -1, "", fmt.Errorf("failed to get Job %s: %w", controllerRef.Name, err)
+	}
+
+	progress, what := -1, ""
+	estimate := func(percent int, basis string) {
+		percent = min(percent, 100)
+		if percent > progress {
+			progress, what = percent, basis
+		}
+	}
+	if completions := job.Spec.Completions; completions != nil && *completions > 0 {
+		estimate(int(job.Status.Succeeded*100 / *completions), fmt.Sprintf("%d of its %d completions succeeded", job.Status.Succeeded, *completions))
+	}
+
+	if pod.Status.StartTime == nil {
+		return progress, what, nil
+	}
+	runTime := now.Sub(pod.Status.StartTime.Time)
+	if expected, succeeded := succeededPodRunTime(pods, job.UID); succeeded > 0 && expected > 0 {
+		estimate(int(runTime*100/expected), fmt.Sprintf("the pod ran for %s, while the Job's %d succeeded pods took %s on average", runTime.Round(time.Second), succeeded, expected.Round(time.Second)))
+	} else if deadline := job.Spec.ActiveDeadlineSeconds; deadline != nil && *deadline > 0 && job.Status.StartTime != nil {
+		activeDeadline := time.Duration(*deadline) * time.Second
+		elapsed := now.Sub(job.Status.StartTime.Time)
+		estimate(int(elapsed*100/activeDeadline), fmt.Sprintf("the Job ran for %s of its %s active deadline", elapsed.Round(time.Second), activeDeadline))
+	}
+	return progress, what, nil
+}
+
+// returns the average run time of the succeeded pods of a Job, from their start to their last container's exit, along
+// with their number
+func succeededPodRunTime(pods []core.Pod, jobUID types.UID) (time.Duration, int) {
+	var total time.Duration
+	succeeded := 0
+	for i := range pods {
+		pod := &pods[i]
+		controllerRef := meta.GetControllerOf(pod)
+		if controllerRef == nil || controllerRef.UID != jobUID || pod.Status.Phase != core.PodSucceeded || pod.Status.StartTime == nil {
+			continue
+		}
+		var finishedAt time.Time
+		for _, status := range pod.Status.ContainerStatuses {
+			if terminated := status.State.Terminated; terminated != nil && terminated.FinishedAt.After(finishedAt) {
+				finishedAt = terminated.FinishedAt.Time
+			}
+		}
+		if finishedAt.IsZero() {
+			continue
+		}
+		total += finishedAt.Sub(pod.Status.StartTime.Time)
+		succeeded++
+	}
+	if succeeded == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(succeeded), succeeded
+}
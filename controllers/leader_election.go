@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	coordination "k8s.io/api/coordination/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs=get
+
+// reports whether the pod holds the Lease its workload elects its leader with; a missing or unheld Lease has no leader
+func (r *PodRebalancer) podLeads(ctx context.Context, pod *core.Pod, election *api_v1.LeaderElection) (bool, error) {
+	namespace := election.LeaseNamespace
+	if namespace == "" {
+		namespace = pod.Namespace
+	}
+	lease := &coordination.Lease{}
+	// Leases are read uncached, as caching them would mean watching every node's heartbeat Lease
+	if err := r.uncachedReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: election.LeaseName}, lease); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get lease %s/%s: %w", namespace, election.LeaseName, err)
+	}
+	if lease.Spec.HolderIdentity == nil {
+		return false, nil
+	}
+	return leaseHeldBy(*lease.Spec.HolderIdentity, pod), nil
+}
+
+// reports whether a Lease holder identity names the pod, client-go's leaderelection package identifying the leader by
+// its hostname, optionally followed by an underscore and a unique suffix
+func leaseHeldBy(holder string, pod *core.Pod) bool {
+	for _, name := range []string{pod.Name, pod.Spec.Hostname} {
+		if name != "" && (holder == name || strings.HasPrefix(holder, name+"_")) {
+			return true
+		}
+	}
+	return false
+}
+
+// moves the candidates holding their workload's leader election Lease behind the others, along with their checks, so
+// that the other replicas are planned first and the single eviction per owner defers the leader to a later cycle;
+// candidates whose Lease can't be read keep their place
+func (r *PodRebalancer) leadersLast(ctx context.Context, state *rebalanceState, candidates []strategyCandidate, checks []candidateCheck) ([]strategyCandidate, []candidateCheck) {
+	var ordered, leaders []strategyCandidate
+	var orderedChecks, leaderChecks []candidateCheck
+	for i, candidate := range candidates {
+		election := candidate.profile.Spec.Eviction.LeaderElection
+		if checks[i].passed && candidate.profileFound && election != nil {
+			leads, err := r.podLeads(ctx, candidate.pod, election)
+			if err != nil {
+				state.log.Error(err, "failed to check whether pod is its workload's leader", "pod", candidate.pod.Name, "namespace", candidate.pod.Namespace)
+			} else if leads {
+				state.log.V(1).Info("pod holds its workload's leader election lease, planning it after the other replicas", "pod", candidate.pod.Name, "namespace", candidate.pod.Namespace, "lease", election.LeaseName)
+				leaders = append(leaders, candidate)
+				leaderChecks = append(leaderChecks, checks[i])
+				continue
+			}
+		}
+		ordered = append(ordered, candidate)
+		orderedChecks = append(orderedChecks, checks[i])
+	}
+	return append(ordered, leaders...), append(orderedChecks, leaderChecks...)
+}
+
+// returns the pre-eviction hooks to run against a pod, leaving out the leader-only hooks unless the pod holds its
+// workload's leader election Lease
+func (r *PodRebalancer) podEvictionHooks(ctx context.Context, pod *core.Pod, profile *api_v1.WorkloadProfile) ([]api_v1.PreEvictionHook, error) {
+	eviction := profile.Spec.Eviction
+	var hooks []api_v1.PreEvictionHook
+	leads, checked := false, false
+	for _, hook := range eviction.PreEvictionHooks {
+		if hook.LeaderOnly {
+			if eviction.LeaderElection == nil {
+				continue
+			}
+			if !checked {
+				var err error
+				if leads, err = r.podLeads(ctx, pod, eviction.LeaderElection); err != nil {
+					return nil, err
+				}
+				checked = true
+			}
+			if !leads {
+				continue
+			}
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
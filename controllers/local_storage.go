@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims;persistentvolumes,verbs=get;list;watch
+
+// cluster autoscaler's pod annotation listing the pod's emptyDir volumes, comma-separated, whose data may be lost on eviction
+const SafeToEvictLocalVolumesAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict-local-volumes"
+
+// reports whether the pods of a profile may be evicted despite keeping data on their node, as set by the profile or
+// otherwise by --evict-local-storage
+func (r *PodRebalancer) evictsLocalStorage(profile *api_v1.WorkloadProfile) bool {
+	if profile != nil && profile.Spec.Eviction.EvictLocalStorage != nil {
+		return *profile.Spec.Eviction.EvictLocalStorage
+	}
+	return r.EvictLocalStorage
+}
+
+// returns why evicting a pod would lose the data it keeps in an emptyDir volume or leave it unschedulable elsewhere
+// for its local PersistentVolume; empty when the pod keeps no data on its node
+func (r *PodRebalancer) localStorageReason(ctx context.Context, pod *core.Pod) (string, error) {
+	safe := map[string]bool{}
+	for _, name := range strings.Split(pod.Annotations[SafeToEvictLocalVolumesAnnotation], ",") {
+		safe[strings.TrimSpace(name)] = true
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil && !safe[volume.Name] {
+			return fmt.Sprintf("it keeps data in emptyDir volume %s", volume.Name), nil
+		}
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		pvc := &core.PersistentVolumeClaim{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: volume.PersistentVolumeClaim.ClaimName}, pvc); err != nil {
+			return "", fmt.Errorf("failed to get PersistentVolumeClaim %s: %w", volume.PersistentVolumeClaim.ClaimName, err)
+		}
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv := &core.PersistentVolume{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+			return "", fmt.Errorf("failed to get PersistentVolume %s: %w", pvc.Spec.VolumeName, err)
+		}
+		if pv.Spec.Local != nil || pv.Spec.HostPath != nil {
+			return fmt.Sprintf("it uses local PersistentVolume %s", pv.Name), nil
+		}
+	}
+	return "", nil
+}
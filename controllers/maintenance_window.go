@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1beta1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/maintenance"
+)
+
+// parses the maintenance windows of a RebalancePolicy, returning the valid ones alongside the errors of the invalid ones
+func parsePolicyWindows(windows []api_v1alpha1.MaintenanceWindow) ([]maintenance.Window, error) {
+	parsed := make([]maintenance.Window, 0, len(windows))
+	var errs []error
+	for i, w := range windows {
+		window, err := maintenance.Parse(w.Days, w.Start, w.End, w.TimeZone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("maintenance window %d: %w", i, err))
+			continue
+		}
+		parsed = append(parsed, window)
+	}
+	return parsed, errors.Join(errs...)
+}
+
+// parses the maintenance windows of a workload profile, returning the valid ones alongside the errors of the invalid ones
+func parseProfileWindows(windows []api_v1beta1.MaintenanceWindow) ([]maintenance.Window, error) {
+	parsed := make([]maintenance.Window, 0, len(windows))
+	var errs []error
+	for i, w := range windows {
+		window, err := maintenance.Parse(w.Days, w.Start, w.End, w.TimeZone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("maintenance window %d: %w", i, err))
+			continue
+		}
+		parsed = append(parsed, window)
+	}
+	return parsed, errors.Join(errs...)
+}
+
+// shortens the requeue delay so that the next cycle starts when a deferring maintenance window opens
+func requeueAtWindow(requeueAfter time.Duration, opensAt time.Time, now time.Time) time.Duration {
+	if untilOpen := opensAt.Sub(now); untilOpen > 0 && untilOpen < requeueAfter {
+		return untilOpen
+	}
+	return requeueAfter
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"fmt"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+)
+
+// returns the number of ready replicas reported by a pod's owner, false for owners that don't report one
+func ownerReadyReplicas(owner client.Object) (int32, bool) {
+	switch o := owner.(type) {
+	case *apps.Deployment:
+		return o.Status.ReadyReplicas, true
+	case *apps.StatefulSet:
+		return o.Status.ReadyReplicas, true
+	case *apps.ReplicaSet:
+		return o.Status.ReadyReplicas, true
+	}
+	return 0, false
+}
+
+// checks that evicting a pod leaves its owner with at least minAvailable ready replicas; pods without an owner
+// reporting ready replicas are not held back
+func checkMinAvailable(pod *core.Pod, owner client.Object, minAvailable int32) error {
+	if owner == nil {
+		return nil
+	}
+	ready, ok := ownerReadyReplicas(owner)
+	if !ok {
+		return nil
+	}
+	return checkMinAvailableLeft(pod, owner, ready, minAvailable)
+}
+
+// checks that evicting a pod leaves its owner, with the given ready replicas, with at least minAvailable of them
+func checkMinAvailableLeft(pod *core.Pod, owner client.Object, ready int32, minAvailable int32) error {
+	// evicting a pod that isn't ready leaves the ready replicas unchanged
+	remaining := ready
+	if podReady(pod) {
+		remaining--
+	}
+	if remaining < minAvailable {
+		return fmt.Errorf("evicting the pod would leave %s with %d ready replicas, below the profile's minimum of %d", owner.GetName(), remaining, minAvailable)
+	}
+	return nil
+}
+
+// reports whether a profile allows evicting the last ready replica of its pods' owners
+func evictsLastReplica(profile *api_v1.WorkloadProfile) bool {
+	return profile != nil && profile.Spec.Eviction.EvictLastReplica != nil && *profile.Spec.Eviction.EvictLastReplica
+}
+
+// checks that evicting a pod doesn't leave its owner without a ready replica, whether or not a PodDisruptionBudget
+// covers it; pods without an owner reporting ready replicas are not held back
+func checkLastReplica(pod *core.Pod, owner client.Object) error {
+	if owner == nil {
+		return nil
+	}
+	if ready, ok := ownerReadyReplicas(owner); ok {
+		return checkLastReplicaLeft(pod, owner, ready)
+	}
+	return nil
+}
+
+// checks that evicting a pod doesn't leave its owner, with the given ready replicas, without a ready replica
+func checkLastReplicaLeft(pod *core.Pod, owner client.Object, ready int32) error {
+	if podReady(pod) && ready <= 1 {
+		return fmt.Errorf("evicting the pod would leave %s without a ready replica", owner.GetName())
+	}
+	return nil
+}
+
+// ready replicas left to the owners of the pods planned for eviction, as the evictions planned in a cycle take them
+// away before the owners' status reflects it
+type readyReplicasLeft struct {
+	ready map[types.UID]int32
+	pods  []core.Pod
+}
+
+// counts the ready replicas left to owners against the given pods
+func newReadyReplicasLeft(pods []core.Pod) *readyReplicasLeft {
+	return &readyReplicasLeft{ready: map[types.UID]int32{}, pods: pods}
+}
+
+// returns the ready replicas left to a pod's owner, false for owners that don't report them; an owner's status lags
+// behind the pods evicted in the previous cycles, which are no longer counted once terminating, so that the owners of
+// pods on urgently degraded nodes, which aren't held back by the owner disruption cap, keep a ready replica too
+func (l *readyReplicasLeft) of(owner client.Object) (int32, bool) {
+	if owner == nil {
+		return 0, false
+	}
+	if ready, ok := l.ready[owner.GetUID()]; ok {
+		return ready, true
+	}
+	ready, ok := ownerReadyReplicas(owner)
+	if !ok {
+		return 0, false
+	}
+	if selector := ownerSelector(owner); selector != nil {
+		running := int32(0)
+		for i := range l.pods {
+			pod := &l.pods[i]
+			if pod.Namespace == owner.GetNamespace() && pod.DeletionTimestamp == nil && podReady(pod) && selector.Matches(labels.Set(pod.Labels)) {
+				running++
+			}
+		}
+		ready = min(ready, running)
+	}
+	l.ready[owner.GetUID()] = ready
+	return ready, true
+}
+
+// takes a ready replica away from the owner of a pod planned for eviction, unless the pod isn't ready
+func (l *readyReplicasLeft) evict(pod *core.Pod, owner client.Object) {
+	if owner == nil || !podReady(pod) {
+		return
+	}
+	if ready, ok := l.of(owner); ok {
+		l.ready[owner.GetUID()] = ready - 1
+	}
+}
+
+// returns the skip of a pod left in place as the last ready replica of its owner, reported on the pod and on the owner,
+// so that the owner's maintainers know to add replicas
+func lastReplicaSkip(pod *core.Pod, owner client.Object, err error) *podSkip {
+	skip := evictionSkipped(metrics.SkipReasonLastReplica, fmt.Sprintf("Pod %s skipped as %v", pod.Name, err))
+	skip.owner, skip.ownerEvent = owner, "LastReplicaProtected"
+	skip.ownerMessage = fmt.Sprintf("Pod %s on node %s is the last ready replica of %s and was left in place; add replicas so that it can be moved", pod.Name, pod.Spec.NodeName, owner.GetName())
+	return skip
+}
+
+// reports a pod left in place as the last ready replica of its owner
+func (r *PodRebalancer) reportLastReplica(pod *core.Pod, owner client.Object, profileName string, err error) {
+	r.reportSkip(pod, profileName, lastReplicaSkip(pod, owner, err))
+}
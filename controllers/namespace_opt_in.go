@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	core "k8s.io/api/core/v1"
+)
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// namespace annotation through which tenants opt their namespace out of rebalancing with "false", or in with "true"
+// when rebalancing is opt-in
+const NamespaceEnabledAnnotation = "kube-balance.io/enabled"
+
+// resolves the namespaces that opted in or out of rebalancing through their annotation; invalid values are ignored
+func (r *PodRebalancer) resolveNamespaceOptIns(ctx context.Context, cfg *rebalanceConfig) error {
+	namespaceList := &core.NamespaceList{}
+	if err := r.List(ctx, namespaceList); err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	cfg.namespaceOptIns = map[string]bool{}
+	for _, namespace := range namespaceList.Items {
+		value, ok := namespace.Annotations[NamespaceEnabledAnnotation]
+		if !ok {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			r.Log.V(1).Info("invalid rebalancing annotation on namespace, ignoring it", "namespace", namespace.Name, "annotation", NamespaceEnabledAnnotation, "value", value)
+			continue
+		}
+		cfg.namespaceOptIns[namespace.Name] = enabled
+	}
+	return nil
+}
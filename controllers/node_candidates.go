@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/internal/maintenance"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// candidate along with the strategy that selected it
+type strategyCandidate struct {
+	evictionCandidate
+	strategy string
+	// pod whose eviction a pod group member is planned along with
+	evictedWith types.UID
+}
+
+// outcome of the checks of a candidate that only depend on its own node
+type candidateCheck struct {
+	// whether the checks were applied, rather than the candidate being left over beyond its node's eviction budget
+	checked bool
+	// whether the candidate passed them, within its node's eviction budget
+	passed bool
+	// owner of the pod, nil when it has none or it couldn't be looked up
+	owner client.Object
+}
+
+// applies the checks that only depend on a candidate's own node through a pool of NodeWorkers workers, checking each
+// node's candidates in order until its eviction budget is used up
+func (r *PodRebalancer) checkNodeCandidates(ctx context.Context, state *rebalanceState, candidates []strategyCandidate, statefulSetOrdinals map[types.UID]int) []candidateCheck {
+	checks := make([]candidateCheck, len(candidates))
+	byNode := map[string][]int{}
+	var nodeNames []string
+	for i, candidate := range candidates {
+		if _, ok := byNode[candidate.node.Name]; !ok {
+			nodeNames = append(nodeNames, candidate.node.Name)
+		}
+		byNode[candidate.node.Name] = append(byNode[candidate.node.Name], i)
+	}
+
+	// each worker writes the checks of the nodes it takes and keeps its own requeue delay, so neither needs locking
+	requeueAfters := make([]time.Duration, len(nodeNames))
+	nodes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(r.NodeWorkers, 1), len(nodeNames)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range nodes {
+				requeueAfters[n] = state.requeueAfter
+				passed := 0
+				for _, i := range byNode[nodeNames[n]] {
+					candidate := candidates[i]
+					_, draining := state.drains[candidate.node.Name]
+					// a drained node's pods are all evicted at once, regardless of the node's budget
+					if maxEvictions := state.cfg.nodeEvictionBudget(candidate.node); !draining && passed >= maxEvictions {
+						state.log.V(1).Info("reached max evictions for node in the current cycle, skipping pod", "pod", candidate.pod.Name, "namespace", candidate.pod.Namespace, "node", candidate.node.Name, "maxEvictions", maxEvictions)
+						continue
+					}
+					checks[i] = r.checkNodeCandidate(ctx, state, candidate, statefulSetOrdinals, &requeueAfters[n])
+					if checks[i].passed {
+						passed++
+					}
+				}
+			}
+		}()
+	}
+	for n := range nodeNames {
+		nodes <- n
+	}
+	close(nodes)
+	wg.Wait()
+
+	for _, requeueAfter := range requeueAfters {
+		if requeueAfter < state.requeueAfter {
+			state.requeueAfter = requeueAfter
+		}
+	}
+	return checks
+}
+
+// applies the checks that only depend on a candidate's own node, shortening requeueAfter for evictions deferred until
+// a later time
+func (r *PodRebalancer) checkNodeCandidate(ctx context.Context, state *rebalanceState, candidate strategyCandidate, statefulSetOrdinals map[types.UID]int, requeueAfter *time.Duration) candidateCheck {
+	skip, owner := r.nodeCandidateSkip(ctx, state, candidate, statefulSetOrdinals)
+	if skip == nil {
+		return candidateCheck{checked: true, passed: true, owner: owner}
+	}
+	if !skip.retryAt.IsZero() {
+		*requeueAfter = requeueAtWindow(*requeueAfter, skip.retryAt, state.now)
+	}
+	r.reportSkip(candidate.pod, candidate.profile.Name, skip)
+	return candidateCheck{checked: true}
+}
+
+// returns why a candidate is left in place by the checks that only depend on its own node, or nil along with its
+// owner when it passes them
+func (r *PodRebalancer) nodeCandidateSkip(ctx context.Context, state *rebalanceState, candidate strategyCandidate, statefulSetOrdinals map[types.UID]int) (*podSkip, client.Object) {
+	pod := candidate.pod
+	// urgently degraded nodes leave no time to wait for any of these
+	urgent := degradation.NodeSeverity(candidate.node) == degradation.SeverityUrgent
+	if !urgent {
+		if skip := r.maintenanceWindowSkip(state, candidate); skip != nil {
+			return skip, nil
+		}
+		if skip := r.minPodAgeSkip(state, candidate); skip != nil {
+			return skip, nil
+		}
+		if skip := r.podCooldownSkip(state, candidate); skip != nil {
+			return skip, nil
+		}
+		if skip := r.debugSessionSkip(state, candidate); skip != nil {
+			return skip, nil
+		}
+		if skip := r.jobCompletionSkip(ctx, state, candidate); skip != nil {
+			return skip, nil
+		}
+	}
+
+	owner, err := getPodOwner(ctx, r, pod)
+	if err != nil {
+		state.log.Error(err, "failed to get pod owner, skipping owner checks", "pod", pod.Name)
+	} else if owner != nil {
+		if skip := r.ownerCooldownSkip(state, candidate, owner); skip != nil {
+			return skip, nil
+		}
+		if skip := r.awaitingReplacementSkip(state, candidate, owner); skip != nil {
+			return skip, nil
+		}
+		if !urgent {
+			if skip := r.rolloutSkip(ctx, state, candidate, owner); skip != nil {
+				return skip, nil
+			}
+		}
+	}
+
+	if skip := statefulSetOrderSkip(state, pod, statefulSetOrdinals); skip != nil {
+		return skip, nil
+	}
+	if skip := minAvailableSkip(state, candidate, owner); skip != nil {
+		return skip, nil
+	}
+	if skip := lastReplicaCheckSkip(state, candidate, owner); skip != nil {
+		return skip, nil
+	}
+	if skip := r.pdbSkip(ctx, state, candidate, owner); skip != nil {
+		return skip, nil
+	}
+
+	// leaving pods whose eviction awaits a retry to the retry queue
+	if r.evictionRetries != nil && r.evictionRetries.isPending(pod.UID) {
+		state.log.V(1).Info("pod eviction awaits a retry, skipping pod", "pod", pod.Name, "namespace", pod.Namespace)
+		return &podSkip{}, nil
+	}
+	if !candidate.profileFound {
+		state.log.V(1).Info("pod ha no defined workload profile, skipping eviction consideration",
+			"pod", pod.Name, "namespace", pod.Namespace, "workloadType", pod.Labels[WorkloadTypeLabel])
+		return &podSkip{}, nil
+	}
+	return nil, owner
+}
+
+// defers evictions outside the profile's maintenance windows
+func (r *PodRebalancer) maintenanceWindowSkip(state *rebalanceState, candidate strategyCandidate) *podSkip {
+	pod, profile := candidate.pod, candidate.profile
+	if !candidate.profileFound || len(profile.Spec.Eviction.MaintenanceWindows) == 0 {
+		return nil
+	}
+	windows, err := parseProfileWindows(profile.Spec.Eviction.MaintenanceWindows)
+	if err != nil {
+		state.log.Error(err, "invalid maintenance windows in workload profile, ignoring them", "profile", profile.Name)
+	}
+	open, opensAt := maintenance.Open(windows, state.now)
+	if open {
+		return nil
+	}
+	state.log.V(1).Info("outside workload profile maintenance windows, deferring pod eviction",
+		"pod", pod.Name, "namespace", pod.Namespace, "profile", profile.Name, "nextWindow", opensAt.Format(time.RFC3339))
+	return &podSkip{
+		reason:    metrics.SkipReasonMaintenanceWindow,
+		eventType: core.EventTypeNormal,
+		event:     "EvictionDeferred",
+		message:   fmt.Sprintf("Eviction of pod %s deferred until the next maintenance window of profile %s at %s", pod.Name, profile.Name, opensAt.Format(time.RFC3339)),
+		retryAt:   opensAt,
+	}
+}
+
+// leaves young pods in place, as they may have just been rescheduled back onto the node
+func (r *PodRebalancer) minPodAgeSkip(state *rebalanceState, candidate strategyCandidate) *podSkip {
+	pod := candidate.pod
+	minAge := r.minPodAge(candidate.podProfile())
+	if minAge <= 0 {
+		return nil
+	}
+	age := state.now.Sub(pod.CreationTimestamp.Time)
+	if age >= minAge {
+		return nil
+	}
+	state.log.V(1).Info("pod is younger than the minimum pod age, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "age", age.Round(time.Second), "minPodAge", minAge)
+	skip := evictionSkipped(metrics.SkipReasonTooYoung, fmt.Sprintf("Pod %s skipped as it is only %s old, younger than the minimum pod age of %s", pod.Name, age.Round(time.Second), minAge))
+	skip.retryAt = pod.CreationTimestamp.Add(minAge)
+	return skip
+}
+
+// leaves pods recreated under the name of a pod evicted recently in place
+func (r *PodRebalancer) podCooldownSkip(state *rebalanceState, candidate strategyCandidate) *podSkip {
+	pod := candidate.pod
+	if r.podCooldowns == nil {
+		return nil
+	}
+	until, ok := r.podCooldowns.cooldown(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, state.now)
+	if !ok {
+		return nil
+	}
+	state.log.V(1).Info("a pod of the same name was evicted recently, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "cooldownUntil", until.Format(time.RFC3339))
+	skip := evictionSkipped(metrics.SkipReasonPodCooldown, fmt.Sprintf("Pod %s skipped as a pod of the same name was evicted recently, in cooldown until %s", pod.Name, until.Format(time.RFC3339)))
+	skip.retryAt = until
+	return skip
+}
+
+// defers the eviction of pods being debugged, as it would end the troubleshooting session
+func (r *PodRebalancer) debugSessionSkip(state *rebalanceState, candidate strategyCandidate) *podSkip {
+	pod := candidate.pod
+	if !r.SkipDebuggedPods {
+		return nil
+	}
+	container := runningEphemeralContainer(pod)
+	if container == "" {
+		return nil
+	}
+	state.log.V(1).Info("pod has a running ephemeral debug container, deferring pod eviction", "pod", pod.Name, "namespace", pod.Namespace, "container", container)
+	return &podSkip{
+		reason:    metrics.SkipReasonDebugSession,
+		eventType: core.EventTypeNormal,
+		event:     "EvictionDeferred",
+		message:   fmt.Sprintf("Eviction of pod %s deferred while its ephemeral container %s runs, as evicting it would end the debugging session", pod.Name, container),
+	}
+}
+
+// leaves the pods of Jobs close to completion to finish on the node rather than losing their work
+func (r *PodRebalancer) jobCompletionSkip(ctx context.Context, state *rebalanceState, candidate strategyCandidate) *podSkip {
+	pod := candidate.pod
+	threshold := state.cfg.jobCompletionThresholdPercent
+	if threshold <= 0 {
+		return nil
+	}
+	progress, what, err := r.jobPodProgress(ctx, pod, state.pods, state.now)
+	if err != nil {
+		state.log.Error(err, "failed to estimate the progress of the pod's Job, skipping completion check", "pod", pod.Name, "namespace", pod.Namespace)
+		return nil
+	}
+	if progress < threshold {
+		return nil
+	}
+	state.log.V(1).Info("pod's Job is close to completion, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "progress", progress, "threshold", threshold, "estimate", what)
+	return evictionSkipped(metrics.SkipReasonJobNearCompletion, fmt.Sprintf("Pod %s skipped as its Job is an estimated %d%% done (%s), leaving it to finish", pod.Name, progress, what))
+}
+
+// leaves the pods of an owner in its eviction cooldown in place, except on drained nodes, which are emptied at once
+func (r *PodRebalancer) ownerCooldownSkip(state *rebalanceState, candidate strategyCandidate, owner client.Object) *podSkip {
+	pod := candidate.pod
+	if _, draining := state.drains[candidate.node.Name]; draining {
+		return nil
+	}
+	cooldownUntilStr, ok := owner.GetAnnotations()[EvictionCooldownAnnotation]
+	if !ok {
+		return nil
+	}
+	cooldownUntil, err := time.Parse(time.RFC3339, cooldownUntilStr)
+	if err != nil || !state.now.Before(cooldownUntil) {
+		return nil
+	}
+	state.log.V(1).Info("pod owner is in eviction cooldown period, skipping pod",
+		"pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName(), "cooldownUntil", cooldownUntil.Format(time.RFC3339))
+	return evictionSkipped(metrics.SkipReasonOwnerCooldown, fmt.Sprintf("Pod %s skipped due to owner %s being in cooldown until %s", pod.Name, owner.GetName(), cooldownUntil.Format(time.RFC3339)))
+}
+
+// holds back the owner's other pods until the pod evicted last has a Ready replacement on a healthy node; StatefulSets
+// always wait, as their pods are replaced one at a time
+func (r *PodRebalancer) awaitingReplacementSkip(state *rebalanceState, candidate strategyCandidate, owner client.Object) *podSkip {
+	pod := candidate.pod
+	if !r.WaitForReschedule && !isStatefulSet(owner) {
+		return nil
+	}
+	waiting, since := r.awaitingReplacement(owner, state.pods, state.nodesByName, state.now)
+	if !waiting {
+		return nil
+	}
+	state.log.V(1).Info("pod owner awaits a ready replacement of its last evicted pod, skipping pod",
+		"pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName(), "since", since.Format(time.RFC3339))
+	return evictionSkipped(metrics.SkipReasonAwaitingReplacement, fmt.Sprintf("Pod %s skipped as owner %s awaits a ready replacement of the pod evicted at %s", pod.Name, owner.GetName(), since.Format(time.RFC3339)))
+}
+
+// leaves the pods of an owner rolling out a new revision in place until the rollout completes
+func (r *PodRebalancer) rolloutSkip(ctx context.Context, state *rebalanceState, candidate strategyCandidate, owner client.Object) *podSkip {
+	pod := candidate.pod
+	if !r.SkipRollouts {
+		return nil
+	}
+	rollingOut, what, err := r.rolloutInProgress(ctx, pod, owner)
+	if err != nil {
+		state.log.Error(err, "failed to check the rollout of the pod owner, skipping rollout check", "pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName())
+		return nil
+	}
+	if !rollingOut {
+		return nil
+	}
+	state.log.V(1).Info("pod owner is rolling out, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName(), "rollout", what)
+	return evictionSkipped(metrics.SkipReasonRolloutInProgress, fmt.Sprintf("Pod %s skipped until the rollout of its owner completes: %s", pod.Name, what))
+}
+
+// evicts the pods of a StatefulSet in reverse ordinal order
+func statefulSetOrderSkip(state *rebalanceState, pod *core.Pod, statefulSetOrdinals map[types.UID]int) *podSkip {
+	uid, ordinal, ok := statefulSetOrdinal(pod)
+	if !ok || ordinal >= statefulSetOrdinals[uid] {
+		return nil
+	}
+	state.log.V(1).Info("a higher ordinal of the pod's StatefulSet is to be evicted first, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "ordinal", ordinal, "highestOrdinal", statefulSetOrdinals[uid])
+	return &podSkip{}
+}
+
+// keeps the owner at the profile's minimum ready replicas, independently of any PDB
+func minAvailableSkip(state *rebalanceState, candidate strategyCandidate, owner client.Object) *podSkip {
+	pod, profile := candidate.pod, candidate.profile
+	if !candidate.profileFound || profile.Spec.MinAvailable == nil {
+		return nil
+	}
+	err := checkMinAvailable(pod, owner, *profile.Spec.MinAvailable)
+	if err == nil {
+		return nil
+	}
+	state.log.V(1).Info("pod eviction would violate the profile's min available replicas, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "profile", profile.Name, "reason", err.Error())
+	return evictionSkipped(metrics.SkipReasonMinAvailable, fmt.Sprintf("Pod %s skipped as %v", pod.Name, err))
+}
+
+// never leaves the owner without a ready replica, even when no PDB covers it, unless the profile allows it
+func lastReplicaCheckSkip(state *rebalanceState, candidate strategyCandidate, owner client.Object) *podSkip {
+	pod := candidate.pod
+	if evictsLastReplica(candidate.podProfile()) {
+		return nil
+	}
+	err := checkLastReplica(pod, owner)
+	if err == nil {
+		return nil
+	}
+	state.log.V(1).Info("pod is the last ready replica of its owner, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName())
+	return lastReplicaSkip(pod, owner, err)
+}
+
+// checks the pod's PodDisruptionBudget; a pod blocked for too long on a degraded node passes, to be deleted outright,
+// while the others back off, their blocks being reported per owner once the cycle is planned
+func (r *PodRebalancer) pdbSkip(ctx context.Context, state *rebalanceState, candidate strategyCandidate, owner client.Object) *podSkip {
+	pod := candidate.pod
+	_, degraded := state.degradedNodes[candidate.node.Name]
+	if retryAt, ok := r.pdbBlocks.backingOff(pod.UID, state.now); ok {
+		if escalated, _ := r.pdbBlockEscalated(pod, state.now); !escalated || !degraded {
+			state.log.V(1).Info("pod blocked by its PDB is backing off, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "retryAt", retryAt.Format(time.RFC3339))
+			return &podSkip{retryAt: retryAt}
+		}
+	}
+	err := r.checkPDB(ctx, pod)
+	if err == nil {
+		r.pdbBlocks.unblocked(pod.UID)
+		return nil
+	}
+	escalated, since := r.pdbBlockEscalated(pod, state.now)
+	if escalated && degraded {
+		state.log.Info("pod blocked by its PDB for too long, planning its forced deletion", "pod", pod.Name, "namespace", pod.Namespace, "blockedSince", since.Format(time.RFC3339))
+		return nil
+	}
+	state.log.V(1).Info("pod cannot be evicted due to PDB violation or check error", "pod", pod.Name, "namespace", pod.Namespace, "error", err.Error())
+	maxBackoff := max(r.PDBBlockMaxBackoff, state.cfg.recheckInterval)
+	return &podSkip{retryAt: r.pdbBlocks.block(pod, owner, err.Error(), state.now, state.cfg.recheckInterval, maxBackoff)}
+}
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// +kubebuilder:rbac:groups="kube-balance.io",resources=nodedrains,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="kube-balance.io",resources=nodedrains/status,verbs=get;update;patch
+
+// modes selecting the degraded nodes that are fully drained at once
+const (
+	// nodes are never drained, a few pods are evicted per cycle
+	DrainModeOff = "off"
+	// urgently degraded nodes are drained
+	DrainModeUrgent = "urgent"
+	// every degraded node is drained
+	DrainModeAll = "all"
+)
+
+// annotation marking a node cordoned for its drain or evacuation, so that only nodes cordoned by the controller are
+// uncordoned by it
+const DrainCordonedAnnotation = "kube-balance.io/drain-cordoned"
+
+// reports whether a degraded node is fully drained rather than a few of its pods evicted per cycle
+func (cfg *rebalanceConfig) drainsNode(node *core.Node) bool {
+	switch cfg.drainMode {
+	case DrainModeAll:
+		return true
+	case DrainModeUrgent:
+		return degradation.NodeSeverity(node) == degradation.SeverityUrgent
+	}
+	return false
+}
+
+// reports whether a degraded node is kept cordoned, as it is fully drained or the policy cordons every degraded node
+// being evacuated
+func (cfg *rebalanceConfig) cordonsNode(node *core.Node) bool {
+	return cfg.cordonDegradedNodes || cfg.drainsNode(node)
+}
+
+// progress of the drain of a node, as observed while planning its evictions
+type nodeDrainProgress struct {
+	node *core.Node
+	// evictable pods still on the node
+	remaining int32
+	// pods left on the node as they may not be evicted
+	unevictable int32
+}
+
+// cordons a node and records the start of its drain on a NodeDrain named after it; a drain that completed or was
+// cancelled is started over once the node is no longer cordoned
+func (r *PodRebalancer) startDrain(ctx context.Context, node *core.Node, reason string) error {
+	drain := &api_v1alpha1.NodeDrain{}
+	err := r.Get(ctx, types.NamespacedName{Name: node.Name}, drain)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get node drain %s: %w", node.Name, err)
+	}
+	found := err == nil
+	restart := found && (drain.Status.Phase == api_v1alpha1.NodeDrainCancelled ||
+		(drain.Status.Phase == api_v1alpha1.NodeDrainCompleted && !node.Spec.Unschedulable && !r.DryRun))
+	if err := r.cordonNode(ctx, node); err != nil {
+		return err
+	}
+	if found && !restart {
+		return nil
+	}
+
+	spec := api_v1alpha1.NodeDrainSpec{
+		Node:     node.Name,
+		Reason:   reason,
+		Severity: string(degradation.NodeSeverity(node)),
+	}
+	if !found {
+		drain = &api_v1alpha1.NodeDrain{
+			ObjectMeta: meta.ObjectMeta{
+				Name: node.Name,
+				// the drain is garbage collected along with its node
+				OwnerReferences: []meta.OwnerReference{{
+					APIVersion: "v1",
+					Kind:       "Node",
+					Name:       node.Name,
+					UID:        node.UID,
+				}},
+			},
+			Spec: spec,
+		}
+		if err := r.Create(ctx, drain); err != nil {
+			return fmt.Errorf("failed to create node drain %s: %w", node.Name, err)
+		}
+	} else if drain.Spec != spec {
+		drain.Spec = spec
+		if err := r.Update(ctx, drain); err != nil {
+			return fmt.Errorf("failed to update node drain %s: %w", node.Name, err)
+		}
+	}
+
+	now := meta.Now()
+	drain.Status = api_v1alpha1.NodeDrainStatus{
+		Phase:     api_v1alpha1.NodeDrainDraining,
+		StartTime: &now,
+	}
+	if err := r.Status().Update(ctx, drain); err != nil {
+		return fmt.Errorf("failed to update status of node drain %s: %w", node.Name, err)
+	}
+
+	r.Log.Info("started draining degraded node", "node", node.Name, "reason", reason)
+	r.Recorder.Eventf(node, core.EventTypeWarning, "NodeDrainStarted", "Node %s cordoned and drained as it is degraded (%s)", node.Name, reason)
+	return nil
+}
+
+// marks a node unschedulable, unless it already is, so that evicted pods aren't scheduled back onto it; a dry run
+// leaves the node schedulable
+func (r *PodRebalancer) cordonNode(ctx context.Context, node *core.Node) error {
+	if node.Spec.Unschedulable || r.DryRun {
+		return nil
+	}
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = true
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+	node.Annotations[DrainCordonedAnnotation] = "true"
+	if err := r.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to cordon node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// records the progress of a node's drain on its NodeDrain, completing it once no evictable pod is left on the node
+func (r *PodRebalancer) reportDrain(ctx context.Context, progress *nodeDrainProgress) error {
+	drain := &api_v1alpha1.NodeDrain{}
+	if err := r.Get(ctx, types.NamespacedName{Name: progress.node.Name}, drain); err != nil {
+		// a drain held back while evictions are paused has no NodeDrain to report on yet
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node drain %s: %w", progress.node.Name, err)
+	}
+
+	patch := client.MergeFrom(drain.DeepCopy())
+	drain.Status.RemainingPods = progress.remaining
+	drain.Status.UnevictablePods = progress.unevictable
+	completed := false
+	switch {
+	case progress.remaining == 0 && drain.Status.Phase != api_v1alpha1.NodeDrainCompleted:
+		now := meta.Now()
+		drain.Status.Phase = api_v1alpha1.NodeDrainCompleted
+		drain.Status.CompletionTime = &now
+		completed = true
+	case progress.remaining > 0:
+		drain.Status.Phase = api_v1alpha1.NodeDrainDraining
+		drain.Status.CompletionTime = nil
+	}
+	if err := r.Status().Patch(ctx, drain, patch); err != nil {
+		return fmt.Errorf("failed to update status of node drain %s: %w", drain.Name, err)
+	}
+
+	if completed {
+		r.Log.Info("completed drain of degraded node", "node", drain.Name, "unevictablePods", progress.unevictable)
+		r.Recorder.Eventf(progress.node, core.EventTypeNormal, "NodeDrainCompleted", "Node %s drained, leaving %d pods that may not be evicted", drain.Name, progress.unevictable)
+	}
+	return nil
+}
+
+// uncordons the nodes cordoned for a drain or evacuation that are no longer drained or evacuated, e.g. as they
+// recovered, and cancels their drains still in progress
+func (r *PodRebalancer) releaseDrains(ctx context.Context, cfg rebalanceConfig, nodes []core.Node, degradedNodes map[string]*core.Node) {
+	drained := func(name string) bool {
+		node, ok := degradedNodes[name]
+		return ok && cfg.drainsNode(node)
+	}
+	cordoned := func(name string) bool {
+		node, ok := degradedNodes[name]
+		return ok && cfg.cordonsNode(node)
+	}
+
+	for i := range nodes {
+		node := &nodes[i]
+		if _, ok := node.Annotations[DrainCordonedAnnotation]; !ok || cordoned(node.Name) {
+			continue
+		}
+		patch := client.MergeFrom(node.DeepCopy())
+		node.Spec.Unschedulable = false
+		delete(node.Annotations, DrainCordonedAnnotation)
+		if err := r.Patch(ctx, node, patch); err != nil {
+			r.Log.Error(err, "failed to uncordon node", "node", node.Name)
+			continue
+		}
+		r.Log.Info("uncordoned node no longer drained or evacuated", "node", node.Name)
+		r.Recorder.Eventf(node, core.EventTypeNormal, "NodeUncordoned", "Node %s uncordoned as it is no longer drained or evacuated", node.Name)
+	}
+
+	drainList := &api_v1alpha1.NodeDrainList{}
+	if err := r.List(ctx, drainList); err != nil {
+		r.Log.Error(err, "failed to list node drains")
+		return
+	}
+	for i := range drainList.Items {
+		drain := &drainList.Items[i]
+		if drain.Status.Phase != api_v1alpha1.NodeDrainDraining || drained(drain.Name) {
+			continue
+		}
+		patch := client.MergeFrom(drain.DeepCopy())
+		now := meta.Now()
+		drain.Status.Phase = api_v1alpha1.NodeDrainCancelled
+		drain.Status.CompletionTime = &now
+		if err := r.Status().Patch(ctx, drain, patch); err != nil {
+			r.Log.Error(err, "failed to cancel node drain", "node", drain.Name)
+			continue
+		}
+		r.Log.Info("cancelled drain of node no longer drained", "node", drain.Name, "remainingPods", drain.Status.RemainingPods)
+		r.Recorder.Eventf(drain, core.EventTypeNormal, "NodeDrainCancelled", "Drain of node %s cancelled with %d pods remaining as the node is no longer drained", drain.Name, drain.Status.RemainingPods)
+	}
+}
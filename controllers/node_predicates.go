@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// filters node updates down to those that can change how a node is rebalanced, so that a node gaining or losing its
+// degradation reconciles right away instead of on the next recheck, while the kubelet's periodic status heartbeats
+// don't trigger a full rebalancing pass each; node creations and deletions always pass
+func (r *PodRebalancer) nodeChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, okOld := e.ObjectOld.(*core.Node)
+			newNode, okNew := e.ObjectNew.(*core.Node)
+			if !okOld || !okNew {
+				return true
+			}
+			return r.nodeDegradationChanged(oldNode, newNode, time.Now()) || nodeSchedulingChanged(oldNode, newNode)
+		},
+	}
+}
+
+// reports whether a node gained or lost its degradation, or is degraded under a different key, between two versions
+func (r *PodRebalancer) nodeDegradationChanged(oldNode *core.Node, newNode *core.Node, now time.Time) bool {
+	oldDegraded, oldKey := r.DegradationClassifier.IsDegraded(oldNode, now)
+	newDegraded, newKey := r.DegradationClassifier.IsDegraded(newNode, now)
+	return oldDegraded != newDegraded || oldKey != newKey
+}
+
+// reports whether anything rebalancing reads from a node changed between two versions: its labels and annotations,
+// which carry degradation markers and drain state, its taints and cordon, or the status of any of its conditions
+func nodeSchedulingChanged(oldNode *core.Node, newNode *core.Node) bool {
+	if !equality.Semantic.DeepEqual(oldNode.Labels, newNode.Labels) ||
+		!equality.Semantic.DeepEqual(oldNode.Annotations, newNode.Annotations) ||
+		!equality.Semantic.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints) ||
+		oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+		!equality.Semantic.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable) {
+		return true
+	}
+	if len(oldNode.Status.Conditions) != len(newNode.Status.Conditions) {
+		return true
+	}
+	oldConditions := make(map[core.NodeConditionType]core.ConditionStatus, len(oldNode.Status.Conditions))
+	for _, condition := range oldNode.Status.Conditions {
+		oldConditions[condition.Type] = condition.Status
+	}
+	for _, condition := range newNode.Status.Conditions {
+		if status, ok := oldConditions[condition.Type]; !ok || status != condition.Status {
+			return true
+		}
+	}
+	return false
+}
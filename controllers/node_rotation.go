@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// tracks when an eviction was last planned from each degraded node, so that the degraded nodes take turns at the shared
+// eviction budgets instead of those coming first in map order starving the others across cycles
+type degradedNodeRotation struct {
+	mu sync.Mutex
+	// when an eviction was last planned from each degraded node, by node name
+	lastServed map[string]time.Time
+}
+
+// creates a new degradedNodeRotation instance
+func newDegradedNodeRotation() *degradedNodeRotation {
+	return &degradedNodeRotation{lastServed: map[string]time.Time{}}
+}
+
+// returns the names of the degraded nodes in the order they are served this cycle: nodes never served first, then those
+// served longest ago, by name otherwise; nodes that are no longer degraded are forgotten
+func (n *degradedNodeRotation) order(degradedNodes map[string]*core.Node) []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for nodeName := range n.lastServed {
+		if _, ok := degradedNodes[nodeName]; !ok {
+			delete(n.lastServed, nodeName)
+		}
+	}
+	names := make([]string, 0, len(degradedNodes))
+	for nodeName := range degradedNodes {
+		names = append(names, nodeName)
+	}
+	sort.Slice(names, func(i int, j int) bool {
+		servedI, servedJ := n.lastServed[names[i]], n.lastServed[names[j]]
+		if !servedI.Equal(servedJ) {
+			return servedI.Before(servedJ)
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// records that an eviction was planned from a degraded node
+func (n *degradedNodeRotation) served(nodeName string, now time.Time) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.lastServed[nodeName] = now
+}
+
+// interleaves the candidates of several nodes, taking one from each node in turn, so that the nodes coming first don't
+// use up the shared eviction budgets; the candidates of each node keep their order
+func interleaveCandidates(byNode [][]evictionCandidate) []evictionCandidate {
+	var candidates []evictionCandidate
+	for round := 0; ; round++ {
+		added := false
+		for _, nodeCandidates := range byNode {
+			if round < len(nodeCandidates) {
+				candidates = append(candidates, nodeCandidates[round])
+				added = true
+			}
+		}
+		if !added {
+			return candidates
+		}
+	}
+}
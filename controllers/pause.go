@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+)
+
+// reports whether all evictions are paused through --paused or the RebalancePolicy's paused field, logging when
+// evictions are paused or resumed
+func (r *PodRebalancer) evictionsPaused(cfg rebalanceConfig) bool {
+	if r.paused.Swap(cfg.paused) != cfg.paused {
+		if cfg.paused {
+			r.Log.Info("evictions paused by an operator, only detecting degraded nodes and planning evictions until resumed")
+			metrics.Paused.Set(1)
+		} else {
+			r.Log.Info("evictions resumed by an operator")
+			metrics.Paused.Set(0)
+		}
+	}
+	return cfg.paused
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// disruptions the PodDisruptionBudgets allow in a cycle, counted down as evictions are planned against them, as their
+// status only catches up once the evicted pods are gone
+type pdbBudgets struct {
+	pdbs []policy.PodDisruptionBudget
+	// evictions planned against each budget in the cycle
+	planned map[types.NamespacedName]int32
+}
+
+// lists the PodDisruptionBudgets evictions are planned against in a cycle
+func (r *PodRebalancer) listPDBBudgets(ctx context.Context) (*pdbBudgets, error) {
+	pdbList := &policy.PodDisruptionBudgetList{}
+	if err := r.List(ctx, pdbList); err != nil {
+		return nil, fmt.Errorf("failed to list PodDisruptionBudgets: %w", err)
+	}
+	return &pdbBudgets{pdbs: pdbList.Items, planned: map[types.NamespacedName]int32{}}, nil
+}
+
+// returns the budgets selecting the pod
+func (b *pdbBudgets) covering(pod *core.Pod) []*policy.PodDisruptionBudget {
+	var covering []*policy.PodDisruptionBudget
+	for i := range b.pdbs {
+		pdb := &b.pdbs[i]
+		if pdb.Namespace != pod.Namespace {
+			continue
+		}
+		selector, err := meta.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			covering = append(covering, pdb)
+		}
+	}
+	return covering
+}
+
+// returns the name of a budget selecting the pod whose allowed disruptions the evictions planned in the cycle already
+// used up, or an empty string when the pod may be planned; budgets allowing no disruption at all are left to the PDB
+// check, which lets the pods they blocked for too long through to be deleted outright
+func (b *pdbBudgets) exhausted(pod *core.Pod) string {
+	for _, pdb := range b.covering(pod) {
+		planned := b.planned[types.NamespacedName{Namespace: pdb.Namespace, Name: pdb.Name}]
+		if allowed := pdb.Status.DisruptionsAllowed; allowed > 0 && planned >= allowed {
+			return pdb.Name
+		}
+	}
+	return ""
+}
+
+// counts an eviction planned against the budgets selecting the pod
+func (b *pdbBudgets) consume(pod *core.Pod) {
+	for _, pdb := range b.covering(pod) {
+		b.planned[types.NamespacedName{Namespace: pdb.Namespace, Name: pdb.Name}]++
+	}
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/pkg/eviction"
+)
+
+// tracks the pods kept from eviction by their PodDisruptionBudget: since when pods on degraded nodes have been blocked,
+// so that pods blocked for too long can be deleted outright, and when the pods found blocked while planning are checked
+// again, so that they are neither re-checked nor reported every cycle
+type pdbBlockTracker struct {
+	mu     sync.Mutex
+	blocks map[types.UID]*pdbBlock
+}
+
+// PodDisruptionBudget block of a pod
+type pdbBlock struct {
+	// when the pod was first seen blocked
+	since time.Time
+	// whether the pod is backing off after being found blocked while planning, along with the checks that found it
+	// blocked in a row, when the last of them was made and when the pod is checked again
+	backingOff bool
+	attempts   int
+	checkedAt  time.Time
+	retryAt    time.Time
+	// pod and owner reported in the aggregated events, the owner being nil for pods without one
+	pod    *core.Pod
+	owner  client.Object
+	reason string
+}
+
+// creates a new pdbBlockTracker instance
+func newPDBBlockTracker() *pdbBlockTracker {
+	return &pdbBlockTracker{
+		blocks: make(map[types.UID]*pdbBlock),
+	}
+}
+
+// records that a pod's eviction is blocked, returning the time it was first seen blocked
+func (t *pdbBlockTracker) observe(uid types.UID, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	block, ok := t.blocks[uid]
+	if !ok {
+		block = &pdbBlock{since: now}
+		t.blocks[uid] = block
+	}
+	return block.since
+}
+
+// records that planning found a pod blocked, backing it off exponentially from baseDelay up to maxDelay, and returns
+// when it is checked again
+func (t *pdbBlockTracker) block(pod *core.Pod, owner client.Object, reason string, now time.Time, baseDelay time.Duration, maxDelay time.Duration) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	block, ok := t.blocks[pod.UID]
+	if !ok {
+		block = &pdbBlock{since: now}
+		t.blocks[pod.UID] = block
+	}
+	delay := baseDelay
+	for i := 0; i < block.attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	block.backingOff = true
+	block.attempts++
+	block.checkedAt, block.retryAt = now, now.Add(min(delay, maxDelay))
+	block.pod, block.owner, block.reason = pod, owner, reason
+	return block.retryAt
+}
+
+// returns when a pod found blocked while planning is checked again, ok being false once it may be checked
+func (t *pdbBlockTracker) backingOff(uid types.UID, now time.Time) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	block, ok := t.blocks[uid]
+	if !ok || !block.backingOff || !now.Before(block.retryAt) {
+		return time.Time{}, false
+	}
+	return block.retryAt, true
+}
+
+// stops backing off a pod its PodDisruptionBudget no longer blocks
+func (t *pdbBlockTracker) unblocked(uid types.UID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if block, ok := t.blocks[uid]; ok {
+		block.backingOff, block.attempts = false, 0
+		block.pod, block.owner = nil, nil
+	}
+}
+
+// returns the blocks found by the checks made at the given time, and stops backing off the pods whose backoff lapsed
+// more than grace ago without them being checked again, e.g. as their node recovered, along with the number of pods
+// still backing off
+func (t *pdbBlockTracker) settle(now time.Time, grace time.Duration) ([]pdbBlock, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var checked []pdbBlock
+	blocked := 0
+	for _, block := range t.blocks {
+		if !block.backingOff {
+			continue
+		}
+		if block.checkedAt.Equal(now) {
+			checked = append(checked, *block)
+		} else if now.Sub(block.retryAt) > grace {
+			block.backingOff, block.attempts = false, 0
+			block.pod, block.owner = nil, nil
+			continue
+		}
+		blocked++
+	}
+	return checked, blocked
+}
+
+// forgets a pod once it was evicted or deleted
+func (t *pdbBlockTracker) forget(uid types.UID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.blocks, uid)
+}
+
+// forgets the pods missing from the given set, which no longer exist
+func (t *pdbBlockTracker) retain(uids map[types.UID]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for uid := range t.blocks {
+		if !uids[uid] {
+			delete(t.blocks, uid)
+		}
+	}
+}
+
+// records that a pod's eviction is blocked by its PodDisruptionBudget, reporting whether it has been blocked for
+// longer than PDBBlockForceDeleteAfter and should be deleted outright, along with the time it was first seen blocked
+func (r *PodRebalancer) pdbBlockEscalated(pod *core.Pod, now time.Time) (bool, time.Time) {
+	if r.pdbBlocks == nil || r.PDBBlockForceDeleteAfter <= 0 {
+		return false, time.Time{}
+	}
+	since := r.pdbBlocks.observe(pod.UID, now)
+	return now.Sub(since) >= r.PDBBlockForceDeleteAfter, since
+}
+
+// reports the pods found blocked by their PodDisruptionBudget in the cycle with a single event per owner, rather than
+// one per pod, and the number of pods backing off in a gauge
+func (r *PodRebalancer) reportPDBBlocks(cfg rebalanceConfig, now time.Time) {
+	checked, blocked := r.pdbBlocks.settle(now, cfg.recheckInterval)
+	metrics.PDBBlockedEvictions.Set(float64(blocked))
+
+	byOwner := map[types.UID][]pdbBlock{}
+	var owners []types.UID
+	for _, block := range checked {
+		if block.owner == nil {
+			r.Recorder.Eventf(block.pod, core.EventTypeWarning, "PDBViolation", "Pod %s cannot be evicted due to PDB violation, checking again at %s: %s", block.pod.Name, block.retryAt.Format(time.RFC3339), block.reason)
+			continue
+		}
+		uid := block.owner.GetUID()
+		if _, ok := byOwner[uid]; !ok {
+			owners = append(owners, uid)
+		}
+		byOwner[uid] = append(byOwner[uid], block)
+	}
+	for _, uid := range owners {
+		blocks := byOwner[uid]
+		names := make([]string, 0, len(blocks))
+		for _, block := range blocks {
+			names = append(names, block.pod.Name)
+		}
+		sort.Strings(names)
+		owner := blocks[0].owner
+		r.Log.Info("pods blocked by their PodDisruptionBudget, backing off", "owner", owner.GetName(), "namespace", owner.GetNamespace(), "pods", names)
+		r.Recorder.Eventf(owner, core.EventTypeWarning, "PDBViolation", "Evictions of %d pods of %s blocked by their PodDisruptionBudget, checking again with backoff: %s (%s)", len(names), owner.GetName(), strings.Join(names, ", "), blocks[0].reason)
+	}
+}
+
+// deletes a pod outright, bypassing its PodDisruptionBudget; the pod keeps the grace period its eviction would have
+// granted, and a pod recreated under the same name is left alone
+func (r *PodRebalancer) forceDeletePod(ctx context.Context, pod *core.Pod, opts eviction.EvictOptions) error {
+	deleteOpts := []client.DeleteOption{client.Preconditions{UID: &pod.UID}}
+	if opts.GracePeriodSeconds != nil {
+		deleteOpts = append(deleteOpts, client.GracePeriodSeconds(*opts.GracePeriodSeconds))
+	}
+	if opts.DryRun {
+		deleteOpts = append(deleteOpts, client.DryRunAll)
+	}
+	return r.Delete(ctx, pod, deleteOpts...)
+}
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+)
+
+// label marking the PodDisruptionBudgets created and managed by the controller
+const ManagedPDBLabel = "kube-balance.io/managed-pdb"
+
+// annotation recording the workload profile a managed PodDisruptionBudget was generated from
+const ManagedPDBProfileAnnotation = "kube-balance.io/profile"
+
+// periodically creates, updates and deletes PodDisruptionBudgets so that every workload governed by a profile with a
+// minAvailable is covered by one, making drains, upgrades and other disruption sources respect the same constraint
+type PDBManager struct {
+	client.Client
+	Log             logr.Logger
+	ProfilerWatcher *profiles.WorkloadProfileWatcher
+	Interval        time.Duration
+}
+
+// +kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+
+// PodDisruptionBudget desired for a workload
+type desiredPDB struct {
+	owner          client.Object
+	selector       *meta.LabelSelector
+	templateLabels map[string]string
+	minAvailable   int32
+	profile        string
+}
+
+// implements the manager.Runnable interface to keep the managed PodDisruptionBudgets in sync until the context is cancelled
+func (m *PDBManager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	m.Log.Info("starting PodDisruptionBudget manager", "interval", m.Interval)
+	for {
+		if err := m.sync(ctx); err != nil {
+			m.Log.Error(err, "failed to sync managed PodDisruptionBudgets")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolves the workloads governed by a profile with a minAvailable and reconciles their PodDisruptionBudgets
+func (m *PDBManager) sync(ctx context.Context) error {
+	workloadProfiles := m.ProfilerWatcher.GetProfiles()
+	namespacedProfiles := m.ProfilerWatcher.GetNamespacedProfiles()
+
+	podList := &core.PodList{}
+	if err := m.List(ctx, podList); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	nodeList := &core.NodeList{}
+	if err := m.List(ctx, nodeList); err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	nodesByName := make(map[string]*core.Node, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodesByName[nodeList.Items[i].Name] = &nodeList.Items[i]
+	}
+
+	// workloads are resolved from their pods, as profiles govern pods rather than their owners
+	desired := map[types.UID]*desiredPDB{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		profile, ok := profiles.MatchPodScoped(pod, nodesByName[pod.Spec.NodeName], namespacedProfiles, workloadProfiles)
+		if !ok || profile.Spec.MinAvailable == nil {
+			continue
+		}
+		owner, err := getPodOwner(ctx, m, pod)
+		if err != nil {
+			m.Log.Error(err, "failed to get pod owner", "pod", pod.Name, "namespace", pod.Namespace)
+			continue
+		}
+		if owner == nil {
+			continue
+		}
+		if _, seen := desired[owner.GetUID()]; seen {
+			continue
+		}
+		pdb := &desiredPDB{
+			owner:        owner,
+			minAvailable: *profile.Spec.MinAvailable,
+			profile:      profiles.Key(profile),
+		}
+		switch o := owner.(type) {
+		case *apps.Deployment:
+			pdb.selector, pdb.templateLabels = o.Spec.Selector, o.Spec.Template.Labels
+		case *apps.StatefulSet:
+			pdb.selector, pdb.templateLabels = o.Spec.Selector, o.Spec.Template.Labels
+		case *apps.ReplicaSet:
+			pdb.selector, pdb.templateLabels = o.Spec.Selector, o.Spec.Template.Labels
+		}
+		desired[owner.GetUID()] = pdb
+	}
+
+	pdbList := &policy.PodDisruptionBudgetList{}
+	if err := m.List(ctx, pdbList); err != nil {
+		return fmt.Errorf("failed to list PodDisruptionBudgets: %w", err)
+	}
+
+	managed := map[types.NamespacedName]*policy.PodDisruptionBudget{}
+	for i := range pdbList.Items {
+		pdb := &pdbList.Items[i]
+		if pdb.Labels[ManagedPDBLabel] == "true" {
+			managed[types.NamespacedName{Namespace: pdb.Namespace, Name: pdb.Name}] = pdb
+		}
+	}
+
+	kept := map[types.NamespacedName]bool{}
+	for _, want := range desired {
+		key := types.NamespacedName{Namespace: want.owner.GetNamespace(), Name: managedPDBName(want.owner)}
+		// a pod covered by several budgets can't be evicted at all, so workloads with a budget of their own are left alone
+		if covering := unmanagedPDBCovering(pdbList.Items, want.owner.GetNamespace(), want.templateLabels); covering != "" {
+			m.Log.V(1).Info("workload is already covered by a PodDisruptionBudget, not managing one", "owner", want.owner.GetName(), "namespace", want.owner.GetNamespace(), "pdb", covering)
+			continue
+		}
+		kept[key] = true
+		if err := m.apply(ctx, key, want, managed[key]); err != nil {
+			m.Log.Error(err, "failed to reconcile managed PodDisruptionBudget", "pdb", key.Name, "namespace", key.Namespace)
+		}
+	}
+
+	// deleting the budgets of workloads no longer governed by a profile with a minAvailable
+	for key, pdb := range managed {
+		if kept[key] {
+			continue
+		}
+		if err := m.Delete(ctx, pdb); err != nil && !errors.IsNotFound(err) {
+			m.Log.Error(err, "failed to delete managed PodDisruptionBudget", "pdb", key.Name, "namespace", key.Namespace)
+			continue
+		}
+		m.Log.Info("deleted managed PodDisruptionBudget", "pdb", key.Name, "namespace", key.Namespace)
+	}
+
+	return nil
+}
+
+// creates or updates the managed PodDisruptionBudget of a workload
+func (m *PDBManager) apply(ctx context.Context, key types.NamespacedName, want *desiredPDB, existing *policy.PodDisruptionBudget) error {
+	minAvailable := intstr.FromInt32(want.minAvailable)
+	spec := policy.PodDisruptionBudgetSpec{
+		MinAvailable: &minAvailable,
+		Selector:     want.selector.DeepCopy(),
+	}
+
+	if existing == nil {
+		pdb := &policy.PodDisruptionBudget{
+			ObjectMeta: meta.ObjectMeta{
+				Name:        key.Name,
+				Namespace:   key.Namespace,
+				Labels:      map[string]string{ManagedPDBLabel: "true"},
+				Annotations: map[string]string{ManagedPDBProfileAnnotation: want.profile},
+			},
+			Spec: spec,
+		}
+		// owning the budget through the workload lets it be garbage collected along with it
+		if err := controllerutil.SetOwnerReference(want.owner, pdb, m.Scheme()); err != nil {
+			return fmt.Errorf("failed to set owner reference: %w", err)
+		}
+		if err := m.Create(ctx, pdb); err != nil {
+			return fmt.Errorf("failed to create PodDisruptionBudget: %w", err)
+		}
+		m.Log.Info("created managed PodDisruptionBudget", "pdb", key.Name, "namespace", key.Namespace, "minAvailable", want.minAvailable, "profile", want.profile)
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec.MinAvailable, spec.MinAvailable) &&
+		equality.Semantic.DeepEqual(existing.Spec.Selector, spec.Selector) &&
+		existing.Annotations[ManagedPDBProfileAnnotation] == want.profile {
+		return nil
+	}
+
+	patch := client.MergeFrom(existing.DeepCopy())
+	existing.Spec.MinAvailable = spec.MinAvailable
+	existing.Spec.MaxUnavailable = nil
+	existing.Spec.Selector = spec.Selector
+	if existing.Annotations == nil {
+		existing.Annotations = make(map[string]string)
+	}
+	existing.Annotations[ManagedPDBProfileAnnotation] = want.profile
+	if err := m.Patch(ctx, existing, patch); err != nil {
+		return fmt.Errorf("failed to update PodDisruptionBudget: %w", err)
+	}
+	m.Log.Info("updated managed PodDisruptionBudget", "pdb", key.Name, "namespace", key.Namespace, "minAvailable", want.minAvailable, "profile", want.profile)
+	return nil
+}
+
+// names the managed PodDisruptionBudget of a workload after its kind and name
+func managedPDBName(owner client.Object) string {
+	kind := ""
+	switch owner.(type) {
+	case *apps.Deployment:
+		kind = "deployment"
+	case *apps.StatefulSet:
+		kind = "statefulset"
+	case *apps.ReplicaSet:
+		kind = "replicaset"
+	}
+	return strings.Join([]string{"kube-balance", kind, owner.GetName()}, "-")
+}
+
+// returns the name of a PodDisruptionBudget not managed by the controller that selects the workload's pods, if any
+func unmanagedPDBCovering(pdbs []policy.PodDisruptionBudget, namespace string, podLabels map[string]string) string {
+	for _, pdb := range pdbs {
+		if pdb.Namespace != namespace || pdb.Labels[ManagedPDBLabel] == "true" {
+			continue
+		}
+		selector, err := meta.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(podLabels)) {
+			return pdb.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+)
+
+// which Pending pods count towards the threshold past which all evictions are paused
+const (
+	// every Pending pod in the cluster
+	PendingPodsScopeCluster = "cluster"
+	// only the Pending pods created to replace pods kube-balance evicted
+	PendingPodsScopeEvicted = "evicted"
+)
+
+// pauses all evictions while too many pods are Pending, resuming once the scheduler catches up, so that evictions don't
+// pile more unschedulable pods onto a capacity crunch
+type pendingPodsBreaker struct {
+	mu sync.Mutex
+	// when a pod of each controller was last evicted, by the controller's UID, telling the replacements of the evicted
+	// pods apart from the other Pending pods
+	evictedAt map[types.UID]time.Time
+	// whether evictions are currently paused
+	open bool
+}
+
+// creates a new pendingPodsBreaker instance
+func newPendingPodsBreaker() *pendingPodsBreaker {
+	return &pendingPodsBreaker{
+		evictedAt: make(map[types.UID]time.Time),
+	}
+}
+
+// records that a pod was evicted, so that the pods its controller creates from then on count as its replacements
+func (b *pendingPodsBreaker) evicted(pod *core.Pod, at time.Time) {
+	controllerRef := meta.GetControllerOf(pod)
+	if controllerRef == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.evictedAt[controllerRef.UID] = at
+}
+
+// counts the Pending pods within the scope, forgetting the evictions of controllers that no longer have any pods
+func (b *pendingPodsBreaker) countPending(pods []core.Pod, scope string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	controllers := make(map[types.UID]bool, len(b.evictedAt))
+	pending := 0
+	for i := range pods {
+		pod := &pods[i]
+		controllerRef := meta.GetControllerOf(pod)
+		if controllerRef != nil {
+			controllers[controllerRef.UID] = true
+		}
+		if pod.Status.Phase != core.PodPending || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if scope == PendingPodsScopeEvicted {
+			if controllerRef == nil {
+				continue
+			}
+			evictedAt, ok := b.evictedAt[controllerRef.UID]
+			if !ok || pod.CreationTimestamp.Time.Before(evictedAt.Truncate(time.Second)) {
+				continue
+			}
+		}
+		pending++
+	}
+	for uid := range b.evictedAt {
+		if !controllers[uid] {
+			delete(b.evictedAt, uid)
+		}
+	}
+	return pending
+}
+
+// opens or closes the breaker, reporting whether that changed its state
+func (b *pendingPodsBreaker) trip(open bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	changed := b.open != open
+	b.open = open
+	return changed
+}
+
+// reports whether all evictions are paused as more pods within the configured scope are Pending than the threshold
+// allows, logging when evictions are paused or resumed; evictions are never paused when the threshold is 0
+func (r *PodRebalancer) pendingPodsPaused(cfg rebalanceConfig, pods []core.Pod) bool {
+	if r.pendingPods == nil || cfg.pendingPodsThreshold <= 0 {
+		if r.pendingPods != nil && r.pendingPods.trip(false) {
+			metrics.EvictionsPaused.Set(0)
+		}
+		return false
+	}
+
+	pending := r.pendingPods.countPending(pods, cfg.pendingPodsScope)
+	open := pending > cfg.pendingPodsThreshold
+	if r.pendingPods.trip(open) {
+		if open {
+			r.Log.Info("too many pods are Pending, pausing evictions until the scheduler catches up", "pending", pending, "threshold", cfg.pendingPodsThreshold, "scope", cfg.pendingPodsScope)
+			metrics.EvictionsPaused.Set(1)
+		} else {
+			r.Log.Info("the scheduler caught up with the Pending pods, resuming evictions", "pending", pending, "threshold", cfg.pendingPodsThreshold, "scope", cfg.pendingPodsScope)
+			metrics.EvictionsPaused.Set(0)
+		}
+	}
+	return open
+}
+
+// reports whether all evictions are paused as too many pods are Pending, counting them afresh, for the evictions
+// carried out between the checks at the start of each reconcile; evictions carry on when the pods can't be listed
+func (r *PodRebalancer) pendingPodsPausedNow(ctx context.Context, cfg rebalanceConfig) bool {
+	if r.pendingPods == nil || cfg.pendingPodsThreshold <= 0 {
+		return r.pendingPodsPaused(cfg, nil)
+	}
+	podList := &core.PodList{}
+	if err := r.List(ctx, podList); err != nil {
+		r.Log.Error(err, "failed to list pods, skipping the pending pods check")
+		return false
+	}
+	return r.pendingPodsPaused(cfg, podList.Items)
+}
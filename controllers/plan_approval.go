@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"sort"
+
+	core "k8s.io/api/core/v1"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+)
+
+// annotation approving the execution of a RebalancePlan in plan mode, for approvers that may annotate objects but not
+// edit the plan's spec: "true" on the plan approves all of it, while the plan's name on a node approves the evictions
+// from that node, the plan being executed once each of its nodes approved it
+const PlanApprovalAnnotation = "kube-balance.io/approve"
+
+// reports whether a plan was approved for execution, through its spec, its approval annotation or the approval
+// annotations of all the nodes it evicts pods from
+func planApproved(plan *api_v1alpha1.RebalancePlan, nodesByName map[string]*core.Node) bool {
+	if plan.Spec.Approved || plan.Annotations[PlanApprovalAnnotation] == "true" {
+		return true
+	}
+	return len(plan.Spec.Evictions) > 0 && len(unapprovedPlanNodes(plan, nodesByName)) == 0
+}
+
+// returns the names of the nodes a plan evicts pods from whose approval annotation doesn't name the plan, sorted
+func unapprovedPlanNodes(plan *api_v1alpha1.RebalancePlan, nodesByName map[string]*core.Node) []string {
+	seen := map[string]bool{}
+	var unapproved []string
+	for _, planned := range plan.Spec.Evictions {
+		if seen[planned.Node] {
+			continue
+		}
+		seen[planned.Node] = true
+		if node, ok := nodesByName[planned.Node]; !ok || node.Annotations[PlanApprovalAnnotation] != plan.Name {
+			unapproved = append(unapproved, planned.Node)
+		}
+	}
+	sort.Strings(unapproved)
+	return unapproved
+}
+
+// reports a plan awaiting approval on each of the nodes it evicts pods from, so that the approvers of a node learn of
+// the evictions planned from it
+func (r *PodRebalancer) reportAwaitingApproval(plan *api_v1alpha1.RebalancePlan, nodesByName map[string]*core.Node) {
+	r.Recorder.Eventf(plan, core.EventTypeNormal, "PlanAwaitingApproval", "Rebalance plan %s awaits approval: set spec.approved, annotate it with %s=true or annotate each of its nodes with %s=%s", plan.Name, PlanApprovalAnnotation, PlanApprovalAnnotation, plan.Name)
+	evictions := map[string]int{}
+	for _, planned := range plan.Spec.Evictions {
+		evictions[planned.Node]++
+	}
+	for nodeName, count := range evictions {
+		if node, ok := nodesByName[nodeName]; ok {
+			r.Recorder.Eventf(node, core.EventTypeNormal, "EvictionsAwaitingApproval", "Rebalance plan %s lists %d evictions from node %s, awaiting approval through the node's %s=%s annotation", plan.Name, count, nodeName, PlanApprovalAnnotation, plan.Name)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"time"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// returns the minimum age of a profile's pods before they may be evicted, as set by the profile or otherwise by
+// --min-pod-age
+func (r *PodRebalancer) minPodAge(profile *api_v1.WorkloadProfile) time.Duration {
+	if profile != nil && profile.Spec.Eviction.MinPodAge != nil {
+		return profile.Spec.Eviction.MinPodAge.Duration
+	}
+	return r.MinPodAge
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// tracks until when the pods evicted recently are exempt from eviction, by name rather than UID, so that a StatefulSet
+// pod recreated under the same name isn't evicted again right away should it land on another degraded node
+type podCooldownTracker struct {
+	mu    sync.Mutex
+	until map[types.NamespacedName]time.Time
+}
+
+// creates a new podCooldownTracker instance
+func newPodCooldownTracker() *podCooldownTracker {
+	return &podCooldownTracker{
+		until: make(map[types.NamespacedName]time.Time),
+	}
+}
+
+// records that the pod named so was evicted, exempting the pods of that name from eviction until the given time
+func (t *podCooldownTracker) start(name types.NamespacedName, until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.until[name] = until
+}
+
+// returns until when the pods of that name are exempt from eviction, false once their cooldown has ended; ended
+// cooldowns are forgotten
+func (t *podCooldownTracker) cooldown(name types.NamespacedName, now time.Time) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	until, ok := t.until[name]
+	if !ok {
+		return time.Time{}, false
+	}
+	if !now.Before(until) {
+		delete(t.until, name)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// forgets the cooldowns that have ended; pods of workloads with generated names never come back under the same name,
+// so their cooldowns would otherwise be kept for good
+func (t *podCooldownTracker) prune(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, until := range t.until {
+		if !now.Before(until) {
+			delete(t.until, name)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+func TestPlanEvictionsSkipsPodsInCooldown(t *testing.T) {
+	nodes := []*core.Node{degradedNode("node-a", degradation.SeverityNormal), {ObjectMeta: meta.ObjectMeta{Name: "node-b"}}}
+	deploy := testDeployment("app", 3, false)
+	pods := []*core.Pod{testPod("app-0", deploy, "node-a", "128Mi"), testPod("app-1", deploy, "node-a", "128Mi")}
+	r, _ := newTestRebalancer(t, testObjects(nodes, []*apps.Deployment{deploy}, pods)...)
+	state := testState(r, nodes, pods)
+	r.podCooldowns.start(types.NamespacedName{Namespace: "default", Name: "app-0"}, state.now.Add(time.Minute))
+
+	if got := plannedPods(r.planEvictions(context.Background(), state)); !slices.Equal(got, []string{"app-1"}) {
+		t.Errorf("planned evictions = %v, want the pod in cooldown left in place", got)
+	}
+}
+
+func TestPodCooldownTrackerPrunesEndedCooldowns(t *testing.T) {
+	now := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	tracker := newPodCooldownTracker()
+	// pods of workloads with generated names never come back to be checked again
+	tracker.start(types.NamespacedName{Namespace: "default", Name: "web-7d9f8-x2k4p"}, now.Add(-time.Second))
+	tracker.start(types.NamespacedName{Namespace: "default", Name: "db-0"}, now.Add(time.Minute))
+
+	tracker.prune(now)
+
+	if len(tracker.until) != 1 {
+		t.Errorf("cooldowns = %v, want only the one still running", tracker.until)
+	}
+	if _, ok := tracker.cooldown(types.NamespacedName{Namespace: "default", Name: "db-0"}, now); !ok {
+		t.Errorf("cooldown of db-0 ended early")
+	}
+	if _, ok := tracker.cooldown(types.NamespacedName{Namespace: "default", Name: "db-0"}, now.Add(time.Minute)); ok {
+		t.Errorf("cooldown of db-0 still running once it ended")
+	}
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	core "k8s.io/api/core/v1"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+)
+
+// label grouping tightly-coupled pods, e.g. the workers of a distributed job, that are evicted together or not at all
+const PodGroupLabel = "kube-balance.io/pod-group"
+
+// name of the pseudo-strategy the other members of an evicted pod's group are planned for
+const PodGroupStrategy = "pod-group"
+
+// labels naming a pod's group, kube-balance's own first, then those of the Kueue pod groups and of the scheduler-plugins
+// coscheduling plugin
+var podGroupLabels = []string{PodGroupLabel, "kueue.x-k8s.io/pod-group-name", "scheduling.x-k8s.io/pod-group"}
+
+// annotations naming a pod's group, set by Volcano on the pods of its PodGroups
+var podGroupAnnotations = []string{"scheduling.k8s.io/group-name"}
+
+// plans the evictions of the other members of an evicted pod's group; it selects no pods of its own, the members being
+// planned along with the pod that got the group evicted
+type podGroupStrategy struct {
+	r *PodRebalancer
+}
+
+// implements the rebalanceStrategy interface
+func (s *podGroupStrategy) name() string {
+	return PodGroupStrategy
+}
+
+// implements the rebalanceStrategy interface
+func (s *podGroupStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	return nil
+}
+
+// implements the rebalanceStrategy interface; a group being evicted is evicted whole, whatever became of the node of
+// the pod that got it evicted
+func (s *podGroupStrategy) revalidate(node *core.Node, now time.Time) string {
+	return ""
+}
+
+// returns the name of the pod's group, or an empty string for a pod that isn't part of one
+func podGroupName(pod *core.Pod) string {
+	for _, label := range podGroupLabels {
+		if name := pod.Labels[label]; name != "" {
+			return name
+		}
+	}
+	for _, annotation := range podGroupAnnotations {
+		if name := pod.Annotations[annotation]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// groups the pods that haven't completed nor are being deleted by their namespace and group name, e.g. "ml/trainer"
+func groupPods(pods []core.Pod) map[string][]*core.Pod {
+	groups := map[string][]*core.Pod{}
+	for i := range pods {
+		pod := &pods[i]
+		name := podGroupName(pod)
+		if name == "" || pod.DeletionTimestamp != nil || pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		key := pod.Namespace + "/" + name
+		groups[key] = append(groups[key], pod)
+	}
+	return groups
+}
+
+// returns the other members of the pod's group to evict along with it, or the first of them that can't be evicted at
+// all; the members are checked like any other candidate before being planned
+func (r *PodRebalancer) podGroupMembers(ctx context.Context, state *rebalanceState, pod *core.Pod, group string, members []*core.Pod) ([]strategyCandidate, *core.Pod) {
+	var candidates []strategyCandidate
+	for _, member := range members {
+		if member.UID == pod.UID {
+			continue
+		}
+		node, ok := state.nodesByName[member.Spec.NodeName]
+		if !ok {
+			// a member that isn't scheduled yet has nothing to evict
+			if member.Spec.NodeName == "" {
+				continue
+			}
+			return nil, member
+		}
+		evictables := r.nodeEvictables(ctx, state, node.Name)
+		if !slices.Contains(evictables.pods, member) {
+			return nil, member
+		}
+		profile, profileFound := evictables.podProfiles[member]
+		candidates = append(candidates, strategyCandidate{
+			evictionCandidate: evictionCandidate{
+				pod:          member,
+				node:         node,
+				profile:      profile,
+				profileFound: profileFound,
+				reason:       fmt.Sprintf("evicted along with pod %s of its pod group %s", pod.Name, group),
+			},
+			strategy:    PodGroupStrategy,
+			evictedWith: pod.UID,
+		})
+	}
+	return candidates, nil
+}
+
+// returns why a pod group member is no longer evicted, as the pod it was planned along with wasn't evicted, or an empty
+// string otherwise; evictions are carried out in plan order, so that pod's result is recorded before the member's
+func podGroupLeaderSkipReason(plan *api_v1alpha1.RebalancePlan, planned api_v1alpha1.PlannedEviction) string {
+	if planned.EvictedWith == "" {
+		return ""
+	}
+	for i, leader := range plan.Spec.Evictions {
+		if leader.UID != planned.EvictedWith {
+			continue
+		}
+		if i >= len(plan.Status.Results) {
+			return fmt.Sprintf("pod %s of its pod group hasn't been evicted yet", leader.Pod)
+		}
+		switch outcome := plan.Status.Results[i].Outcome; outcome {
+		case api_v1alpha1.PlannedEvictionEvicted, api_v1alpha1.PlannedEvictionForceDeleted, api_v1alpha1.PlannedEvictionDryRun:
+			return ""
+		default:
+			return fmt.Sprintf("pod %s of its pod group wasn't evicted (%s), and evicting part of the group would waste the rest", leader.Pod, outcome)
+		}
+	}
+	return ""
+}
+
+// reports whether the pod a pod group member is evicted along with is part of the batch being prepared, whose outcome
+// isn't known yet
+func evictedWithinBatch(batch []*batchedEviction, planned api_v1alpha1.PlannedEviction) bool {
+	if planned.EvictedWith == "" {
+		return false
+	}
+	for _, entry := range batch {
+		if entry.planned.UID == planned.EvictedWith {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// returns a pod of the given pod group, of its own Deployment
+func groupPod(name string, group string, node string) (*core.Pod, *apps.Deployment) {
+	deploy := testDeployment(name, 3, false)
+	pod := testPod(name, deploy, node, "128Mi")
+	pod.Labels[PodGroupLabel] = group
+	return pod, deploy
+}
+
+func TestPlanEvictionsPlansPodGroupsWhole(t *testing.T) {
+	tests := []struct {
+		name string
+		// makes the member on the healthy node younger than the minimum pod age
+		youngMember bool
+		want        []string
+	}{
+		{name: "every member passes its checks", want: []string{"worker-0", "worker-1"}},
+		{name: "a member fails its checks", youngMember: true, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := []*core.Node{degradedNode("node-a", degradation.SeverityNormal), {ObjectMeta: meta.ObjectMeta{Name: "node-b"}}}
+			leader, leaderOwner := groupPod("worker-0", "train", "node-a")
+			member, memberOwner := groupPod("worker-1", "train", "node-b")
+			if tt.youngMember {
+				member.CreationTimestamp = meta.Now()
+			}
+			pods := []*core.Pod{leader, member}
+			r, _ := newTestRebalancer(t, testObjects(nodes, []*apps.Deployment{leaderOwner, memberOwner}, pods)...)
+			r.GroupAwareEviction = true
+			r.MinPodAge = time.Minute
+
+			evictions := r.planEvictions(context.Background(), testState(r, nodes, pods))
+			if got := plannedPods(evictions); !slices.Equal(got, tt.want) {
+				t.Fatalf("planned evictions = %v, want %v", got, tt.want)
+			}
+			if len(evictions) == 2 && (evictions[1].Strategy != PodGroupStrategy || evictions[1].EvictedWith != leader.UID) {
+				t.Errorf("member eviction = %+v, want it planned along with %s", evictions[1], leader.Name)
+			}
+		})
+	}
+}
+
+func TestExecutePlanEvictsPodGroupMembersOnlyAlongWithTheirPod(t *testing.T) {
+	tests := []struct {
+		name string
+		// leaves the pod the member is evicted along with out of the cluster
+		leaderGone   bool
+		wantOutcomes []api_v1alpha1.PlannedEvictionOutcome
+		wantEvicted  []string
+	}{
+		{
+			name:         "pod evicted",
+			wantOutcomes: []api_v1alpha1.PlannedEvictionOutcome{api_v1alpha1.PlannedEvictionEvicted, api_v1alpha1.PlannedEvictionEvicted},
+			wantEvicted:  []string{"worker-0", "worker-1"},
+		},
+		{
+			name:         "pod skipped",
+			leaderGone:   true,
+			wantOutcomes: []api_v1alpha1.PlannedEvictionOutcome{api_v1alpha1.PlannedEvictionSkipped, api_v1alpha1.PlannedEvictionSkipped},
+			wantEvicted:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := degradedNode("node-a", degradation.SeverityNormal)
+			leader, leaderOwner := groupPod("worker-0", "train", "node-a")
+			member, memberOwner := groupPod("worker-1", "train", "node-a")
+			plan := testPlan("plan", leader, member)
+			plan.Spec.Evictions[1].Strategy = PodGroupStrategy
+			plan.Spec.Evictions[1].EvictedWith = leader.UID
+			pods := []*core.Pod{member}
+			if !tt.leaderGone {
+				pods = append(pods, leader)
+			}
+			r, evictor := newTestRebalancer(t, append(testObjects([]*core.Node{node}, []*apps.Deployment{leaderOwner, memberOwner}, pods), plan)...)
+			// the member waits for its pod's outcome even when both fit in a batch
+			r.EvictionConcurrency = 2
+			r.evictionRetries = newEvictionRetryQueue(time.Hour, time.Hour, 3)
+			defer r.evictionRetries.queue.ShutDown()
+
+			for len(plan.Status.Results) < len(plan.Spec.Evictions) {
+				if _, err := r.executePlan(context.Background(), r.currentConfig(), plan, nil, defaultProfiles()); err != nil {
+					t.Fatalf("executePlan() error = %v", err)
+				}
+			}
+			if got := planOutcomes(plan); !slices.Equal(got, tt.wantOutcomes) {
+				t.Errorf("outcomes = %v, want %v", got, tt.wantOutcomes)
+			}
+			if got := podNames(evictor.Evicted()); !slices.Equal(got, tt.wantEvicted) {
+				t.Errorf("evicted pods = %v, want %v", got, tt.wantEvicted)
+			}
+		})
+	}
+}
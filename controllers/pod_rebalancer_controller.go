@@ -2,26 +2,30 @@ package controllers
 
 import (
 	"context"
-	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
 	core "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/lokeshllkumar/kube-balance/internal/hooks"
+	"github.com/lokeshllkumar/kube-balance/internal/policy"
 	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+	"github.com/lokeshllkumar/kube-balance/internal/promquery"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
 	"github.com/lokeshllkumar/kube-balance/pkg/eviction"
 )
 
 // annotation used to mark a node as degraded
-const NodeDegradedAnnotation = "kube-balance.io/degraded-io"
+const NodeDegradedAnnotation = degradation.DegradedAnnotation
 
 // label used to identify the workload type of a pod
-const WorkloadTypeLabel = "workload.k8s.io/type"
+const WorkloadTypeLabel = profiles.WorkloadTypeLabel
 
 // annotation to be used on a pod's owner to prevent immediate re-eviction after one of its pods has jsut been evicted
 const EvictionCooldownAnnotation = "kube-balance.io/eviction-cooldown-until"
@@ -31,30 +35,188 @@ type PodRebalancer struct {
 	client.Client
 	Scheme                      *runtime.Scheme
 	Log                         logr.Logger
-	Evictor                     *eviction.Evictor
+	Evictor                     eviction.Evictor
 	ProfilerWatcher             *profiles.WorkloadProfileWatcher
+	PolicyWatcher               *policy.RebalancePolicyWatcher
 	RecheckInterval             time.Duration
 	MaxEvictionsPerNodePerCycle int
 	Recorder                    record.EventRecorder
+	// reads the objects only ever read one at a time straight from the API server, so that no informer caches all of
+	// them, e.g. the node heartbeat Leases; the cached client is used when nil
+	APIReader client.Reader
+
+	// decides which nodes are degraded
+	DegradationClassifier *degradation.Classifier
+	// number of consecutive reconcile cycles, at most one per recheck interval, a node must stay degraded before evictions start
+	DegradationConfirmationCycles int
+	// minimum duration a node must stay degraded before evictions start
+	DegradationConfirmationPeriod time.Duration
+	// per-cycle eviction budget for urgently degraded nodes (e.g. spot interruptions)
+	UrgentMaxEvictionsPerNodePerCycle int
+	// share of a zone's nodes that must be degraded at once for the zone to be treated as a correlated failure; 0 disables the check
+	ZoneDegradationThreshold float64
+	// action taken for zones with a correlated failure ("pause" or "throttle")
+	ZoneDegradationAction string
+	// per-cycle eviction budget across all degraded nodes of a throttled zone
+	ZoneThrottledMaxEvictions int
+	// whether RebalancePlans are executed as soon as they are written ("apply") or once approved ("plan")
+	RebalanceMode string
+	// sends evictions as server-side dry runs, reporting the pods that would be evicted through events, logs and metrics without evicting them
+	DryRun bool
+	// halts all evictions and forced deletions, e.g. during an incident, while degraded nodes are still detected and
+	// the evictions that would be carried out are still planned and reported
+	Paused bool
+	// attempts after which an eviction failing in a way worth retrying (a PodDisruptionBudget block or a transient API
+	// error) is given up; evictions are not retried individually when at most 1
+	EvictionRetryMaxAttempts int
+	// backoff before the first retry of an eviction, doubled on every further attempt
+	EvictionRetryBaseDelay time.Duration
+	// upper bound of the backoff between the retries of an eviction
+	EvictionRetryMaxDelay time.Duration
+	// pending evictions of a plan sent at once, in parallel through the evictor, when evictions are retried
+	// individually; evictions are sent one at a time when at most 1
+	EvictionConcurrency int
+	// delay between the batches of evictions sent while carrying out a plan, all of whose evictions are sent within a
+	// single reconcile unless held back; 0 sends them back to back
+	EvictionPacing time.Duration
+	// nodes whose eviction candidates are checked in parallel while planning evictions, so that a node with many slow
+	// checks, e.g. against PodDisruptionBudgets, doesn't hold up the others
+	NodeWorkers int
+	// holds back the eviction of an owner's other pods until the pod evicted last has a replacement scheduled and Ready
+	// on a node that isn't degraded, so that healthy capacity is never taken away faster than it is restored
+	WaitForReschedule bool
+	// duration after an eviction beyond which its owner's other pods are no longer held back; 0 waits indefinitely
+	WaitForRescheduleTimeout time.Duration
+	// leaves the pods of Deployments, StatefulSets and Argo Rollouts rolling out a new revision in place until the
+	// rollout completes, unless their node is urgently degraded
+	SkipRollouts bool
+	// evicts pods using emptyDir volumes or local PersistentVolumes unless their workload profile says otherwise
+	EvictLocalStorage bool
+	// queries the node costs exported by OpenCost for the cost-aware strategy; OpenCost can't be used when nil
+	Prometheus *promquery.Client
+	// runs the pre-eviction hooks of workload profiles; hooks are not run when nil
+	Hooks *hooks.Runner
+	// duration a pod on a degraded node may stay blocked by its PodDisruptionBudget before it is deleted outright,
+	// violating the budget; pods are never deleted when 0
+	PDBBlockForceDeleteAfter time.Duration
+	// longest a pod found blocked by its PodDisruptionBudget backs off before it is checked again, its backoff starting
+	// at the recheck interval and doubling with each check still finding it blocked
+	PDBBlockMaxBackoff time.Duration
+	// duration a Deployment scaled up ahead of an eviction may take to get its extra replica Ready before the pod is
+	// evicted regardless; 0 waits indefinitely
+	SurgeTimeout time.Duration
+	// duration a pod kube-balance evicted from an unreachable degraded node may stay terminating past its grace period
+	// before it is deleted outright; such pods are only reported when 0
+	StuckTerminatingForceDeleteAfter time.Duration
+	// force-deletes the StatefulSet pods stuck terminating too, although their replacement may run alongside them
+	StuckTerminatingForceDeleteStatefulSets bool
+	// degraded nodes fully drained at once rather than a few pods per cycle ("off", "urgent" or "all"); drained nodes
+	// are cordoned and their progress is reported on a NodeDrain
+	DrainMode string
+	// cordons the degraded nodes being evacuated a few pods per cycle too, uncordoning them once their degradation
+	// clears, so that the scheduler doesn't keep placing new pods on them
+	CordonDegradedNodes bool
+	// skips the evictions of pods that would fit on none of the nodes that aren't degraded, given their requests, node
+	// selector, required node affinity and tolerations, as they would only be left Pending
+	CheckSchedulingFeasibility bool
+	// throttles evictions to the CPU and memory left free across the Ready, schedulable nodes that aren't degraded, so
+	// that no more pods are evicted than the rest of the cluster has room to reschedule
+	CheckClusterHeadroom bool
+	// share of the allocatable CPU and memory of the nodes that aren't degraded, in percent, kept free when checking
+	// the cluster headroom
+	ClusterHeadroomReservePercent int
+	// leaves an owner's last replica in a topology zone in place when none of the zone's nodes that aren't degraded
+	// could take its replacement, so that evacuating a node doesn't collapse the owner's zone distribution
+	PreserveZoneBalance bool
+	// evicts the pods of a group, e.g. the workers of a gang-scheduled job, together or not at all, as evicting one of
+	// them only wastes the work of the others
+	GroupAwareEviction bool
+	// defers the eviction of pods with a running ephemeral container, e.g. an active `kubectl debug` session, unless
+	// their node is urgently degraded
+	SkipDebuggedPods bool
+	// eviction priority points a pod on a degraded node gains per container restart, crash-looping pods being evicted
+	// first; restarts are left out of the eviction order when 0
+	RestartCountWeight int
+	// minimum age of a pod before it may be evicted, unless its workload profile says otherwise, so that a pod rescheduled
+	// back onto a degraded node isn't evicted again right away; urgently degraded nodes evict younger pods too
+	MinPodAge time.Duration
+	// evictions sent per minute across all nodes and strategies, so that a mass degradation can't churn the cluster;
+	// evictions aren't rate limited when 0
+	MaxEvictionsPerMinute int
+	// only considers pods in namespaces annotated with kube-balance.io/enabled: "true"; namespaces annotated with
+	// "false" are never considered either way
+	NamespaceOptIn bool
+	// only pods in these namespaces are considered; empty means all namespaces, except kube-system unless listed
+	IncludedNamespaces []string
+	// pods in these namespaces are never considered
+	ExcludedNamespaces []string
+	// PriorityClasses whose pods are never evicted, on top of system-cluster-critical and system-node-critical
+	ProtectedPriorityClasses []string
+	// handling of pods without a controller, which nothing recreates once evicted ("skip", "evict-with-warning" or
+	// "evict-if-profiled")
+	BarePodPolicy string
+	// handling of pods owned by a Job, including those of CronJobs, whose progress is lost when evicted ("skip",
+	// "evict-with-warning" or "evict-if-profiled")
+	JobPodPolicy string
+	// estimated progress, in percent, from which the pods of a Job are left to finish on their node rather than losing
+	// their work, unless it is urgently degraded; 0 evicts them whatever their progress
+	JobCompletionThresholdPercent int
+	// share of an owner's desired replicas, in percent, that may be evicted or rescheduled at once across all nodes and
+	// cycles, at least one; 0 disables the limit
+	MaxOwnerDisruptionPercent int
+	// duration a pod name is exempt from eviction after a pod of that name was evicted, so that a StatefulSet pod
+	// recreated on another degraded node isn't evicted again right away; 0 disables it
+	PodEvictionCooldown time.Duration
+	// Pending pods past which all evictions are paused until the scheduler catches up; 0 never pauses them
+	PendingPodsThreshold int
+	// which Pending pods count towards the threshold: "cluster" for all of them, "evicted" for the replacements of
+	// pods kube-balance evicted
+	PendingPodsScope string
+
+	degradationTracker *degradationTracker
+	evictionRetries    *evictionRetryQueue
+	pdbBlocks          *pdbBlockTracker
+	zoneFailures       *zoneFailureReporter
+	skipReports        *skipReporter
+	evictionRate       *evictionRateLimiter
+	nodeRotation       *degradedNodeRotation
+	podCooldowns       *podCooldownTracker
+	pendingPods        *pendingPodsBreaker
+	paused             atomic.Bool
 }
 
-// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
-// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=nodes/proxy,verbs=get
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="apps",resources=deployments;statefulsets;replicasets,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups="kube-balance.io",resources=workloadprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups="kube-balance.io",resources=namespacedworkloadprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups="kube-balance.io",resources=rebalancepolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="kube-balance.io",resources=rebalancepolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="kube-balance.io",resources=rebalanceplans,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="kube-balance.io",resources=rebalanceplans/status,verbs=get;update;patch
 
 // reconciliation loop for the PodRebalancer controller
 func (r *PodRebalancer) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("kube-balancer", req.NamespacedName)
+	cfg := r.currentConfig()
+	r.acknowledgePolicy(ctx)
+	// tenants opt their namespaces out of rebalancing, so evicting anything without knowing which did isn't safe
+	if err := r.resolveNamespaceOptIns(ctx, &cfg); err != nil {
+		log.Error(err, "failed to resolve namespaces opting in or out of rebalancing")
+		return ctrl.Result{}, err
+	}
 
 	// fetching all worload profiles from the watcher's cache
 	workloadProfiles := r.ProfilerWatcher.GetProfiles()
-	if len(workloadProfiles) == 0 {
-		log.Info("no workload profiles found, skipping rebalancing; ensure WorkloadProfile CRs (custom resources) are created")
+	namespacedProfiles := r.ProfilerWatcher.GetNamespacedProfiles()
+	if len(workloadProfiles) == 0 && len(namespacedProfiles) == 0 {
+		log.Info("no workload profiles found, skipping rebalancing; ensure WorkloadProfile or NamespacedWorkloadProfile CRs (custom resources) are created")
 		return ctrl.Result{
-			RequeueAfter: r.RecheckInterval,
+			RequeueAfter: cfg.recheckInterval,
 		}, nil
 	}
 
@@ -65,23 +227,31 @@ func (r *PodRebalancer) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	// idenitfying degraded nodes
+	// idenitfying degraded nodes, ignoring markers whose TTL has lapsed
+	nodesByName := make(map[string]*core.Node, len(nodeList.Items))
 	degradedNodes := map[string]*core.Node{}
+	degradationKeys := map[string]string{}
 	for i := range nodeList.Items {
 		node := &nodeList.Items[i]
-		if _, ok := node.Annotations[NodeDegradedAnnotation]; ok {
+		nodesByName[node.Name] = node
+		if degraded, key := r.DegradationClassifier.IsDegraded(node, time.Now()); degraded {
 			degradedNodes[node.Name] = node
-			log.V(1).Info("identified degraded node", "node", node.Name)
+			degradationKeys[node.Name] = key
+			log.V(1).Info("identified degraded node", "node", node.Name, "key", key)
 			r.Recorder.Eventf(node, core.EventTypeNormal, "NodeDegraded", "Node %s marked as degraded", node.Name)
 		}
 	}
 
-	if len(degradedNodes) == 0 {
-		log.V(1).Info("no degraded nodes found, skipping rebalancing")
-		return ctrl.Result{
-			RequeueAfter: r.RecheckInterval,
-		}, nil
+	// confirming that nodes have stayed degraded long enough before acting on them
+	now := time.Now()
+	observedNodes := make(map[string]bool, len(degradedNodes))
+	for nodeName := range degradedNodes {
+		observedNodes[nodeName] = true
 	}
+	r.degradationTracker.observe(observedNodes, now, cfg.recheckInterval)
+	r.syncDegradedNodeLabels(ctx, nodeList.Items, degradedNodes)
+	// uncordoning the nodes that recovered, or are no longer drained, before anything else
+	r.releaseDrains(ctx, cfg, nodeList.Items, degradedNodes)
 
 	// listing all pods in the cluster
 	podList := &core.PodList{}
@@ -90,140 +260,157 @@ func (r *PodRebalancer) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	// processing each degraded node
-	for nodeName, _ := range degradedNodes {
-		log.Info("processing degraded node", "node", nodeName)
+	// an operator pausing kube-balance halts all evictions and forced deletions, while the degraded nodes, the pods
+	// stuck terminating and the evictions that would be carried out are still reported
+	paused := r.evictionsPaused(cfg)
 
-		var podsOnDegradedNode []*core.Pod
-		for i := range podList.Items {
-			pod := &podList.Items[i]
-			if pod.Spec.NodeName == nodeName && (pod.Status.Phase == core.PodRunning || pod.Status.Phase == core.PodPending) {
-				podsOnDegradedNode = append(podsOnDegradedNode, pod)
-			}
-		}
+	// reporting the pods that don't finish terminating, which would otherwise hold back the rest of the rebalancing
+	r.handleStuckTerminating(ctx, podList.Items, degradedNodes, paused, now)
+
+	// pausing all evictions while the scheduler catches up with the Pending pods, so that a capacity crunch doesn't
+	// turn into an eviction storm
+	if r.pendingPodsPaused(cfg, podList.Items) {
+		return ctrl.Result{
+			RequeueAfter: cfg.recheckInterval,
+		}, nil
+	}
+
+	// carrying on with a plan that may be executed before planning anything new
+	plan, err := r.activePlan(ctx)
+	if err != nil {
+		log.Error(err, "failed to get the active rebalance plan")
+		return ctrl.Result{}, err
+	}
+	// cancelling the evictions from nodes that recovered mid-drain, so that none of them is carried out
+	if err := r.cancelRecoveredEvictions(ctx, plan, nodesByName, degradedNodes); err != nil {
+		log.Error(err, "failed to cancel the evictions from recovered nodes")
+		return ctrl.Result{}, err
+	}
+	if plan != nil && !paused && (cfg.mode == RebalanceModeApply || planApproved(plan, nodesByName)) {
+		return r.executePlan(ctx, cfg, plan, namespacedProfiles, workloadProfiles)
+	}
 
-		if len(podsOnDegradedNode) == 0 {
-			log.V(1).Info("no running pods found on degraded node", "node", nodeName)
+	requeueAfter := cfg.recheckInterval
+	for nodeName := range degradedNodes {
+		// urgent degradations leave no time to wait for confirmation
+		if degradation.NodeSeverity(degradedNodes[nodeName]) == degradation.SeverityUrgent {
 			continue
 		}
+		confirmed, remaining := r.degradationTracker.confirmed(nodeName, cfg.degradationConfirmationCycles, cfg.degradationConfirmationPeriod, now)
+		if confirmed {
+			continue
+		}
+		log.V(1).Info("node degradation not yet confirmed, deferring evictions", "node", nodeName, "remaining", remaining)
+		r.Recorder.Eventf(degradedNodes[nodeName], core.EventTypeNormal, "DegradationPending", "Node %s is degraded but awaiting confirmation before evictions start", nodeName)
+		delete(degradedNodes, nodeName)
+		if remaining > 0 && remaining < requeueAfter {
+			requeueAfter = remaining
+		}
+	}
+
+	if len(degradedNodes) == 0 && !cfg.rebalancesHealthyNodes() {
+		log.V(1).Info("no confirmed degraded nodes found, skipping rebalancing")
+		if _, err := r.submitPlan(ctx, plan, nil); err != nil {
+			log.Error(err, "failed to retire the pending rebalance plan")
+		}
+		return ctrl.Result{
+			RequeueAfter: requeueAfter,
+		}, nil
+	}
+
+	// detecting correlated, zone-wide degradation so that a whole zone's pods aren't dumped onto the remaining zones at once
+	failingZones := correlatedZoneFailures(summarizeZoneDegradation(nodeList.Items, degradedNodes), cfg.zoneDegradationThreshold)
+	r.reportZoneFailures(failingZones, degradedNodes, cfg.zoneDegradationAction)
+	pausedZones := map[string]bool{}
+	if cfg.zoneDegradationAction == ZoneDegradationActionPause {
+		for zone := range failingZones {
+			pausedZones[zone] = true
+		}
+		failingZones = map[string]zoneDegradation{}
+	}
+
+	if !anyEvacuatedNode(degradedNodes, pausedZones) && !cfg.rebalancesHealthyNodes() {
+		log.V(1).Info("all degraded nodes are in paused zones, skipping rebalancing")
+		if _, err := r.submitPlan(ctx, plan, nil); err != nil {
+			log.Error(err, "failed to retire the pending rebalance plan")
+		}
+		return ctrl.Result{
+			RequeueAfter: requeueAfter,
+		}, nil
+	}
+
+	// rolling back the surges of Deployments whose pods were evicted or recovered
+	r.settleSurges(ctx, podList.Items, nodesByName, now)
+
+	// forgetting the PDB blocks and reported skips of pods that no longer exist, and the pod cooldowns that have ended
+	uids := make(map[types.UID]bool, len(podList.Items))
+	for i := range podList.Items {
+		uids[podList.Items[i].UID] = true
+	}
+	r.pdbBlocks.retain(uids)
+	r.skipReports.retain(uids)
+	if r.podCooldowns != nil {
+		r.podCooldowns.prune(now)
+	}
+
+	// planning the evictions of the pods selected by the rebalancing strategies
+	state := &rebalanceState{
+		log:                log,
+		cfg:                cfg,
+		now:                now,
+		nodes:              nodeList.Items,
+		nodesByName:        nodesByName,
+		degradedNodes:      degradedNodes,
+		degradationKeys:    degradationKeys,
+		pods:               podList.Items,
+		namespacedProfiles: namespacedProfiles,
+		workloadProfiles:   workloadProfiles,
+		failingZones:       failingZones,
+		pausedZones:        pausedZones,
+		requeueAfter:       requeueAfter,
+		drains:             map[string]*nodeDrainProgress{},
+	}
+	plannedEvictions := r.planEvictions(ctx, state)
+	requeueAfter = state.requeueAfter
+	r.reportPDBBlocks(cfg, now)
+
+	// reporting the progress of the drains on their NodeDrains
+	for nodeName, progress := range state.drains {
+		if err := r.reportDrain(ctx, progress); err != nil {
+			log.Error(err, "failed to report node drain progress", "node", nodeName)
+		}
+	}
 
-		// sorting pods by QoS class and then their eviction priority
-		sort.Slice(podsOnDegradedNode, func(i int, j int) bool {
-			podA := podsOnDegradedNode[i]
-			podB := podsOnDegradedNode[j]
-
-			qosA := getPodQoSClass(podA)
-			qosB := getPodQoSClass(podB)
-			if qosA != qosB {
-				return qosClassToEvictionRank(qosA) > qosClassToEvictionRank(qosB)
-			}
-
-			profileA, okA := workloadProfiles[podA.Labels[WorkloadTypeLabel]]
-			profileB, okB := workloadProfiles[podB.Labels[WorkloadTypeLabel]]
-			if !okA && !okB {
-				return false
-			}
-			if !okA {
-				return true
-			}
-			if !okB {
-				return false
-			}
-
-			return profileA.Spec.EvictionPriority > profileB.Spec.EvictionPriority
-		})
-
-		evictedCount := 0
-		for _, pod := range podsOnDegradedNode {
-			if evictedCount >= r.MaxEvictionsPerNodePerCycle {
-				log.V(1).Info("reached max evictions for node in the current cycle", "node", nodeName, "maxEvictions", r.MaxEvictionsPerNodePerCycle)
-				break
-			}
-
-			// checking if the pod's owner is in a cooldown period
-			owner, err := r.getPodOwner(ctx, pod)
-			if err != nil {
-				log.Error(err, "failed to get pod owner, skipping cooldown check", "pod", pod.Name)
-			} else if owner != nil {
-				if cooldownUntilStr, ok := owner.GetAnnotations()[EvictionCooldownAnnotation]; ok {
-					if cooldownUntil, err := time.Parse(time.RFC3339, cooldownUntilStr); err == nil && time.Now().Before(cooldownUntil) {
-						log.V(1).Info("pod owner is in eviction cooldown period, skipping pod",
-							"pod", pod.Name, "namespace", pod.Namespace, "owner", owner.GetName(), "cooldownUntil", cooldownUntil.Format(time.RFC3339))
-						r.Recorder.Eventf(pod, core.EventTypeNormal, "EvictionSkipped", "Pod %s skipped due to owner %s being in cooldown until %s", pod.Name, owner.GetName(), cooldownUntil.Format(time.RFC3339))
-						continue
-					}
-				}
-			}
-
-			// checking Pod Disruption Budget before eviction
-			if err := r.checkPDB(ctx, pod); err != nil {
-				log.V(1).Info("pod cannot be evicted due to PDB violation or check error", "pod", pod.Name, "namespace", pod.Namespace, "error", err.Error())
-				r.Recorder.Eventf(pod, core.EventTypeWarning, "PDBViolation", "Pod %s cannot be evicted due to PDB violation: %v", pod.Name, err)
-				continue
-			}
-
-			workloadType := pod.Labels[WorkloadTypeLabel]
-			profile, profileFound := workloadProfiles[workloadType]
-
-			if profileFound {
-				log.Info("attempting to evist pod from degraded node",
-					"pod", pod.Name,
-					"namespace", pod.Namespace,
-					"node", nodeName,
-					"workloadType", workloadType,
-					"qosClass", getPodQoSClass(pod),
-					"evictionPriority", profile.Spec.EvictionPriority,
-				)
-
-				// eviction logic
-				err := r.Evictor.EvictPod(ctx, pod)
-				if err != nil {
-					if errors.IsTooManyRequests(err) {
-						log.Info("too many eviction requests, backing off", "pod", pod.Name)
-						r.Recorder.Eventf(pod, core.EventTypeWarning, "EvictionRateLimited", "Eviction of pod %s rate limited by K8s API server", pod.Name)
-						return ctrl.Result{
-							RequeueAfter: 10 * time.Second,
-						}, nil
-					}
-					log.Error(err, "failed to evict pod", "pod", pod.Name, "namespace", pod.Namespace)
-					r.Recorder.Eventf(pod, core.EventTypeWarning, "EvictionFailed", "Failed to evict pod %s: %v", pod.Name, err)
-					continue
-				}
-
-				log.Info("successfully evicted pod", "pod", pod.Name, "namespace", pod.Namespace)
-				r.Recorder.Eventf(pod, core.EventTypeNormal, "PodEvicted", "Pod %s evicted from degraded node %s", pod.Name, nodeName)
-				evictedCount++
-
-				// setting cooldown annotation on the pod's owner
-				if owner != nil {
-					cooldownUntil := time.Now().Add(r.RecheckInterval * 2) // cooldown for a minimum of 2 recheck intervals
-					patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
-					annotations := owner.GetAnnotations()
-					if annotations == nil {
-						annotations = make(map[string]string)
-					}
-					annotations[EvictionCooldownAnnotation] = cooldownUntil.Format(time.RFC3339)
-					owner.SetAnnotations(annotations)
-					if err := r.Patch(ctx, owner, patch); err != nil {
-						log.Error(err, "failed to add eviction cooldown annotation to the pod owner", "owner", owner.GetName(), "namespace", owner.GetNamespace())
-						r.Recorder.Eventf(owner, core.EventTypeWarning, "CooldownAnnotationFailed", "Failed to add cooldown annotation to owner %s: %v", owner.GetName(), err)
-					} else {
-						log.V(1).Info("added eviction cooldown annotation to pod owner", "owner", owner.GetName(), "cooldownUntil", cooldownUntil.Format(time.RFC3339))
-						r.Recorder.Eventf(owner, core.EventTypeNormal, "CooldownSet", "Cooldown set on owner %s until %s", owner.GetName(), cooldownUntil.Format(time.RFC3339))
-					}
-				}
-
-				return ctrl.Result{
-					RequeueAfter: 5 * time.Second,
-				}, nil
-			} else {
-				log.V(1).Info("pod ha no defined workload profile, skipping eviction consideration",
-					"pod", pod.Name, "namespace", pod.Namespace, "workloadType", workloadType)
-			}
+	// writing the plan before acting on it
+	previous := plan
+	plan, err = r.submitPlan(ctx, plan, plannedEvictions)
+	if err != nil {
+		log.Error(err, "failed to write rebalance plan")
+		return ctrl.Result{}, err
+	}
+	if plan == nil {
+		return ctrl.Result{
+			RequeueAfter: requeueAfter,
+		}, nil
+	}
+	if paused {
+		log.Info("evictions paused, leaving rebalance plan unexecuted", "plan", plan.Name, "evictions", len(plan.Spec.Evictions))
+		if previous == nil || previous.Name != plan.Name {
+			r.Recorder.Eventf(plan, core.EventTypeNormal, "PlanPaused", "Rebalance plan %s is left unexecuted while evictions are paused", plan.Name)
 		}
+		return ctrl.Result{
+			RequeueAfter: requeueAfter,
+		}, nil
+	}
+	if cfg.mode == RebalanceModeApply {
+		return r.executePlan(ctx, cfg, plan, namespacedProfiles, workloadProfiles)
 	}
 
+	log.Info("rebalance plan awaiting approval", "plan", plan.Name, "evictions", len(plan.Spec.Evictions), "unapprovedNodes", unapprovedPlanNodes(plan, nodesByName))
+	if previous == nil || previous.Name != plan.Name {
+		r.reportAwaitingApproval(plan, nodesByName)
+	}
 	return ctrl.Result{
-		RequeueAfter: r.RecheckInterval,
+		RequeueAfter: requeueAfter,
 	}, nil
 }
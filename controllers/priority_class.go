@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	scheduling "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="scheduling.k8s.io",resources=priorityclasses,verbs=get;list;watch
+
+// returns the scheduling priority a pod was admitted with, 0 when it has none
+func podSchedulingPriority(pod *core.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// returns the cost the pod's owner assigned to losing the pod through the pod-deletion-cost annotation, 0 when it has
+// none or an invalid one, as the ReplicaSet controller treats it
+func podDeletionCost(pod *core.Pod) int32 {
+	cost, err := strconv.ParseInt(pod.Annotations[core.PodDeletionCost], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(cost)
+}
+
+// reports whether a pod runs with another PriorityClass than the one its profile maps it to
+func priorityClassMismatched(pod *core.Pod, profile *api_v1.WorkloadProfile) bool {
+	return profile.Spec.PriorityClass != nil && pod.Spec.PriorityClassName != profile.Spec.PriorityClass.Name
+}
+
+// tracks the pods of each profile running with another PriorityClass than the profile's, reconciling their owners where the profile asks for it
+type priorityClassAudit struct {
+	client.Client
+	// pods running with another PriorityClass, by profile key
+	mismatched map[string]int32
+	// whether each referenced PriorityClass exists, by name
+	exists map[string]bool
+	// owners already reconciled during the audit
+	reconciled map[types.UID]bool
+}
+
+// creates an empty audit for a single pass over the pods
+func newPriorityClassAudit(cli client.Client) *priorityClassAudit {
+	return &priorityClassAudit{
+		Client:     cli,
+		mismatched: map[string]int32{},
+		exists:     map[string]bool{},
+		reconciled: map[types.UID]bool{},
+	}
+}
+
+// looks up a PriorityClass once per audit
+func (a *priorityClassAudit) classExists(ctx context.Context, name string) (bool, error) {
+	if exists, ok := a.exists[name]; ok {
+		return exists, nil
+	}
+	if err := a.Get(ctx, client.ObjectKey{Name: name}, &scheduling.PriorityClass{}); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get priority class %s: %w", name, err)
+		}
+		a.exists[name] = false
+		return false, nil
+	}
+	a.exists[name] = true
+	return true, nil
+}
+
+// counts a pod governed by a profile mapping a PriorityClass, setting the class on the pod's owner when it differs and the profile reconciles it
+func (a *priorityClassAudit) observe(ctx context.Context, pod *core.Pod, profile *api_v1.WorkloadProfile, key string) error {
+	if !priorityClassMismatched(pod, profile) {
+		return nil
+	}
+	a.mismatched[key]++
+
+	mapping := profile.Spec.PriorityClass
+	if !mapping.Reconcile {
+		return nil
+	}
+	// setting a class that does not exist would leave the owner unable to create pods
+	if exists, err := a.classExists(ctx, mapping.Name); err != nil || !exists {
+		return err
+	}
+
+	owner, err := getPodOwner(ctx, a, pod)
+	if err != nil || owner == nil || a.reconciled[owner.GetUID()] {
+		return err
+	}
+	a.reconciled[owner.GetUID()] = true
+	return a.setOwnerPriorityClass(ctx, owner, mapping.Name)
+}
+
+// sets a PriorityClass on the pod template of an owner, dropping the resolved priority so that admission resolves the new class
+func (a *priorityClassAudit) setOwnerPriorityClass(ctx context.Context, owner client.Object, className string) error {
+	var template *core.PodTemplateSpec
+	switch o := owner.(type) {
+	case *apps.Deployment:
+		template = &o.Spec.Template
+	case *apps.StatefulSet:
+		template = &o.Spec.Template
+	case *apps.ReplicaSet:
+		template = &o.Spec.Template
+	default:
+		return nil
+	}
+	if template.Spec.PriorityClassName == className {
+		return nil
+	}
+
+	patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
+	template.Spec.PriorityClassName = className
+	template.Spec.Priority = nil
+	template.Spec.PreemptionPolicy = nil
+	if err := a.Patch(ctx, owner, patch); err != nil {
+		return fmt.Errorf("failed to set priority class %s on %s: %w", className, owner.GetName(), err)
+	}
+	return nil
+}
+
+// builds the PriorityClassConsistent condition of a profile, nil for profiles mapping no PriorityClass
+func (a *priorityClassAudit) condition(ctx context.Context, profile *api_v1.WorkloadProfile, key string, generation int64) (*meta.Condition, error) {
+	mapping := profile.Spec.PriorityClass
+	if mapping == nil {
+		return nil, nil
+	}
+
+	condition := &meta.Condition{
+		Type:               api_v1.WorkloadProfileConditionPriorityClassConsistent,
+		ObservedGeneration: generation,
+	}
+	exists, err := a.classExists(ctx, mapping.Name)
+	if err != nil {
+		return nil, err
+	}
+	mismatched := a.mismatched[key]
+	switch {
+	case !exists:
+		condition.Status = meta.ConditionFalse
+		condition.Reason = "PriorityClassNotFound"
+		condition.Message = fmt.Sprintf("priority class %s does not exist", mapping.Name)
+	case mismatched > 0:
+		condition.Status = meta.ConditionFalse
+		condition.Reason = "PriorityClassMismatch"
+		condition.Message = fmt.Sprintf("%d pods run with another priority class than %s", mismatched, mapping.Name)
+	default:
+		condition.Status = meta.ConditionTrue
+		condition.Reason = "PriorityClassMatched"
+		condition.Message = fmt.Sprintf("governed pods run with priority class %s", mapping.Name)
+	}
+	return condition, nil
+}
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	api_meta "k8s.io/apimachinery/pkg/api/meta"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+)
+
+// periodically publishes the observed usage and eviction statistics of each workload profile on its status
+type ProfileStatusUpdater struct {
+	client.Client
+	Log             logr.Logger
+	ProfilerWatcher *profiles.WorkloadProfileWatcher
+	Interval        time.Duration
+	// compares the governed pods' requests against their profile's recommendation, publishing the drift on the profile's status
+	ReportResourceDrift bool
+	// relative deviation from the recommendation beyond which requests count as drifting
+	ResourceDriftTolerance float64
+}
+
+// +kubebuilder:rbac:groups="kube-balance.io",resources=workloadprofiles/status;namespacedworkloadprofiles/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="kube-balance.io",resources=evictionrecords,verbs=list
+
+// implements the manager.Runnable interface to refresh profile statuses until the context is cancelled
+func (u *ProfileStatusUpdater) Start(ctx context.Context) error {
+	ticker := time.NewTicker(u.Interval)
+	defer ticker.Stop()
+
+	u.Log.Info("starting workload profile status updater", "interval", u.Interval)
+	for {
+		if err := u.updateStatuses(ctx); err != nil {
+			u.Log.Error(err, "failed to update workload profile statuses")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// counts the pods governed by each profile, reconciling their priority classes and measuring their resource drift, and publishes the result on every cluster and namespaced profile
+func (u *ProfileStatusUpdater) updateStatuses(ctx context.Context) error {
+	workloadProfiles := u.ProfilerWatcher.GetProfiles()
+	namespacedProfiles := u.ProfilerWatcher.GetNamespacedProfiles()
+	inheritanceErrors := u.ProfilerWatcher.GetInheritanceErrors()
+
+	podList := &core.PodList{}
+	if err := u.List(ctx, podList); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	nodeList := &core.NodeList{}
+	if err := u.List(ctx, nodeList); err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	nodesByName := make(map[string]*core.Node, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodesByName[nodeList.Items[i].Name] = &nodeList.Items[i]
+	}
+
+	matchedPods := map[string]int32{}
+	priorityClasses := newPriorityClassAudit(u.Client)
+	var drift *resourceDriftReport
+	if u.ReportResourceDrift {
+		drift = newResourceDriftReport(u.ResourceDriftTolerance)
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		if profile, ok := profiles.MatchPodScoped(pod, nodesByName[pod.Spec.NodeName], namespacedProfiles, workloadProfiles); ok {
+			matchedPods[profiles.Key(profile)]++
+			if err := priorityClasses.observe(ctx, pod, &profile, profiles.Key(profile)); err != nil {
+				u.Log.Error(err, "failed to reconcile priority class of pod owner", "pod", pod.Name, "namespace", pod.Namespace, "profile", profile.Name)
+			}
+			if drift != nil {
+				drift.observe(pod, &profile)
+			}
+		}
+	}
+	if drift != nil {
+		drift.publishMetrics()
+	}
+
+	now := time.Now()
+	evictions, err := u.recentEvictions(ctx, namespacedProfiles, now)
+	if err != nil {
+		return err
+	}
+	for name, profile := range workloadProfiles {
+		wp := &api_v1.WorkloadProfile{}
+		if err := u.Get(ctx, client.ObjectKey{Name: name}, wp); err != nil {
+			if !errors.IsNotFound(err) {
+				u.Log.Error(err, "failed to get workload profile", "name", name)
+			}
+			continue
+		}
+		if err := u.publish(ctx, wp, &wp.Status, profile, matchedPods[profiles.Key(profile)], evictions[profiles.Key(profile)], inheritanceErrors[profiles.Key(profile)], priorityClasses, drift); err != nil {
+			u.Log.Error(err, "failed to update workload profile status", "name", name)
+		}
+	}
+
+	for namespace, nsProfiles := range namespacedProfiles {
+		for name, profile := range nsProfiles {
+			nwp := &api_v1.NamespacedWorkloadProfile{}
+			if err := u.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, nwp); err != nil {
+				if !errors.IsNotFound(err) {
+					u.Log.Error(err, "failed to get namespaced workload profile", "namespace", namespace, "name", name)
+				}
+				continue
+			}
+			if err := u.publish(ctx, nwp, &nwp.Status, profile, matchedPods[profiles.Key(profile)], evictions[profiles.Key(profile)], inheritanceErrors[profiles.Key(profile)], priorityClasses, drift); err != nil {
+				u.Log.Error(err, "failed to update namespaced workload profile status", "namespace", namespace, "name", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// patches the status of a profile object, whose status field is passed alongside it, when the observed state changed
+func (u *ProfileStatusUpdater) publish(ctx context.Context, obj client.Object, status *api_v1.WorkloadProfileStatus, profile api_v1.WorkloadProfile, matched int32, evictions profileEvictions, inheritanceErr error, priorityClasses *priorityClassAudit, drift *resourceDriftReport) error {
+	desired := status.DeepCopy()
+	desired.ObservedGeneration = obj.GetGeneration()
+	desired.MatchedPods = matched
+
+	desired.EvictionsLast24h = evictions.count
+	if !evictions.last.IsZero() {
+		desired.LastEvictionTime = &meta.Time{Time: evictions.last}
+	}
+
+	desired.ResourceDrift = nil
+	if drift != nil {
+		desired.ResourceDrift = drift.status(&profile)
+	}
+
+	if matched > 0 {
+		api_meta.SetStatusCondition(&desired.Conditions, meta.Condition{
+			Type:               api_v1.WorkloadProfileConditionActive,
+			Status:             meta.ConditionTrue,
+			Reason:             "PodsMatched",
+			Message:            fmt.Sprintf("%d pods are governed by this profile", matched),
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	} else {
+		api_meta.SetStatusCondition(&desired.Conditions, meta.Condition{
+			Type:               api_v1.WorkloadProfileConditionActive,
+			Status:             meta.ConditionFalse,
+			Reason:             "NoMatchingPods",
+			Message:            "no pods are governed by this profile",
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	}
+
+	if err := validateSelectors(&profile.Spec); err != nil {
+		api_meta.SetStatusCondition(&desired.Conditions, meta.Condition{
+			Type:               api_v1.WorkloadProfileConditionSelectorValid,
+			Status:             meta.ConditionFalse,
+			Reason:             "InvalidSelector",
+			Message:            err.Error(),
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	} else {
+		api_meta.SetStatusCondition(&desired.Conditions, meta.Condition{
+			Type:               api_v1.WorkloadProfileConditionSelectorValid,
+			Status:             meta.ConditionTrue,
+			Reason:             "SelectorParsed",
+			Message:            "the pod and node selectors and the match expression are valid",
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	}
+
+	switch {
+	case profile.Spec.BaseProfile == "":
+		api_meta.RemoveStatusCondition(&desired.Conditions, api_v1.WorkloadProfileConditionBaseProfileResolved)
+	case inheritanceErr != nil:
+		api_meta.SetStatusCondition(&desired.Conditions, meta.Condition{
+			Type:               api_v1.WorkloadProfileConditionBaseProfileResolved,
+			Status:             meta.ConditionFalse,
+			Reason:             "BaseProfileUnresolved",
+			Message:            inheritanceErr.Error(),
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	default:
+		api_meta.SetStatusCondition(&desired.Conditions, meta.Condition{
+			Type:               api_v1.WorkloadProfileConditionBaseProfileResolved,
+			Status:             meta.ConditionTrue,
+			Reason:             "BaseProfileResolved",
+			Message:            fmt.Sprintf("inherits unset fields from %s", profile.Spec.BaseProfile),
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	}
+
+	priorityClass, err := priorityClasses.condition(ctx, &profile, profiles.Key(profile), obj.GetGeneration())
+	if err != nil {
+		return err
+	}
+	if priorityClass == nil {
+		api_meta.RemoveStatusCondition(&desired.Conditions, api_v1.WorkloadProfileConditionPriorityClassConsistent)
+	} else {
+		api_meta.SetStatusCondition(&desired.Conditions, *priorityClass)
+	}
+
+	if equality.Semantic.DeepEqual(desired, status) {
+		return nil
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	*status = *desired
+	if err := u.Status().Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("failed to patch status: %w", err)
+	}
+	return nil
+}
+
+// evictions performed under a profile within the history window
+type profileEvictions struct {
+	count int32
+	last  time.Time
+}
+
+// counts the evictions performed under each profile within the history window from the EvictionRecords, so that the
+// counts survive controller restarts; records collected before the window ends are no longer counted
+//
+// a record names the profile that governed the pod, which resolves to the pod namespace's profile of that name before
+// the cluster-scoped one, just as profiles are matched
+func (u *ProfileStatusUpdater) recentEvictions(ctx context.Context, namespacedProfiles map[string]map[string]api_v1.WorkloadProfile, now time.Time) (map[string]profileEvictions, error) {
+	recordList := &api_v1alpha1.EvictionRecordList{}
+	if err := u.List(ctx, recordList); err != nil {
+		return nil, fmt.Errorf("failed to list eviction records: %w", err)
+	}
+
+	cutoff := now.Add(-profiles.EvictionHistoryWindow)
+	evictions := map[string]profileEvictions{}
+	for i := range recordList.Items {
+		record := &recordList.Items[i]
+		if record.Spec.Profile == "" || record.Spec.Time.Time.Before(cutoff) {
+			continue
+		}
+		if record.Spec.Outcome != api_v1alpha1.EvictionOutcomeEvicted && record.Spec.Outcome != api_v1alpha1.EvictionOutcomeForceDeleted {
+			continue
+		}
+
+		key := "/" + record.Spec.Profile
+		if _, ok := namespacedProfiles[record.Namespace][record.Spec.Profile]; ok {
+			key = record.Namespace + key
+		}
+		stats := evictions[key]
+		stats.count++
+		if record.Spec.Time.Time.After(stats.last) {
+			stats.last = record.Spec.Time.Time
+		}
+		evictions[key] = stats
+	}
+	return evictions, nil
+}
+
+// reports the first error found parsing a profile's pod and node selectors and compiling its match expression
+func validateSelectors(spec *api_v1.WorkloadProfileSpec) error {
+	if _, err := meta.LabelSelectorAsSelector(spec.PodSelector); err != nil {
+		return fmt.Errorf("invalid pod selector: %w", err)
+	}
+	if _, err := meta.LabelSelectorAsSelector(spec.NodeSelector); err != nil {
+		return fmt.Errorf("invalid node selector: %w", err)
+	}
+	if err := profiles.ValidateMatchExpression(spec.MatchExpression); err != nil {
+		return err
+	}
+	return nil
+}
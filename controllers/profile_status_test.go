@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1beta1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// returns an EvictionRecord of an eviction under the given profile, attempted the given time ago
+func testRecord(name string, namespace string, profile string, outcome api_v1alpha1.EvictionOutcome, ago time.Duration, now time.Time) client.Object {
+	return &api_v1alpha1.EvictionRecord{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: api_v1alpha1.EvictionRecordSpec{
+			Pod:     name,
+			Profile: profile,
+			Time:    meta.NewTime(now.Add(-ago)),
+			Outcome: outcome,
+		},
+	}
+}
+
+func TestRecentEvictionsCountsRecordsWithinTheWindow(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	records := []client.Object{
+		testRecord("evicted", "team-a", "web", api_v1alpha1.EvictionOutcomeEvicted, time.Hour, now),
+		testRecord("force-deleted", "team-a", "web", api_v1alpha1.EvictionOutcomeForceDeleted, 2*time.Hour, now),
+		testRecord("failed", "team-a", "web", api_v1alpha1.EvictionOutcomeFailed, time.Minute, now),
+		testRecord("expired", "team-a", "web", api_v1alpha1.EvictionOutcomeEvicted, 25*time.Hour, now),
+		// team-b has no namespaced profile named web, so the record counts toward the cluster-scoped one
+		testRecord("cluster", "team-b", "web", api_v1alpha1.EvictionOutcomeEvicted, 3*time.Hour, now),
+		testRecord("unprofiled", "team-b", "", api_v1alpha1.EvictionOutcomeEvicted, time.Hour, now),
+	}
+	u := &ProfileStatusUpdater{
+		Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(records...).Build(),
+		Log:    logr.Discard(),
+	}
+	namespaced := map[string]map[string]api_v1beta1.WorkloadProfile{"team-a": {"web": {}}}
+
+	evictions, err := u.recentEvictions(context.Background(), namespaced, now)
+	if err != nil {
+		t.Fatalf("recentEvictions() error = %v", err)
+	}
+	want := map[string]profileEvictions{
+		"team-a/web": {count: 2, last: now.Add(-time.Hour)},
+		"/web":       {count: 1, last: now.Add(-3 * time.Hour)},
+	}
+	if len(evictions) != len(want) {
+		t.Fatalf("recentEvictions() = %v, want %v", evictions, want)
+	}
+	for key, w := range want {
+		if got := evictions[key]; got.count != w.count || !got.last.Equal(w.last) {
+			t.Errorf("evictions[%q] = %+v, want %+v", key, got, w)
+		}
+	}
+}
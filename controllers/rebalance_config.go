@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/internal/maintenance"
+)
+
+// runtime knobs in effect for a single reconcile cycle, combining command-line flags with the RebalancePolicy
+type rebalanceConfig struct {
+	recheckInterval                   time.Duration
+	maxEvictionsPerNodePerCycle       int
+	urgentMaxEvictionsPerNodePerCycle int
+	degradationConfirmationCycles     int
+	degradationConfirmationPeriod     time.Duration
+	zoneDegradationThreshold          float64
+	zoneDegradationAction             string
+	zoneThrottledMaxEvictions         int
+	includedNamespaces                map[string]bool
+	excludedNamespaces                map[string]bool
+	namespaceOptIn                    bool
+	namespaceOptIns                   map[string]bool
+	protectedPodSelectors             []labels.Selector
+	maintenanceWindows                []maintenance.Window
+	mode                              string
+	paused                            bool
+	drainMode                         string
+	cordonDegradedNodes               bool
+	lowNodeUtilization                *api_v1.LowNodeUtilization
+	highNodeUtilization               *api_v1.HighNodeUtilization
+	topologySpread                    *api_v1.TopologySpread
+	removeDuplicates                  *api_v1.RemoveDuplicates
+	nodeConstraintViolations          *api_v1.NodeConstraintViolations
+	costAware                         *api_v1.CostAware
+	postRecovery                      *api_v1.PostRecovery
+	restartCountWeight                int
+	evictionOrder                     []string
+	maxEvictionsPerMinute             int
+	barePodPolicy                     string
+	jobPodPolicy                      string
+	jobCompletionThresholdPercent     int
+	maxOwnerDisruptionPercent         int
+	pendingPodsThreshold              int
+	pendingPodsScope                  string
+}
+
+// resolves the configuration for the current cycle; fields set on the RebalancePolicy take precedence over flags
+func (r *PodRebalancer) currentConfig() rebalanceConfig {
+	cfg := rebalanceConfig{
+		recheckInterval:                   r.RecheckInterval,
+		maxEvictionsPerNodePerCycle:       r.MaxEvictionsPerNodePerCycle,
+		urgentMaxEvictionsPerNodePerCycle: r.UrgentMaxEvictionsPerNodePerCycle,
+		degradationConfirmationCycles:     r.DegradationConfirmationCycles,
+		degradationConfirmationPeriod:     r.DegradationConfirmationPeriod,
+		zoneDegradationThreshold:          r.ZoneDegradationThreshold,
+		zoneDegradationAction:             r.ZoneDegradationAction,
+		zoneThrottledMaxEvictions:         r.ZoneThrottledMaxEvictions,
+		mode:                              r.RebalanceMode,
+		paused:                            r.Paused,
+		drainMode:                         r.DrainMode,
+		cordonDegradedNodes:               r.CordonDegradedNodes,
+		restartCountWeight:                r.RestartCountWeight,
+		maxEvictionsPerMinute:             r.MaxEvictionsPerMinute,
+		namespaceOptIn:                    r.NamespaceOptIn,
+		barePodPolicy:                     r.BarePodPolicy,
+		jobPodPolicy:                      r.JobPodPolicy,
+		jobCompletionThresholdPercent:     r.JobCompletionThresholdPercent,
+		maxOwnerDisruptionPercent:         r.MaxOwnerDisruptionPercent,
+		pendingPodsThreshold:              r.PendingPodsThreshold,
+		pendingPodsScope:                  r.PendingPodsScope,
+	}
+	cfg.includedNamespaces = namespaceSet(r.IncludedNamespaces)
+	cfg.excludedNamespaces = namespaceSet(r.ExcludedNamespaces)
+	if cfg.mode == "" {
+		cfg.mode = RebalanceModeApply
+	}
+	if cfg.drainMode == "" {
+		cfg.drainMode = DrainModeOff
+	}
+	if cfg.barePodPolicy == "" {
+		cfg.barePodPolicy = UnmanagedPodPolicyEvictWithWarning
+	}
+	if cfg.jobPodPolicy == "" {
+		cfg.jobPodPolicy = UnmanagedPodPolicySkip
+	}
+	if cfg.pendingPodsScope == "" {
+		cfg.pendingPodsScope = PendingPodsScopeCluster
+	}
+
+	if r.PolicyWatcher == nil {
+		return cfg
+	}
+	rp := r.PolicyWatcher.GetPolicy()
+	if rp == nil {
+		return cfg
+	}
+	r.applyPolicy(&cfg, &rp.Spec)
+	return cfg
+}
+
+// records the policy generation the controller is running with in the policy's status
+func (r *PodRebalancer) acknowledgePolicy(ctx context.Context) {
+	if r.PolicyWatcher == nil {
+		return
+	}
+	rp := r.PolicyWatcher.GetPolicy()
+	if rp == nil || rp.Status.ObservedGeneration == rp.Generation {
+		return
+	}
+
+	patch := client.MergeFrom(rp.DeepCopy())
+	rp.Status.ObservedGeneration = rp.Generation
+	if err := r.Status().Patch(ctx, rp, patch); err != nil {
+		r.Log.Error(err, "failed to update rebalance policy status", "policy", rp.Name)
+	}
+}
+
+// overrides the configuration with the fields set on a RebalancePolicy
+func (r *PodRebalancer) applyPolicy(cfg *rebalanceConfig, spec *api_v1.RebalancePolicySpec) {
+	if spec.RecheckInterval != nil && spec.RecheckInterval.Duration > 0 {
+		cfg.recheckInterval = spec.RecheckInterval.Duration
+	}
+	if spec.MaxEvictionsPerNodePerCycle != nil {
+		cfg.maxEvictionsPerNodePerCycle = *spec.MaxEvictionsPerNodePerCycle
+	}
+	if spec.UrgentMaxEvictionsPerNodePerCycle != nil {
+		cfg.urgentMaxEvictionsPerNodePerCycle = *spec.UrgentMaxEvictionsPerNodePerCycle
+	}
+	if spec.DegradationConfirmationCycles != nil {
+		cfg.degradationConfirmationCycles = *spec.DegradationConfirmationCycles
+	}
+	if spec.DegradationConfirmationPeriod != nil {
+		cfg.degradationConfirmationPeriod = spec.DegradationConfirmationPeriod.Duration
+	}
+	if spec.ZoneDegradationThresholdPercent != nil {
+		cfg.zoneDegradationThreshold = float64(*spec.ZoneDegradationThresholdPercent) / 100
+	}
+	if spec.ZoneDegradationAction != "" {
+		cfg.zoneDegradationAction = spec.ZoneDegradationAction
+	}
+	if spec.ZoneThrottledMaxEvictions != nil {
+		cfg.zoneThrottledMaxEvictions = *spec.ZoneThrottledMaxEvictions
+	}
+	if spec.Mode != "" {
+		cfg.mode = spec.Mode
+	}
+	// a policy pauses evictions on top of --paused, but can't resume those paused by it
+	if spec.Paused != nil && *spec.Paused {
+		cfg.paused = true
+	}
+	if spec.DrainMode != "" {
+		cfg.drainMode = spec.DrainMode
+	}
+	if spec.CordonDegradedNodes != nil {
+		cfg.cordonDegradedNodes = *spec.CordonDegradedNodes
+	}
+	if spec.MaxOwnerDisruptionPercent != nil {
+		cfg.maxOwnerDisruptionPercent = *spec.MaxOwnerDisruptionPercent
+	}
+	if spec.PendingPodsThreshold != nil {
+		cfg.pendingPodsThreshold = *spec.PendingPodsThreshold
+	}
+	if spec.PendingPodsScope != "" {
+		cfg.pendingPodsScope = spec.PendingPodsScope
+	}
+	if spec.BarePods != "" {
+		cfg.barePodPolicy = spec.BarePods
+	}
+	if spec.JobPods != "" {
+		cfg.jobPodPolicy = spec.JobPods
+	}
+	if spec.JobCompletionThresholdPercent != nil {
+		cfg.jobCompletionThresholdPercent = *spec.JobCompletionThresholdPercent
+	}
+	if spec.MaxEvictionsPerMinute != nil {
+		cfg.maxEvictionsPerMinute = *spec.MaxEvictionsPerMinute
+	}
+	if spec.RestartCountWeight != nil {
+		cfg.restartCountWeight = *spec.RestartCountWeight
+	}
+	if len(spec.EvictionOrder) > 0 {
+		cfg.evictionOrder = make([]string, 0, len(spec.EvictionOrder))
+		for _, criterion := range spec.EvictionOrder {
+			cfg.evictionOrder = append(cfg.evictionOrder, string(criterion))
+		}
+	}
+	cfg.lowNodeUtilization = spec.LowNodeUtilization
+	cfg.highNodeUtilization = spec.HighNodeUtilization
+	cfg.topologySpread = spec.TopologySpread
+	cfg.removeDuplicates = spec.RemoveDuplicates
+	cfg.nodeConstraintViolations = spec.NodeConstraintViolations
+	cfg.costAware = spec.CostAware
+	cfg.postRecovery = spec.PostRecovery
+
+	if spec.Namespaces != nil {
+		if len(spec.Namespaces.Include) > 0 {
+			cfg.includedNamespaces = namespaceSet(spec.Namespaces.Include)
+		}
+		if spec.Namespaces.OptIn != nil {
+			cfg.namespaceOptIn = *spec.Namespaces.OptIn
+		}
+		if len(spec.Namespaces.Exclude) > 0 {
+			cfg.excludedNamespaces = namespaceSet(spec.Namespaces.Exclude)
+		}
+	}
+
+	for i := range spec.ProtectedPodSelectors {
+		selector, err := meta.LabelSelectorAsSelector(&spec.ProtectedPodSelectors[i])
+		if err != nil {
+			r.Log.Error(err, "invalid protected pod selector in rebalance policy, ignoring it")
+			continue
+		}
+		cfg.protectedPodSelectors = append(cfg.protectedPodSelectors, selector)
+	}
+
+	windows, err := parsePolicyWindows(spec.MaintenanceWindows)
+	if err != nil {
+		r.Log.Error(err, "invalid maintenance windows in rebalance policy, ignoring them")
+	}
+	cfg.maintenanceWindows = windows
+}
+
+// reports whether pods are moved between nodes that aren't degraded, to balance or pack load, to spread them again, to
+// honour their scheduling constraints or to run them on cheaper nodes, in which case rebalancing goes on while no node
+// is degraded
+func (cfg *rebalanceConfig) rebalancesHealthyNodes() bool {
+	return (cfg.lowNodeUtilization != nil && cfg.lowNodeUtilization.Enabled) ||
+		(cfg.highNodeUtilization != nil && cfg.highNodeUtilization.Enabled) ||
+		(cfg.topologySpread != nil && cfg.topologySpread.Enabled) ||
+		(cfg.removeDuplicates != nil && cfg.removeDuplicates.Enabled) ||
+		(cfg.nodeConstraintViolations != nil && (cfg.nodeConstraintViolations.NodeAffinity || cfg.nodeConstraintViolations.NodeTaints)) ||
+		(cfg.costAware != nil && cfg.costAware.Enabled) ||
+		(cfg.postRecovery != nil && cfg.postRecovery.Enabled)
+}
+
+// returns the set of the given namespaces, nil when there are none
+func namespaceSet(namespaces []string) map[string]bool {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		set[ns] = true
+	}
+	return set
+}
+
+// reports whether pods in the namespace are considered for rebalancing; kube-system is only considered when explicitly
+// included, a namespace opting out through its annotation is never considered, and only namespaces opting in are when
+// rebalancing is opt-in
+func (cfg *rebalanceConfig) namespaceAllowed(namespace string) bool {
+	if cfg.excludedNamespaces[namespace] {
+		return false
+	}
+	if namespace == meta.NamespaceSystem && !cfg.includedNamespaces[namespace] {
+		return false
+	}
+	if enabled, annotated := cfg.namespaceOptIns[namespace]; (annotated || cfg.namespaceOptIn) && !enabled {
+		return false
+	}
+	return len(cfg.includedNamespaces) == 0 || cfg.includedNamespaces[namespace]
+}
+
+// reports whether a pod matches one of the protected pod selectors
+func (cfg *rebalanceConfig) podProtected(pod *core.Pod) bool {
+	for _, selector := range cfg.protectedPodSelectors {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return true
+		}
+	}
+	return false
+}
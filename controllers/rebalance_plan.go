@@ -0,0 +1,629 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1beta1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/hooks"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+	"github.com/lokeshllkumar/kube-balance/pkg/eviction"
+)
+
+// modes deciding when a RebalancePlan is executed
+const (
+	// execute each plan as soon as it is written
+	RebalanceModeApply = "apply"
+	// write plans and wait for them to be approved
+	RebalanceModePlan = "plan"
+)
+
+// number of completed or superseded plans kept for review; older ones are deleted
+const rebalancePlanHistoryLimit = 10
+
+// prefix of the generated RebalancePlan names
+const rebalancePlanNamePrefix = "rebalance-"
+
+// returns the most recent plan that has neither completed nor been superseded, or nil when there is none
+func (r *PodRebalancer) activePlan(ctx context.Context) (*api_v1alpha1.RebalancePlan, error) {
+	planList := &api_v1alpha1.RebalancePlanList{}
+	if err := r.List(ctx, planList); err != nil {
+		return nil, fmt.Errorf("failed to list rebalance plans: %w", err)
+	}
+
+	var active []*api_v1alpha1.RebalancePlan
+	for i := range planList.Items {
+		if !planFinished(&planList.Items[i]) {
+			active = append(active, &planList.Items[i])
+		}
+	}
+	if len(active) == 0 {
+		return nil, nil
+	}
+
+	// a plan written while the cache still lagged behind its predecessor leaves several active ones; only the newest is kept
+	sort.Slice(active, func(i int, j int) bool {
+		return active[j].CreationTimestamp.Before(&active[i].CreationTimestamp)
+	})
+	for _, plan := range active[1:] {
+		patch := client.MergeFrom(plan.DeepCopy())
+		plan.Status.Phase = api_v1alpha1.RebalancePlanSuperseded
+		if err := r.Status().Patch(ctx, plan, patch); err != nil {
+			return nil, fmt.Errorf("failed to supersede rebalance plan %s: %w", plan.Name, err)
+		}
+	}
+	return active[0], nil
+}
+
+// reports whether a plan has reached a terminal phase
+func planFinished(plan *api_v1alpha1.RebalancePlan) bool {
+	return plan.Status.Phase == api_v1alpha1.RebalancePlanCompleted || plan.Status.Phase == api_v1alpha1.RebalancePlanSuperseded
+}
+
+// reports whether two plans list the same evictions, regardless of their order
+func samePlannedEvictions(a []api_v1alpha1.PlannedEviction, b []api_v1alpha1.PlannedEviction) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	nodes := make(map[types.UID]string, len(a))
+	for _, planned := range a {
+		nodes[planned.UID] = planned.Node
+	}
+	for _, planned := range b {
+		if node, ok := nodes[planned.UID]; !ok || node != planned.Node {
+			return false
+		}
+	}
+	return true
+}
+
+// writes a plan for the given evictions, superseding the active plan unless it already lists them; returns nil when nothing is planned
+func (r *PodRebalancer) submitPlan(ctx context.Context, active *api_v1alpha1.RebalancePlan, evictions []api_v1alpha1.PlannedEviction) (*api_v1alpha1.RebalancePlan, error) {
+	if active != nil {
+		if len(evictions) > 0 && samePlannedEvictions(active.Spec.Evictions, evictions) {
+			return active, nil
+		}
+
+		patch := client.MergeFrom(active.DeepCopy())
+		active.Status.Phase = api_v1alpha1.RebalancePlanSuperseded
+		if err := r.Status().Patch(ctx, active, patch); err != nil {
+			return nil, fmt.Errorf("failed to supersede rebalance plan %s: %w", active.Name, err)
+		}
+		r.Log.Info("superseded rebalance plan", "plan", active.Name)
+		r.Recorder.Eventf(active, core.EventTypeNormal, "PlanSuperseded", "Rebalance plan %s superseded as the planned evictions changed", active.Name)
+	}
+
+	if len(evictions) == 0 {
+		return nil, nil
+	}
+
+	plan := &api_v1alpha1.RebalancePlan{
+		ObjectMeta: meta.ObjectMeta{
+			GenerateName: rebalancePlanNamePrefix,
+		},
+		Spec: api_v1alpha1.RebalancePlanSpec{
+			Evictions: evictions,
+		},
+	}
+	if err := r.Create(ctx, plan); err != nil {
+		return nil, fmt.Errorf("failed to create rebalance plan: %w", err)
+	}
+	plan.Status.Phase = api_v1alpha1.RebalancePlanPending
+	if err := r.Status().Update(ctx, plan); err != nil {
+		return nil, fmt.Errorf("failed to update status of rebalance plan %s: %w", plan.Name, err)
+	}
+
+	r.Log.Info("created rebalance plan", "plan", plan.Name, "evictions", len(evictions))
+	r.Recorder.Eventf(plan, core.EventTypeNormal, "PlanCreated", "Rebalance plan %s lists %d evictions", plan.Name, len(evictions))
+	r.prunePlans(ctx)
+	return plan, nil
+}
+
+// carries out the next pending evictions of a plan, recording the outcome of each attempted eviction on the plan's status
+func (r *PodRebalancer) executePlan(ctx context.Context, cfg rebalanceConfig, plan *api_v1alpha1.RebalancePlan, namespacedProfiles map[string]map[string]api_v1beta1.WorkloadProfile, workloadProfiles map[string]api_v1beta1.WorkloadProfile) (ctrl.Result, error) {
+	log := r.Log.WithValues("plan", plan.Name)
+	patch := client.MergeFrom(plan.DeepCopy())
+	if plan.Status.Phase != api_v1alpha1.RebalancePlanApplying {
+		log.Info("applying rebalance plan", "evictions", len(plan.Spec.Evictions), "approved", plan.Spec.Approved)
+		plan.Status.Phase = api_v1alpha1.RebalancePlanApplying
+	}
+
+	result := ctrl.Result{
+		RequeueAfter: 5 * time.Second,
+	}
+	// evictions are sent in batches, paced by EvictionPacing, until the plan is carried out or held back, so that a badly
+	// degraded node isn't drained a single pod per requeue; a plan not retrying evictions individually waits on each
+	// blocked eviction in order, so it sends them one at a time
+	batchSize := 1
+	if r.evictionRetries != nil && r.EvictionConcurrency > 1 {
+		batchSize = r.EvictionConcurrency
+	}
+
+	// results are recorded in plan order, so the evictions without one are still pending
+evictions:
+	for len(plan.Status.Results) < len(plan.Spec.Evictions) {
+		// an operator pausing evictions mid-plan holds back the rest of it right away, rather than once it is carried out
+		current := r.currentConfig()
+		if r.evictionsPaused(current) {
+			log.Info("evictions paused, holding back the rest of the plan")
+			result.RequeueAfter = cfg.recheckInterval
+			break
+		}
+		// so do Pending pods piling up as the plan's evictions are carried out
+		if r.pendingPodsPausedNow(ctx, current) {
+			log.Info("too many pods are Pending, holding back the rest of the plan")
+			result.RequeueAfter = cfg.recheckInterval
+			break
+		}
+		// the cluster-wide eviction rate limit shrinks the batch, and holds the rest of the plan back once exhausted
+		limit, wait := r.evictionRate.reserve(cfg.maxEvictionsPerMinute, batchSize, time.Now())
+		if limit == 0 {
+			log.Info("cluster-wide eviction rate limit reached, holding back the rest of the plan", "maxEvictionsPerMinute", cfg.maxEvictionsPerMinute, "wait", wait.Round(time.Second))
+			metrics.EvictionsRateLimited.Inc()
+			result.RequeueAfter = wait
+			break
+		}
+
+		// re-validating the next pending evictions until a batch of them is ready to be sent
+		var batch []*batchedEviction
+		var prepared []*preparedEviction
+		for next := len(plan.Status.Results); next < len(plan.Spec.Evictions) && len(prepared) < limit; next++ {
+			entry := &batchedEviction{index: next, planned: plan.Spec.Evictions[next]}
+			// a pod group member waits for the outcome of the pod it is evicted along with
+			if evictedWithinBatch(batch, entry.planned) {
+				break
+			}
+			if reason := podGroupLeaderSkipReason(plan, entry.planned); reason != "" {
+				entry.outcome, entry.message = api_v1alpha1.PlannedEvictionSkipped, reason
+			} else {
+				entry.prepared, entry.outcome, entry.message, entry.err = r.preparePlannedEviction(ctx, plan.Name, entry.planned, namespacedProfiles, workloadProfiles)
+			}
+			batch = append(batch, entry)
+			if entry.prepared != nil {
+				prepared = append(prepared, entry.prepared)
+			}
+			if entry.err != nil && r.evictionRetries == nil {
+				break
+			}
+		}
+		evictErrs, unsent := r.sendEvictions(ctx, prepared)
+		// handing back the reserved evictions that were skipped or never sent, as their pre-eviction hooks failed
+		r.evictionRate.refund(cfg.maxEvictionsPerMinute, limit-len(prepared)+unsent, time.Now())
+
+		evicted, transient, blocked := false, false, false
+		for _, entry := range batch {
+			if entry.prepared != nil {
+				entry.outcome, entry.message, entry.err = r.completePlannedEviction(ctx, cfg, plan.Name, entry.prepared, evictErrs[0])
+				evictErrs = evictErrs[1:]
+			}
+			if entry.err != nil && r.evictionRetries == nil {
+				log.Info("eviction may succeed later, backing off", "pod", entry.planned.Pod, "reason", eviction.ReasonOf(entry.err))
+				result.RequeueAfter = 10 * time.Second
+				blocked = true
+				break
+			}
+			// handing the eviction to the retry queue so that it backs off on its own instead of holding up the rest of the plan
+			if entry.err != nil {
+				log.Info("eviction may succeed later, queueing a retry", "pod", entry.planned.Pod, "reason", eviction.ReasonOf(entry.err))
+				r.evictionRetries.add(evictionRetry{plan: plan.Name, index: entry.index, planned: entry.planned})
+				entry.outcome, entry.message = api_v1alpha1.PlannedEvictionRetrying, entry.err.Error()
+			}
+
+			plan.Status.Results = append(plan.Status.Results, api_v1alpha1.PlannedEvictionResult{
+				Pod:       entry.planned.Pod,
+				Namespace: entry.planned.Namespace,
+				Outcome:   entry.outcome,
+				Message:   entry.message,
+				Time:      meta.Now(),
+			})
+			// dry-run evictions leave the pods running, so the rest of the plan needn't be paced
+			evicted = evicted || entry.outcome == api_v1alpha1.PlannedEvictionEvicted || entry.outcome == api_v1alpha1.PlannedEvictionForceDeleted
+			transient = transient || eviction.IsTransient(entry.err)
+		}
+		if blocked {
+			break
+		}
+		// a struggling API server is given time to recover before the rest of the plan
+		if transient {
+			result.RequeueAfter = 10 * time.Second
+			break
+		}
+
+		// giving the scheduler and the API server time to catch up with the evicted pods before the next batch
+		if evicted && r.EvictionPacing > 0 && len(plan.Status.Results) < len(plan.Spec.Evictions) {
+			select {
+			case <-ctx.Done():
+				break evictions
+			case <-time.After(r.EvictionPacing):
+			}
+		}
+	}
+
+	if len(plan.Status.Results) == len(plan.Spec.Evictions) {
+		now := meta.Now()
+		plan.Status.Phase = api_v1alpha1.RebalancePlanCompleted
+		plan.Status.CompletionTime = &now
+		log.Info("completed rebalance plan")
+		r.Recorder.Eventf(plan, core.EventTypeNormal, "PlanCompleted", "Rebalance plan %s completed", plan.Name)
+	}
+
+	if err := r.Status().Patch(ctx, plan, patch); err != nil {
+		log.Error(err, "failed to update rebalance plan status")
+		return ctrl.Result{}, err
+	}
+	return result, nil
+}
+
+// planned eviction found still applicable, ready to be sent
+type preparedEviction struct {
+	planned      api_v1alpha1.PlannedEviction
+	pod          *core.Pod
+	opts         eviction.EvictOptions
+	profile      api_v1beta1.WorkloadProfile
+	profileFound bool
+	// reason for the eviction, stamped on the pod right before it is evicted
+	stamp evictionStamp
+	// deletes the pod outright, as its PodDisruptionBudget has blocked its eviction for too long
+	force bool
+	// time the pod was first seen blocked by its PodDisruptionBudget, when deleted outright
+	blockedSince time.Time
+}
+
+// pending eviction of a plan along with its outcome, as re-validated and then sent as part of a batch
+type batchedEviction struct {
+	// position of the eviction in the plan
+	index    int
+	planned  api_v1alpha1.PlannedEviction
+	prepared *preparedEviction
+	outcome  api_v1alpha1.PlannedEvictionOutcome
+	message  string
+	err      error
+}
+
+// sends prepared evictions through the evictor, at once for those sharing a grace period, returning their errors in
+// order; the pre-eviction hooks of their profiles run first, and pods whose hooks fail are not evicted, while the others
+// are stamped with the reason for their eviction; the number of evictions never sent as their hooks failed is returned
+// as well
+func (r *PodRebalancer) sendEvictions(ctx context.Context, prepared []*preparedEviction) ([]error, int) {
+	errs := make([]error, len(prepared))
+	// hooks may take a while, so those of different pods run in parallel; dry runs leave the pods running and skip them
+	if r.Hooks != nil {
+		var wg sync.WaitGroup
+		for i, p := range prepared {
+			if p.opts.DryRun || !p.profileFound || len(p.profile.Spec.Eviction.PreEvictionHooks) == 0 {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				podHooks, err := r.podEvictionHooks(ctx, p.pod, &p.profile)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				errs[i] = r.Hooks.Run(ctx, p.pod, podHooks)
+			}()
+		}
+		wg.Wait()
+	}
+
+	unsent := 0
+	groups := map[string][]int{}
+	var keys []string
+	for i, p := range prepared {
+		if errs[i] != nil {
+			unsent++
+			continue
+		}
+		// a dry run leaves the pod untouched
+		if !p.opts.DryRun {
+			r.stampEviction(ctx, p.pod, p.stamp)
+		}
+		if p.force {
+			errs[i] = r.forceDeletePod(ctx, p.pod, p.opts)
+			continue
+		}
+		key := ""
+		if p.opts.GracePeriodSeconds != nil {
+			key = strconv.FormatInt(*p.opts.GracePeriodSeconds, 10)
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	for _, key := range keys {
+		indexes := groups[key]
+		pods := make([]*core.Pod, len(indexes))
+		for j, i := range indexes {
+			pods[j] = prepared[i].pod
+		}
+		for j, result := range r.Evictor.EvictPods(ctx, pods, prepared[indexes[0]].opts) {
+			errs[indexes[j]] = result.Err
+		}
+	}
+	return errs, unsent
+}
+
+// re-validates and carries out a single planned eviction; only failures worth retrying later (a PodDisruptionBudget
+// block or a transient API error) are returned as an error, and a PodDisruptionBudget found exhausted beforehand only
+// when evictions are retried; the caller reserves the eviction with the cluster-wide rate limiter beforehand, and it is
+// handed back when the eviction isn't sent
+func (r *PodRebalancer) executePlannedEviction(ctx context.Context, cfg rebalanceConfig, planName string, planned api_v1alpha1.PlannedEviction, namespacedProfiles map[string]map[string]api_v1beta1.WorkloadProfile, workloadProfiles map[string]api_v1beta1.WorkloadProfile) (api_v1alpha1.PlannedEvictionOutcome, string, error) {
+	prepared, outcome, message, err := r.preparePlannedEviction(ctx, planName, planned, namespacedProfiles, workloadProfiles)
+	if prepared == nil {
+		r.evictionRate.refund(cfg.maxEvictionsPerMinute, 1, time.Now())
+		return outcome, message, err
+	}
+	evictErrs, unsent := r.sendEvictions(ctx, []*preparedEviction{prepared})
+	r.evictionRate.refund(cfg.maxEvictionsPerMinute, unsent, time.Now())
+	return r.completePlannedEviction(ctx, cfg, planName, prepared, evictErrs[0])
+}
+
+// re-validates a planned eviction against the current state of its pod, node and profile, returning it ready to be sent;
+// otherwise returns no eviction, along with its outcome or, when retrying it later may succeed, an error
+func (r *PodRebalancer) preparePlannedEviction(ctx context.Context, planName string, planned api_v1alpha1.PlannedEviction, namespacedProfiles map[string]map[string]api_v1beta1.WorkloadProfile, workloadProfiles map[string]api_v1beta1.WorkloadProfile) (*preparedEviction, api_v1alpha1.PlannedEvictionOutcome, string, error) {
+	log := r.Log.WithValues("pod", planned.Pod, "namespace", planned.Namespace, "node", planned.Node)
+
+	// the pod and its node may have changed since the plan was written
+	pod := &core.Pod{}
+	if err := r.Get(ctx, types.NamespacedName{Name: planned.Pod, Namespace: planned.Namespace}, pod); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, api_v1alpha1.PlannedEvictionSkipped, "pod no longer exists", nil
+		}
+		return nil, api_v1alpha1.PlannedEvictionFailed, fmt.Sprintf("failed to get pod: %v", err), nil
+	}
+	if pod.UID != planned.UID {
+		return nil, api_v1alpha1.PlannedEvictionSkipped, "pod was recreated since the plan was written", nil
+	}
+	if pod.DeletionTimestamp != nil {
+		return nil, api_v1alpha1.PlannedEvictionSkipped, "pod is already terminating", nil
+	}
+	if pod.Spec.NodeName != planned.Node {
+		return nil, api_v1alpha1.PlannedEvictionSkipped, fmt.Sprintf("pod is no longer on node %s", planned.Node), nil
+	}
+	if reason := doNotEvictReason(pod); reason != "" {
+		r.Recorder.Eventf(pod, core.EventTypeNormal, "EvictionSkipped", "Pod %s skipped as %s", pod.Name, reason)
+		return nil, api_v1alpha1.PlannedEvictionSkipped, fmt.Sprintf("pod opted out of eviction as %s", reason), nil
+	}
+	if reason := r.criticalPodReason(pod); reason != "" {
+		return nil, api_v1alpha1.PlannedEvictionSkipped, fmt.Sprintf("pod is critical as %s", reason), nil
+	}
+
+	node := &core.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: planned.Node}, node); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, api_v1alpha1.PlannedEvictionSkipped, fmt.Sprintf("node %s no longer exists", planned.Node), nil
+		}
+		return nil, api_v1alpha1.PlannedEvictionFailed, fmt.Sprintf("failed to get node: %v", err), nil
+	}
+	if reason := r.strategyFor(planned).revalidate(node, time.Now()); reason != "" {
+		return nil, api_v1alpha1.PlannedEvictionSkipped, reason, nil
+	}
+	_, key := r.DegradationClassifier.IsDegraded(node, time.Now())
+
+	// checking Pod Disruption Budget before eviction; the budget may allow it once replacements are ready, so it is worth
+	// retrying when retries are enabled, unless it has blocked the pod on a degraded node for so long that it is deleted
+	// outright
+	force, blockedSince := false, time.Time{}
+	if err := r.checkPDB(ctx, pod); err != nil {
+		if evacuatesDegradedNode(planned) {
+			force, blockedSince = r.pdbBlockEscalated(pod, time.Now())
+		}
+		if !force {
+			log.V(1).Info("pod cannot be evicted due to PDB violation or check error", "error", err.Error())
+			r.Recorder.Eventf(pod, core.EventTypeWarning, "PDBViolation", "Pod %s cannot be evicted due to PDB violation: %v", pod.Name, err)
+			if r.evictionRetries != nil {
+				return nil, "", "", err
+			}
+			return nil, api_v1alpha1.PlannedEvictionSkipped, err.Error(), nil
+		}
+	}
+
+	opts := eviction.EvictOptions{DryRun: r.DryRun}
+	profile, profileFound := profiles.MatchPodScoped(pod, node, namespacedProfiles, workloadProfiles)
+	if profileFound {
+		opts.GracePeriodSeconds = profile.Spec.Eviction.GracePeriodSeconds
+	}
+
+	// keeping the owner at the profile's minimum ready replicas, and with a ready replica at all, as its ready replicas
+	// may have dropped since the plan was written
+	var podProfile *api_v1beta1.WorkloadProfile
+	if profileFound {
+		podProfile = &profile
+	}
+	if (profileFound && profile.Spec.MinAvailable != nil) || !evictsLastReplica(podProfile) {
+		owner, err := getPodOwner(ctx, r, pod)
+		if err != nil {
+			return nil, api_v1alpha1.PlannedEvictionFailed, fmt.Sprintf("failed to get pod owner: %v", err), nil
+		}
+		if profileFound && profile.Spec.MinAvailable != nil {
+			if err := checkMinAvailable(pod, owner, *profile.Spec.MinAvailable); err != nil {
+				log.V(1).Info("pod eviction would violate the profile's min available replicas", "profile", profile.Name, "reason", err.Error())
+				r.Recorder.Eventf(pod, core.EventTypeNormal, "EvictionSkipped", "Pod %s skipped as %v", pod.Name, err)
+				metrics.PodsSkipped.WithLabelValues(metrics.SkipReasonMinAvailable, profile.Name).Inc()
+				return nil, api_v1alpha1.PlannedEvictionSkipped, err.Error(), nil
+			}
+		}
+		if !evictsLastReplica(podProfile) {
+			if err := checkLastReplica(pod, owner); err != nil {
+				log.V(1).Info("pod is the last ready replica of its owner", "owner", owner.GetName())
+				r.reportLastReplica(pod, owner, profile.Name, err)
+				return nil, api_v1alpha1.PlannedEvictionSkipped, err.Error(), nil
+			}
+		}
+	}
+
+	// scaling the pod's Deployment up first when its profile asks not to lose capacity, holding the eviction back until
+	// the extra replica is Ready; a dry run leaves the Deployment alone
+	if profileFound && profile.Spec.Eviction.Strategy == api_v1beta1.EvictionStrategySurgeThenEvict && !opts.DryRun {
+		owner, err := getPodOwner(ctx, r, pod)
+		if err != nil {
+			return nil, api_v1alpha1.PlannedEvictionFailed, fmt.Sprintf("failed to get pod owner: %v", err), nil
+		}
+		if deploy, isDeployment := owner.(*apps.Deployment); isDeployment {
+			ready, err := r.surgeBeforeEviction(ctx, deploy, pod, time.Now())
+			if err != nil {
+				return nil, api_v1alpha1.PlannedEvictionFailed, err.Error(), nil
+			}
+			if !ready {
+				log.V(1).Info("deployment awaits a ready surge replica, holding back eviction", "deployment", deploy.Name)
+				return nil, "", "", fmt.Errorf("deployment %s awaits a ready surge replica", deploy.Name)
+			}
+		}
+	}
+
+	log.Info("attempting to evist pod from node", "profile", planned.Profile, "reason", planned.Reason)
+	stamp := newEvictionStamp(node, key, planned, planName)
+	return &preparedEviction{planned: planned, pod: pod, opts: opts, profile: profile, profileFound: profileFound, stamp: stamp, force: force, blockedSince: blockedSince}, "", "", nil
+}
+
+// reports the result of sending a prepared eviction, returning its outcome; only failures worth retrying later (a
+// PodDisruptionBudget block or a transient API error) are returned as an error
+func (r *PodRebalancer) completePlannedEviction(ctx context.Context, cfg rebalanceConfig, planName string, prepared *preparedEviction, err error) (api_v1alpha1.PlannedEvictionOutcome, string, error) {
+	planned, pod, opts := prepared.planned, prepared.pod, prepared.opts
+	log := r.Log.WithValues("pod", planned.Pod, "namespace", planned.Namespace, "node", planned.Node)
+
+	// a pod blocked by its PodDisruptionBudget on a degraded node for too long is deleted outright instead of waiting any longer
+	if eviction.IsBlockedByPDB(err) && evacuatesDegradedNode(planned) {
+		if force, blockedSince := r.pdbBlockEscalated(pod, time.Now()); force {
+			metrics.EvictionFailures.WithLabelValues(string(eviction.FailureBlockedByPDB)).Inc()
+			prepared.force, prepared.blockedSince = true, blockedSince
+			err = r.forceDeletePod(ctx, pod, opts)
+		}
+	}
+	if prepared.force && (errors.IsNotFound(err) || errors.IsConflict(err)) {
+		return api_v1alpha1.PlannedEvictionSkipped, "pod no longer exists", nil
+	}
+
+	// the application failed to prepare for the eviction, which is reported by the hook runner and needs an operator's attention
+	if hooks.IsFailure(err) {
+		if !opts.DryRun {
+			r.recordEviction(ctx, pod, planned, planName, api_v1alpha1.EvictionOutcomeFailed, err.Error())
+		}
+		return api_v1alpha1.PlannedEvictionFailed, err.Error(), nil
+	}
+	if err != nil {
+		reason := eviction.ReasonOf(err)
+		metrics.EvictionFailures.WithLabelValues(string(reason)).Inc()
+		switch reason {
+		case eviction.FailureBlockedByPDB:
+			// the budget may allow the eviction once replacements are ready, so the plan waits for it
+			r.Recorder.Eventf(pod, core.EventTypeWarning, "PDBViolation", "Eviction of pod %s blocked by its PodDisruptionBudget, retrying", pod.Name)
+			return "", "", err
+		case eviction.FailureTransient:
+			r.Recorder.Eventf(pod, core.EventTypeWarning, "EvictionRateLimited", "Eviction of pod %s failed on a transient K8s API error, retrying: %v", pod.Name, err)
+			return "", "", err
+		case eviction.FailurePodNotFound:
+			return api_v1alpha1.PlannedEvictionSkipped, "pod no longer exists", nil
+		case eviction.FailurePodRecreated:
+			return api_v1alpha1.PlannedEvictionSkipped, "pod was recreated since the plan was written", nil
+		}
+		// forbidden and unexpected failures won't resolve by retrying and need an operator's attention
+		log.Error(err, "failed to evict pod", "reason", reason)
+		r.Recorder.Eventf(pod, core.EventTypeWarning, "EvictionFailed", "Failed to evict pod %s: %v", pod.Name, err)
+		if !opts.DryRun {
+			r.recordEviction(ctx, pod, planned, planName, api_v1alpha1.EvictionOutcomeFailed, err.Error())
+		}
+		return api_v1alpha1.PlannedEvictionFailed, err.Error(), nil
+	}
+
+	// a dry run leaves the pod and its owner untouched, so nothing is recorded beyond reporting it
+	if opts.DryRun {
+		log.Info("pod would be evicted from node", "profile", planned.Profile)
+		r.Recorder.Eventf(pod, core.EventTypeNormal, "EvictionDryRun", "Pod %s would be evicted from node %s (dry run)", pod.Name, planned.Node)
+		metrics.DryRunEvictions.WithLabelValues(planned.Profile).Inc()
+		return api_v1alpha1.PlannedEvictionDryRun, "", nil
+	}
+
+	outcome := api_v1alpha1.PlannedEvictionEvicted
+	if prepared.force {
+		message := fmt.Sprintf("deleted outright after its PodDisruptionBudget blocked its eviction since %s", prepared.blockedSince.Format(time.RFC3339))
+		log.Info("force-deleted pod blocked by its PDB for too long", "blockedSince", prepared.blockedSince.Format(time.RFC3339))
+		r.Recorder.Eventf(pod, core.EventTypeWarning, "PodForceDeleted", "Pod %s force-deleted from degraded node %s, violating its PodDisruptionBudget, which blocked its eviction since %s", pod.Name, planned.Node, prepared.blockedSince.Format(time.RFC3339))
+		r.recordEviction(ctx, pod, planned, planName, api_v1alpha1.EvictionOutcomeForceDeleted, message)
+		outcome = api_v1alpha1.PlannedEvictionForceDeleted
+	} else {
+		log.Info("successfully evicted pod")
+		r.Recorder.Eventf(pod, core.EventTypeNormal, "PodEvicted", "Pod %s evicted from node %s", pod.Name, planned.Node)
+		r.recordEviction(ctx, pod, planned, planName, api_v1alpha1.EvictionOutcomeEvicted, "")
+	}
+	if policy, what := cfg.unmanagedPodPolicy(pod); policy == UnmanagedPodPolicyEvictWithWarning {
+		r.Recorder.Eventf(pod, core.EventTypeWarning, "UnmanagedPodEvicted", "Pod %s evicted from node %s although %s", pod.Name, planned.Node, what)
+	}
+	if r.pdbBlocks != nil {
+		r.pdbBlocks.forget(pod.UID)
+	}
+	if r.podCooldowns != nil && r.PodEvictionCooldown > 0 {
+		r.podCooldowns.start(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, time.Now().Add(r.PodEvictionCooldown))
+	}
+	if r.pendingPods != nil {
+		r.pendingPods.evicted(pod, time.Now())
+	}
+
+	// setting cooldown annotation on the pod's owner
+	owner, err := getPodOwner(ctx, r, pod)
+	if err != nil {
+		log.Error(err, "failed to get pod owner, skipping cooldown annotation")
+	} else if owner != nil {
+		r.setOwnerCooldown(ctx, owner, time.Now().Add(cfg.recheckInterval*2)) // cooldown for a minimum of 2 recheck intervals
+		// the replica standing in for the pod is Ready, so the Deployment goes back to its replicas
+		if deploy, ok := owner.(*apps.Deployment); ok {
+			if surge, err := deploymentSurge(deploy); err == nil && surge != nil && surge.PodUID == pod.UID {
+				if err := r.endSurge(ctx, deploy); err != nil {
+					log.Error(err, "failed to restore replicas of surged deployment")
+				}
+			}
+		}
+		if r.WaitForReschedule || isStatefulSet(owner) {
+			r.setOwnerAwaitingReplacement(ctx, owner, time.Now())
+		} else {
+			r.clearOwnerAwaitingReplacement(ctx, owner)
+		}
+	}
+
+	return outcome, "", nil
+}
+
+// deletes the oldest completed and superseded plans beyond the history limit
+func (r *PodRebalancer) prunePlans(ctx context.Context) {
+	planList := &api_v1alpha1.RebalancePlanList{}
+	if err := r.List(ctx, planList); err != nil {
+		r.Log.Error(err, "failed to list rebalance plans for pruning")
+		return
+	}
+
+	var finished []*api_v1alpha1.RebalancePlan
+	for i := range planList.Items {
+		if planFinished(&planList.Items[i]) {
+			finished = append(finished, &planList.Items[i])
+		}
+	}
+	if len(finished) <= rebalancePlanHistoryLimit {
+		return
+	}
+
+	sort.Slice(finished, func(i int, j int) bool {
+		return finished[j].CreationTimestamp.Before(&finished[i].CreationTimestamp)
+	})
+	for _, plan := range finished[rebalancePlanHistoryLimit:] {
+		if err := r.Delete(ctx, plan); err != nil && !errors.IsNotFound(err) {
+			r.Log.Error(err, "failed to delete old rebalance plan", "plan", plan.Name)
+		}
+	}
+}
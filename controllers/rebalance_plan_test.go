@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1beta1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+	"github.com/lokeshllkumar/kube-balance/pkg/eviction"
+)
+
+// returns an approved plan evacuating the given pods from their degraded nodes
+func testPlan(name string, pods ...*core.Pod) *api_v1alpha1.RebalancePlan {
+	plan := &api_v1alpha1.RebalancePlan{
+		ObjectMeta: meta.ObjectMeta{Name: name},
+		Spec:       api_v1alpha1.RebalancePlanSpec{Approved: true},
+	}
+	for _, pod := range pods {
+		plan.Spec.Evictions = append(plan.Spec.Evictions, api_v1alpha1.PlannedEviction{
+			Pod:       pod.Name,
+			Namespace: pod.Namespace,
+			UID:       pod.UID,
+			Node:      pod.Spec.NodeName,
+			Profile:   "default",
+			Strategy:  DegradedNodeStrategy,
+		})
+	}
+	return plan
+}
+
+// returns the outcomes of a plan's evictions carried out so far, in order
+func planOutcomes(plan *api_v1alpha1.RebalancePlan) []api_v1alpha1.PlannedEvictionOutcome {
+	outcomes := make([]api_v1alpha1.PlannedEvictionOutcome, len(plan.Status.Results))
+	for i, result := range plan.Status.Results {
+		outcomes[i] = result.Outcome
+	}
+	return outcomes
+}
+
+// returns the names of evicted pods
+func podNames(evicted []types.NamespacedName) []string {
+	names := make([]string, len(evicted))
+	for i, pod := range evicted {
+		names[i] = pod.Name
+	}
+	return names
+}
+
+func TestExecutePlanQueuesRetriesOfBlockedEvictions(t *testing.T) {
+	node := degradedNode("node-a", degradation.SeverityNormal)
+	web, api := testDeployment("web", 3, false), testDeployment("api", 3, false)
+	blocked, evicted := testPod("web-0", web, "node-a", "128Mi"), testPod("api-0", api, "node-a", "128Mi")
+	plan := testPlan("plan", blocked, evicted)
+	r, evictor := newTestRebalancer(t, append(testObjects([]*core.Node{node}, []*apps.Deployment{web, api}, []*core.Pod{blocked, evicted}), plan)...)
+	r.EvictionConcurrency = 2
+	r.evictionRetries = newEvictionRetryQueue(time.Hour, time.Hour, 3)
+	defer r.evictionRetries.queue.ShutDown()
+	evictor.Err = func(method string, pod *core.Pod) error {
+		if pod.Name == blocked.Name {
+			return blockedByPDB(pod)
+		}
+		return nil
+	}
+
+	if _, err := r.executePlan(context.Background(), r.currentConfig(), plan, nil, defaultProfiles()); err != nil {
+		t.Fatalf("executePlan() error = %v", err)
+	}
+	// the blocked eviction is handed to the retry queue rather than holding up the rest of the plan
+	wantOutcomes := []api_v1alpha1.PlannedEvictionOutcome{api_v1alpha1.PlannedEvictionRetrying, api_v1alpha1.PlannedEvictionEvicted}
+	if got := planOutcomes(plan); !slices.Equal(got, wantOutcomes) {
+		t.Errorf("outcomes = %v, want %v", got, wantOutcomes)
+	}
+	if got, want := podNames(evictor.Evicted()), []string{"api-0"}; !slices.Equal(got, want) {
+		t.Errorf("evicted pods = %v, want %v", got, want)
+	}
+	if !r.evictionRetries.isPending(blocked.UID) {
+		t.Errorf("eviction of pod %s is not queued for a retry", blocked.Name)
+	}
+}
+
+func TestExecutePlanBacksOffOnBlockedEvictionWithoutRetries(t *testing.T) {
+	node := degradedNode("node-a", degradation.SeverityNormal)
+	web, api := testDeployment("web", 3, false), testDeployment("api", 3, false)
+	blocked, held := testPod("web-0", web, "node-a", "128Mi"), testPod("api-0", api, "node-a", "128Mi")
+	plan := testPlan("plan", blocked, held)
+	r, evictor := newTestRebalancer(t, append(testObjects([]*core.Node{node}, []*apps.Deployment{web, api}, []*core.Pod{blocked, held}), plan)...)
+	evictor.Err = func(method string, pod *core.Pod) error {
+		return blockedByPDB(pod)
+	}
+
+	result, err := r.executePlan(context.Background(), r.currentConfig(), plan, nil, defaultProfiles())
+	if err != nil {
+		t.Fatalf("executePlan() error = %v", err)
+	}
+	// the plan waits on the blocked eviction, in order, without attempting the next one
+	if len(plan.Status.Results) != 0 {
+		t.Errorf("results = %v, want none", planOutcomes(plan))
+	}
+	if result.RequeueAfter != 10*time.Second {
+		t.Errorf("requeue after = %v, want %v", result.RequeueAfter, 10*time.Second)
+	}
+	if calls := evictor.Calls(); len(calls) != 1 || calls[0].Pod.Name != blocked.Name {
+		t.Errorf("evictor calls = %v, want a single eviction of %s", calls, blocked.Name)
+	}
+}
+
+func TestExecutePlanHoldsBackEvictionsBeyondRateLimit(t *testing.T) {
+	node := degradedNode("node-a", degradation.SeverityNormal)
+	web := testDeployment("web", 5, false)
+	gone := testPod("web-0", web, "node-a", "128Mi")
+	pods := []*core.Pod{testPod("web-1", web, "node-a", "128Mi"), testPod("web-2", web, "node-a", "128Mi"), testPod("web-3", web, "node-a", "128Mi")}
+	plan := testPlan("plan", append([]*core.Pod{gone}, pods...)...)
+	r, evictor := newTestRebalancer(t, append(testObjects([]*core.Node{node}, []*apps.Deployment{web}, pods), plan)...)
+	r.MaxEvictionsPerMinute = 2
+
+	result, err := r.executePlan(context.Background(), r.currentConfig(), plan, nil, defaultProfiles())
+	if err != nil {
+		t.Fatalf("executePlan() error = %v", err)
+	}
+	// the eviction of the pod that no longer exists is never sent, so it doesn't count towards the limit
+	wantOutcomes := []api_v1alpha1.PlannedEvictionOutcome{api_v1alpha1.PlannedEvictionSkipped, api_v1alpha1.PlannedEvictionEvicted, api_v1alpha1.PlannedEvictionEvicted}
+	if got := planOutcomes(plan); !slices.Equal(got, wantOutcomes) {
+		t.Errorf("outcomes = %v, want %v", got, wantOutcomes)
+	}
+	if got, want := podNames(evictor.Evicted()), []string{"web-1", "web-2"}; !slices.Equal(got, want) {
+		t.Errorf("evicted pods = %v, want %v", got, want)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > 30*time.Second {
+		t.Errorf("requeue after = %v, want the wait for the next token, at most %v", result.RequeueAfter, 30*time.Second)
+	}
+}
+
+func TestExecutePlanRefundsUnusedRateLimitTokens(t *testing.T) {
+	node := degradedNode("node-a", degradation.SeverityNormal)
+	web := testDeployment("web", 5, false)
+	first, second := testPod("web-1", web, "node-a", "128Mi"), testPod("web-2", web, "node-a", "128Mi")
+	firstPlan, secondPlan := testPlan("first", first), testPlan("second", second)
+	r, evictor := newTestRebalancer(t, append(testObjects([]*core.Node{node}, []*apps.Deployment{web}, []*core.Pod{first, second}), firstPlan, secondPlan)...)
+	r.MaxEvictionsPerMinute = 2
+	r.EvictionConcurrency = 3
+	r.evictionRetries = newEvictionRetryQueue(time.Hour, time.Hour, 3)
+	defer r.evictionRetries.queue.ShutDown()
+
+	// the first plan reserves a whole batch for its single eviction, handing back the rest for the second plan
+	for _, plan := range []*api_v1alpha1.RebalancePlan{firstPlan, secondPlan} {
+		if _, err := r.executePlan(context.Background(), r.currentConfig(), plan, nil, defaultProfiles()); err != nil {
+			t.Fatalf("executePlan(%s) error = %v", plan.Name, err)
+		}
+	}
+	if got, want := podNames(evictor.Evicted()), []string{"web-1", "web-2"}; !slices.Equal(got, want) {
+		t.Errorf("evicted pods = %v, want %v", got, want)
+	}
+}
+
+func TestExecutePlanDryRunLeavesPodsRunning(t *testing.T) {
+	node := degradedNode("node-a", degradation.SeverityNormal)
+	web := testDeployment("web", 3, false)
+	pod := testPod("web-0", web, "node-a", "128Mi")
+	plan := testPlan("plan", pod)
+	r, evictor := newTestRebalancer(t, append(testObjects([]*core.Node{node}, []*apps.Deployment{web}, []*core.Pod{pod}), plan)...)
+	r.DryRun = true
+
+	if _, err := r.executePlan(context.Background(), r.currentConfig(), plan, map[string]map[string]api_v1beta1.WorkloadProfile{}, defaultProfiles()); err != nil {
+		t.Fatalf("executePlan() error = %v", err)
+	}
+	if got, want := planOutcomes(plan), []api_v1alpha1.PlannedEvictionOutcome{api_v1alpha1.PlannedEvictionDryRun}; !slices.Equal(got, want) {
+		t.Errorf("outcomes = %v, want %v", got, want)
+	}
+	if evicted := evictor.Evicted(); len(evicted) != 0 {
+		t.Errorf("evicted pods = %v, want none", evicted)
+	}
+	if calls := evictor.Calls(); len(calls) != 1 || calls[0].Method != eviction.MethodEvictPod || !calls[0].Opts.DryRun {
+		t.Errorf("evictor calls = %v, want a single dry-run eviction", calls)
+	}
+	if plan.Status.Phase != api_v1alpha1.RebalancePlanCompleted {
+		t.Errorf("plan phase = %s, want %s", plan.Status.Phase, api_v1alpha1.RebalancePlanCompleted)
+	}
+}
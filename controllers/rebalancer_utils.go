@@ -3,61 +3,63 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubectl/pkg/util/qos"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
-)
-
-// determines the QoS class of a pod
-func getPodQoSClass(pod *core.Pod) core.PodQOSClass {
-	if pod.Spec.Containers == nil {
-		return core.PodQOSBestEffort
-	}
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
-	// for return QOS
-	guaranteed := true
-	burstable := false
-
-	for _, container := range pod.Spec.Containers {
-		// best effort
-		if container.Resources.Requests == nil && container.Resources.Limits == nil {
-			guaranteed = false
-			burstable = false
-			break
-		}
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1beta1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
 
-		// burstable - if requests are not equal to limits for CPU and memory
-		if container.Resources.Requests.Cpu().Cmp(*container.Resources.Limits.Cpu()) != 0 ||
-			container.Resources.Requests.Memory().Cmp(*container.Resources.Limits.Memory()) != 0 {
-			guaranteed = false
-			burstable = true
-		}
+// annotation set by the kubelet on the pods it runs, naming where it got them from; only pods from the API server can be rescheduled
+const (
+	kubeletConfigSourceAnnotation = "kubernetes.io/config.source"
+	kubeletConfigSourceAPI        = "api"
+)
 
-		// guaranteed - if requests are not set
-		if container.Resources.Requests.Cpu().IsZero() || container.Resources.Requests.Memory().IsZero() {
-			guaranteed = false
-		}
+// returns the kind of node-bound pod a pod is (a DaemonSet, mirror or static pod), which evicting would only restart
+// on the same node; empty for any other pod
+func nodeBoundPodKind(pod *core.Pod) string {
+	if _, ok := pod.Annotations[core.MirrorPodAnnotationKey]; ok {
+		return metrics.NodeBoundKindMirror
 	}
-
-	if guaranteed {
-		return core.PodQOSGuaranteed
+	if source, ok := pod.Annotations[kubeletConfigSourceAnnotation]; ok && source != kubeletConfigSourceAPI {
+		return metrics.NodeBoundKindStatic
 	}
-	if burstable {
-		return core.PodQOSBurstable
+	if owner := meta.GetControllerOf(pod); owner != nil {
+		switch owner.Kind {
+		case "DaemonSet":
+			return metrics.NodeBoundKindDaemonSet
+		case "Node":
+			return metrics.NodeBoundKindStatic
+		}
 	}
-	return core.PodQOSBestEffort
+	return ""
+}
+
+// returns the QoS class of a pod, as recorded by the API server or, for pods without one yet, computed the same way
+func getPodQoSClass(pod *core.Pod) core.PodQOSClass {
+	return qos.GetPodQOS(pod)
 }
 
 // assigns a rank for eviction priority
-func qosClassToEvictionRank(qos core.PodQOSClass) int {
-	switch qos {
+func qosClassToEvictionRank(qosClass core.PodQOSClass) int {
+	switch qosClass {
 	case core.PodQOSBestEffort:
 		return 3
 	case core.PodQOSBurstable:
@@ -69,14 +71,106 @@ func qosClassToEvictionRank(qos core.PodQOSClass) int {
 	}
 }
 
+// reports whether any container of the pod requests or is limited on the given resource
+func podRequestsResource(pod *core.Pod, resource core.ResourceName) bool {
+	for _, container := range pod.Spec.Containers {
+		if _, ok := container.Resources.Requests[resource]; ok {
+			return true
+		}
+		if _, ok := container.Resources.Limits[resource]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the number of times the pod's containers, including its init containers, have restarted
+func podRestartCount(pod *core.Pod) int {
+	restarts := 0
+	for _, status := range pod.Status.InitContainerStatuses {
+		restarts += int(status.RestartCount)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += int(status.RestartCount)
+	}
+	return restarts
+}
+
+// reports whether any container of the pod is waiting to be restarted after crashing repeatedly
+func podCrashLooping(pod *core.Pod) bool {
+	for _, statuses := range [][]core.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// returns the pod's total request for a resource across its containers, falling back to the profile's recommended request when the pod declares none
+func podEffectiveRequest(pod *core.Pod, resourceName core.ResourceName, profile *api_v1beta1.WorkloadProfile) resource.Quantity {
+	total := resource.Quantity{}
+	declared := false
+	for _, container := range pod.Spec.Containers {
+		if request, ok := container.Resources.Requests[resourceName]; ok {
+			total.Add(request)
+			declared = true
+		}
+	}
+	if declared || profile == nil {
+		return total
+	}
+
+	if _, recommended, ok := recommendedRequest(pod.Spec.Containers, resourceName, &profile.Spec.Resources); ok {
+		return recommended
+	}
+	return total
+}
+
+// returns the containers' total request for a resource, counting only the containers the recommendation covers,
+// along with the request recommended for them: each container's own recommendation, plus the pod-level one for the
+// containers without; false when nothing is recommended for the resource
+func recommendedRequest(containers []core.Container, resourceName core.ResourceName, recommendation *api_v1beta1.ResourceRecommendation) (resource.Quantity, resource.Quantity, bool) {
+	requested, recommended := resource.Quantity{}, resource.Quantity{}
+	podLevel := recommendation.PodRequest(resourceName)
+	ok, podLevelCovers := false, false
+	for _, container := range containers {
+		if own, hasOwn := recommendation.ContainerRequest(container.Name, resourceName); hasOwn {
+			recommended.Add(own)
+			ok = true
+		} else if podLevel != nil {
+			podLevelCovers = true
+		} else {
+			continue
+		}
+		if request, declared := container.Resources.Requests[resourceName]; declared {
+			requested.Add(request)
+		}
+	}
+	if podLevelCovers {
+		recommended.Add(*podLevel)
+		ok = true
+	}
+	return requested, recommended, ok
+}
+
+// returns the reader of the objects read straight from the API server
+func (r *PodRebalancer) uncachedReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
 // attempts to find the Deployment, StatefulSet, or ReplicaSet that owns the pod
-func (r *PodRebalancer) getPodOwner(ctx context.Context, pod *core.Pod) (client.Object, error) {
+func getPodOwner(ctx context.Context, c client.Reader, pod *core.Pod) (client.Object, error) {
 	for _, ownerRef := range pod.OwnerReferences {
 		if ownerRef.Controller != nil && *ownerRef.Controller {
 			switch ownerRef.Kind {
 			case "ReplicaSet":
 				rs := &apps.ReplicaSet{}
-				if err := r.Get(ctx, types.NamespacedName{
+				if err := c.Get(ctx, types.NamespacedName{
 					Name:      ownerRef.Name,
 					Namespace: pod.Namespace,
 				}, rs); err != nil {
@@ -87,7 +181,7 @@ func (r *PodRebalancer) getPodOwner(ctx context.Context, pod *core.Pod) (client.
 				for _, rsOwnerRef := range rs.OwnerReferences {
 					if rsOwnerRef.Controller != nil && *rsOwnerRef.Controller && rsOwnerRef.Kind == "Deployment" {
 						deploy := &apps.Deployment{}
-						if err := r.Get(ctx, types.NamespacedName{
+						if err := c.Get(ctx, types.NamespacedName{
 							Name:      rsOwnerRef.Name,
 							Namespace: pod.Namespace,
 						}, deploy); err != nil {
@@ -100,7 +194,7 @@ func (r *PodRebalancer) getPodOwner(ctx context.Context, pod *core.Pod) (client.
 				return rs, nil
 			case "StatefulSet":
 				ss := &apps.StatefulSet{}
-				if err := r.Get(ctx, types.NamespacedName{
+				if err := c.Get(ctx, types.NamespacedName{
 					Name:      ownerRef.Name,
 					Namespace: pod.Namespace,
 				}, ss); err != nil {
@@ -109,7 +203,7 @@ func (r *PodRebalancer) getPodOwner(ctx context.Context, pod *core.Pod) (client.
 				return ss, nil
 			case "Deployment":
 				deploy := &apps.Deployment{}
-				if err := r.Get(ctx, types.NamespacedName{
+				if err := c.Get(ctx, types.NamespacedName{
 					Name:      ownerRef.Name,
 					Namespace: pod.Namespace,
 				}, deploy); err != nil {
@@ -123,6 +217,24 @@ func (r *PodRebalancer) getPodOwner(ctx context.Context, pod *core.Pod) (client.
 	return nil, nil // when no controller owner is found
 }
 
+// annotates the pod's owner with the time until which none of its other pods may be evicted
+func (r *PodRebalancer) setOwnerCooldown(ctx context.Context, owner client.Object, cooldownUntil time.Time) {
+	patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
+	annotations := owner.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[EvictionCooldownAnnotation] = cooldownUntil.Format(time.RFC3339)
+	owner.SetAnnotations(annotations)
+	if err := r.Patch(ctx, owner, patch); err != nil {
+		r.Log.Error(err, "failed to add eviction cooldown annotation to the pod owner", "owner", owner.GetName(), "namespace", owner.GetNamespace())
+		r.Recorder.Eventf(owner, core.EventTypeWarning, "CooldownAnnotationFailed", "Failed to add cooldown annotation to owner %s: %v", owner.GetName(), err)
+		return
+	}
+	r.Log.V(1).Info("added eviction cooldown annotation to pod owner", "owner", owner.GetName(), "cooldownUntil", cooldownUntil.Format(time.RFC3339))
+	r.Recorder.Eventf(owner, core.EventTypeNormal, "CooldownSet", "Cooldown set on owner %s until %s", owner.GetName(), cooldownUntil.Format(time.RFC3339))
+}
+
 // checks if evicting a given pod would violate any PodDisruptionBudget
 func (r *PodRebalancer) checkPDB(ctx context.Context, pod *core.Pod) error {
 	pdbList := &policy.PodDisruptionBudgetList{}
@@ -151,8 +263,28 @@ func (r *PodRebalancer) checkPDB(ctx context.Context, pod *core.Pod) error {
 
 // sets up the controller with the Manager by informing it which resources it must watches and how it must handle events
 func (r *PodRebalancer) SetupWithManager(mgr ctrl.Manager) error {
+	r.degradationTracker = newDegradationTracker()
+	r.evictionRate = newEvictionRateLimiter()
+	r.nodeRotation = newDegradedNodeRotation()
+	r.podCooldowns = newPodCooldownTracker()
+	r.pendingPods = newPendingPodsBreaker()
+	if r.DegradationClassifier == nil {
+		r.DegradationClassifier = &degradation.Classifier{}
+	}
+	r.pdbBlocks = newPDBBlockTracker()
+	r.zoneFailures = newZoneFailureReporter()
+	r.skipReports = newSkipReporter()
+	if r.EvictionRetryMaxAttempts > 1 {
+		r.evictionRetries = newEvictionRetryQueue(r.EvictionRetryBaseDelay, r.EvictionRetryMaxDelay, r.EvictionRetryMaxAttempts)
+		if err := mgr.Add(manager.RunnableFunc(r.runEvictionRetries)); err != nil {
+			return fmt.Errorf("failed to add eviction retry queue: %w", err)
+		}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&core.Node{}).
+		For(&core.Node{}, builder.WithPredicates(r.nodeChangedPredicate())).
+		Watches(&api_v1alpha1.RebalancePolicy{}, &handler.EnqueueRequestForObject{}).
+		Watches(&api_v1alpha1.RebalancePlan{}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
 		Watches(&core.Pod{}, &handler.EnqueueRequestForObject{}).
 		Watches(&apps.Deployment{}, &handler.EnqueueRequestForObject{}).
 		Watches(&apps.StatefulSet{}, &handler.EnqueueRequestForObject{}).
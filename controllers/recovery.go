@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+)
+
+// cancels the evictions from nodes that recovered since they were planned: the pending evictions of the plan being
+// applied and the evictions awaiting a retry are recorded as skipped, and each recovered node gets an event
+// summarising the evictions avoided
+func (r *PodRebalancer) cancelRecoveredEvictions(ctx context.Context, plan *api_v1alpha1.RebalancePlan, nodesByName map[string]*core.Node, degradedNodes map[string]*core.Node) error {
+	// nodes that no longer exist are left to the re-validation of each eviction; only the evictions evacuating degraded
+	// nodes are cancelled
+	recovered := func(planned api_v1alpha1.PlannedEviction) bool {
+		if !evacuatesDegradedNode(planned) {
+			return false
+		}
+		_, exists := nodesByName[planned.Node]
+		_, degraded := degradedNodes[planned.Node]
+		return exists && !degraded
+	}
+	avoided := map[string]int{}
+	message := func(nodeName string) string {
+		return fmt.Sprintf("cancelled as node %s recovered", nodeName)
+	}
+
+	var retries []evictionRetry
+	if r.evictionRetries != nil {
+		retries = r.evictionRetries.cancel(func(item evictionRetry) bool {
+			return recovered(item.planned)
+		})
+	}
+	// the plan being applied is updated below, the others right away
+	applying := plan != nil && plan.Status.Phase == api_v1alpha1.RebalancePlanApplying
+	var planRetries []evictionRetry
+	for _, item := range retries {
+		avoided[item.planned.Node]++
+		if r.pdbBlocks != nil {
+			r.pdbBlocks.forget(item.planned.UID)
+		}
+		if applying && item.plan == plan.Name {
+			planRetries = append(planRetries, item)
+			continue
+		}
+		if err := r.recordRetryOutcome(ctx, item, api_v1alpha1.PlannedEvictionSkipped, message(item.planned.Node)); err != nil {
+			r.Log.Error(err, "failed to record cancelled eviction retry on rebalance plan", "plan", item.plan, "pod", item.planned.Pod)
+		}
+	}
+
+	if applying {
+		done := len(plan.Status.Results)
+		var cancelled, kept []api_v1alpha1.PlannedEviction
+		for _, planned := range plan.Spec.Evictions[done:] {
+			if recovered(planned) {
+				cancelled = append(cancelled, planned)
+			} else {
+				kept = append(kept, planned)
+			}
+		}
+
+		// results are recorded in plan order, so the cancelled evictions are moved ahead of the pending ones
+		if len(cancelled) > 0 {
+			patch := client.MergeFrom(plan.DeepCopy())
+			evictions := append(append(append([]api_v1alpha1.PlannedEviction{}, plan.Spec.Evictions[:done]...), cancelled...), kept...)
+			plan.Spec.Evictions = evictions
+			if err := r.Patch(ctx, plan, patch); err != nil {
+				return fmt.Errorf("failed to cancel evictions of rebalance plan %s: %w", plan.Name, err)
+			}
+		}
+
+		if len(cancelled) > 0 || len(planRetries) > 0 {
+			patch := client.MergeFrom(plan.DeepCopy())
+			for _, planned := range cancelled {
+				avoided[planned.Node]++
+				if r.pdbBlocks != nil {
+					r.pdbBlocks.forget(planned.UID)
+				}
+				plan.Status.Results = append(plan.Status.Results, api_v1alpha1.PlannedEvictionResult{
+					Pod:       planned.Pod,
+					Namespace: planned.Namespace,
+					Outcome:   api_v1alpha1.PlannedEvictionSkipped,
+					Message:   message(planned.Node),
+					Time:      meta.Now(),
+				})
+			}
+			for _, item := range planRetries {
+				if item.index >= len(plan.Status.Results) || plan.Status.Results[item.index].Outcome != api_v1alpha1.PlannedEvictionRetrying {
+					continue
+				}
+				result := &plan.Status.Results[item.index]
+				result.Outcome, result.Message, result.Time = api_v1alpha1.PlannedEvictionSkipped, message(item.planned.Node), meta.Now()
+			}
+			if err := r.Status().Patch(ctx, plan, patch); err != nil {
+				return fmt.Errorf("failed to record cancelled evictions on rebalance plan %s: %w", plan.Name, err)
+			}
+		}
+	}
+
+	nodeNames := make([]string, 0, len(avoided))
+	for nodeName := range avoided {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	for _, nodeName := range nodeNames {
+		r.Log.Info("node recovered, cancelled its remaining evictions", "node", nodeName, "cancelled", avoided[nodeName])
+		r.Recorder.Eventf(nodesByName[nodeName], core.EventTypeNormal, "EvictionsCancelled", "Node %s recovered, %d planned evictions cancelled", nodeName, avoided[nodeName])
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// annotation to be used on a pod's owner to hold back the eviction of its other pods until a replacement for the pod
+// evicted at the given time is scheduled and Ready on a healthy node
+const AwaitingReplacementAnnotation = "kube-balance.io/awaiting-replacement-since"
+
+// annotates the pod's owner with the time one of its pods was evicted, so that its other pods are only evicted once a
+// replacement is Ready
+func (r *PodRebalancer) setOwnerAwaitingReplacement(ctx context.Context, owner client.Object, since time.Time) {
+	patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
+	annotations := owner.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AwaitingReplacementAnnotation] = since.Format(time.RFC3339)
+	owner.SetAnnotations(annotations)
+	if err := r.Patch(ctx, owner, patch); err != nil {
+		r.Log.Error(err, "failed to add awaiting replacement annotation to the pod owner", "owner", owner.GetName(), "namespace", owner.GetNamespace())
+		return
+	}
+	r.Log.V(1).Info("added awaiting replacement annotation to pod owner", "owner", owner.GetName(), "since", since.Format(time.RFC3339))
+}
+
+// removes the awaiting replacement annotation from the pod's owner, if any
+func (r *PodRebalancer) clearOwnerAwaitingReplacement(ctx context.Context, owner client.Object) {
+	if _, ok := owner.GetAnnotations()[AwaitingReplacementAnnotation]; !ok {
+		return
+	}
+	patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
+	annotations := owner.GetAnnotations()
+	delete(annotations, AwaitingReplacementAnnotation)
+	owner.SetAnnotations(annotations)
+	if err := r.Patch(ctx, owner, patch); err != nil {
+		r.Log.Error(err, "failed to remove awaiting replacement annotation from the pod owner", "owner", owner.GetName(), "namespace", owner.GetNamespace())
+	}
+}
+
+// reports whether the eviction of an owner's pods is held back until a replacement for its last evicted pod is Ready,
+// along with the time that pod was evicted; the owner is left untouched, its annotation being replaced or removed only
+// once another of its pods is evicted, so that checking candidates that may never be evicted changes nothing
+func (r *PodRebalancer) awaitingReplacement(owner client.Object, pods []core.Pod, nodesByName map[string]*core.Node, now time.Time) (bool, time.Time) {
+	sinceStr, ok := owner.GetAnnotations()[AwaitingReplacementAnnotation]
+	if !ok {
+		return false, time.Time{}
+	}
+	since, err := time.Parse(time.RFC3339, sinceStr)
+	if err != nil {
+		r.Log.Error(err, "invalid awaiting replacement annotation on pod owner, ignoring it", "owner", owner.GetName(), "namespace", owner.GetNamespace())
+		return false, time.Time{}
+	}
+
+	if r.replacementReady(owner, since, pods, nodesByName, now) {
+		r.Log.V(1).Info("replacement pod of owner is ready", "owner", owner.GetName(), "namespace", owner.GetNamespace())
+		return false, since
+	}
+	// a replacement that can't be scheduled, e.g. for lack of healthy capacity, mustn't hold back the owner for good
+	if r.WaitForRescheduleTimeout > 0 && now.Sub(since) >= r.WaitForRescheduleTimeout {
+		r.Log.Info("timed out waiting for a replacement pod of owner", "owner", owner.GetName(), "namespace", owner.GetNamespace(), "since", since.Format(time.RFC3339))
+		r.Recorder.Eventf(owner, core.EventTypeWarning, "ReplacementTimedOut", "No replacement pod of %s became Ready on a healthy node within %s of the last eviction", owner.GetName(), r.WaitForRescheduleTimeout)
+		return false, since
+	}
+	return true, since
+}
+
+// reports whether any of the owner's pods created since the eviction is scheduled and Ready on a node that isn't degraded
+func (r *PodRebalancer) replacementReady(owner client.Object, since time.Time, pods []core.Pod, nodesByName map[string]*core.Node, now time.Time) bool {
+	selector := ownerSelector(owner)
+	if selector == nil {
+		return false
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Namespace != owner.GetNamespace() || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if pod.DeletionTimestamp != nil || pod.CreationTimestamp.Time.Before(since) || !podReady(pod) {
+			continue
+		}
+		node, ok := nodesByName[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		if degraded, _ := r.DegradationClassifier.IsDegraded(node, now); !degraded {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the selector of the pods managed by an owner; nil for owners of a kind without one or with an invalid selector
+func ownerSelector(owner client.Object) labels.Selector {
+	var selector *meta.LabelSelector
+	switch o := owner.(type) {
+	case *apps.Deployment:
+		selector = o.Spec.Selector
+	case *apps.StatefulSet:
+		selector = o.Spec.Selector
+	case *apps.ReplicaSet:
+		selector = o.Spec.Selector
+	}
+	if selector == nil {
+		return nil
+	}
+	s, err := meta.LabelSelectorAsSelector(selector)
+	if err != nil || s.Empty() {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+)
+
+// number of drifting workloads listed on a profile's status
+const maxReportedWorkloadDrifts = 10
+
+// accumulates, for a single pass over the pods, how far the requests of each profile's pods drift from its recommendation
+type resourceDriftReport struct {
+	// relative deviation from the recommendation beyond which requests are drifting
+	tolerance float64
+	// drift by profile key
+	drifts map[string]*api_v1.ResourceDrift
+	// drifting pods by profile name, resource and direction, published as metrics
+	counts map[string]map[core.ResourceName]map[string]int
+	// workloads already reported, by profile key and then by namespace, kind and name
+	seen map[string]map[string]bool
+}
+
+// creates an empty report flagging requests deviating from the recommendation by more than the tolerance
+func newResourceDriftReport(tolerance float64) *resourceDriftReport {
+	return &resourceDriftReport{
+		tolerance: tolerance,
+		drifts:    map[string]*api_v1.ResourceDrift{},
+		counts:    map[string]map[core.ResourceName]map[string]int{},
+		seen:      map[string]map[string]bool{},
+	}
+}
+
+// compares a governed pod's requests against its profile's recommendation
+func (d *resourceDriftReport) observe(pod *core.Pod, profile *api_v1.WorkloadProfile) {
+	key := profiles.Key(*profile)
+	workload := api_v1.WorkloadDrift{}
+	under, over := false, false
+	for _, resourceName := range []core.ResourceName{core.ResourceCPU, core.ResourceMemory} {
+		// containers the profile recommends nothing for are left out of the comparison
+		requested, recommended, ok := recommendedRequest(pod.Spec.Containers, resourceName, &profile.Spec.Resources)
+		if !ok || recommended.IsZero() {
+			continue
+		}
+		drift := float64(requested.MilliValue()-recommended.MilliValue()) / float64(recommended.MilliValue())
+		if math.Abs(drift) <= d.tolerance {
+			continue
+		}
+
+		direction := metrics.DriftDirectionOver
+		if drift < 0 {
+			direction = metrics.DriftDirectionUnder
+			under = true
+		} else {
+			over = true
+		}
+		d.count(profile.Name, resourceName, direction)
+
+		percent := int32(math.Round(drift * 100))
+		switch resourceName {
+		case core.ResourceCPU:
+			workload.CPU, workload.CPUDriftPercent = &requested, &percent
+		case core.ResourceMemory:
+			workload.Memory, workload.MemoryDriftPercent = &requested, &percent
+		}
+	}
+	if !under && !over {
+		return
+	}
+
+	drift := d.drifts[key]
+	if drift == nil {
+		drift = &api_v1.ResourceDrift{}
+		d.drifts[key] = drift
+	}
+	if under {
+		drift.UnderProvisionedPods++
+	}
+	if over {
+		drift.OverProvisionedPods++
+	}
+
+	// the pods of a workload share a template, so the first one observed stands for the workload
+	workload.Kind, workload.Name = podWorkload(pod)
+	workload.Namespace = pod.Namespace
+	workloadKey := workload.Namespace + "/" + workload.Kind + "/" + workload.Name
+	if d.seen[key] == nil {
+		d.seen[key] = map[string]bool{}
+	}
+	if !d.seen[key][workloadKey] {
+		d.seen[key][workloadKey] = true
+		drift.Workloads = append(drift.Workloads, workload)
+	}
+}
+
+// counts a drifting pod towards the metrics
+func (d *resourceDriftReport) count(profileName string, resourceName core.ResourceName, direction string) {
+	if d.counts[profileName] == nil {
+		d.counts[profileName] = map[core.ResourceName]map[string]int{}
+	}
+	if d.counts[profileName][resourceName] == nil {
+		d.counts[profileName][resourceName] = map[string]int{}
+	}
+	d.counts[profileName][resourceName][direction]++
+}
+
+// returns the drift to publish on a profile's status, listing the workloads drifting the furthest first; profiles
+// recommending no resources report none
+func (d *resourceDriftReport) status(profile *api_v1.WorkloadProfile) *api_v1.ResourceDrift {
+	if profile.Spec.Resources.IsEmpty() {
+		return nil
+	}
+	drift, ok := d.drifts[profiles.Key(*profile)]
+	if !ok {
+		return &api_v1.ResourceDrift{}
+	}
+
+	drift = drift.DeepCopy()
+	sort.SliceStable(drift.Workloads, func(i int, j int) bool {
+		driftA, driftB := workloadDriftMagnitude(&drift.Workloads[i]), workloadDriftMagnitude(&drift.Workloads[j])
+		if driftA != driftB {
+			return driftA > driftB
+		}
+		a, b := drift.Workloads[i], drift.Workloads[j]
+		return a.Namespace+"/"+a.Kind+"/"+a.Name < b.Namespace+"/"+b.Kind+"/"+b.Name
+	})
+	if len(drift.Workloads) > maxReportedWorkloadDrifts {
+		drift.Workloads = drift.Workloads[:maxReportedWorkloadDrifts]
+	}
+	return drift
+}
+
+// publishes the drifting pod counts of every profile, dropping those of profiles no longer drifting
+func (d *resourceDriftReport) publishMetrics() {
+	metrics.ProfileDriftedPods.Reset()
+	for profileName, byResource := range d.counts {
+		for resourceName, byDirection := range byResource {
+			for direction, count := range byDirection {
+				metrics.ProfileDriftedPods.WithLabelValues(profileName, string(resourceName), direction).Set(float64(count))
+			}
+		}
+	}
+}
+
+// returns the largest deviation of a workload's requests from the recommendation, in percent
+func workloadDriftMagnitude(workload *api_v1.WorkloadDrift) int32 {
+	var magnitude int32
+	for _, percent := range []*int32{workload.CPUDriftPercent, workload.MemoryDriftPercent} {
+		if percent == nil {
+			continue
+		}
+		if abs := int32(math.Abs(float64(*percent))); abs > magnitude {
+			magnitude = abs
+		}
+	}
+	return magnitude
+}
+
+// returns the kind and name of the workload a pod belongs to without querying its owner; pods of a Deployment are
+// reported under the Deployment's name, and pods without a controller under their own name
+func podWorkload(pod *core.Pod) (string, string) {
+	kind := profiles.OwnerKind(pod)
+	owner := meta.GetControllerOf(pod)
+	if owner == nil {
+		return kind, pod.Name
+	}
+	if kind == profiles.OwnerKindDeployment {
+		return kind, strings.TrimSuffix(owner.Name, "-"+pod.Labels[apps.DefaultDeploymentUniqueLabelKey])
+	}
+	return kind, owner.Name
+}
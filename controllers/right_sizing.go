@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-logr/logr"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+)
+
+// periodically patches the pod templates of Deployments and StatefulSets whose requests deviate from their workload
+// profile's recommendation by more than the threshold, turning the recommendation into the requests the pods run with
+type RightSizer struct {
+	client.Client
+	Log             logr.Logger
+	Recorder        record.EventRecorder
+	ProfilerWatcher *profiles.WorkloadProfileWatcher
+	// relative deviation from the recommendation beyond which a workload's requests are rewritten
+	Threshold float64
+	Interval  time.Duration
+}
+
+// implements the manager.Runnable interface to right-size workloads until the context is cancelled
+func (s *RightSizer) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Log.Info("starting right-sizer", "threshold", s.Threshold, "interval", s.Interval)
+	for {
+		if err := s.rightSize(ctx); err != nil {
+			s.Log.Error(err, "failed to right-size workloads")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolves the Deployments and StatefulSets governed by a profile recommending resources and rewrites the requests of those drifting too far
+func (s *RightSizer) rightSize(ctx context.Context) error {
+	workloadProfiles := s.ProfilerWatcher.GetProfiles()
+	namespacedProfiles := s.ProfilerWatcher.GetNamespacedProfiles()
+
+	podList := &core.PodList{}
+	if err := s.List(ctx, podList); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	nodeList := &core.NodeList{}
+	if err := s.List(ctx, nodeList); err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	nodesByName := make(map[string]*core.Node, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodesByName[nodeList.Items[i].Name] = &nodeList.Items[i]
+	}
+
+	// workloads are resolved from their pods, as profiles govern pods rather than their owners
+	visited := map[types.UID]bool{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		profile, ok := profiles.MatchPodScoped(pod, nodesByName[pod.Spec.NodeName], namespacedProfiles, workloadProfiles)
+		if !ok || profile.Spec.Resources.IsEmpty() {
+			continue
+		}
+		owner, err := getPodOwner(ctx, s, pod)
+		if err != nil {
+			s.Log.Error(err, "failed to get pod owner", "pod", pod.Name, "namespace", pod.Namespace)
+			continue
+		}
+		if owner == nil || visited[owner.GetUID()] {
+			continue
+		}
+		visited[owner.GetUID()] = true
+
+		if err := s.rightSizeOwner(ctx, owner, &profile); err != nil {
+			s.Log.Error(err, "failed to right-size workload", "owner", owner.GetName(), "namespace", owner.GetNamespace(), "profile", profile.Name)
+		}
+	}
+
+	return nil
+}
+
+// rewrites the requests of an owner's pod template to the profile's recommendation when they drift beyond the threshold; patching the template rolls the workload out
+func (s *RightSizer) rightSizeOwner(ctx context.Context, owner client.Object, profile *api_v1.WorkloadProfile) error {
+	var template *core.PodTemplateSpec
+	switch o := owner.(type) {
+	case *apps.Deployment:
+		template = &o.Spec.Template
+	case *apps.StatefulSet:
+		template = &o.Spec.Template
+	default:
+		return nil
+	}
+
+	patch := client.MergeFrom(owner.DeepCopyObject().(client.Object))
+	changed := false
+	for _, resourceName := range []core.ResourceName{core.ResourceCPU, core.ResourceMemory} {
+		if rightSizeResource(template.Spec.Containers, resourceName, &profile.Spec.Resources, s.Threshold) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := s.Patch(ctx, owner, patch); err != nil {
+		return fmt.Errorf("failed to patch pod template requests: %w", err)
+	}
+	s.Log.Info("right-sized workload to its profile's recommendation", "owner", owner.GetName(), "namespace", owner.GetNamespace(), "profile", profile.Name)
+	s.Recorder.Eventf(owner, core.EventTypeNormal, "RightSized", "Pod requests of %s set to the recommendation of workload profile %s", owner.GetName(), profile.Name)
+	return nil
+}
+
+// right-sizes the containers' requests for a resource; containers with a recommendation of their own are right-sized
+// individually, taking on their recommended limit as well, while the rest are scaled together towards the pod-level
+// recommendation
+func rightSizeResource(containers []core.Container, resourceName core.ResourceName, recommendation *api_v1.ResourceRecommendation, threshold float64) bool {
+	changed := false
+	rest := []*core.Container{}
+	for i := range containers {
+		container := &containers[i]
+		recommended, ok := recommendation.ContainerRequest(container.Name, resourceName)
+		if !ok {
+			rest = append(rest, container)
+			continue
+		}
+		if recommended.IsZero() || !rightSizeContainers([]*core.Container{container}, resourceName, recommended, threshold) {
+			continue
+		}
+		if limit, ok := recommendation.ContainerLimit(container.Name, resourceName); ok && limit.Cmp(container.Resources.Requests[resourceName]) >= 0 {
+			if container.Resources.Limits == nil {
+				container.Resources.Limits = core.ResourceList{}
+			}
+			container.Resources.Limits[resourceName] = limit.DeepCopy()
+		}
+		changed = true
+	}
+
+	if recommended := recommendation.PodRequest(resourceName); recommended != nil && !recommended.IsZero() {
+		if rightSizeContainers(rest, resourceName, *recommended, threshold) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// scales the containers' requests for a resource so that they add up to the recommendation, when they deviate from it
+// by more than the threshold; the split between containers is kept, and containers whose limit equalled their request
+// or would fall below it have the limit moved along
+func rightSizeContainers(containers []*core.Container, resourceName core.ResourceName, recommended resource.Quantity, threshold float64) bool {
+	if len(containers) == 0 {
+		return false
+	}
+
+	var total int64
+	for _, container := range containers {
+		if request, ok := container.Resources.Requests[resourceName]; ok {
+			total += request.MilliValue()
+		}
+	}
+	drift := float64(total-recommended.MilliValue()) / float64(recommended.MilliValue())
+	if math.Abs(drift) <= threshold {
+		return false
+	}
+
+	for i, container := range containers {
+		var milli int64
+		switch {
+		case total > 0:
+			request := container.Resources.Requests[resourceName]
+			milli = int64(math.Round(float64(request.MilliValue()) / float64(total) * float64(recommended.MilliValue())))
+		case i == 0:
+			// without any declared request, the first container carries the whole recommendation
+			milli = recommended.MilliValue()
+		default:
+			continue
+		}
+		if milli == 0 {
+			continue
+		}
+
+		request := *resource.NewMilliQuantity(milli, recommended.Format)
+		if resourceName == core.ResourceMemory {
+			request = *resource.NewQuantity(int64(math.Ceil(float64(milli)/1000)), recommended.Format)
+		}
+
+		old, hadRequest := container.Resources.Requests[resourceName]
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = core.ResourceList{}
+		}
+		container.Resources.Requests[resourceName] = request
+		if limit, ok := container.Resources.Limits[resourceName]; ok && ((hadRequest && limit.Cmp(old) == 0) || limit.Cmp(request) < 0) {
+			container.Resources.Limits[resourceName] = request
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// returns a container declaring the given request and limit for a resource, leaving out the empty ones
+func sizedContainer(name string, resourceName core.ResourceName, request string, limit string) *core.Container {
+	container := &core.Container{Name: name}
+	if request != "" {
+		container.Resources.Requests = core.ResourceList{resourceName: resource.MustParse(request)}
+	}
+	if limit != "" {
+		container.Resources.Limits = core.ResourceList{resourceName: resource.MustParse(limit)}
+	}
+	return container
+}
+
+func TestRightSizeContainers(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceName core.ResourceName
+		containers   []*core.Container
+		recommended  string
+		want         []*core.Container
+		wantChanged  bool
+	}{
+		{
+			name:         "within the threshold",
+			resourceName: core.ResourceCPU,
+			containers:   []*core.Container{sizedContainer("app", core.ResourceCPU, "500m", "")},
+			recommended:  "520m",
+			want:         []*core.Container{sizedContainer("app", core.ResourceCPU, "500m", "")},
+		},
+		{
+			name:         "split between containers kept",
+			resourceName: core.ResourceCPU,
+			containers:   []*core.Container{sizedContainer("app", core.ResourceCPU, "300m", ""), sizedContainer("proxy", core.ResourceCPU, "100m", "")},
+			recommended:  "800m",
+			want:         []*core.Container{sizedContainer("app", core.ResourceCPU, "600m", ""), sizedContainer("proxy", core.ResourceCPU, "200m", "")},
+			wantChanged:  true,
+		},
+		{
+			name:         "no declared requests",
+			resourceName: core.ResourceCPU,
+			containers:   []*core.Container{sizedContainer("app", core.ResourceCPU, "", ""), sizedContainer("proxy", core.ResourceCPU, "", "")},
+			recommended:  "800m",
+			want:         []*core.Container{sizedContainer("app", core.ResourceCPU, "800m", ""), sizedContainer("proxy", core.ResourceCPU, "", "")},
+			wantChanged:  true,
+		},
+		{
+			name:         "limit equal to the request moved along",
+			resourceName: core.ResourceCPU,
+			containers:   []*core.Container{sizedContainer("app", core.ResourceCPU, "1", "1")},
+			recommended:  "500m",
+			want:         []*core.Container{sizedContainer("app", core.ResourceCPU, "500m", "500m")},
+			wantChanged:  true,
+		},
+		{
+			name:         "limit above the new request kept",
+			resourceName: core.ResourceCPU,
+			containers:   []*core.Container{sizedContainer("app", core.ResourceCPU, "100m", "2")},
+			recommended:  "200m",
+			want:         []*core.Container{sizedContainer("app", core.ResourceCPU, "200m", "2")},
+			wantChanged:  true,
+		},
+		{
+			name:         "limit below the new request raised",
+			resourceName: core.ResourceCPU,
+			containers:   []*core.Container{sizedContainer("app", core.ResourceCPU, "100m", "150m")},
+			recommended:  "300m",
+			want:         []*core.Container{sizedContainer("app", core.ResourceCPU, "300m", "300m")},
+			wantChanged:  true,
+		},
+		{
+			name:         "memory",
+			resourceName: core.ResourceMemory,
+			containers:   []*core.Container{sizedContainer("app", core.ResourceMemory, "768Mi", ""), sizedContainer("proxy", core.ResourceMemory, "256Mi", "")},
+			recommended:  "2Gi",
+			want:         []*core.Container{sizedContainer("app", core.ResourceMemory, "1536Mi", ""), sizedContainer("proxy", core.ResourceMemory, "512Mi", "")},
+			wantChanged:  true,
+		},
+		{
+			name:         "no containers",
+			resourceName: core.ResourceCPU,
+			recommended:  "500m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rightSizeContainers(tt.containers, tt.resourceName, resource.MustParse(tt.recommended), 0.1); got != tt.wantChanged {
+				t.Errorf("rightSizeContainers() = %v, want %v", got, tt.wantChanged)
+			}
+			for i, want := range tt.want {
+				got := tt.containers[i]
+				for _, list := range []struct {
+					name      string
+					got, want core.ResourceList
+				}{
+					{"request", got.Resources.Requests, want.Resources.Requests},
+					{"limit", got.Resources.Limits, want.Resources.Limits},
+				} {
+					gotQuantity, gotOK := list.got[tt.resourceName]
+					wantQuantity, wantOK := list.want[tt.resourceName]
+					if gotOK != wantOK || gotQuantity.Cmp(wantQuantity) != 0 {
+						t.Errorf("container %s %s = %s, want %s", got.Name, list.name, gotQuantity.String(), wantQuantity.String())
+					}
+				}
+			}
+		})
+	}
+}
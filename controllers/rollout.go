@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups="argoproj.io",resources=rollouts,verbs=get
+
+// kind of the Argo Rollouts managing their ReplicaSets in place of a Deployment, read as unstructured objects as there is
+// no typed client for them
+var argoRolloutGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+
+// reason a Deployment's rollout stalled past its progress deadline, after which it is no longer waited for
+const deploymentProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// reports whether the pod's owner is rolling out a new revision, along with what is still rolling out for its events;
+// Deployments and StatefulSets are checked through their updated replicas and revisions, and ReplicaSets managed by an
+// Argo Rollout through the Rollout's phase
+func (r *PodRebalancer) rolloutInProgress(ctx context.Context, pod *core.Pod, owner client.Object) (bool, string, error) {
+	switch owner := owner.(type) {
+	case *apps.Deployment:
+		progressing, what := deploymentRollingOut(owner)
+		return progressing, what, nil
+	case *apps.StatefulSet:
+		progressing, what := statefulSetRollingOut(owner)
+		return progressing, what, nil
+	case *apps.ReplicaSet:
+		rolloutRef := meta.GetControllerOf(owner)
+		if rolloutRef == nil || rolloutRef.Kind != argoRolloutGVK.Kind || rolloutRef.APIVersion != argoRolloutGVK.GroupVersion().String() {
+			return false, "", nil
+		}
+		return r.argoRolloutInProgress(ctx, pod.Namespace, rolloutRef.Name)
+	}
+	return false, "", nil
+}
+
+// reports whether a Deployment is rolling out a new revision; unlike `kubectl rollout status`, replicas that are merely
+// unavailable, as they are on a degraded node, don't count, and a paused rollout, or one stalled past its progress
+// deadline, isn't waited for as it may never complete
+func deploymentRollingOut(deploy *apps.Deployment) (bool, string) {
+	if deploy.Spec.Paused {
+		return false, ""
+	}
+	for _, condition := range deploy.Status.Conditions {
+		if condition.Type == apps.DeploymentProgressing && condition.Reason == deploymentProgressDeadlineExceeded {
+			return false, ""
+		}
+	}
+	replicas := int32(1)
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	status := deploy.Status
+	switch {
+	case status.ObservedGeneration < deploy.Generation:
+		return true, fmt.Sprintf("Deployment %s has a spec change not yet rolled out", deploy.Name)
+	case status.UpdatedReplicas < replicas:
+		return true, fmt.Sprintf("Deployment %s is rolling out, %d of %d replicas updated", deploy.Name, status.UpdatedReplicas, replicas)
+	case status.Replicas > status.UpdatedReplicas:
+		return true, fmt.Sprintf("Deployment %s is rolling out, %d old replicas pending termination", deploy.Name, status.Replicas-status.UpdatedReplicas)
+	}
+	return false, ""
+}
+
+// reports whether a StatefulSet is rolling out a new revision; StatefulSets updated on delete never roll out on their own
+func statefulSetRollingOut(ss *apps.StatefulSet) (bool, string) {
+	if ss.Spec.UpdateStrategy.Type == apps.OnDeleteStatefulSetStrategyType {
+		return false, ""
+	}
+	replicas := int32(1)
+	if ss.Spec.Replicas != nil {
+		replicas = *ss.Spec.Replicas
+	}
+	status := ss.Status
+	if status.ObservedGeneration < ss.Generation {
+		return true, fmt.Sprintf("StatefulSet %s has a spec change not yet rolled out", ss.Name)
+	}
+	// a partitioned rolling update only updates the ordinals from the partition up
+	if rollingUpdate := ss.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		if updated := replicas - *rollingUpdate.Partition; status.UpdatedReplicas < updated {
+			return true, fmt.Sprintf("StatefulSet %s is rolling out, %d of %d replicas updated", ss.Name, status.UpdatedReplicas, updated)
+		}
+		return false, ""
+	}
+	if status.UpdateRevision != status.CurrentRevision {
+		return true, fmt.Sprintf("StatefulSet %s is rolling out, %d of %d replicas updated", ss.Name, status.UpdatedReplicas, replicas)
+	}
+	return false, ""
+}
+
+// reports whether an Argo Rollout is progressing through, or paused in the middle of, a rollout; clusters without Argo
+// Rollouts installed have none in progress
+func (r *PodRebalancer) argoRolloutInProgress(ctx context.Context, namespace string, name string) (bool, string, error) {
+	rollout := &unstructured.Unstructured{}
+	rollout.SetGroupVersionKind(argoRolloutGVK)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, rollout); err != nil {
+		if errors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("failed to get Argo Rollout %s: %w", name, err)
+	}
+
+	phase, _, _ := unstructured.NestedString(rollout.Object, "status", "phase")
+	if phase == "Progressing" || phase == "Paused" {
+		return true, fmt.Sprintf("Argo Rollout %s is %s", name, phase), nil
+	}
+	return false, "", nil
+}
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// node an evicted pod may be rescheduled onto, with the room left on it as planned evictions are assigned to it
+type schedulingTarget struct {
+	node *core.Node
+	// allocatable resources not yet requested by the node's pods, including the pod count
+	free core.ResourceList
+}
+
+// returns the nodes evicted pods may be rescheduled onto, the Ready and schedulable nodes that aren't degraded, along
+// with the room left on each of them
+func schedulingTargets(nodes []core.Node, pods []core.Pod, degradedNodes map[string]*core.Node) []*schedulingTarget {
+	targets := map[string]*schedulingTarget{}
+	for i := range nodes {
+		node := &nodes[i]
+		if _, degraded := degradedNodes[node.Name]; degraded || node.Spec.Unschedulable || !nodeReady(node) {
+			continue
+		}
+		targets[node.Name] = &schedulingTarget{node: node, free: node.Status.Allocatable.DeepCopy()}
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		target, ok := targets[pod.Spec.NodeName]
+		if !ok || pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		target.reserve(pod)
+	}
+
+	sorted := make([]*schedulingTarget, 0, len(targets))
+	for _, target := range targets {
+		sorted = append(sorted, target)
+	}
+	sort.Slice(sorted, func(i int, j int) bool {
+		return sorted[i].node.Name < sorted[j].node.Name
+	})
+	return sorted
+}
+
+// returns the first node other than its own a pod could be rescheduled onto, reserving room for it there, or nil when
+// the pod would be left Pending; inter-pod affinities and topology spread constraints aren't simulated
+func feasibleTarget(pod *core.Pod, targets []*schedulingTarget) *schedulingTarget {
+	for _, target := range targets {
+		if target.node.Name != pod.Spec.NodeName && target.fits(pod) {
+			target.reserve(pod)
+			return target
+		}
+	}
+	return nil
+}
+
+// reports whether a pod could be scheduled onto the target, given its node selector, required node affinity,
+// tolerations and resource requests
+func (t *schedulingTarget) fits(pod *core.Pod) bool {
+	if !nodeMatchesPodAffinity(t.node, pod) || !toleratesNodeTaints(t.node, pod) {
+		return false
+	}
+	for resourceName, request := range podSchedulingRequests(pod) {
+		free, ok := t.free[resourceName]
+		if !ok || free.Cmp(request) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// subtracts a pod's requests from the room left on the target
+func (t *schedulingTarget) reserve(pod *core.Pod) {
+	for resourceName, request := range podSchedulingRequests(pod) {
+		if free, ok := t.free[resourceName]; ok {
+			free.Sub(request)
+			t.free[resourceName] = free
+		}
+	}
+}
+
+// gives back the room reserved for a pod on the target, once a later check leaves the pod in place
+func (t *schedulingTarget) release(pod *core.Pod) {
+	for resourceName, request := range podSchedulingRequests(pod) {
+		if free, ok := t.free[resourceName]; ok {
+			free.Add(request)
+			t.free[resourceName] = free
+		}
+	}
+}
+
+// returns the resources the scheduler reserves for a pod: the larger of its containers' total requests and of any single
+// init container's, plus its overhead and a slot in the node's pod count
+func podSchedulingRequests(pod *core.Pod) core.ResourceList {
+	requests := core.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		for resourceName, request := range container.Resources.Requests {
+			total := requests[resourceName]
+			total.Add(request)
+			requests[resourceName] = total
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for resourceName, request := range container.Resources.Requests {
+			if total, ok := requests[resourceName]; !ok || request.Cmp(total) > 0 {
+				requests[resourceName] = request.DeepCopy()
+			}
+		}
+	}
+	for resourceName, overhead := range pod.Spec.Overhead {
+		total := requests[resourceName]
+		total.Add(overhead)
+		requests[resourceName] = total
+	}
+	requests[core.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
+	return requests
+}
+
+// reports whether a node satisfies a pod's node selector and required node affinity
+func nodeMatchesPodAffinity(node *core.Node, pod *core.Pod) bool {
+	for key, value := range pod.Spec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		return nodeMatchesSelector(node, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+	}
+	return true
+}
+
+// reports whether a node is Ready
+func nodeReady(node *core.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == core.NodeReady {
+			return condition.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
+
+// reports whether a pod tolerates the node's NoSchedule and NoExecute taints
+func toleratesNodeTaints(node *core.Node, pod *core.Pod) bool {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != core.TaintEffectNoSchedule && taint.Effect != core.TaintEffectNoExecute {
+			continue
+		}
+		if !toleratesTaint(pod.Spec.Tolerations, taint) {
+			return false
+		}
+	}
+	return true
+}
+
+// reports whether any of the tolerations tolerates the taint
+func toleratesTaint(tolerations []core.Toleration, taint *core.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// reports whether a node matches any of the selector's terms, as the scheduler does for a required node affinity; a term
+// without requirements matches no node
+func nodeMatchesSelector(node *core.Node, selector *core.NodeSelector) bool {
+	for _, term := range selector.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		matches := true
+		for _, requirement := range term.MatchExpressions {
+			value, ok := node.Labels[requirement.Key]
+			if !requirementMatches(requirement, value, ok) {
+				matches = false
+				break
+			}
+		}
+		for _, requirement := range term.MatchFields {
+			// metadata.name is the only field supported by the scheduler
+			if !matches || requirement.Key != "metadata.name" || !requirementMatches(requirement, node.Name, true) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// reports whether a node selector requirement holds for a label value, ok telling whether the label is set at all
+func requirementMatches(requirement core.NodeSelectorRequirement, value string, ok bool) bool {
+	switch requirement.Operator {
+	case core.NodeSelectorOpIn:
+		return ok && slices.Contains(requirement.Values, value)
+	case core.NodeSelectorOpNotIn:
+		return !ok || !slices.Contains(requirement.Values, value)
+	case core.NodeSelectorOpExists:
+		return ok
+	case core.NodeSelectorOpDoesNotExist:
+		return !ok
+	case core.NodeSelectorOpGt, core.NodeSelectorOpLt:
+		if !ok || len(requirement.Values) != 1 {
+			return false
+		}
+		actual, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		bound, err := strconv.ParseInt(requirement.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if requirement.Operator == core.NodeSelectorOpGt {
+			return actual > bound
+		}
+		return actual < bound
+	}
+	return false
+}
+
+// describes why a pod fits on none of the scheduling targets, for its skip event
+func noFeasibleTargetReason(targets []*schedulingTarget) string {
+	if len(targets) == 0 {
+		return "no Ready, schedulable node that isn't degraded is left"
+	}
+	return fmt.Sprintf("none of the %d nodes that aren't degraded satisfies its requests, node selector, node affinity or tolerations", len(targets))
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+)
+
+// why a candidate is left in place in a cycle
+type podSkip struct {
+	// reason counted in the PodsSkipped metric; skips without one are only logged
+	reason string
+	// event reported on the pod
+	eventType string
+	event     string
+	message   string
+	// owner told about the skip as well, along with its event
+	owner        client.Object
+	ownerEvent   string
+	ownerMessage string
+	// when the pod is worth checking again, shortening the requeue delay, if known
+	retryAt time.Time
+}
+
+// remembers the reasons each pod was reported skipped for, so that a pod left in place cycle after cycle is only
+// reported once per reason
+type skipReporter struct {
+	mu       sync.Mutex
+	reported map[types.UID]map[string]bool
+}
+
+// creates a new skipReporter instance
+func newSkipReporter() *skipReporter {
+	return &skipReporter{
+		reported: make(map[types.UID]map[string]bool),
+	}
+}
+
+// reports whether a pod's skip for the given reason is new, recording it
+func (t *skipReporter) first(uid types.UID, reason string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.reported[uid][reason] {
+		return false
+	}
+	if t.reported[uid] == nil {
+		t.reported[uid] = map[string]bool{}
+	}
+	t.reported[uid][reason] = true
+	return true
+}
+
+// forgets the skips of a pod planned for eviction, so that they are reported again should it be left in place later
+func (t *skipReporter) forget(uid types.UID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.reported, uid)
+}
+
+// forgets the pods missing from the given set, which no longer exist
+func (t *skipReporter) retain(uids map[types.UID]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for uid := range t.reported {
+		if !uids[uid] {
+			delete(t.reported, uid)
+		}
+	}
+}
+
+// reports a pod left in place with an event and in the PodsSkipped metric, once per pod and reason
+func (r *PodRebalancer) reportSkip(pod *core.Pod, profileName string, skip *podSkip) {
+	if skip.reason == "" || !r.skipReports.first(pod.UID, skip.reason) {
+		return
+	}
+	if skip.event != "" {
+		r.Recorder.Event(pod, skip.eventType, skip.event, skip.message)
+	}
+	if skip.owner != nil && skip.ownerEvent != "" {
+		r.Recorder.Event(skip.owner, core.EventTypeWarning, skip.ownerEvent, skip.ownerMessage)
+	}
+	metrics.PodsSkipped.WithLabelValues(skip.reason, profileName).Inc()
+}
+
+// returns a skip counted under the given reason and reported with an EvictionSkipped event
+func evictionSkipped(reason string, message string) *podSkip {
+	return &podSkip{reason: reason, eventType: core.EventTypeNormal, event: "EvictionSkipped", message: message}
+}
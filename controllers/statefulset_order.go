@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// returns the UID of the StatefulSet controlling a pod and the pod's ordinal, false for pods of other owners or
+// without a parseable ordinal
+func statefulSetOrdinal(pod *core.Pod) (types.UID, int, bool) {
+	owner := meta.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" {
+		return "", 0, false
+	}
+
+	index, ok := pod.Labels[apps.PodIndexLabel]
+	if !ok {
+		index = pod.Name[strings.LastIndex(pod.Name, "-")+1:]
+	}
+	ordinal, err := strconv.Atoi(index)
+	if err != nil {
+		return "", 0, false
+	}
+	return owner.UID, ordinal, true
+}
+
+// reports whether a pod's owner is a StatefulSet
+func isStatefulSet(owner client.Object) bool {
+	_, ok := owner.(*apps.StatefulSet)
+	return ok
+}
+
+// returns, for every StatefulSet with pods on the degraded nodes, the highest ordinal among those pods; StatefulSet
+// pods are evicted one at a time from the highest ordinal down, matching StatefulSet update semantics, so only the pod
+// holding it may be evicted; terminating pods, critical pods and pods opting out of eviction don't hold back the others
+func (r *PodRebalancer) highestDegradedOrdinals(pods []core.Pod, degradedNodes map[string]*core.Node) map[types.UID]int {
+	highest := map[types.UID]int{}
+	for i := range pods {
+		pod := &pods[i]
+		if _, ok := degradedNodes[pod.Spec.NodeName]; !ok || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if pod.Status.Phase != core.PodRunning && pod.Status.Phase != core.PodPending {
+			continue
+		}
+		if doNotEvictReason(pod) != "" || r.criticalPodReason(pod) != "" {
+			continue
+		}
+		uid, ordinal, ok := statefulSetOrdinal(pod)
+		if !ok {
+			continue
+		}
+		if current, seen := highest[uid]; !seen || ordinal > current {
+			highest[uid] = ordinal
+		}
+	}
+	return highest
+}
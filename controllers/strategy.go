@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// selects the pods a rebalancing concern wants evicted, similar to a descheduler profile; the controller merges the
+// candidates of every strategy, drops duplicates and applies its eviction budgets and safety checks before planning them
+type rebalanceStrategy interface {
+	// name identifying the strategy in logs
+	name() string
+	// returns the pods the strategy wants evicted, those of each node in the order they should be evicted
+	candidates(ctx context.Context, state *rebalanceState) []evictionCandidate
+	// returns why an eviction the strategy planned no longer applies to the pod's node, e.g. as the node recovered, or
+	// an empty string while it still does
+	revalidate(node *core.Node, now time.Time) string
+}
+
+// pod a strategy wants evicted
+type evictionCandidate struct {
+	pod *core.Pod
+	// node the pod is evicted from
+	node         *core.Node
+	profile      api_v1.WorkloadProfile
+	profileFound bool
+	// why the strategy selected the pod, recorded on the planned eviction
+	reason string
+}
+
+// returns the profile governing the candidate's pod, nil when none does
+func (c evictionCandidate) podProfile() *api_v1.WorkloadProfile {
+	if !c.profileFound {
+		return nil
+	}
+	return &c.profile
+}
+
+// cluster state a reconcile cycle plans its evictions from, shared by the strategies
+type rebalanceState struct {
+	log                logr.Logger
+	cfg                rebalanceConfig
+	now                time.Time
+	nodes              []core.Node
+	nodesByName        map[string]*core.Node
+	degradedNodes      map[string]*core.Node
+	degradationKeys    map[string]string
+	pods               []core.Pod
+	namespacedProfiles map[string]map[string]api_v1.WorkloadProfile
+	workloadProfiles   map[string]api_v1.WorkloadProfile
+	// zones with a correlated failure whose evictions are throttled
+	failingZones map[string]zoneDegradation
+	// zones with a correlated failure whose degraded nodes aren't evacuated, but for the urgently degraded ones
+	pausedZones map[string]bool
+	// delay before the next reconcile, shortened by strategies deferring evictions until a later time
+	requeueAfter time.Duration
+	// drains of the nodes drained at once this cycle, whose pods are exempt from the per-node eviction budget, the owner
+	// cooldown and the single eviction per owner
+	drains map[string]*nodeDrainProgress
+	// evictable pods of the nodes that aren't degraded, resolved once for all the strategies
+	evictables map[string]*nodeEvictables
+	// hourly cost of the nodes, by name, when the cost-aware strategy priced them
+	nodeCosts map[string]float64
+}
+
+// evictable pods of a node, along with the profiles governing them
+type nodeEvictables struct {
+	pods        []*core.Pod
+	podProfiles map[*core.Pod]api_v1.WorkloadProfile
+}
+
+// strategies whose candidates are planned, in order; a pod selected by several strategies is planned for the first one
+func (r *PodRebalancer) strategies() []rebalanceStrategy {
+	return []rebalanceStrategy{
+		&degradedNodeStrategy{r: r},
+		&lowNodeUtilizationStrategy{r: r},
+		&highNodeUtilizationStrategy{r: r},
+		&topologySpreadStrategy{r: r},
+		&removeDuplicatesStrategy{r: r},
+		&nodeConstraintViolationStrategy{r: r},
+		&costAwareStrategy{r: r},
+		&postRecoveryStrategy{r: r},
+		&podGroupStrategy{r: r},
+	}
+}
+
+// returns the strategy that planned an eviction; evictions planned before strategies were recorded evacuate degraded nodes
+func (r *PodRebalancer) strategyFor(planned api_v1alpha1.PlannedEviction) rebalanceStrategy {
+	for _, strategy := range r.strategies() {
+		if strategy.name() == planned.Strategy {
+			return strategy
+		}
+	}
+	return &degradedNodeStrategy{r: r}
+}
+
+// reports whether an eviction evacuates a degraded node, the only evictions that may bypass a PodDisruptionBudget or are
+// cancelled once the node recovers
+func evacuatesDegradedNode(planned api_v1alpha1.PlannedEviction) bool {
+	return planned.Strategy == "" || planned.Strategy == DegradedNodeStrategy
+}
+
+// returns the number of pods that may be evicted from a node per cycle
+func (cfg *rebalanceConfig) nodeEvictionBudget(node *core.Node) int {
+	budget := cfg.maxEvictionsPerNodePerCycle
+	if degradation.NodeSeverity(node) == degradation.SeverityUrgent && cfg.urgentMaxEvictionsPerNodePerCycle > budget {
+		budget = cfg.urgentMaxEvictionsPerNodePerCycle
+	}
+	return budget
+}
+
+// plans the evictions of the candidates selected by the strategies, dropping duplicates and the candidates beyond
+// their node's and zone's eviction budgets or failing a safety check; the candidates of different nodes are checked in
+// parallel, before the checks spanning nodes are applied to them in order
+func (r *PodRebalancer) planEvictions(ctx context.Context, state *rebalanceState) []api_v1alpha1.PlannedEviction {
+	log := state.log
+	p, err := r.newCyclePlanner(ctx, state)
+	if err != nil {
+		log.Error(err, "failed to list PodDisruptionBudgets, planning no evictions")
+		return nil
+	}
+
+	var candidates []strategyCandidate
+	selected := map[types.UID]bool{}
+	for _, strategy := range r.strategies() {
+		for _, candidate := range strategy.candidates(ctx, state) {
+			if selected[candidate.pod.UID] {
+				log.V(1).Info("pod already selected by another strategy, skipping pod", "pod", candidate.pod.Name, "namespace", candidate.pod.Namespace, "strategy", strategy.name())
+				continue
+			}
+			selected[candidate.pod.UID] = true
+			candidates = append(candidates, strategyCandidate{evictionCandidate: candidate, strategy: strategy.name()})
+		}
+	}
+	checks := r.checkNodeCandidates(ctx, state, candidates, p.statefulSetOrdinals)
+	// planning the leaders of workloads electing one after their other replicas, to spare them a failover
+	candidates, checks = r.leadersLast(ctx, state, candidates, checks)
+	// simulating evicted pods landing on the cheapest nodes first once the nodes are priced
+	if state.nodeCosts != nil {
+		sort.SliceStable(p.targets, func(i int, j int) bool {
+			costI, okI := state.nodeCosts[p.targets[i].node.Name]
+			costJ, okJ := state.nodeCosts[p.targets[j].node.Name]
+			return okI && (!okJ || costI < costJ)
+		})
+	}
+
+	for i, candidate := range candidates {
+		if checks[i].checked {
+			p.nodeChecks[candidate.pod.UID] = checks[i]
+		}
+	}
+
+	for i, candidate := range candidates {
+		if !checks[i].passed || p.plannedGroupMembers[candidate.pod.UID] {
+			continue
+		}
+		pod, node, owner := candidate.pod, candidate.node, checks[i].owner
+		if group := podGroupName(pod); p.podGroups != nil && group != "" {
+			if skip := p.planGroup(ctx, candidate, owner, group); skip != nil {
+				r.reportSkip(pod, candidate.profile.Name, skip)
+				continue
+			}
+		} else {
+			skip, _ := p.check(candidate, owner)
+			if skip != nil {
+				r.reportSkip(pod, candidate.profile.Name, skip)
+				continue
+			}
+			p.plan(candidate, owner)
+		}
+
+		log.Info("planning eviction of pod",
+			"pod", pod.Name,
+			"namespace", pod.Namespace,
+			"node", node.Name,
+			"strategy", candidate.strategy,
+			"workloadType", pod.Labels[WorkloadTypeLabel],
+			"profile", candidate.profile.Name,
+			"qosClass", getPodQoSClass(pod),
+			"evictionPriority", candidate.profile.Spec.Eviction.PriorityOrDefault(),
+		)
+		if candidate.strategy == DegradedNodeStrategy {
+			r.nodeRotation.served(node.Name, state.now)
+		}
+	}
+
+	p.reportHeadroom()
+	return p.evictions
+}
+
+// returns the running and pending pods on a node that may be evicted, along with their profiles and the number of pods
+// considered; pods bound to the node are left out and counted by kind
+func (r *PodRebalancer) evictablePods(ctx context.Context, state *rebalanceState, node *core.Node, nodeBound map[string]int) ([]*core.Pod, map[*core.Pod]api_v1.WorkloadProfile, int32) {
+	log, cfg := state.log, state.cfg
+	var considered int32
+	var pods []*core.Pod
+	podProfiles := map[*core.Pod]api_v1.WorkloadProfile{}
+	for i := range state.pods {
+		pod := &state.pods[i]
+		if pod.Spec.NodeName != node.Name || (pod.Status.Phase != core.PodRunning && pod.Status.Phase != core.PodPending) {
+			continue
+		}
+		// evicting pods bound to their node would only restart them on the same node
+		if kind := nodeBoundPodKind(pod); kind != "" {
+			log.V(1).Info("pod is bound to its node, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "kind", kind)
+			nodeBound[kind]++
+			continue
+		}
+		considered++
+		if !cfg.namespaceAllowed(pod.Namespace) {
+			log.V(1).Info("pod namespace excluded by rebalance policy, skipping pod", "pod", pod.Name, "namespace", pod.Namespace)
+			continue
+		}
+		if cfg.podProtected(pod) {
+			log.V(1).Info("pod matches a protected pod selector, skipping pod", "pod", pod.Name, "namespace", pod.Namespace)
+			continue
+		}
+		// never evicting pods the cluster or its nodes can't run without, whatever their profile says
+		if reason := r.criticalPodReason(pod); reason != "" {
+			log.V(1).Info("pod is critical, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "reason", reason)
+			r.reportSkip(pod, "", &podSkip{reason: metrics.SkipReasonCritical})
+			continue
+		}
+
+		// resolving the workload profile governing the pod, leaving out pods of protected profiles
+		profile, ok := profiles.MatchPodScoped(pod, node, state.namespacedProfiles, state.workloadProfiles)
+		if ok && profile.Spec.Eviction.IsProtected() {
+			log.V(1).Info("pod is governed by a protected workload profile, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "profile", profile.Name)
+			r.reportSkip(pod, profile.Name, evictionSkipped(metrics.SkipReasonProtected, fmt.Sprintf("Pod %s skipped as its workload profile %s is protected", pod.Name, profile.Name)))
+			continue
+		}
+		// honouring the pod's own opt-out, including the conventions of other disruption tooling
+		if reason := doNotEvictReason(pod); reason != "" {
+			log.V(1).Info("pod opted out of eviction, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "reason", reason)
+			r.reportSkip(pod, profile.Name, evictionSkipped(metrics.SkipReasonDoNotEvict, fmt.Sprintf("Pod %s skipped as %s", pod.Name, reason)))
+			continue
+		}
+		// leaving pods nothing would replace, and Job pods whose progress would be lost, in place as the policy asks
+		if skipped, what := cfg.unmanagedPodSkipped(pod, ok); skipped {
+			log.V(1).Info("pod without a controller or owned by a Job left in place by rebalance policy, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "reason", what)
+			r.reportSkip(pod, profile.Name, evictionSkipped(metrics.SkipReasonUnmanaged, fmt.Sprintf("Pod %s skipped as %s", pod.Name, what)))
+			continue
+		}
+		// leaving pods keeping data on the node in place unless the operator accepts losing it
+		var podProfile *api_v1.WorkloadProfile
+		if ok {
+			podProfile = &profile
+		}
+		if !r.evictsLocalStorage(podProfile) {
+			reason, err := r.localStorageReason(ctx, pod)
+			if err != nil {
+				log.Error(err, "failed to check pod for local storage, skipping pod", "pod", pod.Name, "namespace", pod.Namespace)
+				continue
+			}
+			if reason != "" {
+				log.V(1).Info("pod keeps data on its node, skipping pod", "pod", pod.Name, "namespace", pod.Namespace, "reason", reason)
+				r.reportSkip(pod, profile.Name, evictionSkipped(metrics.SkipReasonLocalStorage, fmt.Sprintf("Pod %s skipped as %s", pod.Name, reason)))
+				continue
+			}
+		}
+		if ok {
+			podProfiles[pod] = profile
+		}
+		pods = append(pods, pod)
+	}
+	return pods, podProfiles, considered
+}
+
+// returns the evictable pods of a node, resolving them on first use so that the pods skipped on it are only reported
+// once per cycle
+func (r *PodRebalancer) nodeEvictables(ctx context.Context, state *rebalanceState, nodeName string) *nodeEvictables {
+	if e, ok := state.evictables[nodeName]; ok {
+		return e
+	}
+	if state.evictables == nil {
+		state.evictables = map[string]*nodeEvictables{}
+	}
+	e := &nodeEvictables{}
+	if node, ok := state.nodesByName[nodeName]; ok {
+		e.pods, e.podProfiles, _ = r.evictablePods(ctx, state, node, map[string]int{})
+	}
+	state.evictables[nodeName] = e
+	return e
+}
+
+// sorts a node's pods in the order they are evicted: crash-looping pods first when restarts are weighted, then by their
+// use of the node's failed resource, by the eviction order and by their size
+func sortPodsForEviction(pods []*core.Pod, podProfiles map[*core.Pod]api_v1.WorkloadProfile, degradedResource core.ResourceName, restartCountWeight int, order []string) {
+	if len(order) == 0 {
+		order = defaultEvictionOrder
+	}
+	sort.Slice(pods, func(i int, j int) bool {
+		podA := pods[i]
+		podB := pods[j]
+
+		// pods already failing are the likeliest to suffer from the node's degradation, and lose the least by moving
+		if restartCountWeight > 0 {
+			if crashingA, crashingB := podCrashLooping(podA), podCrashLooping(podB); crashingA != crashingB {
+				return crashingA
+			}
+		}
+
+		if degradedResource != "" {
+			usesA := podRequestsResource(podA, degradedResource)
+			usesB := podRequestsResource(podB, degradedResource)
+			if usesA != usesB {
+				return usesA
+			}
+		}
+
+		for _, criterion := range order {
+			if c := compareForEviction(criterion, podA, podB, podProfiles, restartCountWeight); c != 0 {
+				return c < 0
+			}
+		}
+
+		profileA, profileB := podProfiles[podA], podProfiles[podB]
+		// among equally ranked pods, smaller ones are moved first as they are the likeliest to fit on the remaining nodes
+		for _, resourceName := range []core.ResourceName{core.ResourceMemory, core.ResourceCPU} {
+			sizeA := podEffectiveRequest(podA, resourceName, &profileA)
+			sizeB := podEffectiveRequest(podB, resourceName, &profileB)
+			if cmp := sizeA.Cmp(sizeB); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+}
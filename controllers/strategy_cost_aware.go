@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+)
+
+// name of the strategy moving pods onto cheaper nodes
+const CostAwareStrategy = "cost-aware"
+
+// where the nodes' hourly costs come from
+const (
+	// the cost of the node's instance type scaled by that of its capacity type, as set on the rebalance policy
+	CostSourceLabels = "labels"
+	// the node_total_hourly_cost metric OpenCost exports to Prometheus
+	CostSourceOpenCost = "opencost"
+)
+
+// capacity types nodes are priced by
+const (
+	CapacityTypeSpot     = "spot"
+	CapacityTypeOnDemand = "on-demand"
+)
+
+// query for the hourly cost of each node, labelled with the node's name, as exported by OpenCost
+const openCostNodeCostQuery = "node_total_hourly_cost"
+
+// saving, in percent of the cost of a pod's node, below which moving the pod isn't worthwhile when the policy sets none
+const defaultMinSavingsPercent = 20
+
+// QoS classes of the pods moved when the policy sets none, Guaranteed pods usually being sized for steady capacity
+var defaultCostAwareQoSClasses = []string{string(core.PodQOSBurstable), string(core.PodQOSBestEffort)}
+
+// moves pods off expensive nodes while cheaper nodes, e.g. spot capacity, can take them, so that workloads tolerant of
+// disruption run on the cheapest capacity available; the pods are evicted, so the scheduler decides where they land
+type costAwareStrategy struct {
+	r *PodRebalancer
+}
+
+// implements the rebalanceStrategy interface
+func (s *costAwareStrategy) name() string {
+	return CostAwareStrategy
+}
+
+// implements the rebalanceStrategy interface; node costs are compared again by the next plans, so an eviction still
+// applies once planned
+func (s *costAwareStrategy) revalidate(node *core.Node, now time.Time) string {
+	return ""
+}
+
+// implements the rebalanceStrategy interface
+func (s *costAwareStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	spec, log := state.cfg.costAware, state.log
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+
+	targets := schedulingTargets(state.nodes, state.pods, state.degradedNodes)
+	costs, err := s.r.nodeCosts(ctx, spec, targets)
+	if err != nil {
+		log.Error(err, "failed to get node costs, skipping strategy")
+		return nil
+	}
+	state.nodeCosts = costs
+
+	// only nodes whose cost is known are compared, the cheapest ones taking pods first
+	var priced []*schedulingTarget
+	for _, target := range targets {
+		if _, ok := costs[target.node.Name]; ok {
+			priced = append(priced, target)
+		}
+	}
+	if len(priced) < 2 {
+		log.V(1).Info("too few nodes with a known cost to move pods between", "pricedNodes", len(priced))
+		return nil
+	}
+	sort.SliceStable(priced, func(i int, j int) bool {
+		return costs[priced[i].node.Name] < costs[priced[j].node.Name]
+	})
+	minSavingsPercent := defaultMinSavingsPercent
+	if spec.MinSavingsPercent != nil {
+		minSavingsPercent = *spec.MinSavingsPercent
+	}
+	qosClasses := spec.QoSClasses
+	if len(qosClasses) == 0 {
+		qosClasses = defaultCostAwareQoSClasses
+	}
+
+	// the most expensive nodes are relieved first
+	var candidates []evictionCandidate
+	for i := len(priced) - 1; i > 0; i-- {
+		node := priced[i].node
+		nodeCost := costs[node.Name]
+		maxCost := nodeCost * float64(100-minSavingsPercent) / 100
+		if costs[priced[0].node.Name] > maxCost {
+			break
+		}
+
+		evictables := s.r.nodeEvictables(ctx, state, node.Name)
+		pods := slices.Clone(evictables.pods)
+		sortPodsForEviction(pods, evictables.podProfiles, "", 0, state.cfg.evictionOrder)
+		for _, pod := range pods {
+			if pod.Status.Phase != core.PodRunning || !slices.Contains(qosClasses, string(getPodQoSClass(pod))) {
+				continue
+			}
+			target := cheaperTarget(pod, priced[:i], costs, maxCost)
+			if target == nil {
+				continue
+			}
+			profile, profileFound := evictables.podProfiles[pod]
+			candidates = append(candidates, evictionCandidate{
+				pod:          pod,
+				node:         node,
+				profile:      profile,
+				profileFound: profileFound,
+				reason: fmt.Sprintf("node costs %g while node %s, costing %g, can take the pod; QoS class %s, eviction priority %d",
+					nodeCost, target.node.Name, costs[target.node.Name], getPodQoSClass(pod), profile.Spec.Eviction.PriorityOrDefault()),
+			})
+		}
+	}
+	if len(candidates) > 0 {
+		log.Info("moving pods onto cheaper nodes", "pods", len(candidates), "pricedNodes", len(priced))
+	}
+	return candidates
+}
+
+// returns the cheapest node, among those sorted by cost, costing at most maxCost that the pod could be rescheduled onto,
+// reserving room for it there, or nil when none could take it
+func cheaperTarget(pod *core.Pod, targets []*schedulingTarget, costs map[string]float64, maxCost float64) *schedulingTarget {
+	for _, target := range targets {
+		if costs[target.node.Name] > maxCost {
+			return nil
+		}
+		if target.node.Name != pod.Spec.NodeName && target.fits(pod) {
+			target.reserve(pod)
+			return target
+		}
+	}
+	return nil
+}
+
+// returns the hourly cost of the given nodes by name, from OpenCost or from their instance and capacity types; nodes
+// OpenCost doesn't report are left out
+func (r *PodRebalancer) nodeCosts(ctx context.Context, spec *api_v1alpha1.CostAware, targets []*schedulingTarget) (map[string]float64, error) {
+	costs := map[string]float64{}
+	if spec.Source == CostSourceOpenCost {
+		if r.Prometheus == nil {
+			return nil, fmt.Errorf("the %s cost source requires --prometheus-url", CostSourceOpenCost)
+		}
+		samples, err := r.Prometheus.Query(ctx, openCostNodeCostQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query node costs from OpenCost: %w", err)
+		}
+		for _, sample := range samples {
+			if nodeName := sample.Labels["node"]; nodeName != "" {
+				costs[nodeName] = sample.Value
+			}
+		}
+		return costs, nil
+	}
+
+	for _, target := range targets {
+		instanceCost, capacityPercent := 100, 100
+		if cost, ok := spec.InstanceTypeCosts[target.node.Labels[core.LabelInstanceTypeStable]]; ok {
+			instanceCost = cost
+		}
+		if percent, ok := spec.CapacityTypeCostPercent[nodeCapacityType(target.node)]; ok {
+			capacityPercent = percent
+		}
+		costs[target.node.Name] = float64(instanceCost*capacityPercent) / 100
+	}
+	return costs, nil
+}
+
+// returns a node's capacity type, spot or on-demand, from the labels set by Karpenter and the managed node pools of
+// EKS, GKE and AKS
+func nodeCapacityType(node *core.Node) string {
+	switch {
+	case strings.EqualFold(node.Labels["karpenter.sh/capacity-type"], CapacityTypeSpot),
+		strings.EqualFold(node.Labels["eks.amazonaws.com/capacityType"], CapacityTypeSpot),
+		node.Labels["cloud.google.com/gke-spot"] == "true",
+		node.Labels["cloud.google.com/gke-preemptible"] == "true",
+		node.Labels["kubernetes.azure.com/scalesetpriority"] == CapacityTypeSpot:
+		return CapacityTypeSpot
+	}
+	return CapacityTypeOnDemand
+}
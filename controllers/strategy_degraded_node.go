@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	core "k8s.io/api/core/v1"
+
+	"github.com/lokeshllkumar/kube-balance/internal/maintenance"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// moves pods off confirmed degraded nodes, those using the failed resource first; degraded nodes drained at once are
+// cordoned first, and the nodes take turns, starting from the one served longest ago
+type degradedNodeStrategy struct {
+	r *PodRebalancer
+}
+
+// name of the strategy evacuating degraded nodes
+const DegradedNodeStrategy = "degraded-node"
+
+// implements the rebalanceStrategy interface
+func (s *degradedNodeStrategy) name() string {
+	return DegradedNodeStrategy
+}
+
+// implements the rebalanceStrategy interface
+func (s *degradedNodeStrategy) revalidate(node *core.Node, now time.Time) string {
+	if degraded, _ := s.r.DegradationClassifier.IsDegraded(node, now); !degraded {
+		return fmt.Sprintf("node %s is no longer degraded", node.Name)
+	}
+	return ""
+}
+
+// implements the rebalanceStrategy interface
+func (s *degradedNodeStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	r, log, cfg := s.r, state.log, state.cfg
+	var byNode [][]evictionCandidate
+	nodeBoundExcluded := map[string]int{}
+	for _, nodeName := range r.nodeRotation.order(state.degradedNodes) {
+		node := state.degradedNodes[nodeName]
+		severity := degradation.NodeSeverity(node)
+		zone := node.Labels[TopologyZoneLabel]
+
+		// leaving the nodes of zones with a paused correlated failure alone, but for those about to go away
+		if state.pausedZones[zone] && severity != degradation.SeverityUrgent {
+			log.V(1).Info("node is in a zone with a correlated failure, pausing evictions from node", "node", nodeName, "zone", zone)
+			continue
+		}
+
+		// deferring evictions outside the policy's maintenance windows; urgent degradations can't wait for a window
+		if severity != degradation.SeverityUrgent {
+			if open, opensAt := maintenance.Open(cfg.maintenanceWindows, state.now); !open {
+				log.Info("outside rebalance policy maintenance windows, deferring evictions from node", "node", nodeName, "nextWindow", opensAt.Format(time.RFC3339))
+				r.Recorder.Eventf(node, core.EventTypeNormal, "EvictionsDeferred", "Evictions from node %s deferred until the next maintenance window at %s", nodeName, opensAt.Format(time.RFC3339))
+				state.requeueAfter = requeueAtWindow(state.requeueAfter, opensAt, state.now)
+				continue
+			}
+		}
+
+		// cordoning nodes drained at once, so that none of their evicted pods is scheduled back onto them; nodes are
+		// left alone while evictions are paused, their evictions only being planned for review
+		draining := cfg.drainsNode(node)
+		if draining && cfg.paused {
+			state.drains[nodeName] = &nodeDrainProgress{node: node}
+		} else if draining {
+			if err := r.startDrain(ctx, node, state.degradationKeys[nodeName]); err != nil {
+				log.Error(err, "failed to start draining node", "node", nodeName)
+			}
+			state.drains[nodeName] = &nodeDrainProgress{node: node}
+		} else if cfg.cordonDegradedNodes && !cfg.paused && !node.Spec.Unschedulable {
+			// cordoning the nodes evacuated a few pods per cycle too when the policy asks for it, so that the
+			// scheduler doesn't keep placing new pods on them
+			if err := r.cordonNode(ctx, node); err != nil {
+				log.Error(err, "failed to cordon degraded node", "node", nodeName)
+			} else if !r.DryRun {
+				log.Info("cordoned degraded node", "node", nodeName)
+				r.Recorder.Eventf(node, core.EventTypeNormal, "NodeCordoned", "Node %s cordoned while it is evacuated as it is degraded (%s)", nodeName, state.degradationKeys[nodeName])
+			}
+		}
+
+		log.Info("processing degraded node", "node", nodeName, "zone", zone, "severity", severity, "drain", draining)
+
+		podsOnDegradedNode, podProfiles, considered := r.evictablePods(ctx, state, node, nodeBoundExcluded)
+		// a drain completes once none of the pods it may evict, those governed by a profile, is left on the node
+		if progress, ok := state.drains[nodeName]; ok {
+			progress.remaining = int32(len(podProfiles))
+			progress.unevictable = considered - progress.remaining
+		}
+		if len(podsOnDegradedNode) == 0 {
+			log.V(1).Info("no evictable pods found on degraded node", "node", nodeName)
+			continue
+		}
+		sortPodsForEviction(podsOnDegradedNode, podProfiles, degradation.NodeDegradedResource(node), cfg.restartCountWeight, cfg.evictionOrder)
+
+		var candidates []evictionCandidate
+		for _, pod := range podsOnDegradedNode {
+			profile, profileFound := podProfiles[pod]
+			reason := fmt.Sprintf("node is degraded (%s, severity %s); QoS class %s, eviction priority %d",
+				state.degradationKeys[nodeName], severity, getPodQoSClass(pod), profile.Spec.Eviction.PriorityOrDefault())
+			if cfg.restartCountWeight > 0 {
+				if podCrashLooping(pod) {
+					reason += ", in CrashLoopBackOff"
+				}
+				if restarts := podRestartCount(pod); restarts > 0 {
+					reason += fmt.Sprintf(", %d container restarts", restarts)
+				}
+			}
+			candidates = append(candidates, evictionCandidate{
+				pod:          pod,
+				node:         node,
+				profile:      profile,
+				profileFound: profileFound,
+				reason:       reason,
+			})
+		}
+		byNode = append(byNode, candidates)
+	}
+
+	for _, kind := range []string{metrics.NodeBoundKindDaemonSet, metrics.NodeBoundKindMirror, metrics.NodeBoundKindStatic} {
+		metrics.NodeBoundPodsExcluded.WithLabelValues(kind).Set(float64(nodeBoundExcluded[kind]))
+	}
+	return interleaveCandidates(byNode)
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// name of the strategy packing workloads onto fewer nodes
+const HighNodeUtilizationStrategy = "high-node-utilization"
+
+// empties under-utilized nodes by moving all their pods onto the other nodes, similar to the descheduler's
+// HighNodeUtilization strategy, so that the cluster autoscaler or Karpenter can remove them; a node is only emptied when
+// all its pods may be evicted and fit on the nodes that aren't emptied
+type highNodeUtilizationStrategy struct {
+	r *PodRebalancer
+}
+
+// implements the rebalanceStrategy interface
+func (s *highNodeUtilizationStrategy) name() string {
+	return HighNodeUtilizationStrategy
+}
+
+// implements the rebalanceStrategy interface; a node being emptied stays so until its last pod is moved, so an eviction
+// still applies once planned
+func (s *highNodeUtilizationStrategy) revalidate(node *core.Node, now time.Time) string {
+	return ""
+}
+
+// implements the rebalanceStrategy interface
+func (s *highNodeUtilizationStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	spec, log := state.cfg.highNodeUtilization, state.log
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+	thresholds := utilizationThresholds(spec.Thresholds)
+	if len(thresholds) == 0 {
+		log.Info("high node utilization thresholds unset in rebalance policy, skipping strategy")
+		return nil
+	}
+
+	utilizations, err := s.r.nodeUtilizations(ctx, state, UtilizationBasisRequests)
+	if err != nil {
+		log.Error(err, "failed to measure node utilization, skipping strategy")
+		return nil
+	}
+	var underUtilized []*nodeUtilization
+	for _, u := range utilizations {
+		if u.below(thresholds) {
+			underUtilized = append(underUtilized, u)
+		}
+	}
+	if len(underUtilized) == 0 || len(underUtilized) == len(utilizations) {
+		log.V(1).Info("no nodes to empty", "underUtilized", len(underUtilized), "nodes", len(utilizations))
+		return nil
+	}
+
+	// the least utilized nodes are emptied first, as they have the fewest pods to move
+	load := func(u *nodeUtilization) float64 {
+		total := 0.0
+		for resourceName := range thresholds {
+			total += u.percent(resourceName)
+		}
+		return total
+	}
+	sort.Slice(underUtilized, func(i int, j int) bool {
+		return load(underUtilized[i]) < load(underUtilized[j])
+	})
+
+	// pods are packed onto the nodes that aren't under-utilized, and onto the under-utilized nodes left in place
+	emptied := map[string]bool{}
+	for _, u := range underUtilized {
+		emptied[u.node.Name] = true
+	}
+	targets := schedulingTargets(state.nodes, state.pods, state.degradedNodes)
+
+	var candidates []evictionCandidate
+	for _, u := range underUtilized {
+		pods, podProfiles, considered := s.r.evictablePods(ctx, state, u.node, map[string]int{})
+		// a node is only worth emptying if every pod not bound to it may be evicted, which takes a profile governing it
+		if considered == 0 {
+			continue
+		}
+		if int32(len(podProfiles)) < considered {
+			log.V(1).Info("under-utilized node can't be emptied, leaving it in place", "node", u.node.Name, "pods", considered, "evictable", len(podProfiles))
+			delete(emptied, u.node.Name)
+			continue
+		}
+
+		// every pod must fit on a node that isn't being emptied, without using the room taken by the pods of the nodes
+		// emptied before
+		var kept, trial []*schedulingTarget
+		for _, target := range targets {
+			if !emptied[target.node.Name] {
+				kept = append(kept, target)
+				trial = append(trial, &schedulingTarget{node: target.node, free: target.free.DeepCopy()})
+			}
+		}
+		fits := true
+		for _, pod := range pods {
+			if feasibleTarget(pod, trial) == nil {
+				fits = false
+				break
+			}
+		}
+		if !fits {
+			log.V(1).Info("pods of under-utilized node fit on no other node, leaving it in place", "node", u.node.Name)
+			delete(emptied, u.node.Name)
+			continue
+		}
+		for i := range kept {
+			kept[i].free = trial[i].free
+		}
+
+		utilization := u.describe(thresholds)
+		log.Info("emptying under-utilized node", "node", u.node.Name, "utilization", utilization, "pods", len(pods))
+		sortPodsForEviction(pods, podProfiles, "", 0, state.cfg.evictionOrder)
+		for _, pod := range pods {
+			profile := podProfiles[pod]
+			candidates = append(candidates, evictionCandidate{
+				pod:          pod,
+				node:         u.node,
+				profile:      profile,
+				profileFound: true,
+				reason: fmt.Sprintf("node is under-utilized (%s) and is being emptied; QoS class %s, eviction priority %d",
+					utilization, getPodQoSClass(pod), profile.Spec.Eviction.PriorityOrDefault()),
+			})
+		}
+	}
+	return candidates
+}
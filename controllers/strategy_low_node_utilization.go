@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups="metrics.k8s.io",resources=nodes,verbs=get;list
+
+// name of the strategy balancing load across nodes that aren't degraded
+const LowNodeUtilizationStrategy = "low-node-utilization"
+
+// what a node's utilization is measured from
+const (
+	// the requests of the node's pods
+	UtilizationBasisRequests = "requests"
+	// the usage reported by the metrics API
+	UtilizationBasisUsage = "usage"
+)
+
+// kind of the node usage reported by the metrics API, read as unstructured objects as there is no typed client for it
+var nodeMetricsListGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "NodeMetricsList"}
+
+// resources whose utilization is balanced
+var utilizationResources = []core.ResourceName{core.ResourceCPU, core.ResourceMemory, core.ResourcePods}
+
+// moves pods off over-utilized nodes while under-utilized nodes can take them, similar to the descheduler's
+// LowNodeUtilization strategy, so that load is balanced continuously and not only when nodes degrade
+type lowNodeUtilizationStrategy struct {
+	r *PodRebalancer
+}
+
+// utilization of a node that isn't degraded, updated as pods are selected to move off it
+type nodeUtilization struct {
+	node        *core.Node
+	allocatable core.ResourceList
+	used        core.ResourceList
+}
+
+// returns the share of the node's allocatable resource in use, in percent
+func (u *nodeUtilization) percent(resourceName core.ResourceName) float64 {
+	allocatable, used := u.allocatable[resourceName], u.used[resourceName]
+	if allocatable.IsZero() {
+		return 0
+	}
+	return float64(used.MilliValue()) / float64(allocatable.MilliValue()) * 100
+}
+
+// reports whether the node's utilization is below every threshold
+func (u *nodeUtilization) below(thresholds map[core.ResourceName]float64) bool {
+	for resourceName, threshold := range thresholds {
+		if u.percent(resourceName) >= threshold {
+			return false
+		}
+	}
+	return true
+}
+
+// reports whether the node's utilization is above any of the thresholds
+func (u *nodeUtilization) above(thresholds map[core.ResourceName]float64) bool {
+	for resourceName, threshold := range thresholds {
+		if u.percent(resourceName) > threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// describes the node's utilization of the thresholds' resources, e.g. "cpu 85%, memory 60%"
+func (u *nodeUtilization) describe(thresholds map[core.ResourceName]float64) string {
+	var parts []string
+	for _, resourceName := range utilizationResources {
+		if _, ok := thresholds[resourceName]; ok {
+			parts = append(parts, fmt.Sprintf("%s %.0f%%", resourceName, u.percent(resourceName)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// returns the thresholds set, by resource
+func utilizationThresholds(thresholds api_v1alpha1.ResourceThresholds) map[core.ResourceName]float64 {
+	byResource := map[core.ResourceName]float64{}
+	for resourceName, threshold := range map[core.ResourceName]*int{
+		core.ResourceCPU:    thresholds.CPU,
+		core.ResourceMemory: thresholds.Memory,
+		core.ResourcePods:   thresholds.Pods,
+	} {
+		if threshold != nil {
+			byResource[resourceName] = float64(*threshold)
+		}
+	}
+	return byResource
+}
+
+// implements the rebalanceStrategy interface
+func (s *lowNodeUtilizationStrategy) name() string {
+	return LowNodeUtilizationStrategy
+}
+
+// implements the rebalanceStrategy interface; load is balanced again by the next plans, so an eviction still applies
+// once planned
+func (s *lowNodeUtilizationStrategy) revalidate(node *core.Node, now time.Time) string {
+	return ""
+}
+
+// implements the rebalanceStrategy interface
+func (s *lowNodeUtilizationStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	spec, log := state.cfg.lowNodeUtilization, state.log
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+	thresholds, targetThresholds := utilizationThresholds(spec.Thresholds), utilizationThresholds(spec.TargetThresholds)
+	if len(thresholds) == 0 || len(targetThresholds) == 0 {
+		log.Info("low node utilization thresholds or target thresholds unset in rebalance policy, skipping strategy")
+		return nil
+	}
+
+	utilizations, err := s.r.nodeUtilizations(ctx, state, spec.Basis)
+	if err != nil {
+		log.Error(err, "failed to measure node utilization, skipping strategy")
+		return nil
+	}
+	var underUtilized, overUtilized []*nodeUtilization
+	for _, u := range utilizations {
+		switch {
+		case u.below(thresholds):
+			underUtilized = append(underUtilized, u)
+		case u.above(targetThresholds):
+			overUtilized = append(overUtilized, u)
+		}
+	}
+	if len(underUtilized) == 0 || len(overUtilized) == 0 {
+		log.V(1).Info("no pods to move between nodes", "underUtilized", len(underUtilized), "overUtilized", len(overUtilized))
+		return nil
+	}
+
+	// room the under-utilized nodes have before reaching the target thresholds
+	headroom := core.ResourceList{}
+	for resourceName, threshold := range targetThresholds {
+		total := resource.Quantity{}
+		for _, u := range underUtilized {
+			allocatable, used := u.allocatable[resourceName], u.used[resourceName]
+			room := *resource.NewMilliQuantity(int64(float64(allocatable.MilliValue())*threshold/100)-used.MilliValue(), resource.DecimalSI)
+			if room.Sign() > 0 {
+				total.Add(room)
+			}
+		}
+		headroom[resourceName] = total
+	}
+
+	// the most utilized nodes are relieved first
+	load := func(u *nodeUtilization) float64 {
+		total := 0.0
+		for resourceName := range targetThresholds {
+			total += u.percent(resourceName)
+		}
+		return total
+	}
+	sort.Slice(overUtilized, func(i int, j int) bool {
+		return load(overUtilized[i]) > load(overUtilized[j])
+	})
+
+	var candidates []evictionCandidate
+	for _, u := range overUtilized {
+		log.Info("processing over-utilized node", "node", u.node.Name, "utilization", u.describe(targetThresholds), "underUtilizedNodes", len(underUtilized))
+		pods, podProfiles, _ := s.r.evictablePods(ctx, state, u.node, map[string]int{})
+		sortPodsForEviction(pods, podProfiles, "", 0, state.cfg.evictionOrder)
+		for _, pod := range pods {
+			if !u.above(targetThresholds) {
+				break
+			}
+			// moving the pod must leave the under-utilized nodes within the target thresholds
+			requests := podSchedulingRequests(pod)
+			fits := true
+			for resourceName := range targetThresholds {
+				room, request := headroom[resourceName], requests[resourceName]
+				if room.Cmp(request) < 0 {
+					fits = false
+					break
+				}
+			}
+			if !fits {
+				continue
+			}
+
+			utilization := u.describe(targetThresholds)
+			for resourceName := range targetThresholds {
+				room, used, request := headroom[resourceName], u.used[resourceName], requests[resourceName]
+				room.Sub(request)
+				used.Sub(request)
+				headroom[resourceName], u.used[resourceName] = room, used
+			}
+			profile, profileFound := podProfiles[pod]
+			candidates = append(candidates, evictionCandidate{
+				pod:          pod,
+				node:         u.node,
+				profile:      profile,
+				profileFound: profileFound,
+				reason: fmt.Sprintf("node is over-utilized (%s); QoS class %s, eviction priority %d",
+					utilization, getPodQoSClass(pod), profile.Spec.Eviction.PriorityOrDefault()),
+			})
+		}
+	}
+	return candidates
+}
+
+// returns the CPU, memory and pod utilization of the Ready, schedulable nodes that aren't degraded, measured from
+// their pods' requests or, for CPU and memory, from the usage reported by the metrics API, falling back to requests for
+// nodes it doesn't report; pods selected to move off a node are assumed to take their requests with them either way
+func (r *PodRebalancer) nodeUtilizations(ctx context.Context, state *rebalanceState, basis string) ([]*nodeUtilization, error) {
+	byName := map[string]*nodeUtilization{}
+	var utilizations []*nodeUtilization
+	for i := range state.nodes {
+		node := &state.nodes[i]
+		if _, degraded := state.degradedNodes[node.Name]; degraded || node.Spec.Unschedulable || !nodeReady(node) {
+			continue
+		}
+		u := &nodeUtilization{node: node, allocatable: node.Status.Allocatable, used: core.ResourceList{}}
+		byName[node.Name] = u
+		utilizations = append(utilizations, u)
+	}
+
+	for i := range state.pods {
+		pod := &state.pods[i]
+		u, ok := byName[pod.Spec.NodeName]
+		if !ok || pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed {
+			continue
+		}
+		for resourceName, request := range podSchedulingRequests(pod) {
+			used := u.used[resourceName]
+			used.Add(request)
+			u.used[resourceName] = used
+		}
+	}
+	if basis != UtilizationBasisUsage {
+		return utilizations, nil
+	}
+
+	metricsList := &unstructured.UnstructuredList{}
+	metricsList.SetGroupVersionKind(nodeMetricsListGVK)
+	if err := r.List(ctx, metricsList); err != nil {
+		return nil, fmt.Errorf("failed to list node metrics: %w", err)
+	}
+	for _, item := range metricsList.Items {
+		u, ok := byName[item.GetName()]
+		if !ok {
+			continue
+		}
+		usage, _, err := unstructured.NestedStringMap(item.Object, "usage")
+		if err != nil {
+			return nil, fmt.Errorf("invalid usage in metrics of node %s: %w", item.GetName(), err)
+		}
+		for _, resourceName := range []core.ResourceName{core.ResourceCPU, core.ResourceMemory} {
+			quantity, err := resource.ParseQuantity(usage[string(resourceName)])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s usage in metrics of node %s: %w", resourceName, item.GetName(), err)
+			}
+			u.used[resourceName] = quantity
+		}
+	}
+	return utilizations, nil
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// name of the strategy moving pods off nodes that no longer satisfy their scheduling constraints
+const NodeConstraintViolationStrategy = "node-constraint-violation"
+
+// prefix of the taints the node lifecycle controllers add for node conditions and cordons, which are left to them
+const nodeLifecycleTaintPrefix = "node.kubernetes.io/"
+
+// evicts pods whose node no longer matches their node selector or required node affinity, or has a NoSchedule taint
+// they don't tolerate, similar to the descheduler's RemovePodsViolatingNodeAffinity and RemovePodsViolatingNodeTaints
+// strategies; only Ready, schedulable nodes that aren't degraded are checked, the others being evacuated or left alone
+type nodeConstraintViolationStrategy struct {
+	r *PodRebalancer
+}
+
+// implements the rebalanceStrategy interface
+func (s *nodeConstraintViolationStrategy) name() string {
+	return NodeConstraintViolationStrategy
+}
+
+// implements the rebalanceStrategy interface; pods' constraints are checked again by the next plans, so an eviction
+// still applies once planned
+func (s *nodeConstraintViolationStrategy) revalidate(node *core.Node, now time.Time) string {
+	return ""
+}
+
+// implements the rebalanceStrategy interface
+func (s *nodeConstraintViolationStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	spec, log := state.cfg.nodeConstraintViolations, state.log
+	if spec == nil || (!spec.NodeAffinity && !spec.NodeTaints) {
+		return nil
+	}
+
+	var candidates []evictionCandidate
+	for i := range state.nodes {
+		node := &state.nodes[i]
+		if _, degraded := state.degradedNodes[node.Name]; degraded || node.Spec.Unschedulable || !nodeReady(node) {
+			continue
+		}
+		evictables := s.r.nodeEvictables(ctx, state, node.Name)
+		var pods []*core.Pod
+		reasons := map[*core.Pod]string{}
+		for _, pod := range evictables.pods {
+			if _, ok := evictables.podProfiles[pod]; !ok {
+				continue
+			}
+			var reason string
+			if spec.NodeAffinity && !nodeMatchesPodAffinity(node, pod) {
+				reason = "node no longer matches the pod's node selector or required node affinity"
+			} else if spec.NodeTaints {
+				if taint := untoleratedTaint(node, pod, spec.ExcludedTaints); taint != nil {
+					reason = fmt.Sprintf("pod doesn't tolerate taint %s=%s:%s of the node", taint.Key, taint.Value, taint.Effect)
+				}
+			}
+			if reason != "" {
+				pods = append(pods, pod)
+				reasons[pod] = reason
+			}
+		}
+		if len(pods) == 0 {
+			continue
+		}
+
+		log.Info("pods violating their scheduling constraints found on node", "node", node.Name, "pods", len(pods))
+		sortPodsForEviction(pods, evictables.podProfiles, "", 0, state.cfg.evictionOrder)
+		for _, pod := range pods {
+			profile := evictables.podProfiles[pod]
+			candidates = append(candidates, evictionCandidate{
+				pod:          pod,
+				node:         node,
+				profile:      profile,
+				profileFound: true,
+				reason:       fmt.Sprintf("%s; QoS class %s, eviction priority %d", reasons[pod], getPodQoSClass(pod), profile.Spec.Eviction.PriorityOrDefault()),
+			})
+		}
+	}
+	return candidates
+}
+
+// returns the first NoSchedule taint of a node a pod doesn't tolerate, leaving out the excluded taints and those of the
+// node lifecycle; NoExecute taints are left to the taint manager, which already evicts pods not tolerating them
+func untoleratedTaint(node *core.Node, pod *core.Pod, excluded []string) *core.Taint {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != core.TaintEffectNoSchedule || strings.HasPrefix(taint.Key, nodeLifecycleTaintPrefix) || slices.Contains(excluded, taint.Key) {
+			continue
+		}
+		if !toleratesTaint(pod.Spec.Tolerations, taint) {
+			return taint
+		}
+	}
+	return nil
+}
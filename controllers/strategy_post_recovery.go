@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// name of the strategy moving load back onto recovered nodes
+const PostRecoveryStrategy = "post-recovery"
+
+// defaults of the post-recovery strategy when the policy sets none
+const (
+	defaultPostRecoveryStabilizationPeriod = 5 * time.Minute
+	defaultPostRecoveryWindow              = time.Hour
+	defaultPostRecoveryMaxPodsPerNode      = 5
+)
+
+// resources whose utilization is compared to decide whether a recovered node takes load back
+var postRecoveryResources = map[core.ResourceName]float64{core.ResourceCPU: 0, core.ResourceMemory: 0}
+
+// moves load back onto nodes that recovered from a degradation, off the nodes more utilized than the cluster average,
+// so that the cluster isn't left lopsided once the pods evacuated from them were rescheduled elsewhere; the pods are
+// evicted, so the scheduler decides where they land, usually the emptiest node
+type postRecoveryStrategy struct {
+	r *PodRebalancer
+}
+
+// implements the rebalanceStrategy interface
+func (s *postRecoveryStrategy) name() string {
+	return PostRecoveryStrategy
+}
+
+// implements the rebalanceStrategy interface; load is compared again by the next plans, so an eviction still applies
+// once planned
+func (s *postRecoveryStrategy) revalidate(node *core.Node, now time.Time) string {
+	return ""
+}
+
+// implements the rebalanceStrategy interface
+func (s *postRecoveryStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	spec, log := state.cfg.postRecovery, state.log
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+	stabilizationPeriod, window, maxPods := defaultPostRecoveryStabilizationPeriod, defaultPostRecoveryWindow, defaultPostRecoveryMaxPodsPerNode
+	if spec.StabilizationPeriod != nil {
+		stabilizationPeriod = spec.StabilizationPeriod.Duration
+	}
+	if spec.Window != nil {
+		window = spec.Window.Duration
+	}
+	if spec.MaxPodsPerNodePerCycle != nil {
+		maxPods = *spec.MaxPodsPerNodePerCycle
+	}
+
+	recoveries := s.r.degradationTracker.recoveredSince(state.now.Add(-window))
+	if len(recoveries) == 0 {
+		return nil
+	}
+	utilizations, err := s.r.nodeUtilizations(ctx, state, UtilizationBasisRequests)
+	if err != nil {
+		log.Error(err, "failed to measure node utilization, skipping strategy")
+		return nil
+	}
+	if len(utilizations) < 2 {
+		return nil
+	}
+
+	// a node's load is its average utilization of CPU and memory, compared to the average load of the cluster
+	load := func(u *nodeUtilization) float64 {
+		total := 0.0
+		for resourceName := range postRecoveryResources {
+			total += u.percent(resourceName)
+		}
+		return total / float64(len(postRecoveryResources))
+	}
+	average := 0.0
+	for _, u := range utilizations {
+		average += load(u)
+	}
+	average /= float64(len(utilizations))
+
+	targets := map[string]*schedulingTarget{}
+	for _, target := range schedulingTargets(state.nodes, state.pods, state.degradedNodes) {
+		targets[target.node.Name] = target
+	}
+	var recovered, donors []*nodeUtilization
+	for _, u := range utilizations {
+		if _, ok := recoveries[u.node.Name]; ok {
+			recovered = append(recovered, u)
+		} else if load(u) > average {
+			donors = append(donors, u)
+		}
+	}
+	sort.Slice(recovered, func(i int, j int) bool {
+		return load(recovered[i]) < load(recovered[j])
+	})
+
+	selected := map[*core.Pod]bool{}
+	var candidates []evictionCandidate
+	for _, u := range recovered {
+		recoveredFor := state.now.Sub(recoveries[u.node.Name])
+		if remaining := stabilizationPeriod - recoveredFor; remaining > 0 {
+			log.V(1).Info("recovered node not yet stable, deferring moving load back", "node", u.node.Name, "remaining", remaining)
+			if remaining < state.requeueAfter {
+				state.requeueAfter = remaining
+			}
+			continue
+		}
+		target, ok := targets[u.node.Name]
+		if !ok || load(u) >= average {
+			continue
+		}
+
+		// the most utilized nodes give load back first
+		sort.SliceStable(donors, func(i int, j int) bool {
+			return load(donors[i]) > load(donors[j])
+		})
+		log.Info("moving load back onto recovered node", "node", u.node.Name, "recoveredFor", recoveredFor.Round(time.Second), "utilization", u.describe(postRecoveryResources), "averageUtilization", fmt.Sprintf("%.0f%%", average))
+		moved := 0
+		for _, donor := range donors {
+			evictables := s.r.nodeEvictables(ctx, state, donor.node.Name)
+			pods := slices.Clone(evictables.pods)
+			sortPodsForEviction(pods, evictables.podProfiles, "", 0, state.cfg.evictionOrder)
+			for _, pod := range pods {
+				if moved >= maxPods || load(u) >= average || load(donor) <= average {
+					break
+				}
+				if selected[pod] || pod.Status.Phase != core.PodRunning || !target.fits(pod) {
+					continue
+				}
+
+				utilization := donor.describe(postRecoveryResources)
+				target.reserve(pod)
+				for resourceName, request := range podSchedulingRequests(pod) {
+					used, recoveredUsed := donor.used[resourceName], u.used[resourceName]
+					used.Sub(request)
+					recoveredUsed.Add(request)
+					donor.used[resourceName], u.used[resourceName] = used, recoveredUsed
+				}
+				selected[pod] = true
+				moved++
+				profile, profileFound := evictables.podProfiles[pod]
+				candidates = append(candidates, evictionCandidate{
+					pod:          pod,
+					node:         donor.node,
+					profile:      profile,
+					profileFound: profileFound,
+					reason: fmt.Sprintf("node is more utilized (%s) than average (%.0f%%) while node %s, recovered %s ago, can take the pod; QoS class %s, eviction priority %d",
+						utilization, average, u.node.Name, recoveredFor.Round(time.Second), getPodQoSClass(pod), profile.Spec.Eviction.PriorityOrDefault()),
+				})
+			}
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// name of the strategy spreading replicas colocated on a single node
+const RemoveDuplicatesStrategy = "remove-duplicates"
+
+// evicts the replicas of an owner beyond its fair share of a node, similar to the descheduler's RemoveDuplicates
+// strategy, so that a single node failure takes down as few of them as possible; an owner's fair share is its replicas
+// divided by the nodes they may run on, so replicas outnumbering those nodes are left doubled up
+type removeDuplicatesStrategy struct {
+	r *PodRebalancer
+}
+
+// implements the rebalanceStrategy interface
+func (s *removeDuplicatesStrategy) name() string {
+	return RemoveDuplicatesStrategy
+}
+
+// implements the rebalanceStrategy interface; the replicas' spread is measured again by the next plans, so an eviction
+// still applies once planned
+func (s *removeDuplicatesStrategy) revalidate(node *core.Node, now time.Time) string {
+	return ""
+}
+
+// implements the rebalanceStrategy interface
+func (s *removeDuplicatesStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	spec, log := state.cfg.removeDuplicates, state.log
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+
+	// replicas of each owner by node, owners in the order they are first found
+	var owners []types.UID
+	ownerNames := map[types.UID]string{}
+	replicas := map[types.UID]map[string][]*core.Pod{}
+	for i := range state.pods {
+		pod := &state.pods[i]
+		if pod.DeletionTimestamp != nil || (pod.Status.Phase != core.PodRunning && pod.Status.Phase != core.PodPending) {
+			continue
+		}
+		if _, ok := state.nodesByName[pod.Spec.NodeName]; !ok || nodeBoundPodKind(pod) != "" {
+			continue
+		}
+		owner := meta.GetControllerOf(pod)
+		if owner == nil || slices.Contains(spec.ExcludeOwnerKinds, owner.Kind) {
+			continue
+		}
+		if _, ok := replicas[owner.UID]; !ok {
+			owners = append(owners, owner.UID)
+			ownerNames[owner.UID] = owner.Kind + "/" + owner.Name
+			replicas[owner.UID] = map[string][]*core.Pod{}
+		}
+		replicas[owner.UID][pod.Spec.NodeName] = append(replicas[owner.UID][pod.Spec.NodeName], pod)
+	}
+
+	var candidates []evictionCandidate
+	for _, uid := range owners {
+		byNode := replicas[uid]
+		total, colocated := 0, false
+		var sample *core.Pod
+		for _, pods := range byNode {
+			total += len(pods)
+			colocated = colocated || len(pods) > 1
+			sample = pods[0]
+		}
+		if !colocated {
+			continue
+		}
+
+		// nodes the owner's replicas may run on, judged by one of them as replicas share their scheduling constraints
+		feasible := 0
+		for i := range state.nodes {
+			node := &state.nodes[i]
+			if _, degraded := state.degradedNodes[node.Name]; degraded || node.Spec.Unschedulable || !nodeReady(node) {
+				continue
+			}
+			if nodeMatchesPodAffinity(node, sample) && toleratesNodeTaints(node, sample) {
+				feasible++
+			}
+		}
+		if feasible < 2 {
+			continue
+		}
+		share := (total + feasible - 1) / feasible
+
+		nodeNames := make([]string, 0, len(byNode))
+		for nodeName := range byNode {
+			nodeNames = append(nodeNames, nodeName)
+		}
+		sort.Strings(nodeNames)
+		for _, nodeName := range nodeNames {
+			excess := len(byNode[nodeName]) - share
+			if excess <= 0 {
+				continue
+			}
+			evictables := s.r.nodeEvictables(ctx, state, nodeName)
+			var pods []*core.Pod
+			podProfiles := map[*core.Pod]api_v1.WorkloadProfile{}
+			for _, pod := range byNode[nodeName] {
+				if profile, ok := evictables.podProfiles[pod]; ok {
+					pods = append(pods, pod)
+					podProfiles[pod] = profile
+				}
+			}
+			sortPodsForEviction(pods, podProfiles, "", 0, state.cfg.evictionOrder)
+			if len(pods) > excess {
+				pods = pods[:excess]
+			}
+
+			log.Info("replicas colocated on node", "node", nodeName, "owner", ownerNames[uid], "replicas", len(byNode[nodeName]), "fairShare", share, "evicting", len(pods))
+			for _, pod := range pods {
+				profile := podProfiles[pod]
+				candidates = append(candidates, evictionCandidate{
+					pod:          pod,
+					node:         state.nodesByName[nodeName],
+					profile:      profile,
+					profileFound: true,
+					reason: fmt.Sprintf("%d replicas of %s are colocated on the node, above their fair share of %d; QoS class %s, eviction priority %d",
+						len(byNode[nodeName]), ownerNames[uid], share, getPodQoSClass(pod), profile.Spec.Eviction.PriorityOrDefault()),
+				})
+			}
+		}
+	}
+	return candidates
+}
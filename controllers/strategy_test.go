@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+func TestPlanEvictionsOrdersAndCapsCandidatesPerNode(t *testing.T) {
+	nodes := []*core.Node{degradedNode("node-a", degradation.SeverityNormal), degradedNode("node-b", degradation.SeverityNormal)}
+	var deployments []*apps.Deployment
+	var pods []*core.Pod
+	for _, p := range []struct{ name, node, memory string }{
+		{"a-large", "node-a", "512Mi"},
+		{"a-small", "node-a", "128Mi"},
+		{"a-besteffort", "node-a", ""},
+		{"b-large", "node-b", "512Mi"},
+		{"b-small", "node-b", "128Mi"},
+		{"b-medium", "node-b", "256Mi"},
+	} {
+		deploy := testDeployment(p.name, 3, false)
+		deployments = append(deployments, deploy)
+		pods = append(pods, testPod(p.name, deploy, p.node, p.memory))
+	}
+	r, _ := newTestRebalancer(t, testObjects(nodes, deployments, pods)...)
+	r.MaxEvictionsPerNodePerCycle = 2
+
+	// best-effort pods go first, then the smallest ones, the nodes taking turns until each reaches its budget
+	got := plannedPods(r.planEvictions(context.Background(), testState(r, nodes, pods)))
+	want := []string{"a-besteffort", "b-small", "a-small", "b-medium"}
+	if !slices.Equal(got, want) {
+		t.Errorf("planned evictions = %v, want %v", got, want)
+	}
+}
+
+func TestPlanEvictionsPlansOneEvictionPerOwner(t *testing.T) {
+	nodes := []*core.Node{degradedNode("node-a", degradation.SeverityNormal)}
+	web, api := testDeployment("web", 3, false), testDeployment("api", 3, false)
+	pods := []*core.Pod{
+		testPod("web-0", web, "node-a", "128Mi"),
+		testPod("web-1", web, "node-a", "256Mi"),
+		testPod("api-0", api, "node-a", "512Mi"),
+	}
+	r, _ := newTestRebalancer(t, testObjects(nodes, []*apps.Deployment{web, api}, pods)...)
+	r.MaxEvictionsPerNodePerCycle = 3
+
+	got := plannedPods(r.planEvictions(context.Background(), testState(r, nodes, pods)))
+	want := []string{"web-0", "api-0"}
+	if !slices.Equal(got, want) {
+		t.Errorf("planned evictions = %v, want %v", got, want)
+	}
+}
+
+func TestPlanEvictionsKeepsAReadyReplicaOfEachOwner(t *testing.T) {
+	terminating := testPod("web-2", testDeployment("web", 3, true), "node-b", "128Mi")
+	terminating.DeletionTimestamp = &meta.Time{Time: time.Now()}
+
+	tests := []struct {
+		name     string
+		severity degradation.Severity
+		drain    string
+		pods     []string
+		// pods of the owner only listed in the cycle's state, e.g. as they are terminating
+		statePods []*core.Pod
+		want      []string
+	}{
+		{
+			name:     "drained node",
+			severity: degradation.SeverityNormal,
+			drain:    DrainModeAll,
+			pods:     []string{"web-0", "web-1"},
+			want:     []string{"web-0"},
+		},
+		{
+			name:      "urgently degraded node with a lagging owner status",
+			severity:  degradation.SeverityUrgent,
+			drain:     DrainModeOff,
+			pods:      []string{"web-0"},
+			statePods: []*core.Pod{terminating},
+			want:      []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := []*core.Node{degradedNode("node-a", tt.severity)}
+			// the Deployment still reports a ready replica for a pod that is gone or terminating
+			web := testDeployment("web", 3, true)
+			var pods []*core.Pod
+			for _, name := range tt.pods {
+				pods = append(pods, testPod(name, web, "node-a", "128Mi"))
+			}
+			r, _ := newTestRebalancer(t, testObjects(nodes, []*apps.Deployment{web}, pods)...)
+			r.MaxEvictionsPerNodePerCycle = 3
+			r.UrgentMaxEvictionsPerNodePerCycle = 3
+			r.DrainMode = tt.drain
+
+			got := plannedPods(r.planEvictions(context.Background(), testState(r, nodes, append(pods, tt.statePods...))))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("planned evictions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
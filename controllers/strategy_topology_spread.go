@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// name of the strategy spreading pods again across the domains of their topology spread constraints
+const TopologySpreadStrategy = "topology-spread"
+
+// evicts the fewest pods needed for the pods of each topology spread constraint to be spread within its maxSkew again,
+// similar to the descheduler's RemovePodsViolatingTopologySpreadConstraint strategy; the scheduler only enforces the
+// constraints when placing pods, so they drift out of balance, e.g. once a failed zone recovers
+type topologySpreadStrategy struct {
+	r *PodRebalancer
+}
+
+// topology spread constraint shared by pods of a namespace
+type spreadConstraint struct {
+	namespace  string
+	constraint core.TopologySpreadConstraint
+	// selector of the pods counted in the constraint's domains, including the labels of its matchLabelKeys
+	selector labels.Selector
+	// first pod found with the constraint, whose node selector and required node affinity restrict the nodes making up
+	// its domains
+	pod *core.Pod
+}
+
+// implements the rebalanceStrategy interface
+func (s *topologySpreadStrategy) name() string {
+	return TopologySpreadStrategy
+}
+
+// implements the rebalanceStrategy interface; the pods' spread is measured again by the next plans, so an eviction
+// still applies once planned
+func (s *topologySpreadStrategy) revalidate(node *core.Node, now time.Time) string {
+	return ""
+}
+
+// implements the rebalanceStrategy interface
+func (s *topologySpreadStrategy) candidates(ctx context.Context, state *rebalanceState) []evictionCandidate {
+	spec, log := state.cfg.topologySpread, state.log
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+
+	selected := map[types.UID]bool{}
+	var candidates []evictionCandidate
+	for _, c := range spreadConstraints(state.pods, spec.IncludeSoftConstraints) {
+		podsByDomain := spreadDomains(state, c)
+		if len(podsByDomain) < 2 {
+			continue
+		}
+		domains := make([]string, 0, len(podsByDomain))
+		counts := map[string]int{}
+		for domain, pods := range podsByDomain {
+			domains = append(domains, domain)
+			counts[domain] = len(pods)
+		}
+		sort.Strings(domains)
+
+		// pods are moved from the most to the least populated domain, assuming the scheduler places each evicted pod in
+		// the least populated domain, until the skew is within maxSkew or no pod left in a domain above it may go
+		exhausted := map[string]bool{}
+		victims := map[string][]*core.Pod{}
+		for {
+			var most, least string
+			for _, domain := range domains {
+				if least == "" || counts[domain] < counts[least] {
+					least = domain
+				}
+				if !exhausted[domain] && (most == "" || counts[domain] > counts[most]) {
+					most = domain
+				}
+			}
+			if most == "" || counts[most]-counts[least] <= int(c.constraint.MaxSkew) {
+				break
+			}
+
+			if _, ok := victims[most]; !ok {
+				podProfiles := map[*core.Pod]api_v1.WorkloadProfile{}
+				for _, pod := range podsByDomain[most] {
+					if profile, ok := s.r.nodeEvictables(ctx, state, pod.Spec.NodeName).podProfiles[pod]; ok {
+						podProfiles[pod] = profile
+						victims[most] = append(victims[most], pod)
+					}
+				}
+				sortPodsForEviction(victims[most], podProfiles, "", 0, state.cfg.evictionOrder)
+			}
+			var victim *core.Pod
+			for _, pod := range victims[most] {
+				if !selected[pod.UID] {
+					victim = pod
+					break
+				}
+			}
+			if victim == nil {
+				exhausted[most] = true
+				continue
+			}
+
+			selected[victim.UID] = true
+			profile := s.r.nodeEvictables(ctx, state, victim.Spec.NodeName).podProfiles[victim]
+			candidates = append(candidates, evictionCandidate{
+				pod:          victim,
+				node:         state.nodesByName[victim.Spec.NodeName],
+				profile:      profile,
+				profileFound: true,
+				reason: fmt.Sprintf("pod violates its topology spread constraint on %s (domain %s has %d matching pods, domain %s %d, max skew %d); QoS class %s, eviction priority %d",
+					c.constraint.TopologyKey, most, counts[most], least, counts[least], c.constraint.MaxSkew,
+					getPodQoSClass(victim), profile.Spec.Eviction.PriorityOrDefault()),
+			})
+			counts[most]--
+			counts[least]++
+		}
+	}
+	if len(candidates) > 0 {
+		log.Info("pods violating their topology spread constraints selected", "pods", len(candidates))
+	}
+	return candidates
+}
+
+// returns the distinct topology spread constraints of the running and pending pods, in the order they are first found;
+// ScheduleAnyway constraints are only returned when includeSoft is set
+func spreadConstraints(pods []core.Pod, includeSoft bool) []*spreadConstraint {
+	byKey := map[string]*spreadConstraint{}
+	var constraints []*spreadConstraint
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || (pod.Status.Phase != core.PodRunning && pod.Status.Phase != core.PodPending) {
+			continue
+		}
+		for _, constraint := range pod.Spec.TopologySpreadConstraints {
+			if constraint.WhenUnsatisfiable == core.ScheduleAnyway && !includeSoft {
+				continue
+			}
+			// a constraint without a label selector counts no pods
+			if constraint.LabelSelector == nil {
+				continue
+			}
+			selector, err := meta.LabelSelectorAsSelector(constraint.LabelSelector)
+			if err != nil {
+				continue
+			}
+			for _, key := range constraint.MatchLabelKeys {
+				value, ok := pod.Labels[key]
+				if !ok {
+					continue
+				}
+				if requirement, err := labels.NewRequirement(key, selection.Equals, []string{value}); err == nil {
+					selector = selector.Add(*requirement)
+				}
+			}
+
+			key := fmt.Sprintf("%s/%s/%s/%d/%s", pod.Namespace, constraint.TopologyKey, constraint.WhenUnsatisfiable, constraint.MaxSkew, selector.String())
+			if _, ok := byKey[key]; ok {
+				continue
+			}
+			c := &spreadConstraint{namespace: pod.Namespace, constraint: constraint, selector: selector, pod: pod}
+			byKey[key] = c
+			constraints = append(constraints, c)
+		}
+	}
+	return constraints
+}
+
+// returns the pods counted in each domain of a constraint; its domains are the values of its topology key across the
+// Ready, schedulable nodes that aren't degraded and, unless its node affinity policy is Ignore, match its pods' node
+// selector and required node affinity
+func spreadDomains(state *rebalanceState, c *spreadConstraint) map[string][]*core.Pod {
+	honorAffinity := c.constraint.NodeAffinityPolicy == nil || *c.constraint.NodeAffinityPolicy == core.NodeInclusionPolicyHonor
+	podsByDomain := map[string][]*core.Pod{}
+	for i := range state.nodes {
+		node := &state.nodes[i]
+		domain, ok := node.Labels[c.constraint.TopologyKey]
+		if _, degraded := state.degradedNodes[node.Name]; !ok || degraded || node.Spec.Unschedulable || !nodeReady(node) {
+			continue
+		}
+		if honorAffinity && !nodeMatchesPodAffinity(node, c.pod) {
+			continue
+		}
+		if _, ok := podsByDomain[domain]; !ok {
+			podsByDomain[domain] = nil
+		}
+	}
+
+	for i := range state.pods {
+		pod := &state.pods[i]
+		if pod.Namespace != c.namespace || pod.DeletionTimestamp != nil || (pod.Status.Phase != core.PodRunning && pod.Status.Phase != core.PodPending) {
+			continue
+		}
+		if !c.selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		node, ok := state.nodesByName[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		domain, ok := node.Labels[c.constraint.TopologyKey]
+		if !ok {
+			continue
+		}
+		if pods, ok := podsByDomain[domain]; ok {
+			podsByDomain[domain] = append(pods, pod)
+		}
+	}
+	return podsByDomain
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/internal/metrics"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+)
+
+// key under which a pod's PodStuckTerminating event is remembered by the skip reporter, so that it is only reported once
+const stuckTerminatingReport = "stuck-terminating"
+
+// reports whether the node controller lost contact with a node's kubelet, which then can't finish terminating its pods
+func nodeUnreachable(node *core.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == core.TaintNodeUnreachable {
+			return true
+		}
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == core.NodeReady {
+			return condition.Status == core.ConditionUnknown
+		}
+	}
+	return false
+}
+
+// reports the pods on degraded nodes still terminating past their grace period through the stuck terminating metric and
+// an event per pod; those kube-balance evicted and stuck for longer than StuckTerminatingForceDeleteAfter on an
+// unreachable node are deleted outright, as their kubelet will never confirm their termination and they would otherwise
+// hold back their replacements, unless evictions are paused
+func (r *PodRebalancer) handleStuckTerminating(ctx context.Context, pods []core.Pod, degradedNodes map[string]*core.Node, paused bool, now time.Time) {
+	stuck := map[string]int{}
+	for i := range pods {
+		pod := &pods[i]
+		node, ok := degradedNodes[pod.Spec.NodeName]
+		// the deletion timestamp already accounts for the pod's grace period
+		if !ok || pod.DeletionTimestamp == nil || !now.After(pod.DeletionTimestamp.Time) {
+			continue
+		}
+		stuckFor := now.Sub(pod.DeletionTimestamp.Time).Round(time.Second)
+
+		if !paused && r.StuckTerminatingForceDeleteAfter > 0 && stuckFor >= r.StuckTerminatingForceDeleteAfter && nodeUnreachable(node) && r.forceDeletesStuck(pod) {
+			if r.forceDeleteStuck(ctx, pod, node, stuckFor) {
+				continue
+			}
+		}
+
+		stuck[node.Name]++
+		r.Log.V(1).Info("pod stuck terminating past its grace period", "pod", pod.Name, "namespace", pod.Namespace, "node", node.Name, "stuckFor", stuckFor)
+		if r.skipReports.first(pod.UID, stuckTerminatingReport) {
+			r.Recorder.Eventf(pod, core.EventTypeWarning, "PodStuckTerminating", "Pod %s still terminating on degraded node %s, past its grace period ending at %s", pod.Name, node.Name, pod.DeletionTimestamp.Format(time.RFC3339))
+		}
+	}
+
+	metrics.StuckTerminatingPods.Reset()
+	for nodeName, count := range stuck {
+		metrics.StuckTerminatingPods.WithLabelValues(nodeName).Set(float64(count))
+	}
+}
+
+// reports whether a stuck pod may be deleted outright: only pods kube-balance evicted qualify, and StatefulSet pods
+// only when opted in, as their replacement, sharing their identity and volumes, could run before the unreachable node
+// stops the stuck one
+func (r *PodRebalancer) forceDeletesStuck(pod *core.Pod) bool {
+	if pod.Annotations[EvictionNodeAnnotation] == "" {
+		return false
+	}
+	return r.StuckTerminatingForceDeleteStatefulSets || profiles.OwnerKind(pod) != profiles.OwnerKindStatefulSet
+}
+
+// deletes a stuck pod outright, recording the deletion, and reports whether the pod is gone
+func (r *PodRebalancer) forceDeleteStuck(ctx context.Context, pod *core.Pod, node *core.Node, stuckFor time.Duration) bool {
+	deleteOpts := []client.DeleteOption{client.Preconditions{UID: &pod.UID}, client.GracePeriodSeconds(0)}
+	if r.DryRun {
+		deleteOpts = append(deleteOpts, client.DryRunAll)
+	}
+	err := r.Delete(ctx, pod, deleteOpts...)
+	switch {
+	case errors.IsNotFound(err) || errors.IsConflict(err):
+		// the pod is gone, or was recreated under the same name
+		return true
+	case err != nil:
+		r.Log.Error(err, "failed to force-delete pod stuck terminating", "pod", pod.Name, "namespace", pod.Namespace, "node", node.Name)
+		return false
+	case r.DryRun:
+		r.Log.Info("pod stuck terminating on unreachable node would be force-deleted", "pod", pod.Name, "namespace", pod.Namespace, "node", node.Name, "stuckFor", stuckFor)
+		return false
+	}
+
+	message := fmt.Sprintf("deleted outright after terminating for %s past its grace period on unreachable node %s", stuckFor, node.Name)
+	r.Log.Info("force-deleted pod stuck terminating on unreachable node", "pod", pod.Name, "namespace", pod.Namespace, "node", node.Name, "stuckFor", stuckFor)
+	r.Recorder.Eventf(pod, core.EventTypeWarning, "StuckPodForceDeleted", "Pod %s force-deleted after terminating for %s past its grace period on unreachable node %s", pod.Name, stuckFor, node.Name)
+	planned := api_v1alpha1.PlannedEviction{
+		Pod:       pod.Name,
+		Namespace: pod.Namespace,
+		UID:       pod.UID,
+		Node:      node.Name,
+		Reason:    pod.Annotations[EvictionReasonAnnotation],
+		Profile:   pod.Annotations[EvictionProfileAnnotation],
+		Strategy:  pod.Annotations[EvictionStrategyAnnotation],
+	}
+	r.recordEviction(ctx, pod, planned, pod.Annotations[EvictionPlanAnnotation], api_v1alpha1.EvictionOutcomeForceDeleted, message)
+	metrics.StuckTerminatingForceDeletions.Inc()
+	return true
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	api_v1alpha1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// returns a pod of the given owner kind terminating on a node since well past its grace period, stamped by kube-balance when evicted
+func stuckPod(name string, kind string, node string, evicted bool) *core.Pod {
+	pod := testPod(name, testDeployment(name, 1, false), node, "")
+	pod.OwnerReferences[0].Kind = kind
+	deleted := meta.NewTime(time.Now().Add(-time.Hour))
+	pod.DeletionTimestamp = &deleted
+	// the fake client only keeps deleted objects holding a finalizer
+	pod.Finalizers = []string{"example.com/hold"}
+	if evicted {
+		pod.Annotations = map[string]string{EvictionNodeAnnotation: node, EvictionPlanAnnotation: "plan", EvictionReasonAnnotation: "node is degraded"}
+	}
+	return pod
+}
+
+func TestHandleStuckTerminatingForceDeletesOnlyEvictedPods(t *testing.T) {
+	tests := []struct {
+		name            string
+		pod             *core.Pod
+		statefulSets    bool
+		wantForceDelete bool
+	}{
+		{name: "evicted pod", pod: stuckPod("web-0", "ReplicaSet", "node-a", true), wantForceDelete: true},
+		{name: "pod kube-balance didn't evict", pod: stuckPod("web-0", "ReplicaSet", "node-a", false)},
+		{name: "evicted StatefulSet pod", pod: stuckPod("db-0", "StatefulSet", "node-a", true)},
+		{name: "evicted StatefulSet pod, opted in", pod: stuckPod("db-0", "StatefulSet", "node-a", true), statefulSets: true, wantForceDelete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := degradedNode("node-a", degradation.SeverityNormal)
+			node.Status.Conditions[0].Status = core.ConditionUnknown
+			r, _ := newTestRebalancer(t, testObjects([]*core.Node{node}, []*apps.Deployment{}, []*core.Pod{tt.pod})...)
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+			r.StuckTerminatingForceDeleteAfter = time.Minute
+			r.StuckTerminatingForceDeleteStatefulSets = tt.statefulSets
+
+			// a pod left in place is reported once, however many cycles find it stuck
+			for range 2 {
+				r.handleStuckTerminating(context.Background(), []core.Pod{*tt.pod}, map[string]*core.Node{node.Name: node}, false, time.Now())
+			}
+
+			records := &api_v1alpha1.EvictionRecordList{}
+			if err := r.List(context.Background(), records); err != nil {
+				t.Fatal(err)
+			}
+			if got := len(records.Items) > 0; got != tt.wantForceDelete {
+				t.Fatalf("force-deleted = %v, want %v", got, tt.wantForceDelete)
+			}
+			if tt.wantForceDelete && records.Items[0].Spec.Outcome != api_v1alpha1.EvictionOutcomeForceDeleted {
+				t.Errorf("record outcome = %s, want %s", records.Items[0].Spec.Outcome, api_v1alpha1.EvictionOutcomeForceDeleted)
+			}
+			if !tt.wantForceDelete && len(recorder.Events) != 1 {
+				t.Errorf("reported %d PodStuckTerminating events, want 1", len(recorder.Events))
+			}
+		})
+	}
+}
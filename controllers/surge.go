@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v2"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// annotation recording, on a Deployment scaled up by one ahead of the eviction of one of its pods, which pod the extra
+// replica stands in for and the replicas to restore once it is evicted
+const SurgeAnnotation = "kube-balance.io/surge"
+
+// surge in progress on a Deployment, as recorded in its surge annotation
+type surge struct {
+	// pod the extra replica stands in for
+	PodUID types.UID `json:"podUID"`
+	// replicas of the Deployment before it was scaled up
+	Replicas int32 `json:"replicas"`
+	// time the Deployment was scaled up
+	Since meta.Time `json:"since"`
+}
+
+// returns the surge in progress on a Deployment, if any
+func deploymentSurge(deploy *apps.Deployment) (*surge, error) {
+	value, ok := deploy.Annotations[SurgeAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	s := &surge{}
+	if err := json.Unmarshal([]byte(value), s); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", SurgeAnnotation, err)
+	}
+	return s, nil
+}
+
+// returns the replicas a Deployment asks for, defaulting to 1 as the API server does
+func deploymentReplicas(deploy *apps.Deployment) int32 {
+	if deploy.Spec.Replicas == nil {
+		return 1
+	}
+	return *deploy.Spec.Replicas
+}
+
+// reports whether a pod of a Deployment may be evicted without losing capacity: the Deployment is scaled up by one
+// first, and the pod may be evicted once a replica created since is Ready on a healthy node; a surge not ready within
+// SurgeTimeout is rolled back, and the pod is evicted regardless
+//
+// Deployments scaled by a HorizontalPodAutoscaler aren't surged, as the autoscaler would reset their replicas on its
+// next sync and the extra replica would be removed; their pods are evicted right away instead
+func (r *PodRebalancer) surgeReady(ctx context.Context, deploy *apps.Deployment, pod *core.Pod, pods []core.Pod, nodesByName map[string]*core.Node, now time.Time) (bool, error) {
+	s, err := deploymentSurge(deploy)
+	if err != nil {
+		return false, err
+	}
+	if s == nil {
+		autoscaler, err := r.deploymentAutoscaler(ctx, deploy)
+		if err != nil {
+			return false, err
+		}
+		if autoscaler != "" {
+			r.Log.Info("deployment is scaled by a HorizontalPodAutoscaler, evicting pod without a surge", "deployment", deploy.Name, "namespace", deploy.Namespace, "hpa", autoscaler, "pod", pod.Name)
+			r.Recorder.Eventf(deploy, core.EventTypeWarning, "SurgeSkipped", "%s is scaled by HorizontalPodAutoscaler %s, which would remove a surge replica, evicting pod %s without it", deploy.Name, autoscaler, pod.Name)
+			return true, nil
+		}
+		return false, r.startSurge(ctx, deploy, pod, now)
+	}
+	// a Deployment is only surged for one pod at a time
+	if s.PodUID != pod.UID {
+		return false, nil
+	}
+
+	if r.replacementReady(deploy, s.Since.Time, pods, nodesByName, now) {
+		return true, nil
+	}
+	if r.SurgeTimeout > 0 && now.Sub(s.Since.Time) >= r.SurgeTimeout {
+		r.Log.Info("timed out waiting for the surge replica of deployment, evicting pod without it", "deployment", deploy.Name, "namespace", deploy.Namespace, "pod", pod.Name)
+		r.Recorder.Eventf(deploy, core.EventTypeWarning, "SurgeTimedOut", "No surge replica of %s became Ready on a healthy node within %s, evicting pod %s without it", deploy.Name, r.SurgeTimeout, pod.Name)
+		if err := r.endSurge(ctx, deploy); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// reports whether a Deployment is surged for another of its pods, whose eviction holds back those of its other pods
+func surgedForOtherPod(deploy *apps.Deployment, pod *core.Pod) (bool, error) {
+	s, err := deploymentSurge(deploy)
+	if err != nil || s == nil {
+		return false, err
+	}
+	return s.PodUID != pod.UID, nil
+}
+
+// reports whether the planned eviction of a pod of a Deployment may be carried out, scaling the Deployment up first
+// as it is about to be; surging only as evictions are carried out leaves the Deployments of the pods a plan mode or a
+// later check keeps in place untouched
+func (r *PodRebalancer) surgeBeforeEviction(ctx context.Context, deploy *apps.Deployment, pod *core.Pod, now time.Time) (bool, error) {
+	podList := &core.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(deploy.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list pods of deployment %s: %w", deploy.Name, err)
+	}
+	nodeList := &core.NodeList{}
+	if err := r.List(ctx, nodeList); err != nil {
+		return false, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	nodesByName := make(map[string]*core.Node, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodesByName[nodeList.Items[i].Name] = &nodeList.Items[i]
+	}
+	return r.surgeReady(ctx, deploy, pod, podList.Items, nodesByName, now)
+}
+
+// +kubebuilder:rbac:groups="autoscaling",resources=horizontalpodautoscalers,verbs=get;list;watch
+
+// returns the name of the HorizontalPodAutoscaler scaling a Deployment, empty when there is none
+func (r *PodRebalancer) deploymentAutoscaler(ctx context.Context, deploy *apps.Deployment) (string, error) {
+	hpaList := &autoscaling.HorizontalPodAutoscalerList{}
+	if err := r.List(ctx, hpaList, client.InNamespace(deploy.Namespace)); err != nil {
+		return "", fmt.Errorf("failed to list horizontal pod autoscalers: %w", err)
+	}
+	for _, hpa := range hpaList.Items {
+		target := hpa.Spec.ScaleTargetRef
+		if target.Kind == "Deployment" && target.Name == deploy.Name && (target.APIVersion == "" || target.APIVersion == apps.SchemeGroupVersion.String()) {
+			return hpa.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// scales a Deployment up by one ahead of the eviction of one of its pods
+func (r *PodRebalancer) startSurge(ctx context.Context, deploy *apps.Deployment, pod *core.Pod, now time.Time) error {
+	replicas := deploymentReplicas(deploy)
+	value, err := json.Marshal(surge{PodUID: pod.UID, Replicas: replicas, Since: meta.NewTime(now)})
+	if err != nil {
+		return fmt.Errorf("failed to encode surge: %w", err)
+	}
+
+	patch := client.MergeFrom(deploy.DeepCopy())
+	if deploy.Annotations == nil {
+		deploy.Annotations = make(map[string]string)
+	}
+	deploy.Annotations[SurgeAnnotation] = string(value)
+	surged := replicas + 1
+	deploy.Spec.Replicas = &surged
+	if err := r.Patch(ctx, deploy, patch); err != nil {
+		return fmt.Errorf("failed to scale up deployment %s: %w", deploy.Name, err)
+	}
+
+	r.Log.Info("scaled up deployment ahead of evicting its pod from a degraded node", "deployment", deploy.Name, "namespace", deploy.Namespace, "pod", pod.Name, "replicas", surged)
+	r.Recorder.Eventf(deploy, core.EventTypeNormal, "SurgeStarted", "Scaled %s up to %d replicas ahead of evicting pod %s from degraded node %s", deploy.Name, surged, pod.Name, pod.Spec.NodeName)
+	return nil
+}
+
+// rolls back the surge of a Deployment, restoring its replicas unless they were changed since it was scaled up
+func (r *PodRebalancer) endSurge(ctx context.Context, deploy *apps.Deployment) error {
+	s, err := deploymentSurge(deploy)
+	if s == nil && err == nil {
+		return nil
+	}
+
+	patch := client.MergeFrom(deploy.DeepCopy())
+	delete(deploy.Annotations, SurgeAnnotation)
+	restored := false
+	if s != nil && deploymentReplicas(deploy) == s.Replicas+1 {
+		replicas := s.Replicas
+		deploy.Spec.Replicas = &replicas
+		restored = true
+	}
+	if err := r.Patch(ctx, deploy, patch); err != nil {
+		return fmt.Errorf("failed to restore replicas of deployment %s: %w", deploy.Name, err)
+	}
+
+	if restored {
+		r.Log.Info("restored replicas of surged deployment", "deployment", deploy.Name, "namespace", deploy.Namespace, "replicas", s.Replicas)
+		r.Recorder.Eventf(deploy, core.EventTypeNormal, "SurgeEnded", "Restored %s to %d replicas", deploy.Name, s.Replicas)
+	}
+	return nil
+}
+
+// rolls back the surges no longer needed: those whose pod is gone or terminating, or no longer on a degraded node
+func (r *PodRebalancer) settleSurges(ctx context.Context, pods []core.Pod, nodesByName map[string]*core.Node, now time.Time) {
+	deployList := &apps.DeploymentList{}
+	if err := r.List(ctx, deployList); err != nil {
+		r.Log.Error(err, "failed to list deployments for settling surges")
+		return
+	}
+
+	podsByUID := make(map[types.UID]*core.Pod, len(pods))
+	for i := range pods {
+		podsByUID[pods[i].UID] = &pods[i]
+	}
+
+	for i := range deployList.Items {
+		deploy := &deployList.Items[i]
+		s, err := deploymentSurge(deploy)
+		if s == nil && err == nil {
+			continue
+		}
+		if s != nil {
+			if pod, ok := podsByUID[s.PodUID]; ok && pod.DeletionTimestamp == nil {
+				if node, ok := nodesByName[pod.Spec.NodeName]; ok {
+					if degraded, _ := r.DegradationClassifier.IsDegraded(node, now); degraded {
+						continue
+					}
+				}
+			}
+		}
+		if err := r.endSurge(ctx, deploy); err != nil {
+			r.Log.Error(err, "failed to settle surge of deployment", "deployment", deploy.Name, "namespace", deploy.Namespace)
+		}
+	}
+}
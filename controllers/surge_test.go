@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v2"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// returns the current state of a Deployment
+func getDeployment(t *testing.T, r *PodRebalancer, name string) *apps.Deployment {
+	t.Helper()
+	deploy := &apps.Deployment{}
+	if err := r.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, deploy); err != nil {
+		t.Fatal(err)
+	}
+	return deploy
+}
+
+// returns a rebalancer over a degraded and a healthy node and a Deployment of 3 replicas with a pod on the degraded node
+func surgeFixture(t *testing.T, extra ...client.Object) (*PodRebalancer, *core.Pod) {
+	t.Helper()
+	nodes := []*core.Node{degradedNode("node-a", degradation.SeverityNormal), {ObjectMeta: meta.ObjectMeta{Name: "node-b"}}}
+	deploy := testDeployment("web", 3, true)
+	pod := testPod("web-0", deploy, "node-a", "")
+	r, _ := newTestRebalancer(t, append(testObjects(nodes, []*apps.Deployment{deploy}, []*core.Pod{pod}), extra...)...)
+	r.SurgeTimeout = 10 * time.Minute
+	return r, pod
+}
+
+func TestSurgeBeforeEvictionWaitsForAReadyReplica(t *testing.T) {
+	r, pod := surgeFixture(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	if ready, err := r.surgeBeforeEviction(ctx, getDeployment(t, r, "web"), pod, now); err != nil || ready {
+		t.Fatalf("surgeBeforeEviction() = %v, %v, want the eviction held back", ready, err)
+	}
+	deploy := getDeployment(t, r, "web")
+	if s, err := deploymentSurge(deploy); err != nil || s == nil || s.PodUID != pod.UID || s.Replicas != 3 {
+		t.Fatalf("surge = %+v, %v, want one recorded for %s", s, err, pod.Name)
+	}
+	if replicas := deploymentReplicas(deploy); replicas != 4 {
+		t.Errorf("replicas = %d, want the Deployment scaled up by one", replicas)
+	}
+
+	// the surge replica is still starting
+	if ready, err := r.surgeBeforeEviction(ctx, deploy, pod, now.Add(time.Minute)); err != nil || ready {
+		t.Fatalf("surgeBeforeEviction() = %v, %v, want the eviction held back until the replica is Ready", ready, err)
+	}
+
+	replica := testPod("web-1", deploy, "node-b", "")
+	replica.CreationTimestamp = meta.NewTime(now.Add(time.Minute))
+	if err := r.Create(ctx, replica); err != nil {
+		t.Fatal(err)
+	}
+	if ready, err := r.surgeBeforeEviction(ctx, getDeployment(t, r, "web"), pod, now.Add(2*time.Minute)); err != nil || !ready {
+		t.Fatalf("surgeBeforeEviction() = %v, %v, want the eviction carried out", ready, err)
+	}
+
+	if err := r.endSurge(ctx, getDeployment(t, r, "web")); err != nil {
+		t.Fatalf("endSurge() error = %v", err)
+	}
+	deploy = getDeployment(t, r, "web")
+	if _, ok := deploy.Annotations[SurgeAnnotation]; ok || deploymentReplicas(deploy) != 3 {
+		t.Errorf("replicas = %d with surge annotation %v, want the original replicas restored", deploymentReplicas(deploy), ok)
+	}
+}
+
+func TestSurgeBeforeEvictionRollsBackOnTimeout(t *testing.T) {
+	r, pod := surgeFixture(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	if _, err := r.surgeBeforeEviction(ctx, getDeployment(t, r, "web"), pod, now); err != nil {
+		t.Fatal(err)
+	}
+	if ready, err := r.surgeBeforeEviction(ctx, getDeployment(t, r, "web"), pod, now.Add(11*time.Minute)); err != nil || !ready {
+		t.Fatalf("surgeBeforeEviction() = %v, %v, want the pod evicted without the surge replica", ready, err)
+	}
+	deploy := getDeployment(t, r, "web")
+	if _, ok := deploy.Annotations[SurgeAnnotation]; ok || deploymentReplicas(deploy) != 3 {
+		t.Errorf("replicas = %d with surge annotation %v, want the surge rolled back", deploymentReplicas(deploy), ok)
+	}
+}
+
+func TestSettleSurgesRollsBackSurgesOfRecoveredPods(t *testing.T) {
+	r, pod := surgeFixture(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	if _, err := r.surgeBeforeEviction(ctx, getDeployment(t, r, "web"), pod, now); err != nil {
+		t.Fatal(err)
+	}
+	// the node recovered, so the pod stays where it is
+	recovered := map[string]*core.Node{"node-a": {ObjectMeta: meta.ObjectMeta{Name: "node-a"}}}
+	r.settleSurges(ctx, []core.Pod{*pod}, recovered, now.Add(time.Minute))
+
+	deploy := getDeployment(t, r, "web")
+	if _, ok := deploy.Annotations[SurgeAnnotation]; ok || deploymentReplicas(deploy) != 3 {
+		t.Errorf("replicas = %d with surge annotation %v, want the surge rolled back", deploymentReplicas(deploy), ok)
+	}
+}
+
+func TestSurgeBeforeEvictionSkipsAutoscaledDeployments(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    autoscaling.CrossVersionObjectReference
+		wantSurge bool
+	}{
+		{name: "autoscaled", target: autoscaling.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}},
+		{name: "other deployment autoscaled", target: autoscaling.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"}, wantSurge: true},
+		{name: "statefulset of the same name autoscaled", target: autoscaling.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "web"}, wantSurge: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hpa := &autoscaling.HorizontalPodAutoscaler{
+				ObjectMeta: meta.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       autoscaling.HorizontalPodAutoscalerSpec{ScaleTargetRef: tt.target, MaxReplicas: 10},
+			}
+			r, pod := surgeFixture(t, hpa)
+
+			ready, err := r.surgeBeforeEviction(context.Background(), getDeployment(t, r, "web"), pod, time.Now())
+			if err != nil {
+				t.Fatalf("surgeBeforeEviction() error = %v", err)
+			}
+			if ready == tt.wantSurge {
+				t.Errorf("surgeBeforeEviction() = %v, want %v", ready, !tt.wantSurge)
+			}
+			deploy := getDeployment(t, r, "web")
+			if _, surged := deploy.Annotations[SurgeAnnotation]; surged != tt.wantSurge || (deploymentReplicas(deploy) == 4) != tt.wantSurge {
+				t.Errorf("surged = %v with %d replicas, want surged %v", surged, deploymentReplicas(deploy), tt.wantSurge)
+			}
+		})
+	}
+}
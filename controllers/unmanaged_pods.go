@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"fmt"
+
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// how pods without a controller, which nothing recreates once evicted, and pods of Jobs, whose progress is lost when
+// evicted, are handled
+const (
+	// the pods are never evicted
+	UnmanagedPodPolicySkip = "skip"
+	// the pods are evicted like any other, with a warning event recorded on each of them
+	UnmanagedPodPolicyEvictWithWarning = "evict-with-warning"
+	// the pods are only evicted when governed by a workload profile, which then vouches for them
+	UnmanagedPodPolicyEvictIfProfiled = "evict-if-profiled"
+)
+
+// returns the policy handling a pod without a controller or owned by a Job, along with what makes it such a pod for its
+// events; the policy is empty for the pods of any other controller
+func (cfg *rebalanceConfig) unmanagedPodPolicy(pod *core.Pod) (string, string) {
+	controllerRef := meta.GetControllerOf(pod)
+	switch {
+	case controllerRef == nil:
+		return cfg.barePodPolicy, "it has no controller to recreate it"
+	case controllerRef.Kind == "Job" && controllerRef.APIVersion == batch.SchemeGroupVersion.String():
+		return cfg.jobPodPolicy, fmt.Sprintf("it runs Job %s, whose progress it would lose", controllerRef.Name)
+	}
+	return "", ""
+}
+
+// reports whether a pod is left in place by the policy handling pods without a controller or owned by a Job, given
+// whether a workload profile governs it
+func (cfg *rebalanceConfig) unmanagedPodSkipped(pod *core.Pod, profiled bool) (bool, string) {
+	policy, what := cfg.unmanagedPodPolicy(pod)
+	return policy == UnmanagedPodPolicySkip || (policy == UnmanagedPodPolicyEvictIfProfiled && !profiled), what
+}
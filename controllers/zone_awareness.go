@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"sync"
+
+	core "k8s.io/api/core/v1"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// label identifying the topology zone a node belongs to
+const TopologyZoneLabel = core.LabelTopologyZone
+
+// actions taken when a large share of a zone's nodes is degraded at once
+const (
+	// stop the evictions from the zone's degraded nodes, but for the urgently degraded ones
+	ZoneDegradationActionPause = "pause"
+	// cap the evictions from the zone's degraded nodes per cycle
+	ZoneDegradationActionThrottle = "throttle"
+)
+
+// summarises how degraded a single topology zone is
+type zoneDegradation struct {
+	totalNodes    int
+	degradedNodes int
+}
+
+// returns the share of the zone's nodes that are degraded
+func (z zoneDegradation) fraction() float64 {
+	if z.totalNodes == 0 {
+		return 0
+	}
+	return float64(z.degradedNodes) / float64(z.totalNodes)
+}
+
+// groups nodes by topology zone and counts how many of them are degraded; nodes without a zone label are ignored
+func summarizeZoneDegradation(nodes []core.Node, degradedNodes map[string]*core.Node) map[string]zoneDegradation {
+	zones := map[string]zoneDegradation{}
+	for i := range nodes {
+		zone := nodes[i].Labels[TopologyZoneLabel]
+		if zone == "" {
+			continue
+		}
+		summary := zones[zone]
+		summary.totalNodes++
+		if _, ok := degradedNodes[nodes[i].Name]; ok {
+			summary.degradedNodes++
+		}
+		zones[zone] = summary
+	}
+	return zones
+}
+
+// returns the zones whose degraded share meets the correlated-failure threshold
+func correlatedZoneFailures(zones map[string]zoneDegradation, threshold float64) map[string]zoneDegradation {
+	failing := map[string]zoneDegradation{}
+	if threshold <= 0 {
+		return failing
+	}
+	for zone, summary := range zones {
+		// a single degraded node is never treated as a zone-wide failure
+		if summary.degradedNodes > 1 && summary.fraction() >= threshold {
+			failing[zone] = summary
+		}
+	}
+	return failing
+}
+
+// reports whether any degraded node is evacuated, as it is urgently degraded or outside the paused zones
+func anyEvacuatedNode(degradedNodes map[string]*core.Node, pausedZones map[string]bool) bool {
+	for _, node := range degradedNodes {
+		if !pausedZones[node.Labels[TopologyZoneLabel]] || degradation.NodeSeverity(node) == degradation.SeverityUrgent {
+			return true
+		}
+	}
+	return false
+}
+
+// remembers the action reported for each zone with a correlated failure, so that it is only reported as it starts
+type zoneFailureReporter struct {
+	mu       sync.Mutex
+	reported map[string]string
+}
+
+// creates a new zoneFailureReporter instance
+func newZoneFailureReporter() *zoneFailureReporter {
+	return &zoneFailureReporter{
+		reported: make(map[string]string),
+	}
+}
+
+// records the zones failing in a cycle, returning those whose failure, or the action taken for it, is new; the zones
+// that stopped failing are returned too
+func (t *zoneFailureReporter) update(failing map[string]zoneDegradation, action string) ([]string, []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var started, ended []string
+	for zone := range failing {
+		if t.reported[zone] != action {
+			t.reported[zone] = action
+			started = append(started, zone)
+		}
+	}
+	for zone := range t.reported {
+		if _, ok := failing[zone]; !ok {
+			delete(t.reported, zone)
+			ended = append(ended, zone)
+		}
+	}
+	return started, ended
+}
+
+// reports the zones whose correlated failure started in the cycle on their degraded nodes, and those whose failure ended
+func (r *PodRebalancer) reportZoneFailures(failing map[string]zoneDegradation, degradedNodes map[string]*core.Node, action string) {
+	outcome := "throttled"
+	if action == ZoneDegradationActionPause {
+		outcome = "paused, but for the urgently degraded nodes"
+	}
+	started, ended := r.zoneFailures.update(failing, action)
+	for _, zone := range started {
+		summary := failing[zone]
+		r.Log.Info("correlated degradation detected in zone", "zone", zone, "degradedNodes", summary.degradedNodes, "totalNodes", summary.totalNodes, "action", action)
+		for _, node := range degradedNodes {
+			if node.Labels[TopologyZoneLabel] == zone {
+				r.Recorder.Eventf(node, core.EventTypeWarning, "ZoneDegraded", "%d/%d nodes in zone %s are degraded; evictions are %s", summary.degradedNodes, summary.totalNodes, zone, outcome)
+			}
+		}
+	}
+	for _, zone := range ended {
+		r.Log.Info("correlated degradation in zone ended", "zone", zone)
+	}
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// returns the node placed in the given zone
+func inZone(node *core.Node, zone string) *core.Node {
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	node.Labels[TopologyZoneLabel] = zone
+	return node
+}
+
+func TestPlanEvictionsPausesZonesButForUrgentNodes(t *testing.T) {
+	nodes := []*core.Node{
+		inZone(degradedNode("node-a", degradation.SeverityNormal), "zone-1"),
+		inZone(degradedNode("node-b", degradation.SeverityUrgent), "zone-1"),
+		inZone(&core.Node{ObjectMeta: meta.ObjectMeta{Name: "node-c"}}, "zone-2"),
+	}
+	deployA, deployB := testDeployment("app-a", 3, false), testDeployment("app-b", 3, false)
+	pods := []*core.Pod{testPod("app-a-0", deployA, "node-a", "128Mi"), testPod("app-b-0", deployB, "node-b", "128Mi")}
+	r, _ := newTestRebalancer(t, testObjects(nodes, []*apps.Deployment{deployA, deployB}, pods)...)
+	state := testState(r, nodes, pods)
+	state.pausedZones = map[string]bool{"zone-1": true}
+
+	if got := plannedPods(r.planEvictions(context.Background(), state)); !slices.Equal(got, []string{"app-b-0"}) {
+		t.Errorf("planned evictions = %v, want only the urgently degraded node's pod", got)
+	}
+	if _, ok := state.degradedNodes["node-a"]; !ok {
+		t.Errorf("paused node-a was dropped from the degraded nodes")
+	}
+}
+
+func TestZoneFailureReporterReportsFailuresOnce(t *testing.T) {
+	reporter := newZoneFailureReporter()
+	failing := map[string]zoneDegradation{"zone-1": {degradedNodes: 2, totalNodes: 3}}
+
+	steps := []struct {
+		failing     map[string]zoneDegradation
+		action      string
+		wantStarted []string
+		wantEnded   []string
+	}{
+		{failing: failing, action: ZoneDegradationActionPause, wantStarted: []string{"zone-1"}},
+		{failing: failing, action: ZoneDegradationActionPause},
+		{failing: failing, action: ZoneDegradationActionThrottle, wantStarted: []string{"zone-1"}},
+		{failing: map[string]zoneDegradation{}, action: ZoneDegradationActionThrottle, wantEnded: []string{"zone-1"}},
+	}
+	for i, step := range steps {
+		started, ended := reporter.update(step.failing, step.action)
+		if !slices.Equal(started, step.wantStarted) || !slices.Equal(ended, step.wantEnded) {
+			t.Errorf("step %d: update() = %v, %v, want %v, %v", i, started, ended, step.wantStarted, step.wantEnded)
+		}
+	}
+}
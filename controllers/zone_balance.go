@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// counts the running pods of each owner in each topology zone, by the owner's UID and then the zone; pods being deleted
+// and pods on nodes without a zone are left out
+func countOwnerZoneReplicas(pods []core.Pod, nodesByName map[string]*core.Node) map[types.UID]map[string]int {
+	replicas := map[types.UID]map[string]int{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != core.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		ownerRef := meta.GetControllerOf(pod)
+		node, ok := nodesByName[pod.Spec.NodeName]
+		if ownerRef == nil || !ok || node.Labels[TopologyZoneLabel] == "" {
+			continue
+		}
+		if replicas[ownerRef.UID] == nil {
+			replicas[ownerRef.UID] = map[string]int{}
+		}
+		replicas[ownerRef.UID][node.Labels[TopologyZoneLabel]]++
+	}
+	return replicas
+}
+
+// reports whether evicting a pod would leave its owner without a replica in the pod's zone, as it is the owner's last
+// one there and none of the zone's nodes that aren't degraded could take its replacement, which would land in another
+// zone instead
+func collapsesZone(pod *core.Pod, zone string, ownerZoneReplicas map[types.UID]map[string]int, targets []*schedulingTarget) bool {
+	ownerRef := meta.GetControllerOf(pod)
+	if ownerRef == nil || zone == "" || ownerZoneReplicas[ownerRef.UID][zone] > 1 {
+		return false
+	}
+	for _, target := range targets {
+		if target.node.Name != pod.Spec.NodeName && target.node.Labels[TopologyZoneLabel] == zone && target.fits(pod) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// averaged stall percentages and cumulative stall time reported by a single PSI line
+type PressureLine struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	// total stall time in microseconds
+	Total uint64
+}
+
+// pressure stall information for a single resource, as exposed under /proc/pressure
+type Pressure struct {
+	// share of time in which at least one task was stalled on the resource
+	Some PressureLine
+	// share of time in which all non-idle tasks were stalled on the resource
+	Full PressureLine
+}
+
+// reads the PSI data for a resource ("cpu", "memory" or "io") relative to the given proc root
+func ReadPressure(procRoot string, resource string) (Pressure, error) {
+	path := filepath.Join(procRoot, "pressure", resource)
+	f, err := os.Open(path)
+	if err != nil {
+		return Pressure{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	pressure := Pressure{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		line, err := parsePressureLine(fields[1:])
+		if err != nil {
+			return Pressure{}, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		switch fields[0] {
+		case "some":
+			pressure.Some = line
+		case "full":
+			pressure.Full = line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Pressure{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return pressure, nil
+}
+
+// parses the key=value fields of a PSI line
+func parsePressureLine(fields []string) (PressureLine, error) {
+	line := PressureLine{}
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return PressureLine{}, fmt.Errorf("malformed field %q", field)
+		}
+
+		var err error
+		switch key {
+		case "avg10":
+			line.Avg10, err = strconv.ParseFloat(value, 64)
+		case "avg60":
+			line.Avg60, err = strconv.ParseFloat(value, 64)
+		case "avg300":
+			line.Avg300, err = strconv.ParseFloat(value, 64)
+		case "total":
+			line.Total, err = strconv.ParseUint(value, 10, 64)
+		}
+		if err != nil {
+			return PressureLine{}, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	return line, nil
+}
@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// source recorded on nodes marked by the pressure detector
+const PressureSource = "pressure-agent"
+
+// source recorded by the I/O saturation detector the pressure detector replaced, whose markers it clears
+const legacyIOSaturationSource = "io-agent"
+
+// a single threshold on one PSI signal, e.g. "io:full:avg10>25"
+type PressureRule struct {
+	// "cpu", "memory" or "io"
+	Resource string
+	// "some" or "full"
+	Kind string
+	// "avg10", "avg60" or "avg300"
+	Window string
+	// stall percentage above which the rule is breached
+	Threshold float64
+}
+
+// renders the rule in the same form accepted by ParsePressureRules
+func (r PressureRule) String() string {
+	return fmt.Sprintf("%s:%s:%s>%g", r.Resource, r.Kind, r.Window, r.Threshold)
+}
+
+// extracts the value this rule applies to from a resource's pressure data
+func (r PressureRule) value(pressure Pressure) float64 {
+	line := pressure.Some
+	if r.Kind == "full" {
+		line = pressure.Full
+	}
+	switch r.Window {
+	case "avg60":
+		return line.Avg60
+	case "avg300":
+		return line.Avg300
+	default:
+		return line.Avg10
+	}
+}
+
+// parses a comma-separated list of rules of the form resource:kind:window>threshold
+func ParsePressureRules(value string) ([]PressureRule, error) {
+	rules := []PressureRule{}
+	for _, raw := range strings.Split(value, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		signal, thresholdStr, ok := strings.Cut(raw, ">")
+		if !ok {
+			return nil, fmt.Errorf("pressure rule %q is missing a '>' threshold", raw)
+		}
+		parts := strings.Split(signal, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("pressure rule %q must have the form resource:kind:window>threshold", raw)
+		}
+		threshold, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("pressure rule %q has an invalid threshold: %w", raw, err)
+		}
+
+		rule := PressureRule{Resource: parts[0], Kind: parts[1], Window: parts[2], Threshold: threshold}
+		switch {
+		case rule.Resource != "cpu" && rule.Resource != "memory" && rule.Resource != "io":
+			return nil, fmt.Errorf("pressure rule %q has an unknown resource %q", raw, rule.Resource)
+		case rule.Kind != "some" && rule.Kind != "full":
+			return nil, fmt.Errorf("pressure rule %q has an unknown kind %q", raw, rule.Kind)
+		case rule.Window != "avg10" && rule.Window != "avg60" && rule.Window != "avg300":
+			return nil, fmt.Errorf("pressure rule %q has an unknown window %q", raw, rule.Window)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// watches a node's PSI metrics and marks the node as degraded while any rule stays breached
+type PressureDetector struct {
+	// name of the node the agent runs on
+	NodeName string
+	// mount point of the host's /proc filesystem
+	ProcRoot string
+	// thresholds evaluated on every sample
+	Rules []PressureRule
+	// how long a rule must stay breached before the node is marked
+	SustainFor time.Duration
+	// how often pressure is sampled
+	Interval time.Duration
+	Marker   *degradation.Marker
+	Log      logr.Logger
+
+	breachedSince map[string]time.Time
+	marked        bool
+}
+
+// samples pressure until the context is cancelled
+func (d *PressureDetector) Run(ctx context.Context) error {
+	if len(d.Rules) == 0 {
+		return fmt.Errorf("pressure detector requires at least one rule")
+	}
+	d.breachedSince = make(map[string]time.Time)
+
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	d.Log.Info("starting pressure detector", "node", d.NodeName, "rules", d.Rules, "sustainFor", d.SustainFor)
+	if err := d.Marker.Unmark(ctx, d.NodeName, legacyIOSaturationSource); err != nil {
+		d.Log.Error(err, "failed to clear the I/O saturation detector's marker", "node", d.NodeName)
+	}
+	for {
+		if err := d.check(ctx, time.Now()); err != nil {
+			d.Log.Error(err, "pressure check failed", "node", d.NodeName)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// samples pressure once and updates the node's degradation marker accordingly
+func (d *PressureDetector) check(ctx context.Context, now time.Time) error {
+	samples := map[string]Pressure{}
+	breaches := []string{}
+	for _, rule := range d.Rules {
+		pressure, ok := samples[rule.Resource]
+		if !ok {
+			var err error
+			pressure, err = ReadPressure(d.ProcRoot, rule.Resource)
+			if err != nil {
+				return err
+			}
+			samples[rule.Resource] = pressure
+		}
+
+		key := rule.String()
+		current := rule.value(pressure)
+		if current <= rule.Threshold {
+			delete(d.breachedSince, key)
+			continue
+		}
+
+		since, ok := d.breachedSince[key]
+		if !ok {
+			since = now
+			d.breachedSince[key] = since
+		}
+		if now.Sub(since) < d.SustainFor {
+			d.Log.V(1).Info("pressure above threshold, waiting for it to be sustained", "node", d.NodeName, "rule", key, "pressure", current, "since", since)
+			continue
+		}
+		breaches = append(breaches, fmt.Sprintf("%s %s pressure %.2f%% (%s) above %.2f%% for %s", rule.Resource, rule.Kind, current, rule.Window, rule.Threshold, now.Sub(since).Round(time.Second)))
+	}
+
+	if len(breaches) == 0 {
+		if d.marked {
+			if err := d.Marker.Unmark(ctx, d.NodeName, PressureSource); err != nil {
+				return err
+			}
+			d.marked = false
+		}
+		return nil
+	}
+
+	// the marker is refreshed every sample with a TTL spanning a few intervals, so it lapses on its own if the agent stops
+	if err := d.Marker.Mark(ctx, d.NodeName, degradation.Marking{
+		Source: PressureSource,
+		Reason: strings.Join(breaches, "; "),
+		TTL:    3 * d.Interval,
+	}); err != nil {
+		return err
+	}
+	d.marked = true
+	return nil
+}
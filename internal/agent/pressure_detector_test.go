@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// writes the I/O PSI file of a proc root, reporting the given full avg10 pressure
+func writeIOPressure(t *testing.T, procRoot string, full string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(procRoot, "pressure"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "some avg10=40.00 avg60=20.00 avg300=5.00 total=123456\nfull avg10=" + full + " avg60=10.00 avg300=2.00 total=65432\n"
+	if err := os.WriteFile(filepath.Join(procRoot, "pressure", "io"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// returns a detector for node-a applying the given rules, marking the node through a fake client
+func testPressureDetector(t *testing.T, node *core.Node, rules string) (*PressureDetector, client.Client) {
+	t.Helper()
+	parsed, err := ParsePressureRules(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(clientscheme.Scheme).WithObjects(node).Build()
+	return &PressureDetector{
+		NodeName:      node.Name,
+		ProcRoot:      t.TempDir(),
+		Rules:         parsed,
+		SustainFor:    time.Minute,
+		Interval:      15 * time.Second,
+		Marker:        degradation.NewMarker(cli, logr.Discard()),
+		Log:           logr.Discard(),
+		breachedSince: map[string]time.Time{},
+	}, cli
+}
+
+// returns the degradation source recorded on a node
+func markedBy(t *testing.T, cli client.Client, name string) string {
+	t.Helper()
+	node := &core.Node{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Name: name}, node); err != nil {
+		t.Fatal(err)
+	}
+	return node.Annotations[degradation.SourceAnnotation]
+}
+
+func TestParsePressureRules(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []PressureRule
+		wantErr bool
+	}{
+		{value: "io:full:avg10>25, memory:some:avg60>10.5", want: []PressureRule{{"io", "full", "avg10", 25}, {"memory", "some", "avg60", 10.5}}},
+		{value: "", want: []PressureRule{}},
+		{value: "io:full:avg10", wantErr: true},
+		{value: "disk:full:avg10>25", wantErr: true},
+		{value: "io:partial:avg10>25", wantErr: true},
+		{value: "io:full:avg30>25", wantErr: true},
+		{value: "io:full>25", wantErr: true},
+		{value: "io:full:avg10>lots", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePressureRules(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePressureRules(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePressureRules(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPressureDetectorMarksSustainedPressure(t *testing.T) {
+	d, cli := testPressureDetector(t, &core.Node{ObjectMeta: meta.ObjectMeta{Name: "node-a"}}, "io:full:avg10>25")
+	start := time.Now()
+	steps := []struct {
+		full       string
+		at         time.Duration
+		wantMarked bool
+	}{
+		{full: "30.00", at: 0},
+		{full: "30.00", at: 2 * time.Minute, wantMarked: true},
+		{full: "5.00", at: 3 * time.Minute},
+	}
+	for i, step := range steps {
+		writeIOPressure(t, d.ProcRoot, step.full)
+		if err := d.check(context.Background(), start.Add(step.at)); err != nil {
+			t.Fatalf("step %d: check() error = %v", i, err)
+		}
+		if got := markedBy(t, cli, "node-a") == PressureSource; got != step.wantMarked {
+			t.Errorf("step %d: marked = %v, want %v", i, got, step.wantMarked)
+		}
+	}
+}
+
+func TestPressureDetectorClearsIOSaturationMarker(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: "node-a", Annotations: map[string]string{
+		degradation.DegradedAnnotation: "true",
+		degradation.SourceAnnotation:   legacyIOSaturationSource,
+		degradation.ReasonAnnotation:   "I/O pressure 30.00% above 25.00% for 2m0s",
+	}}}
+	d, cli := testPressureDetector(t, node, "io:full:avg10>25")
+	writeIOPressure(t, d.ProcRoot, "5.00")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := d.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := markedBy(t, cli, "node-a"); got != "" {
+		t.Errorf("node still marked by %q, want the I/O saturation marker cleared", got)
+	}
+}
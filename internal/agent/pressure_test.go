@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPressure(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Pressure
+		wantErr bool
+	}{
+		{
+			name:    "some and full",
+			content: "some avg10=40.00 avg60=20.00 avg300=5.00 total=123456\nfull avg10=12.50 avg60=10.00 avg300=2.00 total=65432\n",
+			want: Pressure{
+				Some: PressureLine{Avg10: 40, Avg60: 20, Avg300: 5, Total: 123456},
+				Full: PressureLine{Avg10: 12.5, Avg60: 10, Avg300: 2, Total: 65432},
+			},
+		},
+		{
+			// kernels before 5.13 report no full line for CPU
+			name:    "some only",
+			content: "some avg10=0.31 avg60=0.12 avg300=0.04 total=9876\n",
+			want:    Pressure{Some: PressureLine{Avg10: 0.31, Avg60: 0.12, Avg300: 0.04, Total: 9876}},
+		},
+		{
+			name:    "blank lines and unknown fields",
+			content: "\nsome avg10=1.00 avg60=1.00 avg300=1.00 total=1 stall=3\n\n",
+			want:    Pressure{Some: PressureLine{Avg10: 1, Avg60: 1, Avg300: 1, Total: 1}},
+		},
+		{
+			name:    "malformed field",
+			content: "some avg10 1.00\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			content: "some avg10=high avg60=1.00 avg300=1.00 total=1\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procRoot := t.TempDir()
+			if tt.content != "" {
+				if err := os.MkdirAll(filepath.Join(procRoot, "pressure"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(procRoot, "pressure", "cpu"), []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := ReadPressure(procRoot, "cpu")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadPressure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadPressure() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// source recorded on nodes marked by the spot interruption watcher
+const SpotInterruptionSource = "spot-agent"
+
+// cloud providers whose instance metadata service can be polled for interruption notices
+const (
+	ProviderAWS = "aws"
+	ProviderGCP = "gcp"
+)
+
+// instance metadata endpoints
+const (
+	awsIMDSTokenURL          = "http://169.254.169.254/latest/api/token"
+	awsSpotInstanceActionURL = "http://169.254.169.254/latest/meta-data/spot/instance-action"
+	gcpPreemptedURL          = "http://metadata.google.internal/computeMetadata/v1/instance/preempted"
+)
+
+// polls the instance metadata service for spot/preemption notices and marks the node as urgently degraded when one arrives
+type SpotInterruptionWatcher struct {
+	// name of the node the agent runs on
+	NodeName string
+	// cloud provider whose metadata service is polled ("aws" or "gcp")
+	Provider string
+	// how often the metadata service is polled
+	Interval   time.Duration
+	HTTPClient *http.Client
+	Marker     *degradation.Marker
+	Log        logr.Logger
+}
+
+// polls for interruption notices until the context is cancelled
+func (w *SpotInterruptionWatcher) Run(ctx context.Context) error {
+	if w.Provider != ProviderAWS && w.Provider != ProviderGCP {
+		return fmt.Errorf("unsupported spot interruption provider %q", w.Provider)
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	w.Log.Info("starting spot interruption watcher", "node", w.NodeName, "provider", w.Provider)
+	for {
+		if err := w.check(ctx); err != nil {
+			w.Log.Error(err, "spot interruption check failed", "node", w.NodeName)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// polls the metadata service once and marks the node if an interruption is pending
+func (w *SpotInterruptionWatcher) check(ctx context.Context) error {
+	var interrupted bool
+	var err error
+	switch w.Provider {
+	case ProviderAWS:
+		interrupted, err = w.awsInterruptionPending(ctx)
+	case ProviderGCP:
+		interrupted, err = w.gcpPreempted(ctx)
+	}
+	if err != nil || !interrupted {
+		return err
+	}
+
+	// interruptions cannot be cancelled, so the marker only needs to outlive the instance
+	return w.Marker.Mark(ctx, w.NodeName, degradation.Marking{
+		Source:   SpotInterruptionSource,
+		Reason:   fmt.Sprintf("%s spot interruption notice received", w.Provider),
+		Severity: degradation.SeverityUrgent,
+		TTL:      10 * time.Minute,
+	})
+}
+
+// queries the AWS spot instance-action endpoint through IMDSv2; a 404 means no interruption is scheduled
+func (w *SpotInterruptionWatcher) awsInterruptionPending(ctx context.Context) (bool, error) {
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPut, awsIMDSTokenURL, nil)
+	if err != nil {
+		return false, err
+	}
+	tokenReq.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, status, err := w.get(tokenReq)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		// the token response is sent with a hop limit of 1 by default, which the pod network's extra hop uses up
+		return false, fmt.Errorf("timed out obtaining IMDSv2 token; run the agent with hostNetwork, or raise the instance's metadata hop limit to 2: %w", err)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to obtain IMDSv2 token: %w", err)
+	}
+	if status != http.StatusOK {
+		return false, fmt.Errorf("failed to obtain IMDSv2 token: status %d", status)
+	}
+
+	actionReq, err := http.NewRequestWithContext(ctx, http.MethodGet, awsSpotInstanceActionURL, nil)
+	if err != nil {
+		return false, err
+	}
+	actionReq.Header.Set("X-aws-ec2-metadata-token", token)
+	_, status, err = w.get(actionReq)
+	if err != nil {
+		return false, fmt.Errorf("failed to query spot instance action: %w", err)
+	}
+
+	switch status {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status %d from spot instance action endpoint", status)
+	}
+}
+
+// queries the GCP metadata server's preempted flag
+func (w *SpotInterruptionWatcher) gcpPreempted(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpPreemptedURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	body, status, err := w.get(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to query preemption status: %w", err)
+	}
+	if status != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d from preemption endpoint", status)
+	}
+	return strings.EqualFold(strings.TrimSpace(body), "TRUE"), nil
+}
+
+// performs a metadata request and returns its body and status code
+func (w *SpotInterruptionWatcher) get(req *http.Request) (string, int, error) {
+	resp, err := w.HTTPClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", 0, err
+	}
+	return string(body), resp.StatusCode, nil
+}
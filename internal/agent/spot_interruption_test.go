@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// routes every request to a test server, whatever its host
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// returns a watcher whose metadata requests are answered by the given handler
+func testWatcher(t *testing.T, provider string, handler http.HandlerFunc) *SpotInterruptionWatcher {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	return &SpotInterruptionWatcher{
+		NodeName:   "node-a",
+		Provider:   provider,
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}, Timeout: 200 * time.Millisecond},
+		Log:        logr.Discard(),
+	}
+}
+
+func TestAWSInterruptionPending(t *testing.T) {
+	tests := []struct {
+		name         string
+		actionStatus int
+		// delays the token response past the client's timeout
+		tokenTimeout bool
+		want         bool
+		wantErr      string
+	}{
+		{name: "interruption scheduled", actionStatus: http.StatusOK, want: true},
+		{name: "no interruption", actionStatus: http.StatusNotFound},
+		{name: "unexpected status", actionStatus: http.StatusInternalServerError, wantErr: "unexpected status 500"},
+		{name: "token timeout", tokenTimeout: true, wantErr: "hostNetwork"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := testWatcher(t, ProviderAWS, func(rw http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case "/latest/api/token":
+					if tt.tokenTimeout {
+						time.Sleep(time.Second)
+					}
+					_, _ = rw.Write([]byte("token"))
+				case "/latest/meta-data/spot/instance-action":
+					if req.Header.Get("X-aws-ec2-metadata-token") != "token" {
+						rw.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					rw.WriteHeader(tt.actionStatus)
+				}
+			})
+
+			got, err := w.awsInterruptionPending(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("awsInterruptionPending() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("awsInterruptionPending() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGCPPreempted(t *testing.T) {
+	for body, want := range map[string]bool{"TRUE\n": true, "FALSE": false} {
+		w := testWatcher(t, ProviderGCP, func(rw http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Metadata-Flavor") != "Google" {
+				rw.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = rw.Write([]byte(body))
+		})
+		if got, err := w.gcpPreempted(context.Background()); err != nil || got != want {
+			t.Errorf("gcpPreempted() with body %q = %v, %v, want %v", body, got, err, want)
+		}
+	}
+}
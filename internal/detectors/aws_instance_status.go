@@ -0,0 +1,120 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// maximum number of instance IDs accepted by a single DescribeInstanceStatus call
+const maxInstanceStatusIDs = 100
+
+// subset of the EC2 API used by the AWS instance status detector
+type EC2InstanceStatusAPI interface {
+	DescribeInstanceStatus(ctx context.Context, params *ec2.DescribeInstanceStatusInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceStatusOutput, error)
+}
+
+// marks nodes as degraded when their backing EC2 instance fails its instance or system status checks or has a scheduled event pending
+type AWSInstanceStatusDetector struct {
+	Client client.Client
+	EC2    EC2InstanceStatusAPI
+}
+
+// creates a new AWSInstanceStatusDetector instance
+func NewAWSInstanceStatusDetector(cli client.Client, ec2API EC2InstanceStatusAPI) *AWSInstanceStatusDetector {
+	return &AWSInstanceStatusDetector{
+		Client: cli,
+		EC2:    ec2API,
+	}
+}
+
+// implements the degradation.Detector interface
+func (d *AWSInstanceStatusDetector) Name() string {
+	return "aws-instance-status"
+}
+
+// implements the degradation.Detector interface
+func (d *AWSInstanceStatusDetector) Detect(ctx context.Context) ([]degradation.Finding, error) {
+	nodeList := &core.NodeList{}
+	if err := d.Client.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodesByInstance := map[string]string{}
+	instanceIDs := []string{}
+	for _, node := range nodeList.Items {
+		instanceID, ok := awsInstanceID(node.Spec.ProviderID)
+		if !ok {
+			continue
+		}
+		nodesByInstance[instanceID] = node.Name
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+
+	findings := []degradation.Finding{}
+	for start := 0; start < len(instanceIDs); start += maxInstanceStatusIDs {
+		end := min(start+maxInstanceStatusIDs, len(instanceIDs))
+
+		out, err := d.EC2.DescribeInstanceStatus(ctx, &ec2.DescribeInstanceStatusInput{
+			InstanceIds:         instanceIDs[start:end],
+			IncludeAllInstances: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe EC2 instance status: %w", err)
+		}
+
+		for _, status := range out.InstanceStatuses {
+			nodeName, ok := nodesByInstance[aws.ToString(status.InstanceId)]
+			if !ok {
+				continue
+			}
+			reasons := instanceStatusProblems(status)
+			findings = append(findings, degradation.Finding{
+				Node:     nodeName,
+				Degraded: len(reasons) > 0,
+				Reason:   strings.Join(reasons, "; "),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// extracts the EC2 instance ID from a node provider ID of the form aws:///<zone>/<instance-id>
+func awsInstanceID(providerID string) (string, bool) {
+	if !strings.HasPrefix(providerID, "aws://") {
+		return "", false
+	}
+	instanceID := providerID[strings.LastIndex(providerID, "/")+1:]
+	if !strings.HasPrefix(instanceID, "i-") {
+		return "", false
+	}
+	return instanceID, true
+}
+
+// lists the failed status checks and pending scheduled events of an instance
+func instanceStatusProblems(status ec2types.InstanceStatus) []string {
+	problems := []string{}
+	if status.SystemStatus != nil && status.SystemStatus.Status == ec2types.SummaryStatusImpaired {
+		problems = append(problems, "EC2 system status check impaired")
+	}
+	if status.InstanceStatus != nil && status.InstanceStatus.Status == ec2types.SummaryStatusImpaired {
+		problems = append(problems, "EC2 instance status check impaired")
+	}
+	for _, event := range status.Events {
+		// completed events keep being reported with a "[Completed]" description prefix
+		if strings.HasPrefix(aws.ToString(event.Description), "[Completed]") || strings.HasPrefix(aws.ToString(event.Description), "[Canceled]") {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("EC2 scheduled event %s", event.Code))
+	}
+	return problems
+}
@@ -0,0 +1,109 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EC2 API reporting fixed instance statuses, recording the instance IDs of each call
+type fakeEC2 struct {
+	statuses map[string]ec2types.InstanceStatus
+	calls    [][]string
+}
+
+func (f *fakeEC2) DescribeInstanceStatus(_ context.Context, params *ec2.DescribeInstanceStatusInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceStatusOutput, error) {
+	f.calls = append(f.calls, params.InstanceIds)
+	out := &ec2.DescribeInstanceStatusOutput{}
+	for _, id := range params.InstanceIds {
+		status, ok := f.statuses[id]
+		if !ok {
+			status = ec2types.InstanceStatus{
+				SystemStatus:   &ec2types.InstanceStatusSummary{Status: ec2types.SummaryStatusOk},
+				InstanceStatus: &ec2types.InstanceStatusSummary{Status: ec2types.SummaryStatusOk},
+			}
+		}
+		status.InstanceId = aws.String(id)
+		out.InstanceStatuses = append(out.InstanceStatuses, status)
+	}
+	return out, nil
+}
+
+// returns a node backed by the given EC2 instance
+func ec2Node(name string, instanceID string) *core.Node {
+	return &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: name},
+		Spec:       core.NodeSpec{ProviderID: "aws:///us-east-1a/" + instanceID},
+	}
+}
+
+func TestAWSInstanceStatusDetectorDetect(t *testing.T) {
+	ec2API := &fakeEC2{statuses: map[string]ec2types.InstanceStatus{
+		"i-system": {SystemStatus: &ec2types.InstanceStatusSummary{Status: ec2types.SummaryStatusImpaired}},
+		"i-instance": {
+			InstanceStatus: &ec2types.InstanceStatusSummary{Status: ec2types.SummaryStatusImpaired},
+			Events:         []ec2types.InstanceStatusEvent{{Code: ec2types.EventCodeInstanceRetirement, Description: aws.String("The instance is scheduled for retirement")}},
+		},
+		"i-completed": {Events: []ec2types.InstanceStatusEvent{{Code: ec2types.EventCodeSystemReboot, Description: aws.String("[Completed] Scheduled reboot")}}},
+	}}
+	cli := fakeNodeClient(
+		ec2Node("healthy", "i-healthy"),
+		ec2Node("system", "i-system"),
+		ec2Node("instance", "i-instance"),
+		ec2Node("completed", "i-completed"),
+		&core.Node{ObjectMeta: meta.ObjectMeta{Name: "gce"}, Spec: core.NodeSpec{ProviderID: "gce://project/us-central1-a/gce"}},
+	)
+	detector := NewAWSInstanceStatusDetector(cli, ec2API)
+
+	findings, err := detector.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, finding := range findings {
+		got[finding.Node] = finding.Reason
+		if finding.Degraded != (finding.Reason != "") {
+			t.Errorf("finding for %s: degraded = %v with reason %q", finding.Node, finding.Degraded, finding.Reason)
+		}
+	}
+	want := map[string]string{
+		"healthy":   "",
+		"system":    "EC2 system status check impaired",
+		"instance":  "EC2 instance status check impaired; EC2 scheduled event instance-retirement",
+		"completed": "",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	for node, reason := range want {
+		if got[node] != reason {
+			t.Errorf("reason for %s = %q, want %q", node, got[node], reason)
+		}
+	}
+}
+
+func TestAWSInstanceStatusDetectorBatchesInstances(t *testing.T) {
+	nodes := []*core.Node{}
+	for i := range maxInstanceStatusIDs + 1 {
+		nodes = append(nodes, ec2Node(fmt.Sprintf("node-%d", i), fmt.Sprintf("i-%d", i)))
+	}
+	ec2API := &fakeEC2{}
+	detector := NewAWSInstanceStatusDetector(fakeNodeClient(nodes...), ec2API)
+
+	findings, err := detector.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(findings) != len(nodes) {
+		t.Errorf("findings = %d, want one per node (%d)", len(findings), len(nodes))
+	}
+	if len(ec2API.calls) != 2 || len(ec2API.calls[0]) != maxInstanceStatusIDs || len(ec2API.calls[1]) != 1 {
+		t.Errorf("DescribeInstanceStatus calls = %d, want a full batch of %d and one of the remaining instance", len(ec2API.calls), maxInstanceStatusIDs)
+	}
+}
@@ -0,0 +1,203 @@
+package detectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// filesystem statistics reported by the kubelet summary API
+type FilesystemStats struct {
+	AvailableBytes *uint64 `json:"availableBytes"`
+	CapacityBytes  *uint64 `json:"capacityBytes"`
+	InodesFree     *uint64 `json:"inodesFree"`
+	Inodes         *uint64 `json:"inodes"`
+}
+
+// subset of the kubelet summary API response consumed by the detector
+type NodeSummary struct {
+	Node struct {
+		Fs      *FilesystemStats `json:"fs"`
+		Runtime *struct {
+			ImageFs *FilesystemStats `json:"imageFs"`
+		} `json:"runtime"`
+	} `json:"node"`
+}
+
+// retrieves the kubelet summary of a node
+type SummaryFetcher interface {
+	NodeSummary(ctx context.Context, nodeName string) (*NodeSummary, error)
+}
+
+// fetches kubelet summaries through the API server's node proxy
+type ProxySummaryFetcher struct {
+	Clientset kubernetes.Interface
+}
+
+// implements the SummaryFetcher interface
+func (f *ProxySummaryFetcher) NodeSummary(ctx context.Context, nodeName string) (*NodeSummary, error) {
+	raw, err := f.Clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").
+		Name(nodeName).
+		SubResource("proxy").
+		Suffix("stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch kubelet summary for node %s: %w", nodeName, err)
+	}
+
+	summary := &NodeSummary{}
+	if err := json.Unmarshal(raw, summary); err != nil {
+		return nil, fmt.Errorf("failed to decode kubelet summary for node %s: %w", nodeName, err)
+	}
+	return summary, nil
+}
+
+// a single used-fraction sample of one filesystem signal
+type usageSample struct {
+	at   time.Time
+	used float64
+}
+
+// predicts filesystem and inode exhaustion from usage trends and marks nodes that will cross their limit within the horizon
+type FilesystemExhaustionDetector struct {
+	Client  client.Client
+	Summary SummaryFetcher
+	// used fraction (0-1) treated as exhausted; should sit below the kubelet's hard eviction thresholds
+	UsageLimit float64
+	// how far ahead exhaustion is predicted
+	Horizon time.Duration
+	// how much usage history is used to compute the trend
+	TrendWindow time.Duration
+
+	mu      sync.Mutex
+	history map[string]map[string][]usageSample
+}
+
+// creates a new FilesystemExhaustionDetector instance
+func NewFilesystemExhaustionDetector(cli client.Client, summary SummaryFetcher, usageLimit float64, horizon time.Duration, trendWindow time.Duration) *FilesystemExhaustionDetector {
+	return &FilesystemExhaustionDetector{
+		Client:      cli,
+		Summary:     summary,
+		UsageLimit:  usageLimit,
+		Horizon:     horizon,
+		TrendWindow: trendWindow,
+		history:     make(map[string]map[string][]usageSample),
+	}
+}
+
+// implements the degradation.Detector interface
+func (d *FilesystemExhaustionDetector) Name() string {
+	return "filesystem-exhaustion"
+}
+
+// implements the degradation.Detector interface
+func (d *FilesystemExhaustionDetector) Detect(ctx context.Context) ([]degradation.Finding, error) {
+	nodeList := &core.NodeList{}
+	if err := d.Client.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	findings := make([]degradation.Finding, 0, len(nodeList.Items))
+	seen := make(map[string]bool, len(nodeList.Items))
+	var errs []string
+
+	for _, node := range nodeList.Items {
+		seen[node.Name] = true
+		summary, err := d.Summary.NodeSummary(ctx, node.Name)
+		if err != nil {
+			// a node whose summary can't be fetched keeps its current marker until the next run
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		signals := map[string]float64{}
+		if fs := summary.Node.Fs; fs != nil {
+			addUsageSignals(signals, "nodefs", fs)
+		}
+		if summary.Node.Runtime != nil && summary.Node.Runtime.ImageFs != nil {
+			addUsageSignals(signals, "imagefs", summary.Node.Runtime.ImageFs)
+		}
+
+		problems := []string{}
+		for signal, used := range signals {
+			if eta, ok := d.predict(node.Name, signal, usageSample{at: now, used: used}); ok {
+				problems = append(problems, fmt.Sprintf("%s at %.0f%%, predicted to reach %.0f%% in %s", signal, used*100, d.UsageLimit*100, eta.Round(time.Minute)))
+			}
+		}
+		sort.Strings(problems)
+
+		findings = append(findings, degradation.Finding{
+			Node:     node.Name,
+			Degraded: len(problems) > 0,
+			Reason:   strings.Join(problems, "; "),
+		})
+	}
+
+	for nodeName := range d.history {
+		if !seen[nodeName] {
+			delete(d.history, nodeName)
+		}
+	}
+
+	if len(errs) > 0 && len(findings) == 0 {
+		return nil, fmt.Errorf("failed to fetch kubelet summaries: %s", strings.Join(errs, "; "))
+	}
+	return findings, nil
+}
+
+// records a sample and reports whether the signal is predicted to reach the usage limit within the horizon, along with the estimated time until it does
+func (d *FilesystemExhaustionDetector) predict(nodeName string, signal string, sample usageSample) (time.Duration, bool) {
+	if d.history[nodeName] == nil {
+		d.history[nodeName] = map[string][]usageSample{}
+	}
+
+	cutoff := sample.at.Add(-d.TrendWindow)
+	samples := append(d.history[nodeName][signal], sample)
+	for len(samples) > 1 && samples[0].at.Before(cutoff) {
+		samples = samples[1:]
+	}
+	d.history[nodeName][signal] = samples
+
+	if sample.used >= d.UsageLimit {
+		return 0, true
+	}
+	if len(samples) < 2 {
+		return 0, false
+	}
+
+	// linear trend between the oldest and newest sample in the window
+	oldest := samples[0]
+	elapsed := sample.at.Sub(oldest.at)
+	growth := sample.used - oldest.used
+	if elapsed <= 0 || growth <= 0 {
+		return 0, false
+	}
+
+	eta := time.Duration((d.UsageLimit - sample.used) / growth * float64(elapsed))
+	return eta, eta <= d.Horizon
+}
+
+// adds the used byte and inode fractions of a filesystem to the signal set
+func addUsageSignals(signals map[string]float64, name string, fs *FilesystemStats) {
+	if fs.CapacityBytes != nil && fs.AvailableBytes != nil && *fs.CapacityBytes > 0 {
+		signals[name+" space"] = 1 - float64(*fs.AvailableBytes)/float64(*fs.CapacityBytes)
+	}
+	if fs.Inodes != nil && fs.InodesFree != nil && *fs.Inodes > 0 {
+		signals[name+" inodes"] = 1 - float64(*fs.InodesFree)/float64(*fs.Inodes)
+	}
+}
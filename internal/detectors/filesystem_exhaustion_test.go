@@ -0,0 +1,148 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// kubelet summaries by node name; nodes without one fail to be fetched
+type fakeSummaries map[string]*NodeSummary
+
+func (f fakeSummaries) NodeSummary(_ context.Context, nodeName string) (*NodeSummary, error) {
+	summary, ok := f[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("failed to fetch kubelet summary for node %s: connection refused", nodeName)
+	}
+	return summary, nil
+}
+
+// returns filesystem statistics with the given used fractions of space and inodes
+func filesystemStats(usedSpace float64, usedInodes float64) *FilesystemStats {
+	capacity, inodes := uint64(1000), uint64(1000)
+	available, inodesFree := uint64((1-usedSpace)*1000), uint64((1-usedInodes)*1000)
+	return &FilesystemStats{AvailableBytes: &available, CapacityBytes: &capacity, InodesFree: &inodesFree, Inodes: &inodes}
+}
+
+// returns a kubelet summary with the given node and image filesystems
+func nodeSummary(nodefs *FilesystemStats, imagefs *FilesystemStats) *NodeSummary {
+	summary := &NodeSummary{}
+	summary.Node.Fs = nodefs
+	if imagefs != nil {
+		summary.Node.Runtime = &struct {
+			ImageFs *FilesystemStats `json:"imageFs"`
+		}{ImageFs: imagefs}
+	}
+	return summary
+}
+
+func namedNode(name string) *core.Node {
+	return &core.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+}
+
+func TestFilesystemExhaustionDetectorDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		summaries fakeSummaries
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name: "usage limits",
+			summaries: fakeSummaries{
+				"healthy": nodeSummary(filesystemStats(0.5, 0.1), filesystemStats(0.5, 0.1)),
+				"nodefs":  nodeSummary(filesystemStats(0.95, 0.1), nil),
+				"imagefs": nodeSummary(filesystemStats(0.5, 0.1), filesystemStats(0.5, 0.92)),
+			},
+			want: map[string]string{
+				"healthy": "",
+				"nodefs":  "nodefs space at 95%, predicted to reach 90% in 0s",
+				"imagefs": "imagefs inodes at 92%, predicted to reach 90% in 0s",
+			},
+		},
+		{
+			name:      "summary of a node unavailable",
+			summaries: fakeSummaries{"healthy": nodeSummary(filesystemStats(0.5, 0.1), nil)},
+			want:      map[string]string{"healthy": ""},
+		},
+		{
+			name:      "no summary available",
+			summaries: fakeSummaries{},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := fakeNodeClient(namedNode("healthy"), namedNode("nodefs"), namedNode("imagefs"))
+			detector := NewFilesystemExhaustionDetector(cli, tt.summaries, 0.9, time.Hour, time.Hour)
+
+			findings, err := detector.Detect(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Detect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := map[string]string{}
+			for _, finding := range findings {
+				got[finding.Node] = finding.Reason
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("findings = %v, want %v", got, tt.want)
+			}
+			for node, reason := range tt.want {
+				if got[node] != reason {
+					t.Errorf("reason for %s = %q, want %q", node, got[node], reason)
+				}
+			}
+		})
+	}
+}
+
+func TestFilesystemExhaustionDetectorPredict(t *testing.T) {
+	start := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		samples []usageSample
+		wantETA time.Duration
+		want    bool
+	}{
+		{
+			name:    "single sample below the limit",
+			samples: []usageSample{{at: start, used: 0.5}},
+		},
+		{
+			name:    "growing to the limit within the horizon",
+			samples: []usageSample{{at: start, used: 0.5}, {at: start.Add(10 * time.Minute), used: 0.6}},
+			wantETA: 30 * time.Minute,
+			want:    true,
+		},
+		{
+			name:    "growing to the limit beyond the horizon",
+			samples: []usageSample{{at: start, used: 0.5}, {at: start.Add(10 * time.Minute), used: 0.51}},
+			wantETA: 390 * time.Minute,
+		},
+		{
+			name:    "shrinking",
+			samples: []usageSample{{at: start, used: 0.6}, {at: start.Add(10 * time.Minute), used: 0.5}},
+		},
+		{
+			name:    "trend limited to the window",
+			samples: []usageSample{{at: start, used: 0.1}, {at: start.Add(2 * time.Hour), used: 0.6}, {at: start.Add(2*time.Hour + 10*time.Minute), used: 0.6}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewFilesystemExhaustionDetector(nil, nil, 0.9, time.Hour, time.Hour)
+			var eta time.Duration
+			var ok bool
+			for _, sample := range tt.samples {
+				eta, ok = detector.predict("node-a", "nodefs space", sample)
+			}
+			if ok != tt.want || (tt.wantETA != 0 && eta.Round(time.Minute) != tt.wantETA) {
+				t.Errorf("predict() = %s, %v, want %s, %v", eta, ok, tt.wantETA, tt.want)
+			}
+		})
+	}
+}
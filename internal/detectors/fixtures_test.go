@@ -0,0 +1,59 @@
+package detectors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lokeshllkumar/kube-balance/internal/promquery"
+)
+
+// returns a Prometheus client answering each query with the given samples, and every other query with an empty vector
+func fakePrometheus(t *testing.T, results map[string][]promquery.Sample) *promquery.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result := []map[string]any{}
+		for _, sample := range results[req.URL.Query().Get("query")] {
+			result = append(result, map[string]any{"metric": sample.Labels, "value": []any{0, formatValue(sample.Value)}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "success",
+			"data":   map[string]any{"resultType": "vector", "result": result},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return promquery.NewClient(server.URL)
+}
+
+// formats a sample value the way the Prometheus API does
+func formatValue(value float64) string {
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+// returns a fake client holding the given nodes
+func fakeNodeClient(nodes ...*core.Node) client.Client {
+	objs := make([]client.Object, 0, len(nodes))
+	for _, node := range nodes {
+		objs = append(objs, node)
+	}
+	return fake.NewClientBuilder().WithScheme(clientscheme.Scheme).WithObjects(objs...).Build()
+}
+
+// returns a node with the given labels, allocating the given number of GPUs
+func gpuNode(name string, gpus int64, labels map[string]string) *core.Node {
+	return &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: name, Labels: labels},
+		Status: core.NodeStatus{
+			Allocatable: core.ResourceList{NvidiaGPUResource: *resource.NewQuantity(gpus, resource.DecimalSI)},
+		},
+	}
+}
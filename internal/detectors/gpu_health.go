@@ -0,0 +1,113 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/internal/promquery"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// extended resource advertised by the NVIDIA device plugin
+const NvidiaGPUResource core.ResourceName = "nvidia.com/gpu"
+
+// default DCGM exporter queries, formatted with the series label holding the node name; each returns one series per
+// node with a non-zero value when the GPU is faulty
+//
+// DCGM_FI_DEV_XID_ERRORS is a gauge holding the last XID seen, which it keeps long after the fault, so only the XIDs
+// reported within the window count
+const (
+	DefaultGPUXIDQuery = `count by (%[1]s) (changes(DCGM_FI_DEV_XID_ERRORS[10m]) > 0)`
+	DefaultGPUECCQuery = `sum by (%[1]s) (increase(DCGM_FI_DEV_ECC_DBE_VOL_TOTAL[10m])) > 0`
+)
+
+// default series label holding the node name; the DCGM exporter's own Hostname label holds the exporter pod's
+// hostname, so the scrape config is expected to relabel __meta_kubernetes_pod_node_name into it
+const DefaultGPUNodeLabel = "node"
+
+// marks GPU nodes as degraded when DCGM exporter metrics report XID errors or ECC faults, or when a GPU operator label flags them as unhealthy
+type GPUHealthDetector struct {
+	Client client.Client
+	// optional; DCGM-based checks are skipped when nil
+	Prometheus *promquery.Client
+	// queries whose non-zero results indicate a faulty GPU, keyed by a short description
+	Queries map[string]string
+	// series label holding the node name
+	NodeLabel string
+	// node labels (key=value) set by GPU operators or node problem detectors when a GPU is unhealthy
+	UnhealthyNodeLabels map[string]string
+}
+
+// creates a new GPUHealthDetector instance using the default DCGM queries, reading node names from the given series label
+func NewGPUHealthDetector(cli client.Client, prom *promquery.Client, nodeLabel string, unhealthyNodeLabels map[string]string) *GPUHealthDetector {
+	return &GPUHealthDetector{
+		Client:     cli,
+		Prometheus: prom,
+		Queries: map[string]string{
+			"XID errors":            fmt.Sprintf(DefaultGPUXIDQuery, nodeLabel),
+			"double-bit ECC errors": fmt.Sprintf(DefaultGPUECCQuery, nodeLabel),
+		},
+		NodeLabel:           nodeLabel,
+		UnhealthyNodeLabels: unhealthyNodeLabels,
+	}
+}
+
+// implements the degradation.Detector interface
+func (d *GPUHealthDetector) Name() string {
+	return "gpu-health"
+}
+
+// implements the degradation.Detector interface
+func (d *GPUHealthDetector) Detect(ctx context.Context) ([]degradation.Finding, error) {
+	nodeList := &core.NodeList{}
+	if err := d.Client.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	problems := map[string][]string{}
+	if d.Prometheus != nil {
+		for description, query := range d.Queries {
+			samples, err := d.Prometheus.Query(ctx, query)
+			if err != nil {
+				return nil, err
+			}
+			for _, sample := range samples {
+				nodeName := sample.Labels[d.NodeLabel]
+				if nodeName == "" || sample.Value == 0 {
+					continue
+				}
+				problems[nodeName] = append(problems[nodeName], fmt.Sprintf("GPU %s (%g)", description, sample.Value))
+			}
+		}
+	}
+
+	findings := []degradation.Finding{}
+	for _, node := range nodeList.Items {
+		gpus := node.Status.Allocatable[NvidiaGPUResource]
+		if gpus.IsZero() {
+			continue
+		}
+
+		nodeProblems := problems[node.Name]
+		for key, value := range d.UnhealthyNodeLabels {
+			if node.Labels[key] == value {
+				nodeProblems = append(nodeProblems, fmt.Sprintf("GPU unhealthy label %s=%s", key, value))
+			}
+		}
+		sort.Strings(nodeProblems)
+
+		findings = append(findings, degradation.Finding{
+			Node:     node.Name,
+			Degraded: len(nodeProblems) > 0,
+			Reason:   strings.Join(nodeProblems, "; "),
+			Resource: NvidiaGPUResource,
+		})
+	}
+
+	return findings, nil
+}
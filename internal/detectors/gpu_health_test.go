@@ -0,0 +1,60 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lokeshllkumar/kube-balance/internal/promquery"
+)
+
+func TestGPUHealthDetectorDetect(t *testing.T) {
+	prom := fakePrometheus(t, map[string][]promquery.Sample{
+		fmt.Sprintf(DefaultGPUXIDQuery, "node"): {
+			{Labels: map[string]string{"node": "gpu-xid"}, Value: 1},
+			// series the scrape config didn't relabel carry no node name
+			{Labels: map[string]string{"Hostname": "nvidia-dcgm-exporter-x7k2p"}, Value: 1},
+		},
+		fmt.Sprintf(DefaultGPUECCQuery, "node"): {
+			{Labels: map[string]string{"node": "gpu-ecc"}, Value: 2},
+			{Labels: map[string]string{"node": "cpu-only"}, Value: 1},
+		},
+	})
+	cli := fakeNodeClient(
+		gpuNode("gpu-healthy", 8, nil),
+		gpuNode("gpu-xid", 8, nil),
+		gpuNode("gpu-ecc", 8, nil),
+		gpuNode("gpu-labelled", 8, map[string]string{"nvidia.com/gpu.health": "unhealthy"}),
+		gpuNode("cpu-only", 0, nil),
+	)
+	detector := NewGPUHealthDetector(cli, prom, DefaultGPUNodeLabel, map[string]string{"nvidia.com/gpu.health": "unhealthy"})
+
+	findings, err := detector.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, finding := range findings {
+		if finding.Resource != NvidiaGPUResource {
+			t.Errorf("finding for %s has resource %q, want %q", finding.Node, finding.Resource, NvidiaGPUResource)
+		}
+		got[finding.Node] = finding.Reason
+		if finding.Degraded != (finding.Reason != "") {
+			t.Errorf("finding for %s: degraded = %v with reason %q", finding.Node, finding.Degraded, finding.Reason)
+		}
+	}
+	want := map[string]string{
+		"gpu-healthy":  "",
+		"gpu-xid":      "GPU XID errors (1)",
+		"gpu-ecc":      "GPU double-bit ECC errors (2)",
+		"gpu-labelled": "GPU unhealthy label nvidia.com/gpu.health=unhealthy",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	for node, reason := range want {
+		if got[node] != reason {
+			t.Errorf("reason for %s = %q, want %q", node, got[node], reason)
+		}
+	}
+}
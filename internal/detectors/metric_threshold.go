@@ -0,0 +1,100 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/internal/promquery"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// a PromQL query whose per-node value marks the node as degraded once it exceeds a threshold
+type MetricCheck struct {
+	Description string
+	Query       string
+	Threshold   float64
+}
+
+// marks nodes as degraded when any of a set of Prometheus metric checks exceeds its threshold
+type MetricThresholdDetector struct {
+	Client     client.Client
+	Prometheus *promquery.Client
+	// identifies the detector and the degradation source recorded on nodes
+	DetectorName string
+	Checks       []MetricCheck
+	// series label holding the node name
+	NodeLabel string
+}
+
+// creates a detector for network resource exhaustion signals exported by node-exporter; thresholds are the conntrack table fill ratio and the per-second NIC error and drop rates
+func NewNetworkExhaustionDetector(cli client.Client, prom *promquery.Client, nodeLabel string, conntrackRatio float64, errorRate float64, dropRate float64) *MetricThresholdDetector {
+	return &MetricThresholdDetector{
+		Client:       cli,
+		Prometheus:   prom,
+		DetectorName: "network-exhaustion",
+		NodeLabel:    nodeLabel,
+		Checks: []MetricCheck{
+			{
+				Description: "conntrack table fill ratio",
+				Query:       fmt.Sprintf(`max by (%[1]s) (node_nf_conntrack_entries / node_nf_conntrack_entries_limit)`, nodeLabel),
+				Threshold:   conntrackRatio,
+			},
+			{
+				Description: "NIC errors/s",
+				Query:       fmt.Sprintf(`sum by (%[1]s) (rate(node_network_receive_errs_total[5m]) + rate(node_network_transmit_errs_total[5m]))`, nodeLabel),
+				Threshold:   errorRate,
+			},
+			{
+				Description: "packet drops/s",
+				Query:       fmt.Sprintf(`sum by (%[1]s) (rate(node_network_receive_drop_total[5m]) + rate(node_network_transmit_drop_total[5m]))`, nodeLabel),
+				Threshold:   dropRate,
+			},
+		},
+	}
+}
+
+// implements the degradation.Detector interface
+func (d *MetricThresholdDetector) Name() string {
+	return d.DetectorName
+}
+
+// implements the degradation.Detector interface
+func (d *MetricThresholdDetector) Detect(ctx context.Context) ([]degradation.Finding, error) {
+	nodeList := &core.NodeList{}
+	if err := d.Client.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	problems := map[string][]string{}
+	for _, check := range d.Checks {
+		samples, err := d.Prometheus.Query(ctx, check.Query)
+		if err != nil {
+			return nil, err
+		}
+		for _, sample := range samples {
+			nodeName := sample.Labels[d.NodeLabel]
+			if nodeName == "" || sample.Value <= check.Threshold {
+				continue
+			}
+			problems[nodeName] = append(problems[nodeName], fmt.Sprintf("%s %.2f above %.2f", check.Description, sample.Value, check.Threshold))
+		}
+	}
+
+	findings := make([]degradation.Finding, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		nodeProblems := problems[node.Name]
+		sort.Strings(nodeProblems)
+		findings = append(findings, degradation.Finding{
+			Node:     node.Name,
+			Degraded: len(nodeProblems) > 0,
+			Reason:   strings.Join(nodeProblems, "; "),
+		})
+	}
+
+	return findings, nil
+}
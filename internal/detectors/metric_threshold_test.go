@@ -0,0 +1,60 @@
+package detectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lokeshllkumar/kube-balance/internal/promquery"
+)
+
+func TestNetworkExhaustionDetectorDetect(t *testing.T) {
+	detector := NewNetworkExhaustionDetector(nil, nil, "instance", 0.9, 1, 10)
+	prom := fakePrometheus(t, map[string][]promquery.Sample{
+		detector.Checks[0].Query: {
+			{Labels: map[string]string{"instance": "conntrack"}, Value: 0.95},
+			{Labels: map[string]string{"instance": "healthy"}, Value: 0.5},
+			{Labels: map[string]string{"instance": "both"}, Value: 0.99},
+			// series without the node label are ignored
+			{Labels: map[string]string{"job": "node-exporter"}, Value: 1},
+		},
+		detector.Checks[1].Query: {
+			{Labels: map[string]string{"instance": "healthy"}, Value: 1},
+			{Labels: map[string]string{"instance": "both"}, Value: 3},
+		},
+		detector.Checks[2].Query: {
+			{Labels: map[string]string{"instance": "drops"}, Value: 12.5},
+		},
+	})
+	detector.Client = fakeNodeClient(namedNode("healthy"), namedNode("conntrack"), namedNode("drops"), namedNode("both"))
+	detector.Prometheus = prom
+
+	findings, err := detector.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, finding := range findings {
+		got[finding.Node] = finding.Reason
+		if finding.Degraded != (finding.Reason != "") {
+			t.Errorf("finding for %s: degraded = %v with reason %q", finding.Node, finding.Degraded, finding.Reason)
+		}
+	}
+	want := map[string]string{
+		// a value at the threshold doesn't exceed it
+		"healthy":   "",
+		"conntrack": "conntrack table fill ratio 0.95 above 0.90",
+		"drops":     "packet drops/s 12.50 above 10.00",
+		"both":      "NIC errors/s 3.00 above 1.00; conntrack table fill ratio 0.99 above 0.90",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	for node, reason := range want {
+		if got[node] != reason {
+			t.Errorf("reason for %s = %q, want %q", node, got[node], reason)
+		}
+	}
+	if detector.Name() != "network-exhaustion" {
+		t.Errorf("Name() = %q, want network-exhaustion", detector.Name())
+	}
+}
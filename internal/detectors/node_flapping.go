@@ -0,0 +1,141 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	cache "k8s.io/client-go/tools/cache"
+	controller_cache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// marks nodes whose Ready condition repeatedly flips between Ready and NotReady within a sliding window
+//
+// transitions are recorded as the Node informer delivers them, since a periodic check would miss those undone in
+// between, as a node's Ready condition only holds its latest transition
+type NodeFlappingDetector struct {
+	Client client.Client
+	Cache  controller_cache.Cache
+	// sliding window over which Ready transitions are counted
+	Window time.Duration
+	// number of transitions within the window at which a node counts as flapping
+	Threshold int
+
+	mu          sync.Mutex
+	transitions map[string][]time.Time
+}
+
+// creates a new NodeFlappingDetector instance
+func NewNodeFlappingDetector(cli client.Client, c controller_cache.Cache, window time.Duration, threshold int) *NodeFlappingDetector {
+	return &NodeFlappingDetector{
+		Client:      cli,
+		Cache:       c,
+		Window:      window,
+		Threshold:   threshold,
+		transitions: make(map[string][]time.Time),
+	}
+}
+
+// implements the degradation.Detector interface
+func (d *NodeFlappingDetector) Name() string {
+	return "node-flapping"
+}
+
+// implements the manager.Runnable interface to record the Ready transitions of nodes from the Node informer
+func (d *NodeFlappingDetector) Start(ctx context.Context) error {
+	informer, err := d.Cache.GetInformer(ctx, &core.Node{})
+	if err != nil {
+		return fmt.Errorf("failed to get Node informer: %v", err)
+	}
+
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: d.observeUpdate,
+	}); err != nil {
+		return fmt.Errorf("failed to watch nodes: %v", err)
+	}
+	return nil
+}
+
+// implements the degradation.Detector interface
+func (d *NodeFlappingDetector) Detect(ctx context.Context) ([]degradation.Finding, error) {
+	nodeList := &core.NodeList{}
+	if err := d.Client.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-d.Window)
+	seen := make(map[string]bool, len(nodeList.Items))
+	findings := make([]degradation.Finding, 0, len(nodeList.Items))
+
+	for _, node := range nodeList.Items {
+		seen[node.Name] = true
+
+		// dropping transitions that fell out of the window
+		history := d.transitions[node.Name]
+		kept := history[:0]
+		for _, t := range history {
+			if t.After(cutoff) {
+				kept = append(kept, t)
+			}
+		}
+		d.transitions[node.Name] = kept
+
+		finding := degradation.Finding{Node: node.Name}
+		if len(kept) >= d.Threshold {
+			finding.Degraded = true
+			finding.Reason = fmt.Sprintf("Ready condition changed %d times within %s", len(kept), d.Window)
+		}
+		findings = append(findings, finding)
+	}
+
+	// forgetting nodes that have left the cluster
+	for nodeName := range d.transitions {
+		if !seen[nodeName] {
+			delete(d.transitions, nodeName)
+		}
+	}
+
+	return findings, nil
+}
+
+// records a Ready transition when a node update flips its Ready condition
+func (d *NodeFlappingDetector) observeUpdate(oldObj interface{}, newObj interface{}) {
+	oldNode, ok := oldObj.(*core.Node)
+	if !ok {
+		return
+	}
+	newNode, ok := newObj.(*core.Node)
+	if !ok {
+		return
+	}
+	if nodeReady(oldNode) != nodeReady(newNode) {
+		d.record(newNode.Name, time.Now())
+	}
+}
+
+// appends a Ready transition to a node's history
+func (d *NodeFlappingDetector) record(nodeName string, transition time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.transitions[nodeName] = append(d.transitions[nodeName], transition)
+}
+
+// reports whether a node's Ready condition is True
+func nodeReady(node *core.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == core.NodeReady {
+			return condition.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package detectors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// returns a node whose Ready condition has the given status
+func readyNode(name string, status core.ConditionStatus) *core.Node {
+	return &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: name},
+		Status: core.NodeStatus{
+			Conditions: []core.NodeCondition{{Type: core.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestNodeFlappingDetectorCountsInformerTransitions(t *testing.T) {
+	tests := []struct {
+		name string
+		// Ready statuses the node goes through, as delivered by the informer
+		statuses []core.ConditionStatus
+		// transitions recorded before the window
+		expired      int
+		wantDegraded bool
+	}{
+		{
+			name:         "flapping",
+			statuses:     []core.ConditionStatus{core.ConditionTrue, core.ConditionFalse, core.ConditionTrue, core.ConditionUnknown, core.ConditionTrue},
+			wantDegraded: true,
+		},
+		{
+			name:     "updates leaving readiness unchanged",
+			statuses: []core.ConditionStatus{core.ConditionFalse, core.ConditionUnknown, core.ConditionFalse, core.ConditionTrue, core.ConditionTrue},
+		},
+		{
+			name:     "transitions fell out of the window",
+			statuses: []core.ConditionStatus{core.ConditionTrue, core.ConditionFalse, core.ConditionTrue},
+			expired:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := readyNode("node-a", tt.statuses[len(tt.statuses)-1])
+			detector := NewNodeFlappingDetector(fakeNodeClient(node), nil, 10*time.Minute, 4)
+			for i := 0; i < tt.expired; i++ {
+				detector.record(node.Name, time.Now().Add(-time.Hour))
+			}
+			for i := 1; i < len(tt.statuses); i++ {
+				detector.observeUpdate(readyNode(node.Name, tt.statuses[i-1]), readyNode(node.Name, tt.statuses[i]))
+			}
+
+			findings, err := detector.Detect(context.Background())
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if len(findings) != 1 || findings[0].Degraded != tt.wantDegraded {
+				t.Errorf("findings = %+v, want node-a degraded = %v", findings, tt.wantDegraded)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// marks nodes covered by a NodeMaintenanceWindow as degraded from the start of its drain until the maintenance ends, so they are drained ahead of it
+type NodeMaintenanceDetector struct {
+	Client client.Client
+}
+
+// creates a new NodeMaintenanceDetector instance
+func NewNodeMaintenanceDetector(cli client.Client) *NodeMaintenanceDetector {
+	return &NodeMaintenanceDetector{
+		Client: cli,
+	}
+}
+
+// +kubebuilder:rbac:groups="kube-balance.io",resources=nodemaintenancewindows,verbs=get;list;watch
+// +kubebuilder:rbac:groups="kube-balance.io",resources=nodemaintenancewindows/status,verbs=get;update;patch
+
+// implements the degradation.Detector interface
+func (d *NodeMaintenanceDetector) Name() string {
+	return "node-maintenance"
+}
+
+// implements the degradation.Detector interface
+func (d *NodeMaintenanceDetector) Detect(ctx context.Context) ([]degradation.Finding, error) {
+	windowList := &api_v1.NodeMaintenanceWindowList{}
+	if err := d.Client.List(ctx, windowList); err != nil {
+		return nil, fmt.Errorf("failed to list node maintenance windows: %w", err)
+	}
+	nodeList := &core.NodeList{}
+	if err := d.Client.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	now := time.Now()
+	reasons := map[string]string{}
+	for i := range windowList.Items {
+		window := &windowList.Items[i]
+		// a window with an invalid selector drains nothing rather than failing every other window
+		nodes, err := matchMaintenanceNodes(window, nodeList.Items)
+		if err != nil {
+			nodes = nil
+		}
+
+		phase := maintenancePhase(window, now)
+		if phase == api_v1.NodeMaintenanceDraining {
+			reason := fmt.Sprintf("planned maintenance %s from %s to %s", window.Name, window.Spec.Start.UTC().Format(time.RFC3339), window.Spec.End.UTC().Format(time.RFC3339))
+			if window.Spec.Reason != "" {
+				reason += ": " + window.Spec.Reason
+			}
+			for _, nodeName := range nodes {
+				if _, ok := reasons[nodeName]; !ok {
+					reasons[nodeName] = reason
+				}
+			}
+		}
+
+		if err := d.updateStatus(ctx, window, phase, nodes); err != nil {
+			return nil, err
+		}
+	}
+
+	findings := make([]degradation.Finding, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		reason, degraded := reasons[node.Name]
+		findings = append(findings, degradation.Finding{
+			Node:     node.Name,
+			Degraded: degraded,
+			Reason:   reason,
+		})
+	}
+	return findings, nil
+}
+
+// returns the names of the nodes a window covers, by name or by label
+func matchMaintenanceNodes(window *api_v1.NodeMaintenanceWindow, nodes []core.Node) ([]string, error) {
+	var selector labels.Selector
+	if window.Spec.NodeSelector != nil {
+		var err error
+		if selector, err = meta.LabelSelectorAsSelector(window.Spec.NodeSelector); err != nil {
+			return nil, err
+		}
+	}
+
+	var matched []string
+	for _, node := range nodes {
+		if slices.Contains(window.Spec.NodeNames, node.Name) || (selector != nil && selector.Matches(labels.Set(node.Labels))) {
+			matched = append(matched, node.Name)
+		}
+	}
+	return matched, nil
+}
+
+// determines where a window stands at the given time
+func maintenancePhase(window *api_v1.NodeMaintenanceWindow, now time.Time) api_v1.NodeMaintenanceWindowPhase {
+	switch {
+	case !now.Before(window.Spec.End.Time):
+		return api_v1.NodeMaintenanceCompleted
+	case !now.Before(window.Spec.DrainStart()):
+		return api_v1.NodeMaintenanceDraining
+	default:
+		return api_v1.NodeMaintenanceScheduled
+	}
+}
+
+// publishes the window's phase and matched nodes on its status when they changed
+func (d *NodeMaintenanceDetector) updateStatus(ctx context.Context, window *api_v1.NodeMaintenanceWindow, phase api_v1.NodeMaintenanceWindowPhase, nodes []string) error {
+	if window.Status.Phase == phase && slices.Equal(window.Status.Nodes, nodes) {
+		return nil
+	}
+
+	patch := client.MergeFrom(window.DeepCopy())
+	window.Status.Phase = phase
+	window.Status.Nodes = nodes
+	if err := d.Client.Status().Patch(ctx, window, patch); err != nil {
+		return fmt.Errorf("failed to update status of node maintenance window %s: %w", window.Name, err)
+	}
+	return nil
+}
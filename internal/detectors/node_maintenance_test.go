@@ -0,0 +1,106 @@
+package detectors
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+)
+
+// returns a maintenance window of the given nodes starting and ending at the given offsets from now
+func maintenanceWindow(name string, start time.Duration, end time.Duration, nodeNames ...string) *api_v1.NodeMaintenanceWindow {
+	now := time.Now()
+	return &api_v1.NodeMaintenanceWindow{
+		ObjectMeta: meta.ObjectMeta{Name: name},
+		Spec: api_v1.NodeMaintenanceWindowSpec{
+			NodeNames: nodeNames,
+			Start:     meta.NewTime(now.Add(start)),
+			End:       meta.NewTime(now.Add(end)),
+		},
+	}
+}
+
+func TestNodeMaintenanceDetectorDetect(t *testing.T) {
+	draining := maintenanceWindow("kernel-upgrade", 30*time.Minute, 2*time.Hour, "node-a")
+	draining.Spec.Reason = "kernel upgrade"
+	byLabel := maintenanceWindow("rack-power", 10*time.Minute, time.Hour)
+	byLabel.Spec.NodeSelector = &meta.LabelSelector{MatchLabels: map[string]string{"rack": "r1"}}
+	// a node under several windows keeps the reason of the first
+	byLabel.Spec.NodeNames = []string{"node-a"}
+	scheduled := maintenanceWindow("firmware", 5*time.Hour, 6*time.Hour, "node-c")
+	completed := maintenanceWindow("network", -2*time.Hour, -time.Hour, "node-c")
+	invalid := maintenanceWindow("invalid", 0, time.Hour)
+	invalid.Spec.NodeSelector = &meta.LabelSelector{MatchExpressions: []meta.LabelSelectorRequirement{{Key: "rack", Operator: "Near"}}}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientscheme.AddToScheme(scheme))
+	utilruntime.Must(api_v1.SchemeBuilder.AddToScheme(scheme))
+	windows := []*api_v1.NodeMaintenanceWindow{draining, byLabel, scheduled, completed, invalid}
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			draining, byLabel, scheduled, completed, invalid,
+			namedNode("node-a"),
+			&core.Node{ObjectMeta: meta.ObjectMeta{Name: "node-b", Labels: map[string]string{"rack": "r1"}}},
+			namedNode("node-c"),
+		).
+		WithStatusSubresource(&api_v1.NodeMaintenanceWindow{}).
+		Build()
+	detector := NewNodeMaintenanceDetector(cli)
+
+	findings, err := detector.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, finding := range findings {
+		got[finding.Node] = finding.Reason
+		if finding.Degraded != (finding.Reason != "") {
+			t.Errorf("finding for %s: degraded = %v with reason %q", finding.Node, finding.Degraded, finding.Reason)
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("findings = %v, want one per node", got)
+	}
+	if !strings.HasPrefix(got["node-a"], "planned maintenance kernel-upgrade from ") || !strings.HasSuffix(got["node-a"], ": kernel upgrade") {
+		t.Errorf("reason for node-a = %q, want the kernel-upgrade window with its reason", got["node-a"])
+	}
+	if !strings.HasPrefix(got["node-b"], "planned maintenance rack-power from ") {
+		t.Errorf("reason for node-b = %q, want the rack-power window", got["node-b"])
+	}
+	if got["node-c"] != "" {
+		t.Errorf("reason for node-c = %q, want it left undrained outside its windows", got["node-c"])
+	}
+
+	wantStatus := map[string]struct {
+		phase api_v1.NodeMaintenanceWindowPhase
+		nodes []string
+	}{
+		"kernel-upgrade": {api_v1.NodeMaintenanceDraining, []string{"node-a"}},
+		"rack-power":     {api_v1.NodeMaintenanceDraining, []string{"node-a", "node-b"}},
+		"firmware":       {api_v1.NodeMaintenanceScheduled, []string{"node-c"}},
+		"network":        {api_v1.NodeMaintenanceCompleted, []string{"node-c"}},
+		"invalid":        {api_v1.NodeMaintenanceDraining, nil},
+	}
+	for _, window := range windows {
+		current := &api_v1.NodeMaintenanceWindow{}
+		if err := cli.Get(context.Background(), client.ObjectKeyFromObject(window), current); err != nil {
+			t.Fatal(err)
+		}
+		want := wantStatus[window.Name]
+		if current.Status.Phase != want.phase || !slices.Equal(current.Status.Nodes, want.nodes) {
+			t.Errorf("status of %s = %s %v, want %s %v", window.Name, current.Status.Phase, current.Status.Nodes, want.phase, want.nodes)
+		}
+	}
+}
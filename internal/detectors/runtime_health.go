@@ -0,0 +1,252 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// a class of runtime failure events and how much each occurrence contributes to a node's degradation score
+type RuntimeSignal struct {
+	// event reason the signal is selected by
+	Reason string
+	// optional case-insensitive substrings, one of which the event message must contain
+	MessageContains []string
+	// score contributed by each occurrence
+	Weight float64
+}
+
+// default runtime failure signals
+var DefaultRuntimeSignals = map[string]RuntimeSignal{
+	"sandbox creation failures": {Reason: "FailedCreatePodSandBox", Weight: 1},
+	"image pull timeouts":       {Reason: "Failed", MessageContains: []string{"i/o timeout", "deadline exceeded", "timed out"}, Weight: 1},
+	// restarts reported by node-problem-detector's systemd monitor
+	"containerd restarts": {Reason: "ContainerdStart", Weight: 3},
+	"cri-o restarts":      {Reason: "CRIOStart", Weight: 3},
+	"docker restarts":     {Reason: "DockerStart", Weight: 3},
+}
+
+// scores nodes by the rate of container runtime failure events observed on them and marks nodes whose score crosses a threshold
+type RuntimeHealthDetector struct {
+	// uncached reader, so that events don't need to be held in the manager's cache
+	Reader client.Reader
+	// runtime failure signals, keyed by a short description
+	Signals map[string]RuntimeSignal
+	// window over which events are counted
+	Window time.Duration
+	// score at which a node counts as degraded
+	Threshold float64
+
+	mu sync.Mutex
+	// events seen by previous checks, by UID
+	observed map[types.UID]*observedEvent
+}
+
+// occurrences of an event first seen by a check
+type eventOccurrences struct {
+	at    time.Time
+	count int32
+}
+
+// an event's count as of the latest check, along with the occurrences each check added to it
+type observedEvent struct {
+	count       int32
+	occurrences []eventOccurrences
+}
+
+// creates a new RuntimeHealthDetector instance using the default signals
+func NewRuntimeHealthDetector(reader client.Reader, window time.Duration, threshold float64) *RuntimeHealthDetector {
+	return &RuntimeHealthDetector{
+		Reader:    reader,
+		Signals:   DefaultRuntimeSignals,
+		Window:    window,
+		Threshold: threshold,
+		observed:  make(map[types.UID]*observedEvent),
+	}
+}
+
+// implements the degradation.Detector interface
+func (d *RuntimeHealthDetector) Name() string {
+	return "runtime-health"
+}
+
+// implements the degradation.Detector interface
+//
+// an aggregated event's count covers every occurrence since it was first seen, so only the occurrences observed within
+// the window are counted: the whole count of events first seen within it, and the growth of each count since
+func (d *RuntimeHealthDetector) Detect(ctx context.Context) ([]degradation.Finding, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-d.Window)
+	scores := map[string]float64{}
+	counts := map[string]map[string]int32{}
+	seen := map[types.UID]bool{}
+
+	// several signals may share a reason, so events are fetched once per reason
+	byReason := map[string][]string{}
+	for description, signal := range d.Signals {
+		byReason[signal.Reason] = append(byReason[signal.Reason], description)
+	}
+
+	for reason, descriptions := range byReason {
+		eventList := &core.EventList{}
+		if err := d.Reader.List(ctx, eventList, &client.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("reason", reason),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list %s events: %w", reason, err)
+		}
+
+		for _, event := range eventList.Items {
+			nodeName := eventNodeName(&event)
+			if nodeName == "" || eventTime(&event).Before(cutoff) {
+				continue
+			}
+			seen[event.UID] = true
+			occurrences := d.observe(&event, cutoff)
+			if occurrences == 0 {
+				continue
+			}
+			for _, description := range descriptions {
+				signal := d.Signals[description]
+				if !messageMatches(event.Message, signal.MessageContains) {
+					continue
+				}
+				scores[nodeName] += float64(occurrences) * signal.Weight
+				if counts[nodeName] == nil {
+					counts[nodeName] = map[string]int32{}
+				}
+				counts[nodeName][description] += occurrences
+			}
+		}
+	}
+
+	// forgetting events that were deleted or fell out of the window
+	for uid := range d.observed {
+		if !seen[uid] {
+			delete(d.observed, uid)
+		}
+	}
+
+	findings := make([]degradation.Finding, 0, len(scores))
+	for nodeName, score := range scores {
+		finding := degradation.Finding{Node: nodeName}
+		if score >= d.Threshold {
+			details := []string{}
+			for description, count := range counts[nodeName] {
+				details = append(details, fmt.Sprintf("%d %s", count, description))
+			}
+			sort.Strings(details)
+			finding.Degraded = true
+			finding.Reason = fmt.Sprintf("container runtime degradation score %.1f within %s (%s)", score, d.Window, strings.Join(details, ", "))
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
+
+// records an event's count and returns the number of its occurrences within the window; callers must hold the lock
+//
+// an event first seen with a count it had already reached before the window counts once, as only its latest occurrence
+// is known to fall within the window
+func (d *RuntimeHealthDetector) observe(event *core.Event, cutoff time.Time) int32 {
+	count := eventCount(event)
+	observed, ok := d.observed[event.UID]
+	if !ok {
+		observed = &observedEvent{}
+		d.observed[event.UID] = observed
+		added := count
+		if eventFirstTime(event).Before(cutoff) {
+			added = 1
+		}
+		observed.occurrences = append(observed.occurrences, eventOccurrences{at: eventTime(event), count: added})
+	} else if count > observed.count {
+		observed.occurrences = append(observed.occurrences, eventOccurrences{at: eventTime(event), count: count - observed.count})
+	}
+	observed.count = count
+
+	i := 0
+	for i < len(observed.occurrences) && observed.occurrences[i].at.Before(cutoff) {
+		i++
+	}
+	observed.occurrences = observed.occurrences[i:]
+
+	var occurrences int32
+	for _, o := range observed.occurrences {
+		occurrences += o.count
+	}
+	return occurrences
+}
+
+// returns the node an event was observed on
+func eventNodeName(event *core.Event) string {
+	if event.InvolvedObject.Kind == "Node" {
+		return event.InvolvedObject.Name
+	}
+	if event.Source.Host != "" {
+		return event.Source.Host
+	}
+	return event.ReportingInstance
+}
+
+// returns the most recent time an event was observed
+func eventTime(event *core.Event) time.Time {
+	if event.Series != nil && !event.Series.LastObservedTime.IsZero() {
+		return event.Series.LastObservedTime.Time
+	}
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// returns the time an event was first observed
+func eventFirstTime(event *core.Event) time.Time {
+	if !event.FirstTimestamp.IsZero() {
+		return event.FirstTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// returns the number of occurrences an event stands for
+func eventCount(event *core.Event) int32 {
+	if event.Series != nil && event.Series.Count > 0 {
+		return event.Series.Count
+	}
+	if event.Count > 0 {
+		return event.Count
+	}
+	return 1
+}
+
+// reports whether a message contains one of the given substrings; an empty list matches any message
+func messageMatches(message string, substrings []string) bool {
+	if len(substrings) == 0 {
+		return true
+	}
+	message = strings.ToLower(message)
+	for _, substring := range substrings {
+		if strings.Contains(message, strings.ToLower(substring)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package detectors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// returns a sandbox failure event on the given node, first seen at first and last seen at last
+func sandboxEvent(uid string, node string, count int32, first time.Time, last time.Time) *core.Event {
+	return &core.Event{
+		ObjectMeta:     meta.ObjectMeta{Name: uid, Namespace: "default", UID: types.UID(uid)},
+		InvolvedObject: core.ObjectReference{Kind: "Pod", Name: "web-0"},
+		Source:         core.EventSource{Host: node},
+		Reason:         "FailedCreatePodSandBox",
+		Count:          count,
+		FirstTimestamp: meta.NewTime(first),
+		LastTimestamp:  meta.NewTime(last),
+	}
+}
+
+func TestRuntimeHealthDetectorCountsOccurrencesWithinTheWindow(t *testing.T) {
+	now := time.Now()
+	c := fake.NewClientBuilder().
+		WithScheme(clientscheme.Scheme).
+		WithIndex(&core.Event{}, "reason", func(obj client.Object) []string { return []string{obj.(*core.Event).Reason} }).
+		WithObjects(
+			// aggregated over a day, with only its latest occurrence within the window
+			sandboxEvent("old", "node-a", 50, now.Add(-24*time.Hour), now.Add(-time.Minute)),
+			sandboxEvent("new", "node-b", 4, now.Add(-5*time.Minute), now.Add(-time.Minute)),
+		).
+		Build()
+	detector := NewRuntimeHealthDetector(c, 10*time.Minute, 3)
+
+	check := func() map[string]bool {
+		findings, err := detector.Detect(context.Background())
+		if err != nil {
+			t.Fatalf("Detect() error = %v", err)
+		}
+		degraded := map[string]bool{}
+		for _, finding := range findings {
+			degraded[finding.Node] = finding.Degraded
+		}
+		return degraded
+	}
+
+	if got := check(); got["node-a"] || !got["node-b"] {
+		t.Fatalf("first check: degraded = %v, want only node-b", got)
+	}
+
+	// the old event recurs twice, adding to the occurrence already counted
+	event := &core.Event{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "old"}, event); err != nil {
+		t.Fatal(err)
+	}
+	event.Count, event.LastTimestamp = 52, meta.NewTime(now)
+	if err := c.Update(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if got := check(); !got["node-a"] {
+		t.Errorf("second check: degraded = %v, want node-a with 3 occurrences within the window", got)
+	}
+}
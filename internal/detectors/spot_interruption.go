@@ -0,0 +1,75 @@
+package detectors
+
+import (
+	"context"
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// taints placed by common termination handlers when a spot/preemptible interruption notice is received
+var DefaultSpotInterruptionTaints = []string{
+	// aws-node-termination-handler, on spot interruption notices
+	"aws-node-termination-handler/spot-itn",
+	// aws-node-termination-handler, on ASG lifecycle termination
+	"aws-node-termination-handler/asg-lifecycle-termination",
+	// GKE, ahead of preemption of spot/preemptible VMs
+	"cloud.google.com/impending-node-termination",
+}
+
+// marks nodes carrying spot interruption taints as urgently degraded
+type SpotInterruptionDetector struct {
+	Client client.Client
+	// taint keys that signal an imminent interruption
+	TaintKeys []string
+}
+
+// creates a new SpotInterruptionDetector instance
+func NewSpotInterruptionDetector(cli client.Client, taintKeys []string) *SpotInterruptionDetector {
+	return &SpotInterruptionDetector{
+		Client:    cli,
+		TaintKeys: taintKeys,
+	}
+}
+
+// implements the degradation.Detector interface
+func (d *SpotInterruptionDetector) Name() string {
+	return "spot-interruption"
+}
+
+// implements the degradation.Detector interface
+func (d *SpotInterruptionDetector) Detect(ctx context.Context) ([]degradation.Finding, error) {
+	nodeList := &core.NodeList{}
+	if err := d.Client.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	findings := make([]degradation.Finding, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		finding := degradation.Finding{Node: node.Name}
+		for _, taint := range node.Spec.Taints {
+			if d.isInterruptionTaint(taint.Key) {
+				finding.Degraded = true
+				finding.Severity = degradation.SeverityUrgent
+				finding.Reason = fmt.Sprintf("spot interruption notice (taint %s)", taint.Key)
+				break
+			}
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
+
+// reports whether a taint key signals an imminent interruption
+func (d *SpotInterruptionDetector) isInterruptionTaint(key string) bool {
+	for _, k := range d.TaintKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package detectors
+
+import (
+	"context"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// returns a node carrying taints of the given keys
+func taintedNode(name string, keys ...string) *core.Node {
+	node := namedNode(name)
+	for _, key := range keys {
+		node.Spec.Taints = append(node.Spec.Taints, core.Taint{Key: key, Effect: core.TaintEffectNoSchedule})
+	}
+	return node
+}
+
+func TestSpotInterruptionDetectorDetect(t *testing.T) {
+	cli := fakeNodeClient(
+		taintedNode("healthy"),
+		taintedNode("cordoned", "node.kubernetes.io/unschedulable"),
+		taintedNode("aws-spot", "node.kubernetes.io/unschedulable", "aws-node-termination-handler/spot-itn"),
+		taintedNode("gke-preempted", "cloud.google.com/impending-node-termination"),
+	)
+	detector := NewSpotInterruptionDetector(cli, DefaultSpotInterruptionTaints)
+
+	findings, err := detector.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	want := map[string]string{
+		"healthy":       "",
+		"cordoned":      "",
+		"aws-spot":      "spot interruption notice (taint aws-node-termination-handler/spot-itn)",
+		"gke-preempted": "spot interruption notice (taint cloud.google.com/impending-node-termination)",
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("findings = %+v, want one per node", findings)
+	}
+	for _, finding := range findings {
+		if finding.Reason != want[finding.Node] {
+			t.Errorf("reason for %s = %q, want %q", finding.Node, finding.Reason, want[finding.Node])
+		}
+		// interruption notices leave no time for a regular drain
+		wantSeverity := degradation.SeverityNormal
+		if finding.Degraded {
+			wantSeverity = degradation.SeverityUrgent
+		}
+		if finding.Degraded != (finding.Reason != "") || finding.Severity != wantSeverity {
+			t.Errorf("finding for %s: degraded = %v, severity = %q", finding.Node, finding.Degraded, finding.Severity)
+		}
+	}
+}
+
+func TestSpotInterruptionDetectorCustomTaints(t *testing.T) {
+	cli := fakeNodeClient(taintedNode("aws-spot", "aws-node-termination-handler/spot-itn"), taintedNode("karpenter", "karpenter.sh/disruption"))
+	detector := NewSpotInterruptionDetector(cli, []string{"karpenter.sh/disruption"})
+
+	findings, err := detector.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	for _, finding := range findings {
+		if finding.Degraded != (finding.Node == "karpenter") {
+			t.Errorf("finding for %s: degraded = %v, want only the configured taints to mark nodes", finding.Node, finding.Degraded)
+		}
+	}
+}
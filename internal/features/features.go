@@ -0,0 +1,66 @@
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// features that can be switched on or off through --feature-gates
+const (
+	// evicts pods by creating EvictionRequests through the upstream declarative eviction API, letting workloads that
+	// coordinate their own evacuation take part, instead of sending eviction requests; alpha, off by default
+	EvictionRequest = "EvictionRequest"
+)
+
+// whether each known feature is enabled when not set through --feature-gates
+var defaults = map[string]bool{
+	EvictionRequest: false,
+}
+
+// features enabled or disabled through --feature-gates, falling back to their defaults
+type Gates map[string]bool
+
+// parses a comma-separated list of <feature>=<bool> pairs (e.g. "EvictionRequest=true"), rejecting unknown features
+func Parse(spec string) (Gates, error) {
+	gates := Gates{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid feature gate %q: must be of the form <feature>=<bool>", pair)
+		}
+		name = strings.TrimSpace(name)
+		if _, known := defaults[name]; !known {
+			return nil, fmt.Errorf("unknown feature gate %q: must be one of %s", name, strings.Join(Known(), ", "))
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for feature gate %s: %w", name, err)
+		}
+		gates[name] = enabled
+	}
+	return gates, nil
+}
+
+// reports whether a feature is enabled
+func (g Gates) Enabled(name string) bool {
+	if enabled, ok := g[name]; ok {
+		return enabled
+	}
+	return defaults[name]
+}
+
+// returns the names of the known features, sorted
+func Known() []string {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,212 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/remotecommand"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+
+// maximum number of bytes of a hook's output quoted in its error
+const maxOutputBytes = 1024
+
+// failed pre-eviction hook
+type Error struct {
+	Hook string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("pre-eviction hook %s failed: %v", e.Hook, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// reports whether an error is the failure of a pre-eviction hook
+func IsFailure(err error) bool {
+	var hookErr *Error
+	return errors.As(err, &hookErr)
+}
+
+// JSON body of the request sent by an HTTP hook
+type Request struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
+	Node      string `json:"node"`
+	Hook      string `json:"hook"`
+}
+
+// runs the pre-eviction hooks of workload profiles against the pods about to be evicted
+type Runner struct {
+	HTTPClient *http.Client
+	// configuration and clientset used to execute commands in pods
+	Config    *rest.Config
+	Clientset kubernetes.Interface
+	Log       logr.Logger
+	Recorder  record.EventRecorder
+}
+
+// creates a new Runner executing commands in pods through the API server at the given configuration
+func NewRunner(config *rest.Config, log logr.Logger, recorder record.EventRecorder) (*Runner, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	return &Runner{
+		HTTPClient: &http.Client{},
+		Config:     config,
+		Clientset:  clientset,
+		Log:        log,
+		Recorder:   recorder,
+	}, nil
+}
+
+// runs the hooks against a pod in order, stopping at the first failing one unless its failure policy is Ignore, in
+// which case the failure is reported and the next hook runs; the failure stopping the hooks is returned as an *Error
+func (h *Runner) Run(ctx context.Context, pod *core.Pod, hooks []api_v1.PreEvictionHook) error {
+	for i := range hooks {
+		hook := &hooks[i]
+		log := h.Log.WithValues("pod", pod.Name, "namespace", pod.Namespace, "hook", hook.Name)
+
+		err := h.run(ctx, pod, hook)
+		if err == nil {
+			log.V(1).Info("pre-eviction hook succeeded")
+			continue
+		}
+		if hook.IgnoresFailure() {
+			log.Info("pre-eviction hook failed, ignoring as per its failure policy", "error", err.Error())
+			h.Recorder.Eventf(pod, core.EventTypeWarning, "PreEvictionHookFailed", "Pre-eviction hook %s of pod %s failed, evicting anyway: %v", hook.Name, pod.Name, err)
+			continue
+		}
+		log.Info("pre-eviction hook failed, abandoning eviction", "error", err.Error())
+		h.Recorder.Eventf(pod, core.EventTypeWarning, "PreEvictionHookFailed", "Pre-eviction hook %s of pod %s failed, not evicting: %v", hook.Name, pod.Name, err)
+		return &Error{Hook: hook.Name, Err: err}
+	}
+	return nil
+}
+
+// runs a single hook within its timeout
+func (h *Runner) run(ctx context.Context, pod *core.Pod, hook *api_v1.PreEvictionHook) error {
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout())
+	defer cancel()
+
+	switch {
+	case hook.HTTP != nil:
+		return h.call(ctx, pod, hook.Name, hook.HTTP)
+	case hook.Exec != nil:
+		return h.exec(ctx, pod, hook.Exec)
+	}
+	return fmt.Errorf("neither http nor exec is set")
+}
+
+// sends the HTTP request of a hook, expecting a 2xx response
+func (h *Runner) call(ctx context.Context, pod *core.Pod, hookName string, hook *api_v1.HTTPHook) error {
+	url, err := hookURL(pod, hook)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(Request{
+		Pod:       pod.Name,
+		Namespace: pod.Namespace,
+		UID:       string(pod.UID),
+		Node:      pod.Spec.NodeName,
+		Hook:      hookName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		output, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutputBytes))
+		return fmt.Errorf("%s responded with %s: %s", url, resp.Status, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// returns the URL an HTTP hook calls: its own, or the pod's IP on its port and path
+func hookURL(pod *core.Pod, hook *api_v1.HTTPHook) (string, error) {
+	if hook.URL != "" {
+		return hook.URL, nil
+	}
+	if pod.Status.PodIP == "" {
+		return "", fmt.Errorf("pod has no IP")
+	}
+	if hook.Port == 0 {
+		return "", fmt.Errorf("neither url nor port is set")
+	}
+	scheme := "http"
+	if hook.Scheme == core.URISchemeHTTPS {
+		scheme = "https"
+	}
+	path := hook.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(hook.Port))), path), nil
+}
+
+// executes the command of a hook in the pod, expecting it to exit with status 0
+func (h *Runner) exec(ctx context.Context, pod *core.Pod, hook *api_v1.ExecHook) error {
+	container := hook.Container
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	req := h.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&core.PodExecOptions{
+			Container: container,
+			Command:   hook.Command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(h.Config, http.MethodPost, req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	var output bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &output, Stderr: &output}); err != nil {
+		quoted := output.String()
+		if len(quoted) > maxOutputBytes {
+			quoted = quoted[len(quoted)-maxOutputBytes:]
+		}
+		return fmt.Errorf("command in container %s failed: %w: %s", container, err, strings.TrimSpace(quoted))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package injection
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/internal/profiles"
+)
+
+// annotation recording the workload profile whose recommendation was injected into a pod
+const InjectedProfileAnnotation = "kube-balance.io/injected-resources-profile"
+
+// path the pod mutating webhook is served on, as generated by controller-runtime for core/v1 pods
+const PodResourceInjectionPath = "/mutate--v1-pod"
+
+// sets the requests of pods being created to the recommendation of the workload profile governing them, for the
+// resources none of the pod's containers declare, so that new workloads get the QoS class the platform team intends,
+// and adds the profile's rescheduling hints, so that the replacements of evicted pods land on healthier nodes
+//
+// pods are not yet scheduled when they are created, so profiles restricted to certain nodes never apply
+type PodResourceInjector struct {
+	Log             logr.Logger
+	ProfilerWatcher *profiles.WorkloadProfileWatcher
+}
+
+// implements the admission.CustomDefaulter interface to inject the recommendation and the rescheduling hints into a pod being created
+func (i *PodResourceInjector) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*core.Pod)
+	if !ok {
+		return fmt.Errorf("expected a pod, got %T", obj)
+	}
+	// pods created through a workload controller may only carry their namespace on the admission request; it is set on
+	// a copy for matching, leaving the pod's metadata to the API server
+	matched := pod
+	if pod.Namespace == "" {
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			matched = pod.DeepCopy()
+			matched.Namespace = req.Namespace
+		}
+	}
+
+	profile, ok := profiles.MatchPodScoped(matched, nil, i.ProfilerWatcher.GetNamespacedProfiles(), i.ProfilerWatcher.GetProfiles())
+	if !ok {
+		return nil
+	}
+	if injectResources(pod.Spec.Containers, &profile.Spec.Resources) {
+		annotate(pod, InjectedProfileAnnotation, profiles.Key(profile))
+		i.Log.V(1).Info("injected profile resource recommendation into pod", "pod", podName(pod), "namespace", matched.Namespace, "profile", profile.Name)
+	}
+	if profile.Spec.Rescheduling != nil && mergeReschedulingHints(pod, profile.Spec.Rescheduling) {
+		annotate(pod, ReschedulingHintsProfileAnnotation, profiles.Key(profile))
+		i.Log.V(1).Info("added profile rescheduling hints to pod", "pod", podName(pod), "namespace", matched.Namespace, "profile", profile.Name)
+	}
+	return nil
+}
+
+// sets an annotation on a pod
+func annotate(pod *core.Pod, key string, value string) {
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[key] = value
+}
+
+// injects the recommendation of every resource into the containers declaring no request or limit for it; containers
+// with a recommendation of their own get it individually, while the pod-level recommendation goes to the first of the
+// remaining containers when none of them declares one; reports whether anything was injected
+func injectResources(containers []core.Container, recommendation *api_v1.ResourceRecommendation) bool {
+	injected := false
+	for _, resourceName := range []core.ResourceName{core.ResourceCPU, core.ResourceMemory} {
+		rest := []*core.Container{}
+		for i := range containers {
+			container := &containers[i]
+			request, hasRequest := recommendation.ContainerRequest(container.Name, resourceName)
+			limit, hasLimit := recommendation.ContainerLimit(container.Name, resourceName)
+			if !hasRequest && !hasLimit {
+				rest = append(rest, container)
+				continue
+			}
+			if declared([]*core.Container{container}, resourceName) {
+				continue
+			}
+			if hasRequest {
+				injectResource(container, resourceName, request, recommendation.QOSClass)
+			}
+			// a limit below the request would make the pod invalid
+			if hasLimit && (!hasRequest || limit.Cmp(request) >= 0) {
+				if container.Resources.Limits == nil {
+					container.Resources.Limits = core.ResourceList{}
+				}
+				container.Resources.Limits[resourceName] = limit.DeepCopy()
+			}
+			injected = true
+		}
+
+		// without any declared request, the first of the remaining containers carries the whole recommendation
+		recommended := recommendation.PodRequest(resourceName)
+		if recommended == nil || recommended.IsZero() || len(rest) == 0 || declared(rest, resourceName) {
+			continue
+		}
+		injectResource(rest[0], resourceName, *recommended, recommendation.QOSClass)
+		injected = true
+	}
+	return injected
+}
+
+// sets a container's request for a resource, along with an equal limit when the recommendation is for the Guaranteed QoS class
+func injectResource(container *core.Container, resourceName core.ResourceName, request resource.Quantity, qosClass core.PodQOSClass) {
+	if container.Resources.Requests == nil {
+		container.Resources.Requests = core.ResourceList{}
+	}
+	container.Resources.Requests[resourceName] = request.DeepCopy()
+	if qosClass == core.PodQOSGuaranteed {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = core.ResourceList{}
+		}
+		container.Resources.Limits[resourceName] = request.DeepCopy()
+	}
+}
+
+// reports whether any container declares a request or a limit for a resource; a limit alone implies an equal request
+func declared(containers []*core.Container, resourceName core.ResourceName) bool {
+	for _, container := range containers {
+		if _, ok := container.Resources.Requests[resourceName]; ok {
+			return true
+		}
+		if _, ok := container.Resources.Limits[resourceName]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the name of a pod for logging, falling back to its generate name as pods of workloads are named by the API server
+func podName(pod *core.Pod) string {
+	if pod.Name != "" {
+		return pod.Name
+	}
+	return pod.GenerateName
+}
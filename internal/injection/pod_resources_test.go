@@ -0,0 +1,129 @@
+package injection
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// returns a container declaring the given requests and limits
+func container(name string, requests core.ResourceList, limits core.ResourceList) core.Container {
+	return core.Container{Name: name, Resources: core.ResourceRequirements{Requests: requests, Limits: limits}}
+}
+
+// returns a resource list of the given CPU and memory quantities, leaving out the empty ones
+func resources(cpu string, memory string) core.ResourceList {
+	list := core.ResourceList{}
+	if cpu != "" {
+		list[core.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		list[core.ResourceMemory] = resource.MustParse(memory)
+	}
+	return list
+}
+
+func quantity(value string) *resource.Quantity {
+	q := resource.MustParse(value)
+	return &q
+}
+
+func TestInjectResources(t *testing.T) {
+	tests := []struct {
+		name           string
+		containers     []core.Container
+		recommendation api_v1.ResourceRecommendation
+		want           []core.Container
+		wantInjected   bool
+	}{
+		{
+			name:           "pod-level recommendation",
+			containers:     []core.Container{container("app", nil, nil)},
+			recommendation: api_v1.ResourceRecommendation{CPU: quantity("500m"), Memory: quantity("256Mi")},
+			want:           []core.Container{container("app", resources("500m", "256Mi"), nil)},
+			wantInjected:   true,
+		},
+		{
+			name:           "guaranteed QoS class",
+			containers:     []core.Container{container("app", nil, nil)},
+			recommendation: api_v1.ResourceRecommendation{CPU: quantity("500m"), QOSClass: core.PodQOSGuaranteed},
+			want:           []core.Container{container("app", resources("500m", ""), resources("500m", ""))},
+			wantInjected:   true,
+		},
+		{
+			name:           "declared resource left alone",
+			containers:     []core.Container{container("app", resources("1", ""), nil)},
+			recommendation: api_v1.ResourceRecommendation{CPU: quantity("500m"), Memory: quantity("256Mi")},
+			want:           []core.Container{container("app", resources("1", "256Mi"), nil)},
+			wantInjected:   true,
+		},
+		{
+			name:           "declared limit implies a request",
+			containers:     []core.Container{container("app", nil, resources("1", ""))},
+			recommendation: api_v1.ResourceRecommendation{CPU: quantity("500m")},
+			want:           []core.Container{container("app", nil, resources("1", ""))},
+		},
+		{
+			name:       "pod-level recommendation goes to the first container without one of its own",
+			containers: []core.Container{container("proxy", nil, nil), container("app", nil, nil), container("log", nil, nil)},
+			recommendation: api_v1.ResourceRecommendation{
+				CPU:        quantity("500m"),
+				Containers: map[string]api_v1.ContainerRecommendation{"proxy": {Requests: resources("50m", "")}},
+			},
+			want: []core.Container{
+				container("proxy", resources("50m", ""), nil),
+				container("app", resources("500m", ""), nil),
+				container("log", nil, nil),
+			},
+			wantInjected: true,
+		},
+		{
+			name:       "pod-level recommendation skipped when a remaining container declares the resource",
+			containers: []core.Container{container("app", nil, nil), container("log", resources("10m", ""), nil)},
+			recommendation: api_v1.ResourceRecommendation{
+				CPU: quantity("500m"),
+			},
+			want: []core.Container{container("app", nil, nil), container("log", resources("10m", ""), nil)},
+		},
+		{
+			name:       "container limit",
+			containers: []core.Container{container("app", nil, nil)},
+			recommendation: api_v1.ResourceRecommendation{
+				Containers: map[string]api_v1.ContainerRecommendation{"app": {Requests: resources("", "256Mi"), Limits: resources("", "512Mi")}},
+			},
+			want:         []core.Container{container("app", resources("", "256Mi"), resources("", "512Mi"))},
+			wantInjected: true,
+		},
+		{
+			name:       "container limit below the request",
+			containers: []core.Container{container("app", nil, nil)},
+			recommendation: api_v1.ResourceRecommendation{
+				Containers: map[string]api_v1.ContainerRecommendation{"app": {Requests: resources("", "256Mi"), Limits: resources("", "128Mi")}},
+			},
+			want:         []core.Container{container("app", resources("", "256Mi"), nil)},
+			wantInjected: true,
+		},
+		{
+			name:           "zero pod-level recommendation",
+			containers:     []core.Container{container("app", nil, nil)},
+			recommendation: api_v1.ResourceRecommendation{CPU: quantity("0")},
+			want:           []core.Container{container("app", nil, nil)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := injectResources(tt.containers, &tt.recommendation); got != tt.wantInjected {
+				t.Errorf("injectResources() = %v, want %v", got, tt.wantInjected)
+			}
+			for i, want := range tt.want {
+				if got := tt.containers[i]; !equality.Semantic.DeepEqual(got.Resources, want.Resources) {
+					t.Errorf("container %s resources = %+v, want %+v", got.Name, got.Resources, want.Resources)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package injection
+
+import (
+	"sort"
+
+	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// annotation recording the workload profile whose rescheduling hints were added to a pod
+const ReschedulingHintsProfileAnnotation = "kube-balance.io/rescheduling-hints-profile"
+
+// weight of the preferred node affinity term steering pods away from degraded nodes
+const avoidDegradedNodesWeight = 100
+
+// weight of the preferred node affinity term built from a profile's preferred node selector
+const preferredNodeSelectorWeight = 50
+
+// labels workload controllers set on each pod, telling apart the pods or revisions of a single workload; they are left
+// out of the selectors spreading the workload's pods, which would otherwise match a single pod or revision
+var podIdentityLabels = []string{
+	apps.DefaultDeploymentUniqueLabelKey,
+	apps.ControllerRevisionHashLabelKey,
+	apps.StatefulSetPodNameLabel,
+	apps.PodIndexLabel,
+	batch.JobCompletionIndexAnnotation,
+}
+
+// adds the hints missing from a pod being created, reporting whether anything was added; hints are added at admission
+// rather than onto the pod template of the pod's owner, as patching the template would roll out every replica
+func mergeReschedulingHints(pod *core.Pod, hints *api_v1.ReschedulingHints) bool {
+	spec := &pod.Spec
+	changed := false
+
+	if hints.AvoidDegradedNodes {
+		changed = addPreferredNodeAffinity(spec, core.PreferredSchedulingTerm{
+			Weight: avoidDegradedNodesWeight,
+			Preference: core.NodeSelectorTerm{
+				MatchExpressions: []core.NodeSelectorRequirement{{
+					Key:      degradation.DegradedNodeLabel,
+					Operator: core.NodeSelectorOpNotIn,
+					Values:   []string{"true"},
+				}},
+			},
+		}) || changed
+	}
+
+	if len(hints.PreferredNodeSelector) > 0 {
+		keys := make([]string, 0, len(hints.PreferredNodeSelector))
+		for key := range hints.PreferredNodeSelector {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		term := core.NodeSelectorTerm{}
+		for _, key := range keys {
+			term.MatchExpressions = append(term.MatchExpressions, core.NodeSelectorRequirement{
+				Key:      key,
+				Operator: core.NodeSelectorOpIn,
+				Values:   []string{hints.PreferredNodeSelector[key]},
+			})
+		}
+		changed = addPreferredNodeAffinity(spec, core.PreferredSchedulingTerm{
+			Weight:     preferredNodeSelectorWeight,
+			Preference: term,
+		}) || changed
+	}
+
+	// pods without labels shared with the rest of their workload have nothing to be spread with
+	selector := workloadSelector(pod)
+	if selector == nil {
+		return changed
+	}
+	for _, topologyKey := range hints.SpreadTopologyKeys {
+		spread := false
+		for _, constraint := range spec.TopologySpreadConstraints {
+			if constraint.TopologyKey == topologyKey {
+				spread = true
+				break
+			}
+		}
+		if spread {
+			continue
+		}
+		spec.TopologySpreadConstraints = append(spec.TopologySpreadConstraints, core.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       topologyKey,
+			WhenUnsatisfiable: core.ScheduleAnyway,
+			LabelSelector:     selector.DeepCopy(),
+		})
+		changed = true
+	}
+
+	return changed
+}
+
+// returns a selector of the pods of a pod's workload, built from its labels; nil when it has none but the ones telling
+// it apart from the other pods of its workload
+func workloadSelector(pod *core.Pod) *meta.LabelSelector {
+	matchLabels := map[string]string{}
+	for key, value := range pod.Labels {
+		matchLabels[key] = value
+	}
+	for _, key := range podIdentityLabels {
+		delete(matchLabels, key)
+	}
+	if len(matchLabels) == 0 {
+		return nil
+	}
+	return &meta.LabelSelector{MatchLabels: matchLabels}
+}
+
+// appends a preferred node affinity term unless an identical preference is already present
+func addPreferredNodeAffinity(spec *core.PodSpec, term core.PreferredSchedulingTerm) bool {
+	if spec.Affinity == nil {
+		spec.Affinity = &core.Affinity{}
+	}
+	if spec.Affinity.NodeAffinity == nil {
+		spec.Affinity.NodeAffinity = &core.NodeAffinity{}
+	}
+
+	nodeAffinity := spec.Affinity.NodeAffinity
+	for _, existing := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		if equality.Semantic.DeepEqual(existing.Preference, term.Preference) {
+			return false
+		}
+	}
+	nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, term)
+	return true
+}
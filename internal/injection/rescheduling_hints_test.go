@@ -0,0 +1,96 @@
+package injection
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// returns a pod being created with the given labels
+func labelledPod(labels map[string]string) *core.Pod {
+	return &core.Pod{ObjectMeta: meta.ObjectMeta{GenerateName: "web-", Labels: labels}}
+}
+
+func TestMergeReschedulingHints(t *testing.T) {
+	hints := &api_v1.ReschedulingHints{
+		AvoidDegradedNodes:    true,
+		PreferredNodeSelector: map[string]string{"node-pool": "stable", "disk": "ssd"},
+		SpreadTopologyKeys:    []string{"topology.kubernetes.io/zone"},
+	}
+	pod := labelledPod(map[string]string{"app": "web", "pod-template-hash": "7d9f8"})
+
+	if !mergeReschedulingHints(pod, hints) {
+		t.Fatalf("mergeReschedulingHints() = false, want the hints added")
+	}
+	preferred := pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	if len(preferred) != 2 {
+		t.Fatalf("preferred node affinity terms = %+v, want one per hint", preferred)
+	}
+	if avoid := preferred[0].Preference.MatchExpressions[0]; avoid.Key != degradation.DegradedNodeLabel || avoid.Operator != core.NodeSelectorOpNotIn {
+		t.Errorf("first term = %+v, want it to avoid degraded nodes", avoid)
+	}
+	if selector := preferred[1].Preference.MatchExpressions; len(selector) != 2 || selector[0].Key != "disk" || selector[1].Key != "node-pool" {
+		t.Errorf("second term = %+v, want the preferred node selector in key order", selector)
+	}
+	constraints := pod.Spec.TopologySpreadConstraints
+	if len(constraints) != 1 || constraints[0].WhenUnsatisfiable != core.ScheduleAnyway {
+		t.Fatalf("topology spread constraints = %+v, want a ScheduleAnyway one per key", constraints)
+	}
+	if matchLabels := constraints[0].LabelSelector.MatchLabels; len(matchLabels) != 1 || matchLabels["app"] != "web" {
+		t.Errorf("spread selector = %v, want it to match every revision of the workload", matchLabels)
+	}
+
+	if mergeReschedulingHints(pod, hints) {
+		t.Errorf("mergeReschedulingHints() = true on a pod already carrying the hints, want it left alone")
+	}
+}
+
+func TestWorkloadSelector(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   map[string]string
+	}{
+		{name: "deployment pod", labels: map[string]string{"app": "web", "pod-template-hash": "7d9f8"}, want: map[string]string{"app": "web"}},
+		{
+			name:   "statefulset pod",
+			labels: map[string]string{"app": "db", "controller-revision-hash": "db-5c8f", "statefulset.kubernetes.io/pod-name": "db-0", "apps.kubernetes.io/pod-index": "0"},
+			want:   map[string]string{"app": "db"},
+		},
+		{name: "only identity labels", labels: map[string]string{"pod-template-hash": "7d9f8"}},
+		{name: "no labels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector := workloadSelector(labelledPod(tt.labels))
+			if (selector == nil) != (tt.want == nil) {
+				t.Fatalf("workloadSelector() = %v, want %v", selector, tt.want)
+			}
+			if selector == nil {
+				return
+			}
+			if len(selector.MatchLabels) != len(tt.want) {
+				t.Fatalf("selector = %v, want %v", selector.MatchLabels, tt.want)
+			}
+			for key, value := range tt.want {
+				if selector.MatchLabels[key] != value {
+					t.Errorf("selector = %v, want %v", selector.MatchLabels, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeReschedulingHintsWithoutWorkloadLabels(t *testing.T) {
+	pod := labelledPod(nil)
+	if mergeReschedulingHints(pod, &api_v1.ReschedulingHints{SpreadTopologyKeys: []string{"topology.kubernetes.io/zone"}}) {
+		t.Errorf("mergeReschedulingHints() = true, want no spread constraint for a pod without workload labels")
+	}
+	if len(pod.Spec.TopologySpreadConstraints) != 0 {
+		t.Errorf("topology spread constraints = %+v, want none", pod.Spec.TopologySpreadConstraints)
+	}
+}
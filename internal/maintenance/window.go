@@ -0,0 +1,127 @@
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// recurring weekly period during which disruptive actions are approved
+type Window struct {
+	// days the window opens on; every day when empty
+	days map[time.Weekday]bool
+	// minutes after midnight at which the window opens and closes; a close at or before the open spills into the next day
+	start int
+	end   int
+	// location the times are expressed in
+	location *time.Location
+}
+
+// day names accepted in window definitions, keyed by their lowercase three-letter abbreviation
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parses a window from its day names (e.g. "Mon", "Saturday"), HH:MM start and end times, and IANA time zone (UTC when empty)
+func Parse(days []string, start string, end string, timeZone string) (Window, error) {
+	w := Window{
+		days:     map[time.Weekday]bool{},
+		location: time.UTC,
+	}
+
+	for _, day := range days {
+		name := strings.ToLower(strings.TrimSpace(day))
+		if len(name) < 3 {
+			return Window{}, fmt.Errorf("invalid day %q", day)
+		}
+		weekday, ok := weekdays[name[:3]]
+		if !ok {
+			return Window{}, fmt.Errorf("invalid day %q", day)
+		}
+		w.days[weekday] = true
+	}
+
+	var err error
+	if w.start, err = parseClock(start); err != nil {
+		return Window{}, fmt.Errorf("invalid start: %w", err)
+	}
+	if w.end, err = parseClock(end); err != nil {
+		return Window{}, fmt.Errorf("invalid end: %w", err)
+	}
+
+	if timeZone != "" {
+		if w.location, err = time.LoadLocation(timeZone); err != nil {
+			return Window{}, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+		}
+	}
+
+	return w, nil
+}
+
+// parses an HH:MM time of day into minutes after midnight
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("time %q is not of the form HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// reports whether the window opens on the given day
+func (w Window) opensOn(day time.Weekday) bool {
+	return len(w.days) == 0 || w.days[day]
+}
+
+// reports whether the window is open at the given time
+func (w Window) Contains(t time.Time) bool {
+	local := t.In(w.location)
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	if w.start < w.end {
+		return w.opensOn(today) && minute >= w.start && minute < w.end
+	}
+	// the window spans midnight, or the whole day when start and end coincide
+	return (w.opensOn(today) && minute >= w.start) || (w.opensOn(yesterday) && minute < w.end)
+}
+
+// returns the next time after t at which the window opens
+func (w Window) NextOpen(t time.Time) time.Time {
+	local := t.In(w.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.location)
+	for offset := 0; offset <= 7; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		open := time.Date(day.Year(), day.Month(), day.Day(), w.start/60, w.start%60, 0, 0, w.location)
+		if w.opensOn(day.Weekday()) && open.After(t) {
+			return open
+		}
+	}
+	return t.Add(7 * 24 * time.Hour)
+}
+
+// reports whether any of the windows is open at the given time; when none is, it also returns the earliest time one opens
+//
+// an empty set of windows places no restriction and is always open
+func Open(windows []Window, now time.Time) (bool, time.Time) {
+	if len(windows) == 0 {
+		return true, now
+	}
+
+	var next time.Time
+	for _, w := range windows {
+		if w.Contains(now) {
+			return true, now
+		}
+		if candidate := w.NextOpen(now); next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+	return false, next
+}
@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// reasons recorded when a pod on a degraded node is left in place
+const (
+	// the pod's workload profile is marked as protected
+	SkipReasonProtected = "protected"
+	// the pod opted out of eviction through an annotation
+	SkipReasonDoNotEvict = "do-not-evict"
+	// the pod keeps data on its node in emptyDir volumes or local PersistentVolumes
+	SkipReasonLocalStorage = "local-storage"
+	// the pod's workload profile only allows evictions during maintenance windows, none of which is open
+	SkipReasonMaintenanceWindow = "maintenance-window"
+	// evicting the pod would take its owner below the minimum ready replicas of its workload profile
+	SkipReasonMinAvailable = "min-available"
+	// the pod's owner awaits a Ready replacement of the pod evicted last
+	SkipReasonAwaitingReplacement = "awaiting-replacement"
+	// the pod would fit on none of the nodes that aren't degraded, and would only be left Pending
+	SkipReasonNoFeasibleTarget = "no-feasible-target"
+	// the pod is younger than the minimum pod age, and may have just been rescheduled
+	SkipReasonTooYoung = "too-young"
+	// the pod is critical to its cluster or node, through its PriorityClass or the critical-pod annotation
+	SkipReasonCritical = "critical"
+	// the pod has no controller or is owned by a Job, and the rebalance policy leaves such pods in place
+	SkipReasonUnmanaged = "unmanaged"
+	// the pod is the last ready replica of its owner, and its workload profile doesn't allow evicting it
+	SkipReasonLastReplica = "last-replica"
+	// as many of the pod's owner's replicas as the rebalance policy allows are already being evicted or rescheduled
+	SkipReasonOwnerDisruptions = "owner-disruptions"
+	// a pod of the same name was evicted recently, and may have just been recreated
+	SkipReasonPodCooldown = "pod-cooldown"
+	// the pod's owner is rolling out a new revision
+	SkipReasonRolloutInProgress = "rollout-in-progress"
+	// the pod is its owner's last replica in its zone, whose other nodes have no room for its replacement
+	SkipReasonZoneBalance = "zone-balance"
+	// the nodes that aren't degraded lack the free CPU or memory to reschedule the pod
+	SkipReasonInsufficientHeadroom = "insufficient-headroom"
+	// another member of the pod's group can't be evicted, and the group is only evicted whole
+	SkipReasonPodGroup = "pod-group"
+	// the pod has a running ephemeral container, e.g. an active `kubectl debug` session
+	SkipReasonDebugSession = "debug-session"
+	// the pod runs a Job estimated close enough to completion to be left to finish
+	SkipReasonJobNearCompletion = "job-near-completion"
+	// the evictions planned in the same cycle used up the disruptions the pod's PodDisruptionBudget allows
+	SkipReasonPDBBudgetPlanned = "pdb-budget-planned"
+	// the pod's owner is in its eviction cooldown, after one of its pods was evicted
+	SkipReasonOwnerCooldown = "owner-cooldown"
+	// as many pods of the pod's workload profile as it allows are already being evicted or rescheduled
+	SkipReasonProfileMaxConcurrent = "profile-max-concurrent"
+)
+
+// counts pods on degraded nodes that were deliberately not evicted, by reason and workload profile; a pod left in place
+// cycle after cycle for the same reason is counted once
+var PodsSkipped = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kube_balance_pods_skipped_total",
+		Help: "Number of pods on degraded nodes skipped for eviction, by reason and workload profile",
+	},
+	[]string{"reason", "profile"},
+)
+
+// counts evictions admitted as server-side dry runs, leaving the pods running, by workload profile
+var DryRunEvictions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kube_balance_dry_run_evictions_total",
+		Help: "Number of pods that would have been evicted from degraded nodes had dry-run mode been off, by workload profile",
+	},
+	[]string{"profile"},
+)
+
+// counts failed eviction requests, by failure reason (BlockedByPDB, PodNotFound, PodRecreated, Forbidden, Transient or Unknown)
+var EvictionFailures = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kube_balance_eviction_failures_total",
+		Help: "Number of failed eviction requests, by failure reason",
+	},
+	[]string{"reason"},
+)
+
+// counts the times evictions were held back by the cluster-wide eviction rate limit
+var EvictionsRateLimited = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "kube_balance_evictions_rate_limited_total",
+		Help: "Number of times pending evictions were held back by the cluster-wide eviction rate limit",
+	},
+)
+
+// number of pods whose eviction awaits a retry
+var EvictionRetriesPending = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "kube_balance_eviction_retries_pending",
+		Help: "Number of pods whose eviction failed in a way worth retrying and awaits a retry",
+	},
+)
+
+// number of pods found blocked by their PodDisruptionBudget, backing off before they are checked again
+var PDBBlockedEvictions = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "kube_balance_pdb_blocked_evictions",
+		Help: "Number of pods found blocked by their PodDisruptionBudget, backing off before their eviction is checked again",
+	},
+)
+
+// whether all evictions are paused as too many pods are Pending
+var EvictionsPaused = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "kube_balance_evictions_paused",
+		Help: "Whether all evictions are paused until the scheduler catches up with the Pending pods (1) or not (0)",
+	},
+)
+
+// whether all evictions are paused by an operator
+var Paused = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "kube_balance_paused",
+		Help: "Whether all evictions are paused through --paused or the RebalancePolicy's paused field (1) or not (0)",
+	},
+)
+
+// CPU cores and memory bytes requested by the pods left in place in the last reconcile cycle as the nodes that aren't degraded lacked the headroom to reschedule them, by resource
+var ClusterHeadroomShortfall = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "kube_balance_cluster_headroom_shortfall",
+		Help: "CPU cores and memory bytes requested by the pods left in place in the last reconcile cycle for lack of free capacity on the nodes that aren't degraded, by resource",
+	},
+	[]string{"resource"},
+)
+
+// number of pods on degraded nodes still terminating past their grace period, by node
+var StuckTerminatingPods = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "kube_balance_stuck_terminating_pods",
+		Help: "Number of pods on degraded nodes still terminating past their grace period in the last reconcile cycle, by node",
+	},
+	[]string{"node"},
+)
+
+// counts pods stuck terminating on unreachable nodes that were deleted outright
+var StuckTerminatingForceDeletions = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "kube_balance_stuck_terminating_force_deletions_total",
+		Help: "Number of pods stuck terminating on unreachable degraded nodes that were deleted outright",
+	},
+)
+
+// kinds of pods bound to their node, which would only restart on the same node if evicted
+const (
+	// the pod is managed by a DaemonSet
+	NodeBoundKindDaemonSet = "daemonset"
+	// the pod is the API server's mirror of a static pod
+	NodeBoundKindMirror = "mirror"
+	// the pod is a static pod run by the kubelet from a manifest
+	NodeBoundKindStatic = "static"
+)
+
+// number of pods on degraded nodes excluded from eviction in the last reconcile cycle as they are bound to their node, by kind
+var NodeBoundPodsExcluded = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "kube_balance_node_bound_pods_excluded",
+		Help: "Number of DaemonSet, mirror and static pods on degraded nodes excluded from eviction in the last reconcile cycle, by kind",
+	},
+	[]string{"kind"},
+)
+
+// directions in which a pod's resource requests drift from its profile's recommendation
+const (
+	DriftDirectionUnder = "under"
+	DriftDirectionOver  = "over"
+)
+
+// number of pods whose resource requests drift from their workload profile's recommendation beyond the tolerance, by workload profile, resource and direction
+var ProfileDriftedPods = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "kube_balance_profile_drifted_pods",
+		Help: "Number of pods whose resource requests drift from their workload profile's recommendation, by workload profile, resource and direction",
+	},
+	[]string{"profile", "resource", "direction"},
+)
+
+// registers the collectors with the controller-runtime registry served on the manager's metrics endpoint
+func init() {
+	metrics.Registry.MustRegister(PodsSkipped, DryRunEvictions, EvictionFailures, EvictionsRateLimited, EvictionRetriesPending, PDBBlockedEvictions, EvictionsPaused, Paused, ClusterHeadroomShortfall, StuckTerminatingPods, StuckTerminatingForceDeletions, NodeBoundPodsExcluded, ProfileDriftedPods)
+}
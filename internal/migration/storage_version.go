@@ -0,0 +1,117 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	api_meta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// group version kind of CustomResourceDefinition objects
+var crdGVK = schema.GroupVersionKind{
+	Group:   "apiextensions.k8s.io",
+	Version: "v1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// a custom resource whose stored objects are to be rewritten in its storage version
+type Resource struct {
+	// name of the resource's CustomResourceDefinition (e.g. workloadprofiles.kube-balance.io)
+	CRDName string
+	// empty list of the resource in its storage version, used to list the objects to rewrite
+	List client.ObjectList
+}
+
+// rewrites every stored object of the given resources in their storage version and then drops the older versions from the
+// CRDs' stored versions, so that those versions can later be removed from the CRDs without losing data
+type StorageVersionMigrator struct {
+	Client client.Client
+	Log    logr.Logger
+	// version objects are rewritten in; must be the storage version of every resource
+	StorageVersion string
+	Resources      []Resource
+	// delay between attempts when a migration fails
+	RetryInterval time.Duration
+}
+
+// +kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions,verbs=get
+// +kubebuilder:rbac:groups="apiextensions.k8s.io",resources=customresourcedefinitions/status,verbs=update
+// +kubebuilder:rbac:groups="kube-balance.io",resources=workloadprofiles;namespacedworkloadprofiles,verbs=update
+
+// implements the manager.Runnable interface to migrate the resources, retrying until every one succeeds or the context is cancelled
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	pending := m.Resources
+	for {
+		remaining := []Resource{}
+		for _, res := range pending {
+			if err := m.migrate(ctx, res); err != nil {
+				m.Log.Error(err, "storage version migration failed, will retry", "crd", res.CRDName, "retryInterval", m.RetryInterval)
+				remaining = append(remaining, res)
+			}
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		pending = remaining
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(m.RetryInterval):
+		}
+	}
+}
+
+// rewrites the objects of a single resource when its CRD still records versions other than the storage version
+func (m *StorageVersionMigrator) migrate(ctx context.Context, res Resource) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := m.Client.Get(ctx, client.ObjectKey{Name: res.CRDName}, crd); err != nil {
+		return fmt.Errorf("failed to get CRD %s: %w", res.CRDName, err)
+	}
+
+	storedVersions, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	if err != nil {
+		return fmt.Errorf("failed to read stored versions of CRD %s: %w", res.CRDName, err)
+	}
+	if len(storedVersions) == 1 && storedVersions[0] == m.StorageVersion {
+		m.Log.V(1).Info("no storage version migration needed", "crd", res.CRDName, "version", m.StorageVersion)
+		return nil
+	}
+
+	m.Log.Info("migrating stored objects to the storage version", "crd", res.CRDName, "storedVersions", storedVersions, "version", m.StorageVersion)
+
+	list := res.List.DeepCopyObject().(client.ObjectList)
+	if err := m.Client.List(ctx, list); err != nil {
+		return fmt.Errorf("failed to list objects of CRD %s: %w", res.CRDName, err)
+	}
+
+	// an unchanged update makes the API server re-encode the object in the storage version
+	migrated := 0
+	err = api_meta.EachListItem(list, func(obj runtime.Object) error {
+		if err := m.Client.Update(ctx, obj.(client.Object)); err != nil {
+			return fmt.Errorf("failed to rewrite %s: %w", client.ObjectKeyFromObject(obj.(client.Object)), err)
+		}
+		migrated++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := unstructured.SetNestedStringSlice(crd.Object, []string{m.StorageVersion}, "status", "storedVersions"); err != nil {
+		return fmt.Errorf("failed to set stored versions of CRD %s: %w", res.CRDName, err)
+	}
+	if err := m.Client.Status().Update(ctx, crd); err != nil {
+		return fmt.Errorf("failed to update stored versions of CRD %s: %w", res.CRDName, err)
+	}
+
+	m.Log.Info("storage version migration complete", "crd", res.CRDName, "objects", migrated, "version", m.StorageVersion)
+	return nil
+}
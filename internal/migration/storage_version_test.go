@@ -0,0 +1,91 @@
+package migration
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// returns the CustomResourceDefinition of WorkloadProfiles recording the given stored versions
+func profileCRD(storedVersions ...string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	crd.SetName("workloadprofiles.kube-balance.io")
+	versions := make([]interface{}, 0, len(storedVersions))
+	for _, version := range storedVersions {
+		versions = append(versions, version)
+	}
+	utilruntime.Must(unstructured.SetNestedSlice(crd.Object, versions, "status", "storedVersions"))
+	return crd
+}
+
+func TestStorageVersionMigratorRewritesStoredObjects(t *testing.T) {
+	tests := []struct {
+		name           string
+		storedVersions []string
+		wantRewrites   []string
+	}{
+		{name: "older version stored", storedVersions: []string{"v1alpha1", "v1beta1"}, wantRewrites: []string{"batch", "web"}},
+		{name: "already migrated", storedVersions: []string{"v1beta1"}, wantRewrites: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			utilruntime.Must(v1beta1.SchemeBuilder.AddToScheme(scheme))
+			crd := profileCRD(tt.storedVersions...)
+			rewrites := []string{}
+			cli := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(
+					crd,
+					&v1beta1.WorkloadProfile{ObjectMeta: meta.ObjectMeta{Name: "web"}},
+					&v1beta1.WorkloadProfile{ObjectMeta: meta.ObjectMeta{Name: "batch"}},
+				).
+				WithStatusSubresource(crd).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						if _, ok := obj.(*v1beta1.WorkloadProfile); ok {
+							rewrites = append(rewrites, obj.GetName())
+						}
+						return c.Update(ctx, obj, opts...)
+					},
+				}).
+				Build()
+
+			m := &StorageVersionMigrator{
+				Client:         cli,
+				Log:            logr.Discard(),
+				StorageVersion: "v1beta1",
+				Resources:      []Resource{{CRDName: "workloadprofiles.kube-balance.io", List: &v1beta1.WorkloadProfileList{}}},
+				RetryInterval:  time.Millisecond,
+			}
+			if err := m.Start(context.Background()); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			slices.Sort(rewrites)
+			if !slices.Equal(rewrites, tt.wantRewrites) {
+				t.Errorf("rewritten profiles = %v, want %v", rewrites, tt.wantRewrites)
+			}
+			got := profileCRD()
+			if err := cli.Get(context.Background(), client.ObjectKey{Name: crd.GetName()}, got); err != nil {
+				t.Fatal(err)
+			}
+			if versions, _, _ := unstructured.NestedStringSlice(got.Object, "status", "storedVersions"); !slices.Equal(versions, []string{"v1beta1"}) {
+				t.Errorf("stored versions = %v, want only the storage version", versions)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	cache "k8s.io/client-go/tools/cache"
+	controller_cache "sigs.k8s.io/controller-runtime/pkg/cache"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+)
+
+// default name of the RebalancePolicy the controller reads its configuration from
+const DefaultPolicyName = "default"
+
+// watches the RebalancePolicy custom resource and caches the one the controller is configured to use
+type RebalancePolicyWatcher struct {
+	Cache controller_cache.Cache
+	Log   logr.Logger
+	// name of the RebalancePolicy to use
+	PolicyName string
+
+	// protects the cached policy for concurrent access
+	policyMu sync.RWMutex
+	// cached policy; nil when the named policy doesn't exist
+	policy *api_v1.RebalancePolicy
+}
+
+// creates a new RebalancePolicyWatcher instance
+func NewRebalancePolicyWatcher(c controller_cache.Cache, policyName string, log logr.Logger) *RebalancePolicyWatcher {
+	return &RebalancePolicyWatcher{
+		Cache:      c,
+		Log:        log,
+		PolicyName: policyName,
+	}
+}
+
+// returns a copy of the currently cached policy, or nil when none exists
+func (w *RebalancePolicyWatcher) GetPolicy() *api_v1.RebalancePolicy {
+	w.policyMu.RLock()
+	defer w.policyMu.RUnlock()
+
+	return w.policy.DeepCopy()
+}
+
+// implements the manager.Runnable interface to set up an informer that watches RebalancePolicy custom resources and updates the cached policy
+func (w *RebalancePolicyWatcher) Start(ctx context.Context) error {
+	informer, err := w.Cache.GetInformer(ctx, &api_v1.RebalancePolicy{})
+	if err != nil {
+		return fmt.Errorf("failed to get RebalancePolicy informer: %v", err)
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.set(obj.(*api_v1.RebalancePolicy))
+		},
+		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+			w.set(newObj.(*api_v1.RebalancePolicy))
+		},
+		DeleteFunc: func(obj interface{}) {
+			rp, ok := obj.(*api_v1.RebalancePolicy)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					w.Log.Error(fmt.Errorf("error decoding object, invalid type"), "Failed to decode object for delete event")
+					return
+				}
+				rp, ok = tombstone.Obj.(*api_v1.RebalancePolicy)
+				if !ok {
+					w.Log.Error(fmt.Errorf("error decoding object tombstone, invalid type"), "Failed to decode object for delete event")
+					return
+				}
+			}
+			if rp.Name != w.PolicyName {
+				return
+			}
+			w.policyMu.Lock()
+			w.policy = nil
+			w.policyMu.Unlock()
+			w.Log.Info("rebalance policy deleted, falling back to command-line flags", "name", rp.Name)
+		},
+	})
+
+	w.Log.Info("RebalancePolicyWatcher is ready to receive events via the manager's cache", "policy", w.PolicyName)
+	return nil
+}
+
+// caches a policy if it is the one the controller is configured to use
+func (w *RebalancePolicyWatcher) set(rp *api_v1.RebalancePolicy) {
+	if rp.Name != w.PolicyName {
+		return
+	}
+	w.policyMu.Lock()
+	w.policy = rp.DeepCopy()
+	w.policyMu.Unlock()
+	w.Log.Info("loaded rebalance policy", "name", rp.Name, "generation", rp.Generation)
+}
@@ -0,0 +1,122 @@
+package profiles
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// maximum evaluation cost of a match expression, bounding the time spent matching a single pod
+const matchExpressionCostLimit = 100000
+
+// compiled CEL match expressions, shared by the profile watcher, which compiles the expressions of the profiles it caches, and the matching functions
+var matchExpressions = newExpressionCache()
+
+// caches compiled match expressions by their source
+type expressionCache struct {
+	mu       sync.RWMutex
+	env      *cel.Env
+	programs map[string]cel.Program
+	errs     map[string]error
+}
+
+// creates an empty expression cache whose expressions see the pod as "object"
+func newExpressionCache() *expressionCache {
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		panic(fmt.Sprintf("failed to create CEL environment: %v", err))
+	}
+	return &expressionCache{
+		env:      env,
+		programs: make(map[string]cel.Program),
+		errs:     make(map[string]error),
+	}
+}
+
+// returns the compiled program of an expression, compiling and caching it on first use
+func (c *expressionCache) program(expression string) (cel.Program, error) {
+	c.mu.RLock()
+	program, ok := c.programs[expression]
+	err := c.errs[expression]
+	c.mu.RUnlock()
+	if ok || err != nil {
+		return program, err
+	}
+
+	program, err = c.compile(expression)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.errs[expression] = err
+		return nil, err
+	}
+	c.programs[expression] = program
+	return program, nil
+}
+
+// compiles an expression that must evaluate to a bool
+func (c *expressionCache) compile(expression string) (cel.Program, error) {
+	ast, issues := c.env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid match expression: %w", issues.Err())
+	}
+	if outputType := ast.OutputType(); outputType != cel.BoolType && outputType != cel.DynType {
+		return nil, fmt.Errorf("match expression must evaluate to a bool, not %s", outputType)
+	}
+	program, err := c.env.Program(ast, cel.CostLimit(matchExpressionCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build match expression program: %w", err)
+	}
+	return program, nil
+}
+
+// drops the expressions not in use, keeping the cache bounded by the profiles currently cached
+func (c *expressionCache) retain(inUse map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for expression := range c.programs {
+		if !inUse[expression] {
+			delete(c.programs, expression)
+		}
+	}
+	for expression := range c.errs {
+		if !inUse[expression] {
+			delete(c.errs, expression)
+		}
+	}
+}
+
+// checks that a match expression compiles, reporting why it doesn't
+func ValidateMatchExpression(expression string) error {
+	if expression == "" {
+		return nil
+	}
+	_, err := matchExpressions.program(expression)
+	return err
+}
+
+// reports whether a match expression holds for the pod; an empty expression holds for every pod, while one that fails to compile or evaluate holds for none
+func expressionMatches(expression string, pod *core.Pod) bool {
+	if expression == "" {
+		return true
+	}
+	program, err := matchExpressions.program(expression)
+	if err != nil {
+		return false
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		return false
+	}
+	result, _, err := program.Eval(map[string]any{"object": object})
+	if err != nil {
+		return false
+	}
+	matched, ok := result.Value().(bool)
+	return ok && matched
+}
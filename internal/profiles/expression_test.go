@@ -0,0 +1,76 @@
+package profiles
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateMatchExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{name: "empty", expression: ""},
+		{name: "bool", expression: `object.metadata.labels["tier"] == "batch"`},
+		{name: "dynamic", expression: `object.spec.hostNetwork`},
+		{name: "syntax error", expression: `object.metadata.labels["tier"] ==`, wantErr: true},
+		{name: "undeclared variable", expression: `pod.metadata.name == "web"`, wantErr: true},
+		{name: "not a bool", expression: `1 + 2`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateMatchExpression(tt.expression); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMatchExpression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpressionMatches(t *testing.T) {
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: "trainer-0", Namespace: "ml", Labels: map[string]string{"tier": "batch"}},
+		Spec: core.PodSpec{
+			Containers: []core.Container{{Name: "trainer", Image: "registry.example.com/trainer:1.4"}, {Name: "log-shipper", Image: "fluent-bit:3.0"}},
+		},
+	}
+	tests := []struct {
+		name       string
+		expression string
+		want       bool
+	}{
+		{name: "empty", expression: "", want: true},
+		{name: "label", expression: `object.metadata.labels["tier"] == "batch"`, want: true},
+		{name: "label mismatch", expression: `object.metadata.labels["tier"] == "web"`},
+		{name: "containers", expression: `object.spec.containers.exists(c, c.image.startsWith("registry.example.com/"))`, want: true},
+		{name: "container count", expression: `size(object.spec.containers) > 2`},
+		// evaluation errors, such as a missing key, match no pod
+		{name: "missing annotation", expression: `object.metadata.annotations["team"] == "ml"`},
+		{name: "non-bool result", expression: `object.metadata.name`},
+		{name: "invalid", expression: `object.metadata.name ==`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expressionMatches(tt.expression, pod); got != tt.want {
+				t.Errorf("expressionMatches(%q) = %v, want %v", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpressionCacheRetain(t *testing.T) {
+	cache := newExpressionCache()
+	for _, expression := range []string{`object.spec.hostNetwork`, `object.metadata.name == "web"`, `1 + 2`} {
+		_, _ = cache.program(expression)
+	}
+	cache.retain(map[string]bool{`object.spec.hostNetwork`: true})
+
+	if _, ok := cache.programs[`object.spec.hostNetwork`]; !ok || len(cache.programs) != 1 {
+		t.Errorf("cached programs = %v, want only the expression in use", cache.programs)
+	}
+	if len(cache.errs) != 0 {
+		t.Errorf("cached errors = %v, want none", cache.errs)
+	}
+}
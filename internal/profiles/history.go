@@ -0,0 +1,15 @@
+package profiles
+
+import (
+	"time"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// window over which the recent evictions reported on a profile's status are counted
+const EvictionHistoryWindow = 24 * time.Hour
+
+// identifies a profile across scopes, since a namespaced profile may share its name with a cluster-scoped one
+func Key(profile api_v1.WorkloadProfile) string {
+	return profile.Namespace + "/" + profile.Name
+}
@@ -0,0 +1,145 @@
+package profiles
+
+import (
+	"fmt"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// resolves the base profile chain of every profile, returning the effective profiles and the resolution error of each profile that failed, by profile key
+//
+// profiles whose chain cannot be resolved (a missing base or a cycle) are returned unchanged
+func resolveInheritance(cluster map[string]api_v1.WorkloadProfile, namespaced map[string]map[string]api_v1.WorkloadProfile) (map[string]api_v1.WorkloadProfile, map[string]map[string]api_v1.WorkloadProfile, map[string]error) {
+	errs := map[string]error{}
+
+	effectiveCluster := make(map[string]api_v1.WorkloadProfile, len(cluster))
+	for name, profile := range cluster {
+		resolved, err := resolveProfile(profile, cluster, nil)
+		if err != nil {
+			errs[Key(profile)] = err
+		}
+		effectiveCluster[name] = resolved
+	}
+
+	effectiveNamespaced := make(map[string]map[string]api_v1.WorkloadProfile, len(namespaced))
+	for namespace, nsProfiles := range namespaced {
+		effectiveNamespace := make(map[string]api_v1.WorkloadProfile, len(nsProfiles))
+		for name, profile := range nsProfiles {
+			resolved, err := resolveProfile(profile, cluster, nsProfiles)
+			if err != nil {
+				errs[Key(profile)] = err
+			}
+			effectiveNamespace[name] = resolved
+		}
+		effectiveNamespaced[namespace] = effectiveNamespace
+	}
+
+	return effectiveCluster, effectiveNamespaced, errs
+}
+
+// walks a profile's base chain, filling the fields the profile leaves unset from its nearest ancestor that sets them
+//
+// bases are looked up among the profiles of the profile's own namespace first, if any, and then among the cluster-scoped ones;
+// once the chain reaches a cluster-scoped profile it continues among cluster-scoped profiles only
+func resolveProfile(profile api_v1.WorkloadProfile, cluster map[string]api_v1.WorkloadProfile, namespace map[string]api_v1.WorkloadProfile) (api_v1.WorkloadProfile, error) {
+	resolved := *profile.DeepCopy()
+	visited := map[string]bool{Key(profile): true}
+
+	current := profile
+	for current.Spec.BaseProfile != "" {
+		var base api_v1.WorkloadProfile
+		found := false
+		// a namespaced profile naming itself as its base refers to the cluster-scoped profile of the same name
+		if current.Namespace != "" && current.Spec.BaseProfile != current.Name {
+			base, found = namespace[current.Spec.BaseProfile]
+		}
+		if !found {
+			base, found = cluster[current.Spec.BaseProfile]
+		}
+		if !found {
+			return profile, fmt.Errorf("base profile %q of %q not found", current.Spec.BaseProfile, current.Name)
+		}
+		if visited[Key(base)] {
+			return profile, fmt.Errorf("base profile chain of %q loops back to %q", profile.Name, base.Name)
+		}
+		visited[Key(base)] = true
+
+		inheritSpec(&resolved.Spec, &base.Spec)
+		current = base
+	}
+
+	return resolved, nil
+}
+
+// copies the resource and eviction settings a profile leaves unset from its base; selectors and the default flag describe the profile itself and are never inherited
+func inheritSpec(spec *api_v1.WorkloadProfileSpec, base *api_v1.WorkloadProfileSpec) {
+	if spec.Resources.CPU == nil && base.Resources.CPU != nil {
+		cpu := base.Resources.CPU.DeepCopy()
+		spec.Resources.CPU = &cpu
+	}
+	if spec.Resources.Memory == nil && base.Resources.Memory != nil {
+		memory := base.Resources.Memory.DeepCopy()
+		spec.Resources.Memory = &memory
+	}
+
+	// containers are inherited by name, a profile's own recommendation for a container replacing the base's entirely
+	for name, container := range base.Resources.Containers {
+		if _, ok := spec.Resources.Containers[name]; ok {
+			continue
+		}
+		if spec.Resources.Containers == nil {
+			spec.Resources.Containers = make(map[string]api_v1.ContainerRecommendation)
+		}
+		spec.Resources.Containers[name] = *container.DeepCopy()
+	}
+
+	if spec.Resources.QOSClass == "" {
+		spec.Resources.QOSClass = base.Resources.QOSClass
+	}
+
+	eviction := base.Eviction.DeepCopy()
+	if spec.Eviction.Priority == nil {
+		spec.Eviction.Priority = eviction.Priority
+	}
+	if spec.Eviction.GracePeriodSeconds == nil {
+		spec.Eviction.GracePeriodSeconds = eviction.GracePeriodSeconds
+	}
+	if spec.Eviction.MaxConcurrent == nil {
+		spec.Eviction.MaxConcurrent = eviction.MaxConcurrent
+	}
+	if spec.Eviction.MinPodAge == nil {
+		spec.Eviction.MinPodAge = eviction.MinPodAge
+	}
+	if spec.Eviction.Protected == nil {
+		spec.Eviction.Protected = eviction.Protected
+	}
+	if spec.Eviction.EvictLocalStorage == nil {
+		spec.Eviction.EvictLocalStorage = eviction.EvictLocalStorage
+	}
+	if spec.Eviction.EvictLastReplica == nil {
+		spec.Eviction.EvictLastReplica = eviction.EvictLastReplica
+	}
+	if spec.Eviction.PreEvictionHooks == nil {
+		spec.Eviction.PreEvictionHooks = eviction.PreEvictionHooks
+	}
+	if spec.Eviction.LeaderElection == nil {
+		spec.Eviction.LeaderElection = eviction.LeaderElection
+	}
+	if spec.Eviction.Strategy == "" {
+		spec.Eviction.Strategy = eviction.Strategy
+	}
+	if spec.Eviction.MaintenanceWindows == nil {
+		spec.Eviction.MaintenanceWindows = eviction.MaintenanceWindows
+	}
+
+	if spec.MinAvailable == nil && base.MinAvailable != nil {
+		minAvailable := *base.MinAvailable
+		spec.MinAvailable = &minAvailable
+	}
+	if spec.Rescheduling == nil {
+		spec.Rescheduling = base.Rescheduling.DeepCopy()
+	}
+	if spec.PriorityClass == nil {
+		spec.PriorityClass = base.PriorityClass.DeepCopy()
+	}
+}
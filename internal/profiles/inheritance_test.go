@@ -0,0 +1,98 @@
+package profiles
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// returns a profile in the given namespace, empty for cluster-scoped ones, inheriting from base
+func inheritingProfile(namespace string, name string, base string, priority int) api_v1.WorkloadProfile {
+	profile := api_v1.WorkloadProfile{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       api_v1.WorkloadProfileSpec{BaseProfile: base},
+	}
+	if priority > 0 {
+		profile.Spec.Eviction.Priority = &priority
+	}
+	return profile
+}
+
+func TestResolveProfile(t *testing.T) {
+	memory := resource.MustParse("256Mi")
+	root := inheritingProfile("", "root", "", 10)
+	root.Spec.Resources.Memory = &memory
+	cluster := map[string]api_v1.WorkloadProfile{
+		"root":   root,
+		"middle": inheritingProfile("", "middle", "root", 20),
+		"loop-a": inheritingProfile("", "loop-a", "loop-b", 0),
+		"loop-b": inheritingProfile("", "loop-b", "loop-a", 0),
+		"self":   inheritingProfile("", "self", "self", 0),
+		"orphan": inheritingProfile("", "orphan", "missing", 0),
+		"web":    inheritingProfile("", "web", "root", 0),
+	}
+	namespace := map[string]api_v1.WorkloadProfile{
+		"root":   inheritingProfile("team-a", "root", "", 30),
+		"local":  inheritingProfile("team-a", "local", "root", 0),
+		"shadow": inheritingProfile("team-a", "web", "web", 0),
+	}
+
+	tests := []struct {
+		name       string
+		profile    api_v1.WorkloadProfile
+		namespace  map[string]api_v1.WorkloadProfile
+		wantErr    string
+		wantPrio   int
+		wantMemory bool
+	}{
+		{name: "own setting wins over the base's", profile: cluster["middle"], wantPrio: 20, wantMemory: true},
+		{name: "inherits through a chain", profile: inheritingProfile("", "leaf", "middle", 0), wantPrio: 20, wantMemory: true},
+		{name: "no base", profile: root, wantPrio: 10, wantMemory: true},
+		{name: "cycle", profile: cluster["loop-a"], wantErr: "loops back", wantPrio: 50},
+		{name: "naming itself", profile: cluster["self"], wantErr: "loops back", wantPrio: 50},
+		{name: "missing base", profile: cluster["orphan"], wantErr: `"missing" of "orphan" not found`, wantPrio: 50},
+		{name: "namespaced base before the cluster's", profile: namespace["local"], namespace: namespace, wantPrio: 30},
+		{name: "namespaced profile naming itself inherits the cluster's", profile: namespace["shadow"], namespace: namespace, wantPrio: 10, wantMemory: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := resolveProfile(tt.profile, cluster, tt.namespace)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveProfile() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("resolveProfile() error = %v", err)
+			}
+			if got := resolved.Spec.Eviction.PriorityOrDefault(); got != tt.wantPrio {
+				t.Errorf("priority = %d, want %d", got, tt.wantPrio)
+			}
+			if got := resolved.Spec.Resources.Memory != nil; got != tt.wantMemory {
+				t.Errorf("memory inherited = %v, want %v", got, tt.wantMemory)
+			}
+			if resolved.Spec.BaseProfile != tt.profile.Spec.BaseProfile || resolved.Name != tt.profile.Name {
+				t.Errorf("resolved %s/%s with base %q, want the profile itself", resolved.Namespace, resolved.Name, resolved.Spec.BaseProfile)
+			}
+		})
+	}
+}
+
+func TestResolveProfileLeavesTheBaseUntouched(t *testing.T) {
+	memory := resource.MustParse("256Mi")
+	base := inheritingProfile("", "base", "", 10)
+	base.Spec.Resources.Memory = &memory
+	cluster := map[string]api_v1.WorkloadProfile{"base": base}
+
+	resolved, err := resolveProfile(inheritingProfile("", "child", "base", 0), cluster, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved.Spec.Resources.Memory.Add(resource.MustParse("1Gi"))
+	if got := cluster["base"].Spec.Resources.Memory.String(); got != "256Mi" {
+		t.Errorf("base memory = %s after changing the inherited copy, want 256Mi", got)
+	}
+}
@@ -0,0 +1,200 @@
+package profiles
+
+import (
+	"slices"
+	"sort"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
+)
+
+// label used to identify the workload type of a pod
+const WorkloadTypeLabel = "workload.k8s.io/type"
+
+// kinds of workload a profile can be restricted to through its target kinds
+const (
+	OwnerKindDeployment  = "Deployment"
+	OwnerKindStatefulSet = "StatefulSet"
+	OwnerKindReplicaSet  = "ReplicaSet"
+	OwnerKindJob         = "Job"
+	// pods without a controller
+	OwnerKindPod = "Pod"
+)
+
+// returns the profile governing a pod running on the given node; node may be nil for pods not yet scheduled
+//
+// profiles that don't target the kind of workload owning the pod, or whose match expression doesn't hold for it, never
+// apply; profiles whose node selector matches the pod's node take precedence over profiles without a node selector,
+// while profiles whose node selector doesn't match never apply; within each of the two groups, a profile named
+// by the pod's workload type label always wins, since the pod opted into it explicitly; otherwise, among the
+// profiles whose pod selector or match expression matches, the most specific one wins, ties go to the profile with
+// the lowest eviction priority (the least disruptive choice), and then to the profile name
+func MatchPod(pod *core.Pod, node *core.Node, profiles map[string]api_v1.WorkloadProfile) (api_v1.WorkloadProfile, bool) {
+	kind := OwnerKind(pod)
+	if profile, ok := matchPodInScope(pod, profiles, func(profile *api_v1.WorkloadProfile) bool {
+		return targetsKind(profile, kind) && nodeScoped(profile) && nodeSelectorMatches(profile.Spec.NodeSelector, node) && expressionMatches(profile.Spec.MatchExpression, pod)
+	}); ok {
+		return profile, true
+	}
+	return matchPodInScope(pod, profiles, func(profile *api_v1.WorkloadProfile) bool {
+		return targetsKind(profile, kind) && !nodeScoped(profile) && expressionMatches(profile.Spec.MatchExpression, pod)
+	})
+}
+
+// matches a pod against the profiles accepted by inScope
+func matchPodInScope(pod *core.Pod, profiles map[string]api_v1.WorkloadProfile, inScope func(*api_v1.WorkloadProfile) bool) (api_v1.WorkloadProfile, bool) {
+	if profile, ok := profiles[pod.Labels[WorkloadTypeLabel]]; ok && inScope(&profile) {
+		return profile, true
+	}
+
+	candidates := []api_v1.WorkloadProfile{}
+	for _, profile := range profiles {
+		if (selectorMatches(profile.Spec.PodSelector, pod) || selectsByExpression(&profile)) && inScope(&profile) {
+			candidates = append(candidates, profile)
+		}
+	}
+	if len(candidates) == 0 {
+		return api_v1.WorkloadProfile{}, false
+	}
+
+	sort.Slice(candidates, func(i int, j int) bool {
+		specificityA := matchSpecificity(&candidates[i])
+		specificityB := matchSpecificity(&candidates[j])
+		if specificityA != specificityB {
+			return specificityA > specificityB
+		}
+		priorityA := candidates[i].Spec.Eviction.PriorityOrDefault()
+		priorityB := candidates[j].Spec.Eviction.PriorityOrDefault()
+		if priorityA != priorityB {
+			return priorityA < priorityB
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return candidates[0], true
+}
+
+// returns the profile governing a pod running on the given node, resolving the profiles of the pod's namespace before the cluster-scoped ones
+//
+// a namespace profile that applies to the pod, whether by name or by selector, overrides any cluster profile;
+// pods matching no profile fall back to the namespace's default profile and then to the cluster's
+func MatchPodScoped(pod *core.Pod, node *core.Node, namespaced map[string]map[string]api_v1.WorkloadProfile, cluster map[string]api_v1.WorkloadProfile) (api_v1.WorkloadProfile, bool) {
+	if profile, ok := MatchPod(pod, node, namespaced[pod.Namespace]); ok {
+		return profile, true
+	}
+	if profile, ok := MatchPod(pod, node, cluster); ok {
+		return profile, true
+	}
+	if profile, ok := DefaultProfile(pod, node, namespaced[pod.Namespace]); ok {
+		return profile, true
+	}
+	return DefaultProfile(pod, node, cluster)
+}
+
+// returns the profile flagged as the default for a pod on the given node, among the profiles targeting the pod's kind of
+// workload; defaults scoped to the node take precedence over unscoped ones, and if several remain, the one with the
+// lowest eviction priority wins, then the profile name
+func DefaultProfile(pod *core.Pod, node *core.Node, profiles map[string]api_v1.WorkloadProfile) (api_v1.WorkloadProfile, bool) {
+	kind := OwnerKind(pod)
+	if profile, ok := defaultProfileInScope(profiles, func(profile *api_v1.WorkloadProfile) bool {
+		return targetsKind(profile, kind) && nodeScoped(profile) && nodeSelectorMatches(profile.Spec.NodeSelector, node) && expressionMatches(profile.Spec.MatchExpression, pod)
+	}); ok {
+		return profile, true
+	}
+	return defaultProfileInScope(profiles, func(profile *api_v1.WorkloadProfile) bool {
+		return targetsKind(profile, kind) && !nodeScoped(profile) && expressionMatches(profile.Spec.MatchExpression, pod)
+	})
+}
+
+// returns the default among the profiles accepted by inScope
+func defaultProfileInScope(profiles map[string]api_v1.WorkloadProfile, inScope func(*api_v1.WorkloadProfile) bool) (api_v1.WorkloadProfile, bool) {
+	var chosen api_v1.WorkloadProfile
+	found := false
+	for _, profile := range profiles {
+		if !profile.Spec.IsDefault || !inScope(&profile) {
+			continue
+		}
+		priority := profile.Spec.Eviction.PriorityOrDefault()
+		chosenPriority := chosen.Spec.Eviction.PriorityOrDefault()
+		if !found || priority < chosenPriority || (priority == chosenPriority && profile.Name < chosen.Name) {
+			chosen = profile
+			found = true
+		}
+	}
+	return chosen, found
+}
+
+// returns the kind of workload owning a pod, identified from its controller reference; ReplicaSets carrying the
+// pod-template-hash label are managed by a Deployment, and pods without a controller are reported as OwnerKindPod
+func OwnerKind(pod *core.Pod) string {
+	owner := meta.GetControllerOf(pod)
+	if owner == nil {
+		return OwnerKindPod
+	}
+	if owner.Kind == OwnerKindReplicaSet {
+		if _, ok := pod.Labels[apps.DefaultDeploymentUniqueLabelKey]; ok {
+			return OwnerKindDeployment
+		}
+	}
+	return owner.Kind
+}
+
+// reports whether a profile governs pods owned by the given kind of workload; profiles without target kinds govern every kind
+func targetsKind(profile *api_v1.WorkloadProfile, kind string) bool {
+	return len(profile.Spec.TargetKinds) == 0 || slices.Contains(profile.Spec.TargetKinds, kind)
+}
+
+// reports whether a profile is restricted to certain nodes; an empty node selector applies to every node
+func nodeScoped(profile *api_v1.WorkloadProfile) bool {
+	return selectorSpecificity(profile.Spec.NodeSelector) > 0
+}
+
+// reports whether a node selector matches the node; nothing matches a node that is unknown or a selector that can't be parsed
+func nodeSelectorMatches(selector *meta.LabelSelector, node *core.Node) bool {
+	if node == nil {
+		return false
+	}
+	s, err := meta.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set(node.Labels))
+}
+
+// reports whether a profile's pod selector matches the pod; a missing or empty selector matches nothing
+func selectorMatches(selector *meta.LabelSelector, pod *core.Pod) bool {
+	if selector == nil || selectorSpecificity(selector) == 0 {
+		return false
+	}
+	s, err := meta.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set(pod.Labels))
+}
+
+// reports whether a profile selects pods through its match expression alone, without a pod selector
+func selectsByExpression(profile *api_v1.WorkloadProfile) bool {
+	return profile.Spec.MatchExpression != "" && selectorSpecificity(profile.Spec.PodSelector) == 0
+}
+
+// counts the requirements a profile places on the pods it selects, its match expression counting as one
+func matchSpecificity(profile *api_v1.WorkloadProfile) int {
+	specificity := selectorSpecificity(profile.Spec.PodSelector)
+	if profile.Spec.MatchExpression != "" {
+		specificity++
+	}
+	return specificity
+}
+
+// counts the requirements of a selector, used to prefer narrower selectors over broader ones
+func selectorSpecificity(selector *meta.LabelSelector) int {
+	if selector == nil {
+		return 0
+	}
+	return len(selector.MatchLabels) + len(selector.MatchExpressions)
+}
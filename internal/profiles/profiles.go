@@ -10,7 +10,7 @@ import (
 	controller_cache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1alpha1"
+	api_v1 "github.com/lokeshllkumar/kube-balance/api/v1beta1"
 )
 
 // watches WorkloadProfile custom resources and maintains a cached map
@@ -23,6 +23,8 @@ type WorkloadProfileWatcher struct {
 	profilesMu sync.RWMutex
 	// cached map of workload profiles by name
 	profiles map[string]api_v1.WorkloadProfile
+	// cached namespace-scoped workload profiles, keyed by namespace and then by name
+	namespacedProfiles map[string]map[string]api_v1.WorkloadProfile
 }
 
 // creates a new WorkloadProfileWatcher instance
@@ -32,19 +34,36 @@ func NewWorkloadProfileWatcher(cli client.Client, c controller_cache.Cache, log
 		Cache:    c,
 		Log:      log,
 		profiles: make(map[string]api_v1.WorkloadProfile),
+
+		namespacedProfiles: make(map[string]map[string]api_v1.WorkloadProfile),
 	}
 }
 
-// returns the currently cached workload profiles
+// returns the currently cached workload profiles, with their base profiles resolved
 func (wpw *WorkloadProfileWatcher) GetProfiles() map[string]api_v1.WorkloadProfile {
 	wpw.profilesMu.RLock()
 	defer wpw.profilesMu.RUnlock()
 
-	copiedProfiles := make(map[string]api_v1.WorkloadProfile, len(wpw.profiles))
-	for k, v := range wpw.profiles {
-		copiedProfiles[k] = v
-	}
-	return copiedProfiles
+	effectiveProfiles, _, _ := resolveInheritance(wpw.profiles, nil)
+	return effectiveProfiles
+}
+
+// returns the currently cached namespace-scoped workload profiles, keyed by namespace and then by name, with their base profiles resolved
+func (wpw *WorkloadProfileWatcher) GetNamespacedProfiles() map[string]map[string]api_v1.WorkloadProfile {
+	wpw.profilesMu.RLock()
+	defer wpw.profilesMu.RUnlock()
+
+	_, effectiveNamespaced, _ := resolveInheritance(wpw.profiles, wpw.namespacedProfiles)
+	return effectiveNamespaced
+}
+
+// returns the errors of profiles whose base profile chain cannot be resolved, keyed by profile key
+func (wpw *WorkloadProfileWatcher) GetInheritanceErrors() map[string]error {
+	wpw.profilesMu.RLock()
+	defer wpw.profilesMu.RUnlock()
+
+	_, _, errs := resolveInheritance(wpw.profiles, wpw.namespacedProfiles)
+	return errs
 }
 
 // implements the manager.Runnable interface to set up an infromer that watches WorkloadProfile custom resources and update the local cache
@@ -59,6 +78,7 @@ func (wpw *WorkloadProfileWatcher) Start(ctx context.Context) error {
 			wp := obj.(*api_v1.WorkloadProfile)
 			wpw.profilesMu.Lock()
 			wpw.profiles[wp.Name] = *wp.DeepCopy()
+			wpw.compileExpressions()
 			wpw.profilesMu.Unlock()
 			wpw.Log.V(1).Info("added workload profile to cache", "name", wp.Name)
 		},
@@ -66,6 +86,7 @@ func (wpw *WorkloadProfileWatcher) Start(ctx context.Context) error {
 			wp := newObj.(*api_v1.WorkloadProfile)
 			wpw.profilesMu.Lock()
 			wpw.profiles[wp.Name] = *wp.DeepCopy()
+			wpw.compileExpressions()
 			wpw.profilesMu.Unlock()
 			wpw.Log.V(1).Info("updated workload profile in cache", "name", wp.Name)
 		},
@@ -85,11 +106,100 @@ func (wpw *WorkloadProfileWatcher) Start(ctx context.Context) error {
 			}
 			wpw.profilesMu.Lock()
 			delete(wpw.profiles, wp.Name)
+			wpw.compileExpressions()
 			wpw.profilesMu.Unlock()
 			wpw.Log.V(1).Info("deleted workload profile from cache", "name", wp.Name)
 		},
 	})
 
+	nsInformer, err := wpw.Cache.GetInformer(ctx, &api_v1.NamespacedWorkloadProfile{})
+	if err != nil {
+		return fmt.Errorf("failed to get NamespacedWorkloadProfile informer: %v", err)
+	}
+
+	nsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			wp := obj.(*api_v1.NamespacedWorkloadProfile)
+			wpw.storeNamespacedProfile(wp)
+			wpw.Log.V(1).Info("added namespaced workload profile to cache", "namespace", wp.Namespace, "name", wp.Name)
+		},
+		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+			wp := newObj.(*api_v1.NamespacedWorkloadProfile)
+			wpw.storeNamespacedProfile(wp)
+			wpw.Log.V(1).Info("updated namespaced workload profile in cache", "namespace", wp.Namespace, "name", wp.Name)
+		},
+		DeleteFunc: func(obj interface{}) {
+			wp, ok := obj.(*api_v1.NamespacedWorkloadProfile)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					wpw.Log.Error(fmt.Errorf("error decoding object, invalid type"), "Failed to decode object for delete event")
+					return
+				}
+				wp, ok = tombstone.Obj.(*api_v1.NamespacedWorkloadProfile)
+				if !ok {
+					wpw.Log.Error(fmt.Errorf("error decoding object tombstone, invalid type"), "Failed to decode object for delete event")
+					return
+				}
+			}
+			wpw.profilesMu.Lock()
+			delete(wpw.namespacedProfiles[wp.Namespace], wp.Name)
+			if len(wpw.namespacedProfiles[wp.Namespace]) == 0 {
+				delete(wpw.namespacedProfiles, wp.Namespace)
+			}
+			wpw.compileExpressions()
+			wpw.profilesMu.Unlock()
+			wpw.Log.V(1).Info("deleted namespaced workload profile from cache", "namespace", wp.Namespace, "name", wp.Name)
+		},
+	})
+
 	wpw.Log.Info("WorkloadProfileWatcher is ready to receive events via the manager's cache")
 	return nil
 }
+
+// the watcher only reads profiles, so it runs on every replica, keeping the profiles current for the webhooks they all serve
+func (wpw *WorkloadProfileWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// caches a namespace-scoped profile in the same shape as cluster-scoped ones so both can be matched alike
+func (wpw *WorkloadProfileWatcher) storeNamespacedProfile(nwp *api_v1.NamespacedWorkloadProfile) {
+	wp := api_v1.WorkloadProfile{
+		TypeMeta:   nwp.TypeMeta,
+		ObjectMeta: *nwp.ObjectMeta.DeepCopy(),
+		Spec:       *nwp.Spec.DeepCopy(),
+		Status:     nwp.Status,
+	}
+
+	wpw.profilesMu.Lock()
+	defer wpw.profilesMu.Unlock()
+	if wpw.namespacedProfiles[nwp.Namespace] == nil {
+		wpw.namespacedProfiles[nwp.Namespace] = make(map[string]api_v1.WorkloadProfile)
+	}
+	wpw.namespacedProfiles[nwp.Namespace][nwp.Name] = wp
+	wpw.compileExpressions()
+}
+
+// compiles the match expressions of the cached profiles ahead of matching and forgets those no longer used; must be called with profilesMu held
+func (wpw *WorkloadProfileWatcher) compileExpressions() {
+	inUse := map[string]bool{}
+	compile := func(profile api_v1.WorkloadProfile) {
+		expression := profile.Spec.MatchExpression
+		if expression == "" || inUse[expression] {
+			return
+		}
+		inUse[expression] = true
+		// expressions that fail to compile are reported on the profile's status
+		_ = ValidateMatchExpression(expression)
+	}
+
+	for _, profile := range wpw.profiles {
+		compile(profile)
+	}
+	for _, nsProfiles := range wpw.namespacedProfiles {
+		for _, profile := range nsProfiles {
+			compile(profile)
+		}
+	}
+	matchExpressions.retain(inUse)
+}
@@ -0,0 +1,96 @@
+package promquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// a single series of an instant vector query result
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// minimal client for the Prometheus HTTP query API
+type Client struct {
+	// base URL of the Prometheus server, e.g. http://prometheus.monitoring:9090
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// creates a new Client instance
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// response envelope of the /api/v1/query endpoint
+type queryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]any            `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// evaluates an instant query and returns the resulting vector
+func (c *Client) Query(ctx context.Context, query string) ([]Sample, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/query?%s", c.BaseURL, url.Values{"query": {query}}.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prometheus response: %w", err)
+	}
+
+	parsed := queryResponse{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode prometheus response (status %d): %w", resp.StatusCode, err)
+	}
+	if parsed.Status != "success" {
+		return nil, fmt.Errorf("prometheus query %q failed: %s: %s", query, parsed.ErrorType, parsed.Error)
+	}
+	if parsed.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("prometheus query %q returned %s, expected vector", query, parsed.Data.ResultType)
+	}
+
+	samples := make([]Sample, 0, len(parsed.Data.Result))
+	for _, result := range parsed.Data.Result {
+		valueStr, ok := result.Value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("prometheus query %q returned a malformed sample value", query)
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("prometheus query %q returned a non-numeric sample value: %w", query, err)
+		}
+		samples = append(samples, Sample{
+			Labels: result.Metric,
+			Value:  value,
+		})
+	}
+
+	return samples, nil
+}
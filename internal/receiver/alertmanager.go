@@ -0,0 +1,179 @@
+package receiver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// source recorded on nodes marked through the Alertmanager receiver
+const AlertmanagerSource = "alertmanager"
+
+// alert status reported by Alertmanager for alerts that are still active
+const alertStatusFiring = "firing"
+
+// subset of the Alertmanager webhook payload consumed by the receiver
+type alertmanagerPayload struct {
+	Version string              `json:"version"`
+	Status  string              `json:"status"`
+	Alerts  []alertmanagerAlert `json:"alerts"`
+}
+
+// single alert within an Alertmanager webhook payload
+type alertmanagerAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+// annotation recording, on a node, the alerts firing for it by fingerprint along with their reasons, so that every
+// replica serving the receiver, and any replica after a restart, knows which of them are still firing
+const FiringAlertsAnnotation = "kube-balance.io/firing-alerts"
+
+// alert recorded as firing on a node
+type firingAlert struct {
+	Reason string `json:"reason"`
+	// time of the last notification reporting the alert as firing
+	FiredAt time.Time `json:"firedAt"`
+}
+
+// records an alert transition on the node's firing alerts annotation and returns the reasons of the alerts still firing
+// for the node; the annotation is updated under optimistic locking, as notifications may reach several replicas at once
+//
+// alerts not reported as firing within the alert TTL are dropped, as their resolve notification may never arrive, be it
+// lost, not sent by the Alertmanager receiver or routed elsewhere
+func (s *Server) updateFiringAlerts(ctx context.Context, nodeName string, fingerprint string, reason string, firing bool, now time.Time) ([]string, error) {
+	var alerts map[string]firingAlert
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node := &core.Node{}
+		if err := s.Marker.Client.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+			return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+		}
+		alerts = map[string]firingAlert{}
+		if value, ok := node.Annotations[FiringAlertsAnnotation]; ok {
+			if err := json.Unmarshal([]byte(value), &alerts); err != nil {
+				s.Log.Error(err, "invalid firing alerts annotation on node, starting over", "node", nodeName)
+				alerts = map[string]firingAlert{}
+			}
+		}
+
+		changed := false
+		for key, alert := range alerts {
+			if s.Options.AlertTTL > 0 && now.Sub(alert.FiredAt) > s.Options.AlertTTL {
+				s.Log.Info("dropping alert no longer reported as firing", "node", nodeName, "reason", alert.Reason, "firedAt", alert.FiredAt)
+				delete(alerts, key)
+				changed = true
+			}
+		}
+
+		current, wasFiring := alerts[fingerprint]
+		switch {
+		// the time an alert fired at only matters when alerts expire
+		case firing && (!wasFiring || current.Reason != reason || s.Options.AlertTTL > 0):
+			alerts[fingerprint] = firingAlert{Reason: reason, FiredAt: now}
+			changed = true
+		case !firing && wasFiring:
+			delete(alerts, fingerprint)
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+
+		patch := client.MergeFromWithOptions(node.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if len(alerts) == 0 {
+			delete(node.Annotations, FiringAlertsAnnotation)
+		} else {
+			value, err := json.Marshal(alerts)
+			if err != nil {
+				return fmt.Errorf("failed to encode firing alerts: %w", err)
+			}
+			if node.Annotations == nil {
+				node.Annotations = make(map[string]string)
+			}
+			node.Annotations[FiringAlertsAnnotation] = string(value)
+		}
+		return s.Marker.Client.Patch(ctx, node, patch)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record firing alerts of node %s: %w", nodeName, err)
+	}
+
+	reasons := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		reasons = append(reasons, alert.Reason)
+	}
+	sort.Strings(reasons)
+	return reasons, nil
+}
+
+// maps firing Alertmanager alerts carrying a node label onto node degradation, and resolves it once the alerts clear
+func (s *Server) handleAlertmanager(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload := alertmanagerPayload{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestBytes)).Decode(&payload); err != nil {
+		http.Error(w, fmt.Sprintf("invalid alertmanager payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	failed := 0
+	for _, alert := range payload.Alerts {
+		nodeName := alert.Labels[s.Options.AlertNodeLabel]
+		if nodeName == "" {
+			s.Log.V(1).Info("ignoring alert without node label", "alertname", alert.Labels["alertname"], "label", s.Options.AlertNodeLabel)
+			continue
+		}
+
+		fingerprint := alert.Fingerprint
+		if fingerprint == "" {
+			fingerprint = alert.Labels["alertname"]
+		}
+		reason := alert.Labels["alertname"]
+		if summary := alert.Annotations["summary"]; summary != "" {
+			reason = fmt.Sprintf("%s: %s", reason, summary)
+		}
+
+		reasons, err := s.updateFiringAlerts(req.Context(), nodeName, fingerprint, reason, alert.Status == alertStatusFiring, time.Now())
+		if err != nil {
+			s.Log.Error(err, "failed to apply alert to node degradation", "node", nodeName, "alertname", alert.Labels["alertname"], "status", alert.Status)
+			failed++
+			continue
+		}
+		if len(reasons) > 0 {
+			err = s.Marker.Mark(req.Context(), nodeName, degradation.Marking{
+				Source: AlertmanagerSource,
+				Reason: strings.Join(reasons, "; "),
+				TTL:    s.Options.AlertTTL,
+			})
+		} else {
+			err = s.Marker.Unmark(req.Context(), nodeName, AlertmanagerSource)
+		}
+		if err != nil {
+			s.Log.Error(err, "failed to apply alert to node degradation", "node", nodeName, "alertname", alert.Labels["alertname"], "status", alert.Status)
+			failed++
+		}
+	}
+
+	// a non-2xx response makes Alertmanager retry the notification
+	if failed > 0 {
+		http.Error(w, fmt.Sprintf("failed to apply %d alert(s)", failed), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
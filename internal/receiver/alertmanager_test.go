@@ -0,0 +1,137 @@
+package receiver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// sends an Alertmanager notification to the receiver and returns its response
+func notify(s *Server, method string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/alertmanager", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// returns an Alertmanager notification of a single alert
+func alertNotification(status string, fingerprint string, labels string, summary string) string {
+	return `{"version": "4", "status": "` + status + `", "alerts": [{"status": "` + status + `", "fingerprint": "` + fingerprint + `", "labels": ` + labels + `, "annotations": {"summary": "` + summary + `"}}]}`
+}
+
+func TestHandleAlertmanagerTracksFiringAlerts(t *testing.T) {
+	s, cli := testServer(t, "node-a")
+	steps := []struct {
+		name       string
+		body       string
+		wantReason string
+	}{
+		{
+			name:       "first alert fires",
+			body:       alertNotification("firing", "a1", `{"alertname": "NodeDiskFailing", "node": "node-a"}`, "disk sdb failing"),
+			wantReason: "NodeDiskFailing: disk sdb failing",
+		},
+		{
+			name:       "second alert fires",
+			body:       alertNotification("firing", "b1", `{"alertname": "NodeNICErrors", "node": "node-a"}`, ""),
+			wantReason: "NodeDiskFailing: disk sdb failing; NodeNICErrors",
+		},
+		{
+			name:       "first alert resolves",
+			body:       alertNotification("resolved", "a1", `{"alertname": "NodeDiskFailing", "node": "node-a"}`, "disk sdb failing"),
+			wantReason: "NodeNICErrors",
+		},
+		{
+			name:       "notification repeated",
+			body:       alertNotification("resolved", "a1", `{"alertname": "NodeDiskFailing", "node": "node-a"}`, "disk sdb failing"),
+			wantReason: "NodeNICErrors",
+		},
+		{
+			name: "second alert resolves",
+			body: alertNotification("resolved", "b1", `{"alertname": "NodeNICErrors", "node": "node-a"}`, ""),
+		},
+	}
+	for _, step := range steps {
+		if rec := notify(s, http.MethodPost, step.body); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d (%s)", step.name, rec.Code, http.StatusOK, rec.Body.String())
+		}
+		node := &core.Node{}
+		if err := cli.Get(context.Background(), client.ObjectKey{Name: "node-a"}, node); err != nil {
+			t.Fatal(err)
+		}
+		reason := ""
+		if node.Annotations[degradation.SourceAnnotation] == AlertmanagerSource {
+			reason = node.Annotations[degradation.ReasonAnnotation]
+		}
+		if reason != step.wantReason {
+			t.Errorf("%s: reason = %q, want %q", step.name, reason, step.wantReason)
+		}
+		if _, ok := node.Annotations[FiringAlertsAnnotation]; ok != (step.wantReason != "") {
+			t.Errorf("%s: firing alerts annotation present = %v, want %v", step.name, ok, step.wantReason != "")
+		}
+	}
+}
+
+func TestHandleAlertmanager(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{
+		{name: "alert without node label", method: http.MethodPost, body: alertNotification("firing", "c1", `{"alertname": "Watchdog"}`, ""), wantStatus: http.StatusOK},
+		{name: "unknown node", method: http.MethodPost, body: alertNotification("firing", "d1", `{"alertname": "NodeDown", "node": "node-z"}`, ""), wantStatus: http.StatusInternalServerError},
+		{name: "invalid payload", method: http.MethodPost, body: `{"alerts": "firing"}`, wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := testServer(t, "node-a")
+			if rec := notify(s, tt.method, tt.body); rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleAlertmanagerDropsAlertsWhoseResolveNeverArrives(t *testing.T) {
+	s, cli := testServer(t, "node-a")
+	s.Options.AlertTTL = time.Hour
+	// fired two hours ago and never notified again
+	if _, err := s.updateFiringAlerts(context.Background(), "node-a", "a1", "NodeDiskFailing", true, time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := notify(s, http.MethodPost, alertNotification("firing", "b1", `{"alertname": "NodeNICErrors", "node": "node-a"}`, "")); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (%s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	node := &core.Node{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Name: "node-a"}, node); err != nil {
+		t.Fatal(err)
+	}
+	if reason := node.Annotations[degradation.ReasonAnnotation]; reason != "NodeNICErrors" {
+		t.Errorf("reason = %q, want only the alert still firing", reason)
+	}
+	if strings.Contains(node.Annotations[FiringAlertsAnnotation], "a1") {
+		t.Errorf("firing alerts = %s, want the expired alert dropped", node.Annotations[FiringAlertsAnnotation])
+	}
+
+	if rec := notify(s, http.MethodPost, alertNotification("resolved", "b1", `{"alertname": "NodeNICErrors", "node": "node-a"}`, "")); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (%s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if err := cli.Get(context.Background(), client.ObjectKey{Name: "node-a"}, node); err != nil {
+		t.Fatal(err)
+	}
+	if source := node.Annotations[degradation.SourceAnnotation]; source == AlertmanagerSource {
+		t.Errorf("node still marked by Alertmanager once its last alert resolved")
+	}
+}
@@ -0,0 +1,237 @@
+package receiver
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// source recorded on nodes marked through the HTTP receiver
+const WebhookSource = "webhook"
+
+// maximum accepted request body size
+const maxRequestBytes = 1 << 20
+
+// configures the degradation receiver's listener and authentication
+type Options struct {
+	// address the receiver binds to
+	BindAddress string
+	// path to a file holding the bearer token clients must present
+	TokenFile string
+	// paths to the serving certificate and key; TLS is enabled when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+	// path to a CA bundle used to verify client certificates (mTLS)
+	ClientCAFile string
+	// accepts the bearer token over plain HTTP, where anyone on the network path can read it
+	Insecure bool
+	// Alertmanager label identifying the node an alert refers to
+	AlertNodeLabel string
+	// lifetime of markers set from firing alerts, and of the alerts recorded as firing on a node without being notified
+	// again, guarding against lost resolve notifications; zero disables expiry
+	AlertTTL time.Duration
+}
+
+// body accepted by the degradation endpoint
+type degradationRequest struct {
+	Node     string `json:"node"`
+	Degraded bool   `json:"degraded"`
+	Reason   string `json:"reason,omitempty"`
+	TTL      string `json:"ttl,omitempty"`
+}
+
+// HTTP server through which external systems can mark and unmark nodes as degraded
+type Server struct {
+	Options Options
+	Marker  *degradation.Marker
+	Log     logr.Logger
+
+	token []byte
+	mux   *http.ServeMux
+}
+
+// creates a new Server instance
+func NewServer(opts Options, marker *degradation.Marker, log logr.Logger) (*Server, error) {
+	s := &Server{
+		Options: opts,
+		Marker:  marker,
+		Log:     log,
+		mux:     http.NewServeMux(),
+	}
+
+	if opts.TokenFile != "" {
+		token, err := os.ReadFile(opts.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read receiver token file %s: %w", opts.TokenFile, err)
+		}
+		s.token = []byte(strings.TrimSpace(string(token)))
+		if len(s.token) == 0 {
+			return nil, fmt.Errorf("receiver token file %s is empty", opts.TokenFile)
+		}
+	}
+
+	if len(s.token) == 0 && opts.ClientCAFile == "" {
+		return nil, fmt.Errorf("degradation receiver requires a bearer token or a client CA for mTLS")
+	}
+	if opts.ClientCAFile != "" && (opts.TLSCertFile == "" || opts.TLSKeyFile == "") {
+		return nil, fmt.Errorf("mTLS requires a serving certificate and key")
+	}
+	if len(s.token) > 0 && (opts.TLSCertFile == "" || opts.TLSKeyFile == "") && !opts.Insecure {
+		return nil, fmt.Errorf("bearer token authentication requires a serving certificate and key, as the token would otherwise be sent in the clear, unless insecure serving is allowed")
+	}
+	if len(s.token) > 0 && opts.Insecure && (opts.TLSCertFile == "" || opts.TLSKeyFile == "") {
+		log.Info("degradation receiver accepts its bearer token over plain HTTP")
+	}
+
+	if s.Options.AlertNodeLabel == "" {
+		s.Options.AlertNodeLabel = "node"
+	}
+
+	s.mux.Handle("/degradation", s.authenticated(http.HandlerFunc(s.handleDegradation)))
+	s.mux.Handle("/alertmanager", s.authenticated(http.HandlerFunc(s.handleAlertmanager)))
+	return s, nil
+}
+
+// implements the manager.Runnable interface to serve requests until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.Options.BindAddress,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	listener, err := net.Listen("tcp", s.Options.BindAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Options.BindAddress, err)
+	}
+
+	if s.Options.TLSCertFile != "" && s.Options.TLSKeyFile != "" {
+		tlsConfig, err := s.tlsConfig()
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.Log.Error(err, "failed to shut down degradation receiver")
+		}
+	}()
+
+	s.Log.Info("starting degradation receiver", "address", s.Options.BindAddress)
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("degradation receiver failed: %w", err)
+	}
+	return nil
+}
+
+// receivers answer on every replica since marking nodes is idempotent
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// builds the TLS configuration, requiring verified client certificates when a client CA is configured
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(s.Options.TLSCertFile, s.Options.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load receiver serving certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if s.Options.ClientCAFile != "" {
+		caBundle, err := os.ReadFile(s.Options.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file %s: %w", s.Options.ClientCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", s.Options.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// wraps a handler with bearer token authentication; requests authenticated via mTLS are verified by the TLS listener
+func (s *Server) authenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(s.token) > 0 {
+			presented, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(presented), s.token) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// marks or unmarks a node as degraded
+func (s *Server) handleDegradation(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body := degradationRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestBytes)).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if body.Node == "" {
+		http.Error(w, "node is required", http.StatusBadRequest)
+		return
+	}
+
+	var ttl time.Duration
+	if body.TTL != "" {
+		parsed, err := time.ParseDuration(body.TTL)
+		if err != nil || parsed < 0 {
+			http.Error(w, fmt.Sprintf("invalid ttl %q", body.TTL), http.StatusBadRequest)
+			return
+		}
+		ttl = parsed
+	}
+
+	var err error
+	if body.Degraded {
+		err = s.Marker.Mark(req.Context(), body.Node, degradation.Marking{
+			Source: WebhookSource,
+			Reason: body.Reason,
+			TTL:    ttl,
+		})
+	} else {
+		err = s.Marker.Unmark(req.Context(), body.Node, WebhookSource)
+	}
+	if err != nil {
+		s.Log.Error(err, "failed to update node degradation", "node", body.Node, "degraded", body.Degraded)
+		http.Error(w, "failed to update node degradation", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
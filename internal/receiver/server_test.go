@@ -0,0 +1,105 @@
+package receiver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/lokeshllkumar/kube-balance/pkg/degradation"
+)
+
+// returns the path of a token file holding the given token
+func tokenFile(t *testing.T, token string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// returns a receiver accepting the token over plain HTTP, marking the given nodes through a fake client
+func testServer(t *testing.T, nodes ...string) (*Server, client.Client) {
+	t.Helper()
+	objs := []client.Object{}
+	for _, name := range nodes {
+		objs = append(objs, &core.Node{ObjectMeta: meta.ObjectMeta{Name: name}})
+	}
+	cli := fake.NewClientBuilder().WithScheme(clientscheme.Scheme).WithObjects(objs...).Build()
+	s, err := NewServer(Options{TokenFile: tokenFile(t, "secret"), Insecure: true}, degradation.NewMarker(cli, logr.Discard()), logr.Discard())
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	return s, cli
+}
+
+func TestNewServerRequiresTLSForBearerTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "no authentication", opts: Options{}, wantErr: true},
+		{name: "token over plain HTTP", opts: Options{TokenFile: "token"}, wantErr: true},
+		{name: "token over plain HTTP, allowed", opts: Options{TokenFile: "token", Insecure: true}},
+		{name: "token over TLS", opts: Options{TokenFile: "token", TLSCertFile: "tls.crt", TLSKeyFile: "tls.key"}},
+		{name: "client CA without serving certificate", opts: Options{ClientCAFile: "ca.crt"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts.TokenFile != "" {
+				tt.opts.TokenFile = tokenFile(t, "secret")
+			}
+			_, err := NewServer(tt.opts, nil, logr.Discard())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewServer() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleDegradation(t *testing.T) {
+	tests := []struct {
+		name         string
+		token        string
+		body         string
+		wantStatus   int
+		wantDegraded bool
+	}{
+		{name: "mark", token: "secret", body: `{"node": "node-a", "degraded": true, "reason": "disk", "ttl": "30m"}`, wantStatus: http.StatusNoContent, wantDegraded: true},
+		{name: "wrong token", token: "guess", body: `{"node": "node-a", "degraded": true}`, wantStatus: http.StatusUnauthorized},
+		{name: "missing node", token: "secret", body: `{"degraded": true}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid ttl", token: "secret", body: `{"node": "node-a", "degraded": true, "ttl": "soon"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, cli := testServer(t, "node-a")
+			req := httptest.NewRequest(http.MethodPost, "/degradation", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			s.mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			node := &core.Node{}
+			if err := cli.Get(context.Background(), client.ObjectKey{Name: "node-a"}, node); err != nil {
+				t.Fatal(err)
+			}
+			if got := node.Annotations[degradation.SourceAnnotation] == WebhookSource; got != tt.wantDegraded {
+				t.Errorf("marked by webhook = %v, want %v", got, tt.wantDegraded)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package degradation
+
+import (
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// annotation used to mark a node as degraded
+const DegradedAnnotation = "kube-balance.io/degraded-io"
+
+// annotation holding a human-readable reason for a node's degradation
+const ReasonAnnotation = "kube-balance.io/degraded-reason"
+
+// annotation identifying the source (webhook, detector, operator) that marked a node as degraded
+const SourceAnnotation = "kube-balance.io/degraded-source"
+
+// annotation holding, as a JSON object keyed by source, the marker each source holds on a node; the other annotations
+// describe the one in effect, so that a source unmarking a node leaves the markers of the others in place
+const SourcesAnnotation = "kube-balance.io/degraded-sources"
+
+// annotation holding the RFC3339 timestamp after which a node's degradation marker is no longer honoured
+const ExpiresAnnotation = "kube-balance.io/degraded-until"
+
+// annotation holding the severity of a node's degradation
+const SeverityAnnotation = "kube-balance.io/degraded-severity"
+
+// annotation naming the resource (e.g. nvidia.com/gpu) whose failure caused a node's degradation
+const ResourceAnnotation = "kube-balance.io/degraded-resource"
+
+// label the controller keeps on degraded nodes, so that workloads can be steered away from them through node affinity
+const DegradedNodeLabel = "kube-balance.io/degraded"
+
+// describes how urgently a degraded node must be evacuated
+type Severity string
+
+const (
+	// regular degradation, evacuated at the normal per-cycle eviction budget
+	SeverityNormal Severity = ""
+	// imminent node loss (e.g. a spot interruption), evacuated at a higher budget and without waiting for confirmation
+	SeverityUrgent Severity = "urgent"
+)
+
+// returns the severity recorded on a degraded node
+func NodeSeverity(node *core.Node) Severity {
+	return Severity(node.Annotations[SeverityAnnotation])
+}
+
+// returns the resource whose failure caused a node's degradation, if any
+func NodeDegradedResource(node *core.Node) core.ResourceName {
+	return core.ResourceName(node.Annotations[ResourceAnnotation])
+}
+
+// reports whether a node carries a degradation marker that has not yet expired
+func IsDegraded(node *core.Node, now time.Time) bool {
+	if _, ok := node.Annotations[DegradedAnnotation]; !ok {
+		return false
+	}
+	return !IsExpired(node, now)
+}
+
+// reports whether a node's degradation marker carries an expiry timestamp that has already passed
+func IsExpired(node *core.Node, now time.Time) bool {
+	expiresStr, ok := node.Annotations[ExpiresAnnotation]
+	if !ok {
+		return false
+	}
+	expires, err := time.Parse(time.RFC3339, expiresStr)
+	if err != nil {
+		return false
+	}
+	return !now.Before(expires)
+}
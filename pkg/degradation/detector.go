@@ -0,0 +1,88 @@
+package degradation
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+)
+
+// a single node health verdict produced by a detector
+type Finding struct {
+	Node     string
+	Degraded bool
+	Reason   string
+	Severity Severity
+	Resource core.ResourceName
+}
+
+// inspects some signal source and reports which nodes are degraded
+type Detector interface {
+	// identifies the detector; used as the degradation source recorded on nodes
+	Name() string
+	// returns a finding for each node the detector has an opinion on
+	Detect(ctx context.Context) ([]Finding, error)
+}
+
+// periodically runs a detector and applies its findings to nodes through a Marker
+type DetectorRunner struct {
+	Detector Detector
+	Marker   *Marker
+	Interval time.Duration
+	Log      logr.Logger
+}
+
+// creates a new DetectorRunner instance
+func NewDetectorRunner(detector Detector, marker *Marker, interval time.Duration, log logr.Logger) *DetectorRunner {
+	return &DetectorRunner{
+		Detector: detector,
+		Marker:   marker,
+		Interval: interval,
+		Log:      log,
+	}
+}
+
+// implements the manager.Runnable interface to run the detector until the context is cancelled
+func (r *DetectorRunner) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	r.Log.Info("starting degradation detector", "detector", r.Detector.Name(), "interval", r.Interval)
+	for {
+		r.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runs the detector once and marks or unmarks nodes based on its findings
+func (r *DetectorRunner) runOnce(ctx context.Context) {
+	findings, err := r.Detector.Detect(ctx)
+	if err != nil {
+		r.Log.Error(err, "degradation detector failed", "detector", r.Detector.Name())
+		return
+	}
+
+	for _, finding := range findings {
+		if finding.Degraded {
+			// markers are refreshed every run with a TTL spanning a few intervals, so they lapse on their own if the detector stops
+			err = r.Marker.Mark(ctx, finding.Node, Marking{
+				Source:   r.Detector.Name(),
+				Reason:   finding.Reason,
+				Severity: finding.Severity,
+				Resource: finding.Resource,
+				TTL:      3 * r.Interval,
+			})
+		} else {
+			err = r.Marker.Unmark(ctx, finding.Node, r.Detector.Name())
+		}
+		if err != nil {
+			r.Log.Error(err, "failed to apply degradation finding", "detector", r.Detector.Name(), "node", finding.Node, "degraded", finding.Degraded)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package degradation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// where on a node a degradation key is looked up
+type KeyKind string
+
+const (
+	KeyKindAnnotation KeyKind = "annotation"
+	KeyKindLabel      KeyKind = "label"
+)
+
+// a node annotation or label that marks a node as degraded, optionally only when it has a specific value
+type Key struct {
+	Kind KeyKind
+	Name string
+	// required value; empty matches any value
+	Value string
+}
+
+// renders the key in the same form accepted by ParseKeys
+func (k Key) String() string {
+	if k.Value == "" {
+		return fmt.Sprintf("%s:%s", k.Kind, k.Name)
+	}
+	return fmt.Sprintf("%s:%s=%s", k.Kind, k.Name, k.Value)
+}
+
+// reports whether a node carries the key
+func (k Key) Matches(node *core.Node) bool {
+	values := node.Annotations
+	if k.Kind == KeyKindLabel {
+		values = node.Labels
+	}
+	value, ok := values[k.Name]
+	if !ok {
+		return false
+	}
+	return k.Value == "" || value == k.Value
+}
+
+// parses a comma-separated list of keys of the form annotation:<name>[=<value>] or label:<name>[=<value>]
+func ParseKeys(value string) ([]Key, error) {
+	keys := []Key{}
+	for _, raw := range strings.Split(value, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		kind, rest, ok := strings.Cut(raw, ":")
+		if !ok {
+			return nil, fmt.Errorf("degradation key %q must be prefixed with annotation: or label:", raw)
+		}
+		key := Key{Kind: KeyKind(kind)}
+		if key.Kind != KeyKindAnnotation && key.Kind != KeyKindLabel {
+			return nil, fmt.Errorf("degradation key %q has an unknown kind %q", raw, kind)
+		}
+		key.Name, key.Value, _ = strings.Cut(rest, "=")
+		if key.Name == "" {
+			return nil, fmt.Errorf("degradation key %q has an empty name", raw)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// decides whether nodes are degraded based on kube-balance's own marker and any additional operator-configured keys
+type Classifier struct {
+	// additional annotations/labels set by existing fleet-health tooling
+	Keys []Key
+}
+
+// reports whether a node is degraded, returning the key that matched
+func (c *Classifier) IsDegraded(node *core.Node, now time.Time) (bool, string) {
+	// kube-balance's own marker is always honoured so that its receivers and detectors keep working
+	if IsDegraded(node, now) {
+		return true, fmt.Sprintf("%s:%s", KeyKindAnnotation, DegradedAnnotation)
+	}
+	for _, key := range c.Keys {
+		if key.Matches(node) {
+			return true, key.String()
+		}
+	}
+	return false, ""
+}
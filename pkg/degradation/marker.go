@@ -0,0 +1,235 @@
+package degradation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defines an object to mark and unmark nodes as degraded on behalf of a degradation source
+type Marker struct {
+	Client client.Client
+	Log    logr.Logger
+}
+
+// describes a degradation marker applied to a node
+type Marking struct {
+	// identifies who marked the node; only the same source may unmark it
+	Source string
+	// human-readable explanation of the degradation
+	Reason string
+	// how urgently the node must be evacuated
+	Severity Severity
+	// resource whose failure caused the degradation; pods consuming it are evicted first
+	Resource core.ResourceName
+	// lifetime of the marker; zero keeps it until explicitly removed
+	TTL time.Duration
+}
+
+// marker a single source holds on a node, as recorded in the sources annotation
+type sourceMarker struct {
+	Reason   string            `json:"reason,omitempty"`
+	Severity Severity          `json:"severity,omitempty"`
+	Resource core.ResourceName `json:"resource,omitempty"`
+	// RFC3339 timestamp after which the marker lapses
+	Expires string `json:"expires,omitempty"`
+}
+
+// creates a new Marker instance
+func NewMarker(cli client.Client, log logr.Logger) *Marker {
+	return &Marker{
+		Client: cli,
+		Log:    log,
+	}
+}
+
+// records a source's marker on a node; a non-zero TTL makes the marker expire on its own. The marker takes effect
+// unless another source marked the node urgently degraded and this one isn't urgent, so that a second source can't
+// quietly downgrade, e.g., a spot interruption
+func (m *Marker) Mark(ctx context.Context, nodeName string, marking Marking) error {
+	marker := sourceMarker{
+		Reason:   marking.Reason,
+		Severity: marking.Severity,
+		Resource: marking.Resource,
+	}
+	if marking.TTL > 0 {
+		marker.Expires = time.Now().Add(marking.TTL).UTC().Format(time.RFC3339)
+	}
+
+	var effective string
+	err := m.update(ctx, nodeName, marking.Source, func(markers map[string]sourceMarker) bool {
+		markers[marking.Source] = marker
+		effective = effectiveSource(markers, marking.Source)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark node %s as degraded: %w", nodeName, err)
+	}
+
+	if effective != marking.Source {
+		m.Log.V(1).Info("node already marked urgently degraded by a different source, leaving its marker in effect", "node", nodeName, "source", marking.Source, "owner", effective)
+	}
+	m.Log.Info("marked node as degraded", "node", nodeName, "source", marking.Source, "reason", marking.Reason, "severity", marking.Severity, "ttl", marking.TTL)
+	return nil
+}
+
+// removes a source's marker from a node, leaving those of the other sources in place
+func (m *Marker) Unmark(ctx context.Context, nodeName string, source string) error {
+	removed := false
+	err := m.update(ctx, nodeName, "", func(markers map[string]sourceMarker) bool {
+		_, removed = markers[source]
+		delete(markers, source)
+		return removed
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unmark degraded node %s: %w", nodeName, err)
+	}
+
+	if removed {
+		m.Log.Info("removed degradation marker from node", "node", nodeName, "source", source)
+	}
+	return nil
+}
+
+// applies a change to the markers held on a node, retrying on conflicts, and puts the preferred source's marker in
+// effect, keeping the one in effect when empty; the node is left untouched when the change reports that it changed nothing
+func (m *Marker) update(ctx context.Context, nodeName string, preferred string, change func(markers map[string]sourceMarker) bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node := &core.Node{}
+		if err := m.Client.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+			return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+		}
+
+		markers := nodeMarkers(node, time.Now())
+		if !change(markers) {
+			return nil
+		}
+
+		// the optimistic lock keeps the markers other sources record concurrently
+		patch := client.MergeFromWithOptions(node.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		annotations := node.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		if preferred == "" {
+			preferred = annotations[SourceAnnotation]
+		}
+		setMarkerAnnotations(annotations, markers, preferred)
+		node.SetAnnotations(annotations)
+		return m.Client.Patch(ctx, node, patch)
+	})
+}
+
+// returns the unexpired markers held on a node, by source; a node marked before markers were recorded per source holds
+// the single marker its other annotations describe
+func nodeMarkers(node *core.Node, now time.Time) map[string]sourceMarker {
+	markers := map[string]sourceMarker{}
+	if raw, ok := node.Annotations[SourcesAnnotation]; ok {
+		if err := json.Unmarshal([]byte(raw), &markers); err != nil {
+			markers = map[string]sourceMarker{}
+		}
+	} else if _, ok := node.Annotations[DegradedAnnotation]; ok {
+		markers[node.Annotations[SourceAnnotation]] = sourceMarker{
+			Reason:   node.Annotations[ReasonAnnotation],
+			Severity: NodeSeverity(node),
+			Resource: NodeDegradedResource(node),
+			Expires:  node.Annotations[ExpiresAnnotation],
+		}
+	}
+
+	for source, marker := range markers {
+		if marker.expired(now) {
+			delete(markers, source)
+		}
+	}
+	return markers
+}
+
+// reports whether a marker's expiry timestamp has already passed
+func (s sourceMarker) expired(now time.Time) bool {
+	if s.Expires == "" {
+		return false
+	}
+	expires, err := time.Parse(time.RFC3339, s.Expires)
+	if err != nil {
+		return false
+	}
+	return !now.Before(expires)
+}
+
+// returns the source whose marker takes effect: the preferred one, unless another source's marker is urgent and the
+// preferred one isn't, or it holds none; sources are otherwise taken in name order
+func effectiveSource(markers map[string]sourceMarker, preferred string) string {
+	sources := make([]string, 0, len(markers))
+	for source := range markers {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	if marker, ok := markers[preferred]; ok && marker.Severity == SeverityUrgent {
+		return preferred
+	}
+	for _, source := range sources {
+		if markers[source].Severity == SeverityUrgent {
+			return source
+		}
+	}
+	if _, ok := markers[preferred]; ok {
+		return preferred
+	}
+	if len(sources) == 0 {
+		return ""
+	}
+	return sources[0]
+}
+
+// writes the markers onto a node's annotations, putting the preferred source's in effect as effectiveSource allows; the
+// node is marked until the last of them lapses, so it only carries an expiry when every marker does
+func setMarkerAnnotations(annotations map[string]string, markers map[string]sourceMarker, preferred string) {
+	if len(markers) == 0 {
+		for _, key := range []string{DegradedAnnotation, ReasonAnnotation, SourceAnnotation, SourcesAnnotation, SeverityAnnotation, ResourceAnnotation, ExpiresAnnotation} {
+			delete(annotations, key)
+		}
+		return
+	}
+
+	raw, _ := json.Marshal(markers)
+	annotations[SourcesAnnotation] = string(raw)
+
+	source := effectiveSource(markers, preferred)
+	effective := markers[source]
+	annotations[DegradedAnnotation] = "true"
+	annotations[SourceAnnotation] = source
+	setOrDelete(annotations, ReasonAnnotation, effective.Reason)
+	setOrDelete(annotations, SeverityAnnotation, string(effective.Severity))
+	setOrDelete(annotations, ResourceAnnotation, string(effective.Resource))
+
+	expires := ""
+	for _, marker := range markers {
+		if marker.Expires == "" {
+			expires = ""
+			break
+		}
+		if marker.Expires > expires {
+			expires = marker.Expires
+		}
+	}
+	setOrDelete(annotations, ExpiresAnnotation, expires)
+}
+
+// sets an annotation, or deletes it when the value is empty
+func setOrDelete(annotations map[string]string, key string, value string) {
+	if value != "" {
+		annotations[key] = value
+	} else {
+		delete(annotations, key)
+	}
+}
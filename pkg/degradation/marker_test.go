@@ -0,0 +1,110 @@
+package degradation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// a mark or unmark issued by a source
+type markerStep struct {
+	source   string
+	mark     bool
+	severity Severity
+}
+
+func TestMarkerTracksEverySource(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []markerStep
+		// source in effect once the steps ran; empty when the node is no longer degraded
+		wantSource   string
+		wantSeverity Severity
+	}{
+		{
+			name:       "second source unmarking keeps the first one's marker",
+			steps:      []markerStep{{source: "webhook", mark: true}, {source: "alertmanager", mark: true}, {source: "alertmanager"}},
+			wantSource: "webhook",
+		},
+		{
+			name:       "first source unmarking hands over to the second",
+			steps:      []markerStep{{source: "webhook", mark: true}, {source: "alertmanager", mark: true}, {source: "webhook"}},
+			wantSource: "alertmanager",
+		},
+		{
+			name:  "every source unmarking clears the node",
+			steps: []markerStep{{source: "webhook", mark: true}, {source: "alertmanager", mark: true}, {source: "webhook"}, {source: "alertmanager"}},
+		},
+		{
+			name:         "urgent marker stays in effect over a normal one",
+			steps:        []markerStep{{source: "spot-interruption", mark: true, severity: SeverityUrgent}, {source: "webhook", mark: true}},
+			wantSource:   "spot-interruption",
+			wantSeverity: SeverityUrgent,
+		},
+		{
+			name:       "source that never marked the node changes nothing",
+			steps:      []markerStep{{source: "webhook", mark: true}, {source: "alertmanager"}},
+			wantSource: "webhook",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := fake.NewClientBuilder().WithScheme(clientscheme.Scheme).WithObjects(&core.Node{ObjectMeta: meta.ObjectMeta{Name: "node-a"}}).Build()
+			marker := NewMarker(cli, logr.Discard())
+			for _, step := range tt.steps {
+				var err error
+				if step.mark {
+					err = marker.Mark(context.Background(), "node-a", Marking{Source: step.source, Reason: step.source + " says so", Severity: step.severity})
+				} else {
+					err = marker.Unmark(context.Background(), "node-a", step.source)
+				}
+				if err != nil {
+					t.Fatalf("step %+v: %v", step, err)
+				}
+			}
+
+			node := &core.Node{}
+			if err := cli.Get(context.Background(), client.ObjectKey{Name: "node-a"}, node); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantSource == "" {
+				if IsDegraded(node, time.Now()) || node.Annotations[SourcesAnnotation] != "" {
+					t.Errorf("annotations = %v, want the node unmarked", node.Annotations)
+				}
+				return
+			}
+			if !IsDegraded(node, time.Now()) || node.Annotations[SourceAnnotation] != tt.wantSource || NodeSeverity(node) != tt.wantSeverity {
+				t.Errorf("annotations = %v, want the marker of %s in effect with severity %q", node.Annotations, tt.wantSource, tt.wantSeverity)
+			}
+			if got := node.Annotations[ReasonAnnotation]; got != tt.wantSource+" says so" {
+				t.Errorf("reason = %q, want the reason of %s", got, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestMarkerKeepsTheLatestExpiry(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(clientscheme.Scheme).WithObjects(&core.Node{ObjectMeta: meta.ObjectMeta{Name: "node-a"}}).Build()
+	marker := NewMarker(cli, logr.Discard())
+	if err := marker.Mark(context.Background(), "node-a", Marking{Source: "webhook", TTL: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if err := marker.Mark(context.Background(), "node-a", Marking{Source: "node-flapping", TTL: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+
+	node := &core.Node{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Name: "node-a"}, node); err != nil {
+		t.Fatal(err)
+	}
+	if IsExpired(node, time.Now().Add(30*time.Minute)) {
+		t.Errorf("node marker lapses with the shortest-lived source's, at %s", node.Annotations[ExpiresAnnotation])
+	}
+}
@@ -0,0 +1,110 @@
+package eviction
+
+import (
+	"errors"
+	"fmt"
+
+	policy "k8s.io/api/policy/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// why an eviction request failed, letting callers decide whether to retry, skip or alert
+type FailureReason string
+
+const (
+	// the eviction would violate a PodDisruptionBudget (429 with a disruption budget cause); retrying later may succeed
+	FailureBlockedByPDB FailureReason = "BlockedByPDB"
+	// the pod no longer exists
+	FailurePodNotFound FailureReason = "PodNotFound"
+	// the pod was deleted and recreated under the same name (e.g. by a StatefulSet), failing the eviction's UID precondition
+	FailurePodRecreated FailureReason = "PodRecreated"
+	// the eviction was refused for lack of permission or by admission (e.g. a terminating namespace); retrying won't help
+	FailureForbidden FailureReason = "Forbidden"
+	// the API server was unavailable, overloaded or rate limiting, or the request timed out; retrying later may succeed
+	FailureTransient FailureReason = "Transient"
+	// the eviction failed for any other reason
+	FailureUnknown FailureReason = "Unknown"
+)
+
+// failed eviction of a pod, classified by reason
+type Error struct {
+	Reason FailureReason
+	Pod    types.NamespacedName
+	// error returned by the API server
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("failed to create eviction for pod %s: %v", e.Pod, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// classifies an error returned for an eviction request
+func newError(pod types.NamespacedName, err error) *Error {
+	return &Error{Reason: classify(err), Pod: pod, Err: err}
+}
+
+// maps an API error onto a failure reason; a 429 without a disruption budget cause is the API server rate limiting,
+// and a conflict is the pod's UID precondition failing
+func classify(err error) FailureReason {
+	switch {
+	case api_errors.IsTooManyRequests(err) && api_errors.HasStatusCause(err, policy.DisruptionBudgetCause):
+		return FailureBlockedByPDB
+	case api_errors.IsNotFound(err):
+		return FailurePodNotFound
+	case api_errors.IsConflict(err):
+		return FailurePodRecreated
+	case api_errors.IsForbidden(err), api_errors.IsUnauthorized(err):
+		return FailureForbidden
+	case api_errors.IsTooManyRequests(err), api_errors.IsServerTimeout(err), api_errors.IsTimeout(err),
+		api_errors.IsServiceUnavailable(err), api_errors.IsInternalError(err), api_errors.IsUnexpectedServerError(err),
+		utilnet.IsConnectionRefused(err), utilnet.IsConnectionReset(err):
+		return FailureTransient
+	}
+	return FailureUnknown
+}
+
+// returns the reason an eviction failed; FailureUnknown for errors not returned by an Evictor
+func ReasonOf(err error) FailureReason {
+	var evictionErr *Error
+	if errors.As(err, &evictionErr) {
+		return evictionErr.Reason
+	}
+	return FailureUnknown
+}
+
+// reports whether an eviction was blocked by a PodDisruptionBudget
+func IsBlockedByPDB(err error) bool {
+	return ReasonOf(err) == FailureBlockedByPDB
+}
+
+// reports whether an eviction failed as the pod no longer exists
+func IsPodNotFound(err error) bool {
+	return ReasonOf(err) == FailurePodNotFound
+}
+
+// reports whether an eviction failed as the pod was recreated under the same name
+func IsPodRecreated(err error) bool {
+	return ReasonOf(err) == FailurePodRecreated
+}
+
+// reports whether an eviction was refused for lack of permission or by admission
+func IsForbidden(err error) bool {
+	return ReasonOf(err) == FailureForbidden
+}
+
+// reports whether an eviction failed on a transient API error
+func IsTransient(err error) bool {
+	return ReasonOf(err) == FailureTransient
+}
+
+// reports whether retrying a failed eviction later may succeed
+func IsRetriable(err error) bool {
+	reason := ReasonOf(err)
+	return reason == FailureBlockedByPDB || reason == FailureTransient
+}
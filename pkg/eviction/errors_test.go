@@ -0,0 +1,104 @@
+package eviction
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+
+	policy "k8s.io/api/policy/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// returns the error the API server returns for an eviction a PodDisruptionBudget blocks
+func disruptionBudgetError() error {
+	return &api_errors.StatusError{ErrStatus: meta.Status{
+		Status:  meta.StatusFailure,
+		Code:    http.StatusTooManyRequests,
+		Reason:  meta.StatusReasonTooManyRequests,
+		Message: "Cannot evict pod as it would violate the pod's disruption budget.",
+		Details: &meta.StatusDetails{
+			Causes: []meta.StatusCause{{Type: policy.DisruptionBudgetCause, Message: "The disruption budget web needs 2 healthy pods and has 2 currently"}},
+		},
+	}}
+}
+
+func TestClassify(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	tests := []struct {
+		name string
+		err  error
+		want FailureReason
+	}{
+		{"disruption budget", disruptionBudgetError(), FailureBlockedByPDB},
+		{"not found", api_errors.NewNotFound(pods, "web-0"), FailurePodNotFound},
+		{"uid precondition", api_errors.NewConflict(pods, "web-0", errors.New("precondition failed: UID in precondition")), FailurePodRecreated},
+		{"forbidden", api_errors.NewForbidden(pods, "web-0", errors.New("namespace is terminating")), FailureForbidden},
+		{"unauthorized", api_errors.NewUnauthorized("token expired"), FailureForbidden},
+		{"api server rate limit", api_errors.NewTooManyRequests("too many requests", 1), FailureTransient},
+		{"server timeout", api_errors.NewServerTimeout(pods, "create", 1), FailureTransient},
+		{"timeout", api_errors.NewTimeoutError("request timed out", 1), FailureTransient},
+		{"service unavailable", api_errors.NewServiceUnavailable("etcd unavailable"), FailureTransient},
+		{"internal error", api_errors.NewInternalError(errors.New("boom")), FailureTransient},
+		{"connection refused", fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED), FailureTransient},
+		{"connection reset", fmt.Errorf("read tcp: %w", syscall.ECONNRESET), FailureTransient},
+		{"bad request", api_errors.NewBadRequest("invalid eviction"), FailureUnknown},
+		{"other", errors.New("something else"), FailureUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classify(tt.err); got != tt.want {
+				t.Errorf("classify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReasonOf(t *testing.T) {
+	pod := types.NamespacedName{Namespace: "default", Name: "web-0"}
+	blocked := newError(pod, disruptionBudgetError())
+
+	if got := ReasonOf(blocked); got != FailureBlockedByPDB {
+		t.Errorf("ReasonOf() = %s, want %s", got, FailureBlockedByPDB)
+	}
+	// the reason survives the error being wrapped by its callers
+	if got := ReasonOf(fmt.Errorf("failed to evict: %w", blocked)); got != FailureBlockedByPDB {
+		t.Errorf("ReasonOf() of a wrapped error = %s, want %s", got, FailureBlockedByPDB)
+	}
+	// errors not returned by an Evictor aren't classified, even API errors
+	if got := ReasonOf(disruptionBudgetError()); got != FailureUnknown {
+		t.Errorf("ReasonOf() of an API error = %s, want %s", got, FailureUnknown)
+	}
+	if got := ReasonOf(nil); got != FailureUnknown {
+		t.Errorf("ReasonOf(nil) = %s, want %s", got, FailureUnknown)
+	}
+	if !errors.Is(blocked, blocked.Err) {
+		t.Error("eviction error doesn't unwrap to the API error")
+	}
+}
+
+func TestIsRetriable(t *testing.T) {
+	pod := types.NamespacedName{Namespace: "default", Name: "web-0"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"disruption budget", newError(pod, disruptionBudgetError()), true},
+		{"transient", newError(pod, api_errors.NewServiceUnavailable("etcd unavailable")), true},
+		{"not found", newError(pod, api_errors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web-0")), false},
+		{"forbidden", newError(pod, api_errors.NewForbidden(schema.GroupResource{Resource: "pods"}, "web-0", errors.New("denied"))), false},
+		{"unclassified", errors.New("something else"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetriable(tt.err); got != tt.want {
+				t.Errorf("IsRetriable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
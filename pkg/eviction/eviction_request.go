@@ -0,0 +1,145 @@
+package eviction
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups="coordination.k8s.io",resources=evictionrequests,verbs=get;create;update
+
+// kind of the upstream declarative eviction API (KEP-4563), served by clusters enabling it; there is no typed client for
+// it yet, so EvictionRequests are handled as unstructured objects
+var EvictionRequestGVK = schema.GroupVersionKind{Group: "coordination.k8s.io", Version: "v1alpha1", Kind: "EvictionRequest"}
+
+// name identifying the controller among the requesters of an EvictionRequest, unless configured otherwise
+const DefaultRequester = "kube-balance.io"
+
+var _ Evictor = &EvictionRequestEvictor{}
+
+// evicts pods by creating EvictionRequests, through which workloads that coordinate their own evacuation (e.g. by
+// handing off their data first) take part in their pods' eviction instead of having them evicted outright; the
+// evacuation, and the grace period granted to the pod, are then left to the workload's evacuators and the eviction API
+type EvictionRequestEvictor struct {
+	Client client.Client
+	Log    logr.Logger
+	// name the controller requests evictions under, letting it share an EvictionRequest with other requesters
+	Requester string
+	// evictions sent at once by EvictPods; pods are evicted one after another when at most 1
+	Concurrency int
+}
+
+// creates a new EvictionRequestEvictor instance, requesting evictions as DefaultRequester and sending DefaultConcurrency
+// evictions at once
+func NewEvictionRequestEvictor(cli client.Client, log logr.Logger, concurrency int) *EvictionRequestEvictor {
+	return &EvictionRequestEvictor{
+		Client:      cli,
+		Log:         log,
+		Requester:   DefaultRequester,
+		Concurrency: concurrency,
+	}
+}
+
+// requests the eviction of a pod by creating an EvictionRequest for it, or by joining the requesters of an existing one;
+// the request is pinned to the pod's UID, so that a pod recreated under the same name is left alone
+func (e *EvictionRequestEvictor) EvictPod(ctx context.Context, pod *core.Pod, opts EvictOptions) error {
+	podName := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	e.Log.Info("requesting eviction of pod", "pod", pod.Name, "namespace", pod.Namespace, "node", pod.Spec.NodeName, "requester", e.Requester, "dryRun", opts.DryRun)
+
+	createOpts := []client.CreateOption{}
+	if opts.DryRun {
+		createOpts = append(createOpts, client.DryRunAll)
+	}
+	err := e.Client.Create(ctx, e.newEvictionRequest(pod), createOpts...)
+	if api_errors.IsAlreadyExists(err) {
+		err = e.joinEvictionRequest(ctx, pod, opts.DryRun)
+	}
+	if err != nil {
+		return newError(podName, err)
+	}
+
+	if opts.DryRun {
+		e.Log.Info("dry-run eviction request admitted for pod", "pod", pod.Name, "namespace", pod.Namespace)
+		return nil
+	}
+	e.Log.Info("eviction request created for pod", "pod", pod.Name, "namespace", pod.Namespace)
+	return nil
+}
+
+// requests the evictions of the pods through a pool of Concurrency workers; pods not yet requested when the context is
+// cancelled fail with the context's error
+func (e *EvictionRequestEvictor) EvictPods(ctx context.Context, pods []*core.Pod, opts EvictOptions) []Result {
+	return evictConcurrently(ctx, pods, e.Concurrency, func(pod *core.Pod) error {
+		return e.EvictPod(ctx, pod, opts)
+	})
+}
+
+// creates the EvictionRequest of a pod as a server-side dry run, which is admitted or rejected exactly like a real one
+// but leaves the pod running
+func (e *EvictionRequestEvictor) DryRun(ctx context.Context, pod *core.Pod, opts EvictOptions) error {
+	opts.DryRun = true
+	return e.EvictPod(ctx, pod, opts)
+}
+
+// builds the EvictionRequest of a pod, named after it in its namespace, with the controller as its only requester
+func (e *EvictionRequestEvictor) newEvictionRequest(pod *core.Pod) *unstructured.Unstructured {
+	request := &unstructured.Unstructured{}
+	request.SetGroupVersionKind(EvictionRequestGVK)
+	request.SetName(pod.Name)
+	request.SetNamespace(pod.Namespace)
+	request.Object["spec"] = map[string]interface{}{
+		"target": map[string]interface{}{
+			"podRef": map[string]interface{}{
+				"name": pod.Name,
+				"uid":  string(pod.UID),
+			},
+		},
+		"requesters": []interface{}{
+			map[string]interface{}{"name": e.Requester},
+		},
+	}
+	return request
+}
+
+// adds the controller to the requesters of the pod's existing EvictionRequest; a request targeting an earlier pod of the
+// same name fails with a conflict, as an eviction whose UID precondition fails would
+func (e *EvictionRequestEvictor) joinEvictionRequest(ctx context.Context, pod *core.Pod, dryRun bool) error {
+	request := &unstructured.Unstructured{}
+	request.SetGroupVersionKind(EvictionRequestGVK)
+	if err := e.Client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, request); err != nil {
+		return err
+	}
+
+	uid, _, _ := unstructured.NestedString(request.Object, "spec", "target", "podRef", "uid")
+	if pod.UID != "" && uid != string(pod.UID) {
+		return api_errors.NewConflict(schema.GroupResource{Group: EvictionRequestGVK.Group, Resource: "evictionrequests"}, request.GetName(),
+			fmt.Errorf("eviction request targets pod %s, not %s", uid, pod.UID))
+	}
+
+	requesters, _, err := unstructured.NestedSlice(request.Object, "spec", "requesters")
+	if err != nil {
+		return fmt.Errorf("invalid requesters of eviction request %s: %w", request.GetName(), err)
+	}
+	for _, requester := range requesters {
+		if r, ok := requester.(map[string]interface{}); ok && r["name"] == e.Requester {
+			return nil
+		}
+	}
+	requesters = append(requesters, map[string]interface{}{"name": e.Requester})
+	if err := unstructured.SetNestedSlice(request.Object, requesters, "spec", "requesters"); err != nil {
+		return fmt.Errorf("failed to add requester to eviction request %s: %w", request.GetName(), err)
+	}
+
+	updateOpts := []client.UpdateOption{}
+	if dryRun {
+		updateOpts = append(updateOpts, client.DryRunAll)
+	}
+	return e.Client.Update(ctx, request, updateOpts...)
+}
@@ -2,50 +2,194 @@ package eviction
 
 import (
 	"context"
-	"fmt"
+	"sync"
 
 	"github.com/go-logr/logr"
 	core "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// defines an object to evict pods
-type Evictor struct {
+// grace period granted to evicted pods when neither the evictor nor the eviction sets one
+const DefaultGracePeriodSeconds int64 = 30
+
+// evictions sent at once when evicting several pods, unless configured otherwise
+const DefaultConcurrency = 5
+
+// evicts pods on behalf of the controller; implemented by APIEvictor against the K8s API server, by
+// EvictionRequestEvictor through the declarative eviction API and by FakeEvictor, which records calls, for exercising
+// rebalancing logic without an API server
+type Evictor interface {
+	// evicts a single pod; failures are returned as an *Error classifying why the eviction failed
+	EvictPod(ctx context.Context, pod *core.Pod, opts EvictOptions) error
+	// evicts several pods in parallel, attempting every pod even if some evictions fail; returns a result per pod, in the
+	// order of the pods
+	EvictPods(ctx context.Context, pods []*core.Pod, opts EvictOptions) []Result
+	// checks whether a pod could be evicted, running every admission check (e.g. PodDisruptionBudgets) without evicting it
+	DryRun(ctx context.Context, pod *core.Pod, opts EvictOptions) error
+}
+
+var _ Evictor = &APIEvictor{}
+
+// evicts pods through eviction requests to the K8s API server
+type APIEvictor struct {
 	Client client.Client
 	Log    logr.Logger
+	// seconds granted to evicted pods to terminate gracefully when the eviction sets none
+	DefaultGracePeriodSeconds int64
+	// evictions sent at once by EvictPods; pods are evicted one after another when at most 1
+	Concurrency int
 }
 
-// creates a new Evictor instance
-func NewEvictor(cli client.Client, log logr.Logger) *Evictor {
-	return &Evictor{
-		Client: cli,
-		Log:    log,
+// configures an APIEvictor on creation
+type Option func(*APIEvictor)
+
+// sets the grace period granted to evicted pods when the eviction sets none
+func WithDefaultGracePeriodSeconds(seconds int64) Option {
+	return func(e *APIEvictor) {
+		e.DefaultGracePeriodSeconds = seconds
 	}
 }
 
+// sets the number of evictions sent at once when evicting several pods
+func WithConcurrency(concurrency int) Option {
+	return func(e *APIEvictor) {
+		e.Concurrency = concurrency
+	}
+}
+
+// tunes a single eviction
+type EvictOptions struct {
+	// seconds granted to the pod to terminate gracefully, even when shorter than the pod's own
+	// terminationGracePeriodSeconds; when nil, the evictor's default is used, or the pod's own when that is longer
+	GracePeriodSeconds *int64
+	// sends the eviction as a server-side dry run, which is admitted or rejected exactly like a real one but leaves the pod running
+	DryRun bool
+}
+
+// outcome of the eviction of one of several pods
+type Result struct {
+	Pod types.NamespacedName
+	// error the eviction failed with, as an *Error; nil when the pod was evicted
+	Err error
+}
+
+// creates a new APIEvictor instance, granting evicted pods DefaultGracePeriodSeconds and sending DefaultConcurrency
+// evictions at once unless configured otherwise
+func NewEvictor(cli client.Client, log logr.Logger, opts ...Option) *APIEvictor {
+	e := &APIEvictor{
+		Client:                    cli,
+		Log:                       log,
+		DefaultGracePeriodSeconds: DefaultGracePeriodSeconds,
+		Concurrency:               DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
 // performs a soft eviction of a pod by gracefully terminating it via an eviction request to the K8s API server
-func (e *Evictor) EvictPod(ctx context.Context, pod *core.Pod) error {
+func (e *APIEvictor) EvictPod(ctx context.Context, pod *core.Pod, opts EvictOptions) error {
+	eviction := newEviction(pod, e.gracePeriodSeconds(pod, opts), opts.DryRun)
+	e.Log.Info("attempting to evict pod", "pod", pod.Name, "namespace", pod.Namespace, "node", pod.Spec.NodeName, "gracePeriodSeconds", *eviction.DeleteOptions.GracePeriodSeconds, "dryRun", opts.DryRun)
+
+	err := e.Client.SubResource("eviction").Create(ctx, pod, eviction)
+	if err != nil {
+		return newError(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, err)
+	}
+
+	if opts.DryRun {
+		e.Log.Info("dry-run eviction admitted for pod", "pod", pod.Name, "namespace", pod.Namespace)
+		return nil
+	}
+	e.Log.Info("eviction request sent for pod", "pod", pod.Name, "namespace", pod.Namespace)
+	return nil
+}
+
+// evicts the pods through a pool of Concurrency workers; pods not yet evicted when the context is cancelled fail with
+// the context's error
+func (e *APIEvictor) EvictPods(ctx context.Context, pods []*core.Pod, opts EvictOptions) []Result {
+	return evictConcurrently(ctx, pods, e.Concurrency, func(pod *core.Pod) error {
+		return e.EvictPod(ctx, pod, opts)
+	})
+}
+
+// evicts the pods through a pool of workers, returning a result per pod in the order of the pods; pods not yet evicted
+// when the context is cancelled fail with the context's error
+func evictConcurrently(ctx context.Context, pods []*core.Pod, concurrency int, evict func(pod *core.Pod) error) []Result {
+	results := make([]Result, len(pods))
+	workers := min(max(concurrency, 1), len(pods))
+
+	// each worker writes the results of the pods it takes, so the results need no locking
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				pod := pods[i]
+				results[i].Pod = types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+				if err := ctx.Err(); err != nil {
+					results[i].Err = newError(results[i].Pod, err)
+					continue
+				}
+				results[i].Err = evict(pod)
+			}
+		}()
+	}
+	for i := range pods {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// sends a server-side dry-run eviction request, which is admitted or rejected exactly like a real one but leaves the pod running
+func (e *APIEvictor) DryRun(ctx context.Context, pod *core.Pod, opts EvictOptions) error {
+	opts.DryRun = true
+	return e.EvictPod(ctx, pod, opts)
+}
+
+// returns the grace period of an eviction: the one requested, which is authoritative, or else the evictor's default,
+// extended to the pod's own terminationGracePeriodSeconds when that is longer, so that pods needing time to drain
+// aren't killed mid-drain; the API server defaults that field to 30 seconds, so extending a requested grace period
+// would keep any shorter one from taking effect
+func (e *APIEvictor) gracePeriodSeconds(pod *core.Pod, opts EvictOptions) int64 {
+	if opts.GracePeriodSeconds != nil {
+		return *opts.GracePeriodSeconds
+	}
+	gracePeriodSeconds := e.DefaultGracePeriodSeconds
+	if pod.Spec.TerminationGracePeriodSeconds != nil && *pod.Spec.TerminationGracePeriodSeconds > gracePeriodSeconds {
+		gracePeriodSeconds = *pod.Spec.TerminationGracePeriodSeconds
+	}
+	return gracePeriodSeconds
+}
+
+// builds the eviction request of a pod, failing with a conflict if the pod was recreated under the same name
+func newEviction(pod *core.Pod, gracePeriodSeconds int64, dryRun bool) *policy.Eviction {
 	eviction := &policy.Eviction{
 		ObjectMeta: meta.ObjectMeta{
 			Name:      pod.Name,
 			Namespace: pod.Namespace,
 		},
 		DeleteOptions: &meta.DeleteOptions{
-			GracePeriodSeconds: func(i int64) *int64 {
-				return &i
-			}(30),
+			GracePeriodSeconds: &gracePeriodSeconds,
 		},
 	}
-
-	e.Log.Info("attempting to evict pod", "pod", pod.Name, "namespace", pod.Namespace, "node", pod.Spec.NodeName)
-
-	err := e.Client.SubResource("eviction").Create(ctx, pod, eviction)
-	if err != nil {
-		return fmt.Errorf("failed to create eviction for pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	// pinning the eviction to the pod it was meant for, as a pod recreated under the same name (e.g. by a StatefulSet)
+	// between listing and evicting must be left alone
+	if pod.UID != "" {
+		uid := pod.UID
+		eviction.DeleteOptions.Preconditions = &meta.Preconditions{UID: &uid}
 	}
-
-	e.Log.Info("eviction request sent for pod", "pod", pod.Name, "namespace", pod.Namespace)
-	return nil
+	if dryRun {
+		eviction.DeleteOptions.DryRun = []string{meta.DryRunAll}
+	}
+	return eviction
 }
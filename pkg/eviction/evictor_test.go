@@ -0,0 +1,36 @@
+package eviction
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
+)
+
+func TestGracePeriodSeconds(t *testing.T) {
+	seconds := func(s int64) *int64 { return &s }
+	tests := []struct {
+		name string
+		// grace period requested by the pod's profile, if any
+		requested *int64
+		// terminationGracePeriodSeconds of the pod, which the API server defaults to 30
+		pod  *int64
+		want int64
+	}{
+		{name: "profile shorter than the pod's", requested: seconds(5), pod: seconds(30), want: 5},
+		{name: "profile longer than the pod's", requested: seconds(120), pod: seconds(30), want: 120},
+		{name: "profile of zero", requested: seconds(0), pod: seconds(30), want: 0},
+		{name: "default", pod: seconds(10), want: 45},
+		{name: "default with a long pod grace period", pod: seconds(600), want: 600},
+		{name: "default without a pod grace period", want: 45},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEvictor(nil, logr.Discard(), WithDefaultGracePeriodSeconds(45))
+			pod := &core.Pod{Spec: core.PodSpec{TerminationGracePeriodSeconds: tt.pod}}
+			if got := e.gracePeriodSeconds(pod, EvictOptions{GracePeriodSeconds: tt.requested}); got != tt.want {
+				t.Errorf("gracePeriodSeconds() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
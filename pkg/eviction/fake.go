@@ -0,0 +1,108 @@
+package eviction
+
+import (
+	"context"
+	"sync"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// methods of the Evictor interface, as recorded by FakeEvictor
+const (
+	MethodEvictPod = "EvictPod"
+	MethodDryRun   = "DryRun"
+)
+
+// a call made to a FakeEvictor
+type EvictorCall struct {
+	// Evictor method called; evicting several pods at once is recorded as one EvictPod call per pod
+	Method string
+	Pod    types.NamespacedName
+	Opts   EvictOptions
+	// error the call returned
+	Err error
+}
+
+var _ Evictor = &FakeEvictor{}
+
+// in-memory Evictor recording every call instead of sending eviction requests, for exercising rebalancing logic without
+// a live API server; safe for concurrent use
+type FakeEvictor struct {
+	mu    sync.Mutex
+	calls []EvictorCall
+
+	// error returned for a call, if any; every call succeeds when nil
+	Err func(method string, pod *core.Pod) error
+}
+
+// records the eviction of a pod, returning the configured error
+func (f *FakeEvictor) EvictPod(_ context.Context, pod *core.Pod, opts EvictOptions) error {
+	return f.record(MethodEvictPod, pod, opts)
+}
+
+// records the eviction of every pod in order, returning the configured errors as the pods' results
+func (f *FakeEvictor) EvictPods(ctx context.Context, pods []*core.Pod, opts EvictOptions) []Result {
+	results := make([]Result, len(pods))
+	for i, pod := range pods {
+		results[i] = Result{
+			Pod: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
+			Err: f.EvictPod(ctx, pod, opts),
+		}
+	}
+	return results
+}
+
+// records a dry-run eviction of a pod, returning the configured error
+func (f *FakeEvictor) DryRun(_ context.Context, pod *core.Pod, opts EvictOptions) error {
+	opts.DryRun = true
+	return f.record(MethodDryRun, pod, opts)
+}
+
+// returns the calls recorded so far, in the order they were made
+func (f *FakeEvictor) Calls() []EvictorCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]EvictorCall(nil), f.calls...)
+}
+
+// returns the pods evicted successfully so far, in the order they were evicted; dry-run evictions leave pods running and are not included
+func (f *FakeEvictor) Evicted() []types.NamespacedName {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	evicted := []types.NamespacedName{}
+	for _, call := range f.calls {
+		if call.Method == MethodEvictPod && !call.Opts.DryRun && call.Err == nil {
+			evicted = append(evicted, call.Pod)
+		}
+	}
+	return evicted
+}
+
+// forgets the calls recorded so far
+func (f *FakeEvictor) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = nil
+}
+
+// records a call along with the configured error, which it returns
+func (f *FakeEvictor) record(method string, pod *core.Pod, opts EvictOptions) error {
+	var err error
+	if f.Err != nil {
+		err = f.Err(method, pod)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, EvictorCall{
+		Method: method,
+		Pod:    types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
+		Opts:   opts,
+		Err:    err,
+	})
+	return err
+}